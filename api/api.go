@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"runtime"
 	"time"
 
@@ -25,8 +26,11 @@ import (
 	"github.com/prometheus/alertmanager/cluster"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/provider"
 	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -43,6 +47,7 @@ type API struct {
 	concurrencyLimitExceeded prometheus.Counter
 	timeout                  time.Duration
 	inFlightSem              chan struct{}
+	readOnly                 bool
 }
 
 // Options for the creation of an API object. Alerts, Silences, and StatusFunc
@@ -52,6 +57,9 @@ type Options struct {
 	Alerts provider.Alerts
 	// Silences to be used by the API. Mandatory.
 	Silences *silence.Silences
+	// NotificationLog of the notification pipeline, exposed by APIv1. If
+	// nil, querying it fails.
+	NotificationLog *nflog.Log
 	// StatusFunc is used be the API to retrieve the AlertStatus of an
 	// alert. Mandatory.
 	StatusFunc func(model.Fingerprint) types.AlertStatus
@@ -74,6 +82,61 @@ type Options struct {
 	// according to the current active configuration. Alerts returned are
 	// filtered by the arguments provided to the function.
 	GroupFunc func(func(*dispatch.Route) bool, func(*types.Alert, time.Time) bool) (dispatch.AlertGroups, map[model.Fingerprint][]string)
+	// ReadOnly makes all APIs reject requests that may change state with
+	// status code 403, while queries are served normally.
+	ReadOnly bool
+	// MaxSilenceStartSkew is the maximum time new silences created through
+	// APIv1 may start in the past. Their start time is set to now, while
+	// silences starting earlier are rejected. The zero value disables the
+	// check.
+	MaxSilenceStartSkew time.Duration
+	// AlertStaleThreshold is the time after which alerts listed by APIv1
+	// that weren't updated are marked as stale. The zero value disables
+	// the marking.
+	AlertStaleThreshold time.Duration
+	// SeverityLabel is the label by which the APIv1 status summarizes the
+	// current alerts. If empty, the severity label is used.
+	SeverityLabel model.LabelName
+	// SilenceCommentPattern is the pattern the comments of silences created
+	// through APIv1 must match. If nil, comments aren't checked.
+	SilenceCommentPattern *regexp.Regexp
+	// EnableSilenceExpireAll enables the APIv1 endpoint expiring all
+	// silences at once.
+	EnableSilenceExpireAll bool
+	// Throttles is the rate limiting of notifications exposed by APIv1.
+	Throttles *notify.Throttles
+	// DispatchStatsFunc returns the aggregation groups of the dispatcher
+	// exposed by APIv1. If nil, APIv1 doesn't expose them.
+	DispatchStatsFunc func() []dispatch.GroupStats
+	// HeavyReadConcurrency is the maximum number of concurrent APIv1
+	// requests listing or counting alerts, silences or notifications. The
+	// zero value (and negative values) disable the limit.
+	HeavyReadConcurrency int
+	// PartialAlerts makes APIv1 respond with the alerts listed so far and
+	// a warning if iterating the alert store fails, rather than an error.
+	PartialAlerts bool
+	// StartupGracePeriod is the time after startup during which alerts
+	// received through APIv1 aren't resolved by the resolve timeout. The
+	// zero value disables the grace period.
+	StartupGracePeriod time.Duration
+	// EnableTemplateRendering enables the APIv1 endpoint rendering
+	// arbitrary templates with the current notification templates.
+	EnableTemplateRendering bool
+	// RedactInternalErrors makes APIv1 respond to internal errors with a
+	// generic message and the ID of the request, under which the full
+	// error is logged.
+	RedactInternalErrors bool
+	// ReceiverBuilder builds the integrations of receivers validated or
+	// tested through APIv1. If nil, receivers can be neither validated nor
+	// tested.
+	ReceiverBuilder apiv1.ReceiverBuilder
+	// AlertMutators transform the alerts received through APIv1 in order
+	// before their resolve timeout is set and they are validated and stored.
+	AlertMutators []apiv1.AlertMutator
+	// GzipMinSize is the size in bytes from which APIv1 responses are
+	// compressed for clients accepting gzip. The zero value keeps the
+	// default of 1KiB and negative values disable the compression.
+	GzipMinSize int
 }
 
 func (o Options) validate() error {
@@ -117,6 +180,23 @@ func New(opts Options) (*API, error) {
 		opts.Peer,
 		log.With(l, "version", "v1"),
 		opts.Registry,
+		apiv1.WithReadOnly(opts.ReadOnly),
+		apiv1.WithNotificationLog(opts.NotificationLog),
+		apiv1.WithMaxSilenceStartSkew(opts.MaxSilenceStartSkew),
+		apiv1.WithStaleThreshold(opts.AlertStaleThreshold),
+		apiv1.WithSeverityLabel(opts.SeverityLabel),
+		apiv1.WithThrottles(opts.Throttles),
+		apiv1.WithDispatchStats(opts.DispatchStatsFunc),
+		apiv1.WithSilenceExpireAll(opts.EnableSilenceExpireAll),
+		apiv1.WithSilenceCommentPattern(opts.SilenceCommentPattern),
+		apiv1.WithHeavyReadConcurrency(opts.HeavyReadConcurrency),
+		apiv1.WithPartialAlerts(opts.PartialAlerts),
+		apiv1.WithStartupGracePeriod(opts.StartupGracePeriod),
+		apiv1.WithTemplateRendering(opts.EnableTemplateRendering),
+		apiv1.WithRedactInternalErrors(opts.RedactInternalErrors),
+		apiv1.WithReceiverBuilder(opts.ReceiverBuilder),
+		apiv1.WithAlertMutators(opts.AlertMutators...),
+		apiv1.WithGzipMinSize(opts.GzipMinSize),
 	)
 
 	v2, err := apiv2.NewAPI(
@@ -161,6 +241,7 @@ func New(opts Options) (*API, error) {
 		concurrencyLimitExceeded: concurrencyLimitExceeded,
 		timeout:                  opts.Timeout,
 		inFlightSem:              make(chan struct{}, concurrency),
+		readOnly:                 opts.ReadOnly,
 	}, nil
 }
 
@@ -188,7 +269,7 @@ func (api *API) Register(r *route.Router, routePrefix string) *http.ServeMux {
 	// limitHandler below).
 	mux.Handle(
 		apiPrefix+"/api/v2/",
-		api.limitHandler(http.StripPrefix(apiPrefix+"/api/v2", api.v2.Handler)),
+		api.limitHandler(api.readOnlyHandler(http.StripPrefix(apiPrefix+"/api/v2", api.v2.Handler))),
 	)
 
 	return mux
@@ -201,6 +282,35 @@ func (api *API) Update(cfg *config.Config, setAlertStatus func(model.LabelSet))
 	api.v2.Update(cfg, setAlertStatus)
 }
 
+// Ready returns true once the API has a configuration to serve and the
+// cluster peer, if any, has settled.
+func (api *API) Ready() bool {
+	return api.v1.Ready()
+}
+
+// UpdateTemplate sets the notification templates of the current
+// configuration, used by APIv1 to render templates.
+func (api *API) UpdateTemplate(t *template.Template) {
+	api.v1.UpdateTemplate(t)
+}
+
+// readOnlyHandler rejects requests that may change state if the API is in
+// read-only mode. APIv1 checks its own endpoints, which are marked as mutating
+// when registered, to respond in its own format.
+func (api *API) readOnlyHandler(h http.Handler) http.Handler {
+	if !api.readOnly {
+		return h
+	}
+	return http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			h.ServeHTTP(rsp, req)
+		default:
+			http.Error(rsp, "The API is in read-only mode.\n", http.StatusForbidden)
+		}
+	})
+}
+
 func (api *API) limitHandler(h http.Handler) http.Handler {
 	concLimiter := http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
 		if req.Method == http.MethodGet { // Only limit concurrency of GETs.
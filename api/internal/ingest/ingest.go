@@ -0,0 +1,266 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ingest prepares the alerts received through the API for the alert
+// store, so that all API versions treat them alike.
+package ingest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/alertmanager/api/metrics"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/provider"
+	"github.com/prometheus/alertmanager/relabel"
+	"github.com/prometheus/alertmanager/store"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// maxRecentAlerts is the maximum number of recently received alerts held to
+// drop duplicate updates.
+const maxRecentAlerts = 100000
+
+// Ingester prepares the alerts received through one API version.
+type Ingester struct {
+	alerts provider.Alerts
+	m      *metrics.Alerts
+
+	// recent holds the recently received alerts to drop duplicate updates.
+	recent *store.Recent
+}
+
+// NewIngester returns an Ingester looking up the previous versions of the
+// received alerts in the given store and counting them in the given metrics.
+func NewIngester(alerts provider.Alerts, m *metrics.Alerts) *Ingester {
+	return &Ingester{
+		alerts: alerts,
+		m:      m,
+		recent: store.NewRecent(maxRecentAlerts),
+	}
+}
+
+// Options configure the ingestion beyond the Alertmanager configuration.
+type Options struct {
+	// Mutate transforms each alert before its resolve timeout is set. An
+	// error rejects the alert. If nil, alerts aren't transformed.
+	Mutate func(*types.Alert) error
+	// GraceEnd is the end of the startup grace period, before which alerts
+	// aren't resolved by the resolve timeout. The zero value disables the
+	// grace period.
+	GraceEnd time.Time
+}
+
+// Rejection is a received alert that was rejected along with the reason.
+type Rejection struct {
+	Alert *types.Alert
+	Err   error
+}
+
+// Ingest relabels, filters, completes and validates the received alerts
+// according to the configuration. It returns the alerts to store and the
+// rejected alerts. Alerts dropped by the relabeling, the ingestion filter or
+// the deduplication are in neither.
+func (in *Ingester) Ingest(alerts []*types.Alert, cfg *config.Config, opts Options, now time.Time) ([]*types.Alert, []Rejection) {
+	var (
+		globalConfig   = cfg.Global
+		resolveTimeout = time.Duration(globalConfig.ResolveTimeout)
+		dedupWindow    = time.Duration(globalConfig.AlertDedupWindow)
+		mergeStrategy  = globalConfig.AnnotationMergeStrategy
+	)
+
+	if len(cfg.AlertRelabelConfigs) > 0 {
+		alerts = relabelAlerts(alerts, cfg.AlertRelabelConfigs)
+	}
+	if cfg.IngestionFilter != nil {
+		// Filtered alerts are dropped silently, as they would be by the
+		// relabeling.
+		accepted := alerts[:0]
+		for _, a := range alerts {
+			if !cfg.IngestionFilter.Accepts(a.Labels) {
+				in.m.Filtered().Inc()
+				continue
+			}
+			accepted = append(accepted, a)
+		}
+		alerts = accepted
+	}
+
+	// Make a best effort to insert all alerts that are valid.
+	var (
+		validAlerts = make([]*types.Alert, 0, len(alerts))
+		rejected    []Rejection
+	)
+	reject := func(a *types.Alert, err error) {
+		rejected = append(rejected, Rejection{Alert: a, Err: err})
+		in.m.Invalid().Inc()
+	}
+
+	// The mutators run before the timeouts are set, so that the labels and
+	// times they set are taken into account.
+	if opts.Mutate != nil {
+		mutated := alerts[:0]
+		for _, a := range alerts {
+			if err := opts.Mutate(a); err != nil {
+				reject(a, err)
+				continue
+			}
+			mutated = append(mutated, a)
+		}
+		alerts = mutated
+	}
+
+	for _, alert := range alerts {
+		alert.UpdatedAt = now
+
+		// Ensure StartsAt is set.
+		if alert.StartsAt.IsZero() {
+			if alert.EndsAt.IsZero() {
+				alert.StartsAt = now
+			} else {
+				alert.StartsAt = alert.EndsAt
+			}
+		}
+		// If no end time is defined, set a timeout after which an alert
+		// is marked resolved if it is not updated. Alerts exempt from the
+		// timeout keep firing until they are explicitly resolved.
+		if alert.EndsAt.IsZero() && globalConfig.HasResolveTimeout(alert.Labels) {
+			alert.Timeout = true
+			alert.EndsAt = now.Add(resolveTimeout)
+			// The store may have been empty at startup, so sources might
+			// not have re-sent their alerts yet.
+			if alert.EndsAt.Before(opts.GraceEnd) {
+				alert.EndsAt = opts.GraceEnd
+			}
+		}
+	}
+
+	for _, a := range alerts {
+		removeEmptyLabels(a.Labels)
+		if n := truncateAnnotations(a, globalConfig); n > 0 {
+			in.m.TruncatedAnnotations().Add(float64(n))
+		}
+
+		// The stored alert is looked up once for all the checks against it.
+		prev, err := in.alerts.Get(a.Fingerprint())
+		if err != nil {
+			prev = nil
+		}
+
+		if a.EndsAt.IsZero() || a.EndsAt.After(now) {
+			in.m.Firing().Inc()
+		} else {
+			// Resolving an alert that is already resolved is a no-op.
+			if prev != nil && prev.ResolvedAt(now) {
+				continue
+			}
+			in.m.Resolved().Inc()
+		}
+
+		if err := a.Validate(); err != nil {
+			reject(a, err)
+			continue
+		}
+		if err := mergeAnnotations(a, prev, mergeStrategy, now); err != nil {
+			reject(a, err)
+			continue
+		}
+		if !a.ResolvedAt(now) {
+			if prev != nil && !prev.ResolvedAt(now) {
+				in.m.Refreshed().Inc()
+			} else {
+				in.m.FirstSeen().Inc()
+			}
+		}
+		if dedupWindow > 0 && in.recent.Duplicate(a, now, dedupWindow) {
+			in.m.Deduplicated().Inc()
+			continue
+		}
+		validAlerts = append(validAlerts, a)
+	}
+	return validAlerts, rejected
+}
+
+// mergeAnnotations combines the annotations of the alert with those of the
+// previous alert with the same fingerprint according to the strategy, if the
+// previous alert is still firing. The previous alert is nil if there is none.
+func mergeAnnotations(a, prev *types.Alert, strategy string, now time.Time) error {
+	if strategy != config.AnnotationMergeUnion && strategy != config.AnnotationMergeReject {
+		return nil
+	}
+	if prev == nil || prev.ResolvedAt(now) {
+		return nil
+	}
+
+	if strategy == config.AnnotationMergeReject {
+		for name, v := range a.Annotations {
+			if pv, ok := prev.Annotations[name]; ok && pv != v {
+				return fmt.Errorf("annotation %q of alert %s conflicts with the firing alert", name, a.Labels)
+			}
+		}
+		return nil
+	}
+	for name, v := range prev.Annotations {
+		if _, ok := a.Annotations[name]; ok {
+			continue
+		}
+		if a.Annotations == nil {
+			a.Annotations = model.LabelSet{}
+		}
+		a.Annotations[name] = v
+	}
+	return nil
+}
+
+// relabelAlerts applies the relabel configurations to the labels of the
+// alerts. Alerts whose label set is dropped are removed from the result.
+func relabelAlerts(alerts []*types.Alert, cfgs []*relabel.Config) []*types.Alert {
+	res := alerts[:0]
+	for _, a := range alerts {
+		ls := relabel.Process(a.Labels, cfgs...)
+		if ls == nil {
+			continue
+		}
+		a.Labels = ls
+		res = append(res, a)
+	}
+	return res
+}
+
+// truncateAnnotations truncates the annotation values of the alert that
+// exceed the configured limits. It returns the number of truncated values.
+func truncateAnnotations(a *types.Alert, cfg *config.GlobalConfig) int {
+	n := 0
+	for name, v := range a.Annotations {
+		limit := cfg.AnnotationLimit(string(name))
+		if limit <= 0 {
+			continue
+		}
+		if s, truncated := notify.Truncate(string(v), limit); truncated {
+			a.Annotations[name] = model.LabelValue(s)
+			n++
+		}
+	}
+	return n
+}
+
+func removeEmptyLabels(ls model.LabelSet) {
+	for k, v := range ls {
+		if string(v) == "" {
+			delete(ls, k)
+		}
+	}
+}
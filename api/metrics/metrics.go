@@ -20,6 +20,12 @@ type Alerts struct {
 	firing   prometheus.Counter
 	resolved prometheus.Counter
 	invalid  prometheus.Counter
+
+	truncatedAnnotations prometheus.Counter
+	deduplicated         prometheus.Counter
+	firstSeen            prometheus.Counter
+	refreshed            prometheus.Counter
+	filtered             prometheus.Counter
 }
 
 // NewAlerts returns an *Alerts struct for the given API version.
@@ -34,13 +40,38 @@ func NewAlerts(version string, r prometheus.Registerer) *Alerts {
 		Help:        "The total number of received alerts that were invalid.",
 		ConstLabels: prometheus.Labels{"version": version},
 	})
+	numTruncatedAnnotations := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "alertmanager_alerts_annotations_truncated_total",
+		Help:        "The total number of annotation values of received alerts that were truncated.",
+		ConstLabels: prometheus.Labels{"version": version},
+	})
+	numDeduplicatedAlerts := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "alertmanager_alerts_deduplicated_total",
+		Help:        "The total number of received alerts that were dropped as duplicates of recent updates.",
+		ConstLabels: prometheus.Labels{"version": version},
+	})
+	numFiringAlerts := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "alertmanager_alerts_firing_received_total",
+		Help:        "The total number of valid firing alerts received, by whether they were first seen or refreshed an alert that was still firing.",
+		ConstLabels: prometheus.Labels{"version": version},
+	}, []string{"kind"})
+	numFilteredAlerts := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "alertmanager_alerts_filtered_total",
+		Help:        "The total number of received alerts that were dropped by the ingestion filter.",
+		ConstLabels: prometheus.Labels{"version": version},
+	})
 	if r != nil {
-		r.MustRegister(numReceivedAlerts, numInvalidAlerts)
+		r.MustRegister(numReceivedAlerts, numInvalidAlerts, numTruncatedAnnotations, numDeduplicatedAlerts, numFiringAlerts, numFilteredAlerts)
 	}
 	return &Alerts{
-		firing:   numReceivedAlerts.WithLabelValues("firing"),
-		resolved: numReceivedAlerts.WithLabelValues("resolved"),
-		invalid:  numInvalidAlerts,
+		firing:               numReceivedAlerts.WithLabelValues("firing"),
+		resolved:             numReceivedAlerts.WithLabelValues("resolved"),
+		invalid:              numInvalidAlerts,
+		truncatedAnnotations: numTruncatedAnnotations,
+		deduplicated:         numDeduplicatedAlerts,
+		firstSeen:            numFiringAlerts.WithLabelValues("first_seen"),
+		refreshed:            numFiringAlerts.WithLabelValues("refresh"),
+		filtered:             numFilteredAlerts,
 	}
 }
 
@@ -52,3 +83,20 @@ func (a *Alerts) Resolved() prometheus.Counter { return a.resolved }
 
 // Invalid returns a counter of invalid alerts.
 func (a *Alerts) Invalid() prometheus.Counter { return a.invalid }
+
+// TruncatedAnnotations returns a counter of truncated annotation values.
+func (a *Alerts) TruncatedAnnotations() prometheus.Counter { return a.truncatedAnnotations }
+
+// Deduplicated returns a counter of alerts dropped as duplicates.
+func (a *Alerts) Deduplicated() prometheus.Counter { return a.deduplicated }
+
+// FirstSeen returns a counter of firing alerts without a firing alert of the
+// same fingerprint.
+func (a *Alerts) FirstSeen() prometheus.Counter { return a.firstSeen }
+
+// Refreshed returns a counter of firing alerts re-posted before the alert of
+// the same fingerprint was resolved.
+func (a *Alerts) Refreshed() prometheus.Counter { return a.refreshed }
+
+// Filtered returns a counter of alerts dropped by the ingestion filter.
+func (a *Alerts) Filtered() prometheus.Counter { return a.filtered }
@@ -14,12 +14,25 @@
 package v1
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,32 +43,173 @@ import (
 	"github.com/prometheus/common/route"
 	"github.com/prometheus/common/version"
 
+	"github.com/prometheus/alertmanager/api/internal/ingest"
 	"github.com/prometheus/alertmanager/api/metrics"
 	"github.com/prometheus/alertmanager/cluster"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/notify/email"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/provider"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/store"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 )
 
 var corsHeaders = map[string]string{
-	"Access-Control-Allow-Headers":  "Accept, Authorization, Content-Type, Origin",
-	"Access-Control-Allow-Methods":  "GET, POST, DELETE, OPTIONS",
+	"Access-Control-Allow-Headers":  "Accept, Authorization, Content-Type, Origin, " + requestIDHeader,
+	"Access-Control-Allow-Methods":  "GET, POST, PUT, DELETE, OPTIONS",
 	"Access-Control-Allow-Origin":   "*",
-	"Access-Control-Expose-Headers": "Date",
+	"Access-Control-Expose-Headers": "Date, " + requestIDHeader + ", " + serverTimingHeader + ", " + processingTimeHeader,
 	"Cache-Control":                 "no-cache, no-store, must-revalidate",
+	"Timing-Allow-Origin":           "*",
 }
 
 // Alert is the API representation of an alert, which is a regular alert
 // annotated with silencing and inhibition info.
 type Alert struct {
 	*model.Alert
-	Status      types.AlertStatus `json:"status"`
-	Receivers   []string          `json:"receivers"`
-	Fingerprint string            `json:"fingerprint"`
+	Status    types.AlertStatus `json:"status"`
+	Receivers []string          `json:"receivers"`
+	// PrimaryReceiver is the receiver of the first route matching the
+	// alert.
+	PrimaryReceiver string `json:"primaryReceiver,omitempty"`
+	Fingerprint     string `json:"fingerprint"`
+	// Stale is true if the alert wasn't updated for longer than the
+	// staleness threshold, which may indicate that its source is down.
+	Stale bool `json:"stale"`
+	// Silences are the silences of the alert, inlined if requested.
+	Silences []*alertSilence `json:"silences,omitempty"`
+}
+
+// alertSilence is a silence of an alert along with the matchers of the
+// silence that the labels of the alert satisfy.
+type alertSilence struct {
+	*types.Silence
+	MatchedMatchers labels.Matchers `json:"matchedMatchers"`
+}
+
+// requestIDHeader is the header correlating API requests with the errors
+// logged for them. The ID of a request is taken from the header or generated
+// if absent, and is echoed in the response.
+const requestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the maximum length of request IDs accepted from
+// clients. Longer IDs are replaced by a generated one.
+const maxRequestIDLength = 128
+
+// serverTimingHeader and processingTimeHeader report the time the handler
+// spent on a request until it started writing the response.
+const (
+	serverTimingHeader   = "Server-Timing"
+	processingTimeHeader = "X-Processing-Ms"
+)
+
+// requestWriter is the http.ResponseWriter passed to the API handlers. It
+// carries the details of the request logged along with API errors, and sets
+// the timing headers when the response is written.
+type requestWriter struct {
+	http.ResponseWriter
+	endpoint   string
+	remoteAddr string
+	requestID  string
+	// acceptsGzip is true if the client accepts gzip-encoded responses.
+	acceptsGzip bool
+
+	start       time.Time
+	wroteHeader bool
+}
+
+func (w *requestWriter) WriteHeader(code int) {
+	w.setTiming()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *requestWriter) Write(b []byte) (int, error) {
+	w.setTiming()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *requestWriter) setTiming() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	ms := float64(time.Since(w.start)) / float64(time.Millisecond)
+	w.Header().Set(serverTimingHeader, fmt.Sprintf("app;dur=%.3f", ms))
+	w.Header().Set(processingTimeHeader, fmt.Sprintf("%.3f", ms))
+}
+
+// withRequestInfo passes the details of the request served at the given
+// endpoint to the handler, and sets the request ID and timing headers of the
+// response.
+func withRequestInfo(endpoint string, f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		f(&requestWriter{
+			ResponseWriter: w,
+			endpoint:       endpoint,
+			remoteAddr:     r.RemoteAddr,
+			requestID:      id,
+			acceptsGzip:    acceptsGzip(r.Header.Get("Accept-Encoding")),
+			start:          time.Now(),
+		}, r)
+	}
+}
+
+// acceptsGzip returns true if the value of an Accept-Encoding header allows
+// gzip, either explicitly or through a wildcard.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params := part, ""
+		if i := strings.Index(part, ";"); i >= 0 {
+			coding, params = part[:i], part[i+1:]
+		}
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, "q=") {
+			if q, err := strconv.ParseFloat(params[2:], 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// validRequestID returns true if the request ID is non-empty, not too long and
+// only consists of printable ASCII characters, so that it is safe to log and
+// echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// This should never happen.
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
 }
 
 // Enables cross-site script calls.
@@ -76,13 +230,269 @@ type API struct {
 	logger   log.Logger
 	m        *metrics.Alerts
 
+	// ingester prepares the received alerts for the alert store.
+	ingester *ingest.Ingester
+
 	getAlertStatus getAlertStatusFn
 
+	// nflog is the notification log of the notification pipeline.
+	nflog *nflog.Log
+
+	// readOnly makes the API reject all requests that may change state.
+	readOnly bool
+
+	// maxStartSkew is the maximum time new silences may start in the past.
+	// Zero means no limit.
+	maxStartSkew time.Duration
+
+	// staleThreshold is the time after which alerts that weren't updated
+	// are marked as stale. Zero disables the marking.
+	staleThreshold time.Duration
+
+	// commentPattern is the pattern the comments of silences must match.
+	// Nil means no requirement.
+	commentPattern *regexp.Regexp
+
+	// expireAll enables the endpoint expiring all silences.
+	expireAll bool
+
+	// throttles is the rate limiting of notifications recorded by the
+	// notification pipeline.
+	throttles *notify.Throttles
+
+	// dispatchStats returns the aggregation groups of the dispatcher. It is
+	// nil if they aren't exposed.
+	dispatchStats func() []dispatch.GroupStats
+
+	// heavyReads limits the number of concurrent requests scanning the alert
+	// or silence stores. It is nil if the number isn't limited.
+	heavyReads chan struct{}
+
+	// severityLabel is the label by which the status summarizes the
+	// current alerts.
+	severityLabel model.LabelName
+
+	// partialAlerts makes listing alerts return the alerts collected until
+	// the store failed, with a warning, rather than an error.
+	partialAlerts bool
+
+	// startupGracePeriod is the time after startup during which received
+	// alerts aren't resolved by the resolve timeout.
+	startupGracePeriod time.Duration
+
+	// renderTemplates enables the endpoint rendering arbitrary templates.
+	renderTemplates bool
+	// tmpl is the template of the current configuration.
+	tmpl *template.Template
+
+	// redactInternalErrors replaces the messages of internal errors in
+	// responses by a generic one referring to the logged error.
+	redactInternalErrors bool
+
+	// gzipMinSize is the size in bytes from which responses are compressed
+	// for clients accepting gzip. Zero disables the compression.
+	gzipMinSize int
+
+	// alertMutators transform the received alerts in order before they are
+	// validated and stored.
+	alertMutators []AlertMutator
+
+	// buildReceiver builds the integrations of receivers to validate or
+	// test them. It is nil if receivers can be neither validated nor tested.
+	buildReceiver ReceiverBuilder
+
 	mtx sync.RWMutex
 }
 
 type getAlertStatusFn func(model.Fingerprint) types.AlertStatus
 
+// defaultSeverityLabel is the label by which the status summarizes the current
+// alerts if none is configured.
+const defaultSeverityLabel = "severity"
+
+// Option configures optional behavior of the API.
+type Option func(*API)
+
+// WithReadOnly configures whether the API rejects all requests that may
+// change state, such as adding alerts or creating and expiring silences.
+func WithReadOnly(readOnly bool) Option {
+	return func(api *API) {
+		api.readOnly = readOnly
+	}
+}
+
+// WithMaxSilenceStartSkew configures the maximum time new silences may start
+// in the past to account for clock skew of clients. The start time of such
+// silences is set to now, while silences starting earlier are rejected. Zero
+// disables the check.
+func WithMaxSilenceStartSkew(d time.Duration) Option {
+	return func(api *API) {
+		api.maxStartSkew = d
+	}
+}
+
+// WithStaleThreshold configures the time after which alerts that weren't
+// updated are marked as stale in responses. Zero disables the marking.
+func WithStaleThreshold(d time.Duration) Option {
+	return func(api *API) {
+		api.staleThreshold = d
+	}
+}
+
+// WithSilenceCommentPattern configures the pattern the comments of silences
+// must match, e.g. to require a ticket reference. Nil disables the check.
+func WithSilenceCommentPattern(re *regexp.Regexp) Option {
+	return func(api *API) {
+		api.commentPattern = re
+	}
+}
+
+// WithSilenceExpireAll configures whether the API serves the endpoint
+// expiring all active and pending silences at once.
+func WithSilenceExpireAll(enabled bool) Option {
+	return func(api *API) {
+		api.expireAll = enabled
+	}
+}
+
+// WithHeavyReadConcurrency configures the maximum number of concurrent
+// requests listing or counting alerts, silences or notifications. Requests exceeding the
+// limit are rejected with 503 Service Unavailable. Zero or less disables the
+// limit.
+func WithHeavyReadConcurrency(n int) Option {
+	return func(api *API) {
+		if n > 0 {
+			api.heavyReads = make(chan struct{}, n)
+		} else {
+			api.heavyReads = nil
+		}
+	}
+}
+
+// WithPartialAlerts configures listing alerts to respond with the alerts
+// collected so far and a warning if iterating the alert store fails, rather
+// than with an error.
+func WithPartialAlerts(partial bool) Option {
+	return func(api *API) {
+		api.partialAlerts = partial
+	}
+}
+
+// WithStartupGracePeriod configures the time after startup during which
+// received alerts aren't resolved by the resolve timeout, giving their
+// sources time to re-send them. Zero disables the grace period.
+func WithStartupGracePeriod(d time.Duration) Option {
+	return func(api *API) {
+		api.startupGracePeriod = d
+	}
+}
+
+// WithTemplateRendering configures whether the API serves the endpoint
+// rendering arbitrary templates with the current notification templates.
+func WithTemplateRendering(enabled bool) Option {
+	return func(api *API) {
+		api.renderTemplates = enabled
+	}
+}
+
+// WithRedactInternalErrors configures whether the messages of internal
+// errors, which may reveal details such as file paths, are replaced in
+// responses by a generic message with the ID of the request. The full error
+// is logged along with the ID.
+func WithRedactInternalErrors(redact bool) Option {
+	return func(api *API) {
+		api.redactInternalErrors = redact
+	}
+}
+
+// AlertMutator transforms alerts received through the API before their resolve
+// timeout is set and they are validated and stored, for example to enrich them
+// with data of other systems. It may modify the alert in place. Returning an
+// error rejects the alert, which is reported to the client like an invalid
+// alert.
+type AlertMutator interface {
+	MutateAlert(*types.Alert) error
+}
+
+// AlertMutatorFunc is a function implementing AlertMutator.
+type AlertMutatorFunc func(*types.Alert) error
+
+// MutateAlert implements AlertMutator.
+func (f AlertMutatorFunc) MutateAlert(a *types.Alert) error { return f(a) }
+
+// WithAlertMutators appends mutators applied to the received alerts in order.
+// The first mutator rejecting an alert stops the mutation of that alert.
+func WithAlertMutators(ms ...AlertMutator) Option {
+	return func(api *API) {
+		api.alertMutators = append(api.alertMutators, ms...)
+	}
+}
+
+// ReceiverBuilder builds the integrations of the receiver with the given
+// template. It returns a *types.MultiError, possibly wrapped, of
+// *notify.IntegrationError for the integrations that failed.
+type ReceiverBuilder func(*config.Receiver, *template.Template) ([]notify.Integration, error)
+
+// WithReceiverBuilder configures the builder of the integrations of receivers
+// validated or tested through the API. Without one, receivers can be neither
+// validated nor tested.
+func WithReceiverBuilder(b ReceiverBuilder) Option {
+	return func(api *API) {
+		api.buildReceiver = b
+	}
+}
+
+// defaultGzipMinSize is the size in bytes from which responses are compressed
+// unless configured otherwise. Smaller responses aren't worth the overhead.
+const defaultGzipMinSize = 1024
+
+// WithGzipMinSize configures the size in bytes from which responses are
+// compressed for clients accepting gzip. Zero keeps the default of 1KiB and
+// negative sizes disable the compression.
+func WithGzipMinSize(n int) Option {
+	return func(api *API) {
+		switch {
+		case n < 0:
+			api.gzipMinSize = 0
+		case n > 0:
+			api.gzipMinSize = n
+		}
+	}
+}
+
+// WithThrottles configures the rate limiting of notifications exposed by the
+// API.
+func WithThrottles(t *notify.Throttles) Option {
+	return func(api *API) {
+		api.throttles = t
+	}
+}
+
+// WithDispatchStats configures the function returning the aggregation groups
+// of the dispatcher exposed by the API.
+func WithDispatchStats(f func() []dispatch.GroupStats) Option {
+	return func(api *API) {
+		api.dispatchStats = f
+	}
+}
+
+// WithSeverityLabel configures the label by which the status summarizes the
+// current alerts. An empty name keeps the default severity label.
+func WithSeverityLabel(name model.LabelName) Option {
+	return func(api *API) {
+		if name != "" {
+			api.severityLabel = name
+		}
+	}
+}
+
+// WithNotificationLog configures the notification log exposed by the API.
+func WithNotificationLog(l *nflog.Log) Option {
+	return func(api *API) {
+		api.nflog = l
+	}
+}
+
 // New returns a new API.
 func New(
 	alerts provider.Alerts,
@@ -91,44 +501,206 @@ func New(
 	peer cluster.ClusterPeer,
 	l log.Logger,
 	r prometheus.Registerer,
+	opts ...Option,
 ) *API {
 	if l == nil {
 		l = log.NewNopLogger()
 	}
 
-	return &API{
+	m := metrics.NewAlerts("v1", r)
+	api := &API{
 		alerts:         alerts,
 		silences:       silences,
 		getAlertStatus: sf,
 		uptime:         time.Now(),
 		peer:           peer,
 		logger:         l,
-		m:              metrics.NewAlerts("v1", r),
+		m:              m,
+		ingester:       ingest.NewIngester(alerts, m),
+		severityLabel:  defaultSeverityLabel,
+		gzipMinSize:    defaultGzipMinSize,
+	}
+	for _, o := range opts {
+		o(api)
 	}
+	return api
 }
 
 // Register registers the API handlers under their correct routes
 // in the given router.
 func (api *API) Register(r *route.Router) {
-	wrap := func(f http.HandlerFunc) http.HandlerFunc {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	wrap := func(endpoint string, mutating bool, f http.HandlerFunc) http.HandlerFunc {
+		return withRequestInfo(endpoint, func(w http.ResponseWriter, r *http.Request) {
 			setCORS(w)
+			if api.readOnly && mutating {
+				api.respondError(w, apiError{
+					typ: errorForbidden,
+					err: errors.New("the API is in read-only mode"),
+				}, nil)
+				return
+			}
 			f(w, r)
 		})
 	}
 
-	r.Options("/*path", wrap(func(w http.ResponseWriter, r *http.Request) {}))
+	r.Options("/*path", wrap("/*path", false, func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Record the methods served on each path so that requests using any
+	// other method can be answered with 405 Method Not Allowed.
+	methods := map[string]func(string, http.HandlerFunc){
+		http.MethodGet:    r.Get,
+		http.MethodPost:   r.Post,
+		http.MethodPut:    r.Put,
+		http.MethodDelete: r.Del,
+	}
+	allowed := map[string][]string{}
+	register := func(method, path string, mutating bool, f http.HandlerFunc) {
+		allowed[path] = append(allowed[path], method)
+		methods[method](path, wrap(path, mutating, f))
+	}
+	// Queries are served in read-only mode, whatever their method, while
+	// endpoints changing state or contacting receivers are rejected.
+	handle := func(method, path string, f http.HandlerFunc) {
+		register(method, path, false, f)
+	}
+	handleMutating := func(method, path string, f http.HandlerFunc) {
+		register(method, path, true, f)
+	}
+
+	handle(http.MethodGet, "/", api.index(allowed))
+	handle(http.MethodGet, "/status", api.limitHeavyRead(api.status))
+	handle(http.MethodGet, "/status/config", api.statusConfig)
+	handle(http.MethodGet, "/status/silences/consistency", api.silenceConsistency)
+	handle(http.MethodGet, "/status/receivers", api.receiverStats)
+	handle(http.MethodGet, "/status/dispatch", api.dispatchStatus)
+	handle(http.MethodGet, "/receivers", api.receivers)
+	handle(http.MethodGet, "/receivers/:name", api.getReceiver)
+	handle(http.MethodPost, "/receivers/:name/diff", api.diffReceiver)
+	handle(http.MethodPost, "/receivers/:name/would-notify", api.limitHeavyRead(api.wouldNotify))
+	handleMutating(http.MethodPost, "/receivers/:name/test-smtp", api.testSMTP)
+	handleMutating(http.MethodPost, "/receivers/:name/test", api.testReceiver)
+	// The path is singular like that of a single silence, as the router
+	// doesn't allow it beside the receiver names.
+	handle(http.MethodPost, "/receiver/validate", api.validateReceiver)
+
+	handle(http.MethodPost, "/templates/render", api.renderTemplate)
+
+	handle(http.MethodPost, "/routes/timings", api.routeTimings)
+
+	handle(http.MethodGet, "/alerts", api.limitHeavyRead(api.listAlerts))
+	handleMutating(http.MethodPost, "/alerts", api.addAlerts)
+	handle(http.MethodGet, "/alerts/alertnames", api.limitHeavyRead(api.listAlertNames))
+	handle(http.MethodGet, "/alerts/count", api.limitHeavyRead(api.countAlerts))
+	handle(http.MethodPost, "/alerts/status", api.alertStatuses)
+
+	handle(http.MethodGet, "/nflog", api.limitHeavyRead(api.listNotificationLog))
+
+	handle(http.MethodGet, "/silences", api.limitHeavyRead(api.listSilences))
+	handleMutating(http.MethodPost, "/silences", api.setSilence)
+	handleMutating(http.MethodDelete, "/silences", api.delSilences)
+	handle(http.MethodGet, "/silences/presets", api.listSilencePresets)
+	handle(http.MethodPost, "/silences/impact", api.silenceImpact)
+	handleMutating(http.MethodPost, "/silences/expire-all", api.expireAllSilences)
+	handleMutating(http.MethodPost, "/silences/extend-by-id", api.extendSilencesByID)
+	handle(http.MethodGet, "/silence/:sid", api.getSilence)
+	handleMutating(http.MethodPut, "/silence/:sid", api.updateSilence)
+	handleMutating(http.MethodDelete, "/silence/:sid", api.delSilence)
+
+	for path, ms := range allowed {
+		notAllowed := withRequestInfo(path, api.methodNotAllowed(append(ms, http.MethodOptions)))
+		for method, reg := range methods {
+			if !containsString(ms, method) {
+				reg(path, notAllowed)
+			}
+		}
+	}
+}
+
+// heavyReadRetryAfter is the number of seconds after which clients rejected
+// by the heavy read concurrency limit are asked to retry.
+const heavyReadRetryAfter = "1"
+
+// limitHeavyRead returns a handler which rejects requests with 503 Service
+// Unavailable while the heavy read concurrency limit is reached, rather than
+// letting them queue up for the stores.
+func (api *API) limitHeavyRead(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if api.heavyReads == nil {
+			f(w, r)
+			return
+		}
+		select {
+		case api.heavyReads <- struct{}{}:
+			defer func() { <-api.heavyReads }()
+		default:
+			w.Header().Set("Retry-After", heavyReadRetryAfter)
+			api.respondError(w, apiError{
+				typ: errorUnavailable,
+				err: fmt.Errorf("limit of concurrent heavy reads reached (%d), try again later", cap(api.heavyReads)),
+			}, nil)
+			return
+		}
+		f(w, r)
+	}
+}
+
+// indexMaxAge is the time for which clients may cache the API index.
+const indexMaxAge = time.Hour
 
-	r.Get("/status", wrap(api.status))
-	r.Get("/receivers", wrap(api.receivers))
+type endpoint struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
+}
+
+// index returns a handler responding with the API version and the endpoints
+// served, given as the methods allowed per path.
+func (api *API) index(allowed map[string][]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpoints := make([]endpoint, 0, len(allowed))
+		for path, ms := range allowed {
+			ms = append([]string(nil), ms...)
+			sort.Strings(ms)
+			endpoints = append(endpoints, endpoint{Path: path, Methods: ms})
+		}
+		sort.Slice(endpoints, func(i, j int) bool {
+			return endpoints[i].Path < endpoints[j].Path
+		})
+
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(indexMaxAge.Seconds())))
+		api.respond(w, struct {
+			Version   string     `json:"version"`
+			Endpoints []endpoint `json:"endpoints"`
+		}{
+			Version:   "v1",
+			Endpoints: endpoints,
+		})
+	}
+}
 
-	r.Get("/alerts", wrap(api.listAlerts))
-	r.Post("/alerts", wrap(api.addAlerts))
+// methodNotAllowed returns a handler responding with 405 Method Not Allowed
+// and an Allow header listing the given methods.
+func (api *API) methodNotAllowed(allowed []string) http.HandlerFunc {
+	allowed = append([]string(nil), allowed...)
+	sort.Strings(allowed)
+	allow := strings.Join(allowed, ", ")
 
-	r.Get("/silences", wrap(api.listSilences))
-	r.Post("/silences", wrap(api.setSilence))
-	r.Get("/silence/:sid", wrap(api.getSilence))
-	r.Del("/silence/:sid", wrap(api.delSilence))
+	return func(w http.ResponseWriter, r *http.Request) {
+		setCORS(w)
+		w.Header().Set("Allow", allow)
+		api.respondError(w, apiError{
+			typ: errorMethodNotAllowed,
+			err: fmt.Errorf("method %s not allowed, use one of %s", r.Method, allow),
+		}, nil)
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
 // Update sets the configuration string to a new value.
@@ -140,11 +712,34 @@ func (api *API) Update(cfg *config.Config) {
 	api.route = dispatch.NewRoute(cfg.Route, nil)
 }
 
+// Ready returns true once a configuration has been loaded and, if clustering
+// is enabled, the peer has settled.
+func (api *API) Ready() bool {
+	api.mtx.RLock()
+	loaded := api.config != nil
+	api.mtx.RUnlock()
+
+	return loaded && (api.peer == nil || api.peer.Status() == "ready")
+}
+
+// UpdateTemplate sets the notification templates of the current
+// configuration.
+func (api *API) UpdateTemplate(t *template.Template) {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+
+	api.tmpl = t
+}
+
 type errorType string
 
 const (
-	errorInternal errorType = "server_error"
-	errorBadData  errorType = "bad_data"
+	errorInternal         errorType = "server_error"
+	errorBadData          errorType = "bad_data"
+	errorNotFound         errorType = "not_found"
+	errorForbidden        errorType = "forbidden"
+	errorMethodNotAllowed errorType = "method_not_allowed"
+	errorUnavailable      errorType = "unavailable"
 )
 
 type apiError struct {
@@ -168,142 +763,218 @@ func (api *API) receivers(w http.ResponseWriter, req *http.Request) {
 	api.respond(w, receivers)
 }
 
-func (api *API) status(w http.ResponseWriter, req *http.Request) {
+type receiverIntegration struct {
+	Integration  string `json:"integration"`
+	Index        int    `json:"index"`
+	SendResolved bool   `json:"sendResolved"`
+}
+
+type resolvedReceiver struct {
+	Name         string                `json:"name"`
+	Integrations []receiverIntegration `json:"integrations"`
+	// Config is the full configuration of the receiver, with secrets
+	// masked.
+	Config *config.Receiver `json:"config"`
+}
+
+// getReceiver responds with the integrations of a receiver and the settings
+// applied to them after defaults were filled in, along with the configuration
+// of the receiver.
+func (api *API) getReceiver(w http.ResponseWriter, r *http.Request) {
+	name := route.Param(r.Context(), "name")
+
 	api.mtx.RLock()
+	defer api.mtx.RUnlock()
 
-	var status = struct {
-		ConfigYAML    string            `json:"configYAML"`
-		ConfigJSON    *config.Config    `json:"configJSON"`
-		VersionInfo   map[string]string `json:"versionInfo"`
-		Uptime        time.Time         `json:"uptime"`
-		ClusterStatus *clusterStatus    `json:"clusterStatus"`
-	}{
-		ConfigYAML: api.config.String(),
-		ConfigJSON: api.config,
-		VersionInfo: map[string]string{
-			"version":   version.Version,
-			"revision":  version.Revision,
-			"branch":    version.Branch,
-			"buildUser": version.BuildUser,
-			"buildDate": version.BuildDate,
-			"goVersion": version.GoVersion,
-		},
-		Uptime:        api.uptime,
-		ClusterStatus: getClusterStatus(api.peer),
+	for _, rc := range api.config.Receivers {
+		if rc.Name != name {
+			continue
+		}
+		res := resolvedReceiver{Name: rc.Name, Integrations: []receiverIntegration{}, Config: rc}
+		for _, ic := range rc.Integrations() {
+			res.Integrations = append(res.Integrations, receiverIntegration{
+				Integration:  ic.Name,
+				Index:        ic.Index,
+				SendResolved: ic.NotifierConfig.SendResolved(),
+			})
+		}
+		api.respond(w, res)
+		return
+	}
+
+	api.respondError(w, apiError{
+		typ: errorNotFound,
+		err: fmt.Errorf("receiver %q not found", name),
+	}, nil)
+}
+
+// diffReceiver responds with the field-level differences of the receiver
+// configuration in the request body compared to the stored receiver of the
+// same name. The body is a receiver in the configuration file format.
+func (api *API) diffReceiver(w http.ResponseWriter, r *http.Request) {
+	name := route.Param(r.Context(), "name")
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
 	}
 
+	api.mtx.RLock()
+	conf := api.config
 	api.mtx.RUnlock()
 
-	api.respond(w, status)
-}
+	var current *config.Receiver
+	for _, rc := range conf.Receivers {
+		if rc.Name == name {
+			current = rc
+			break
+		}
+	}
+	if current == nil {
+		api.respondError(w, apiError{
+			typ: errorNotFound,
+			err: fmt.Errorf("receiver %q not found", name),
+		}, nil)
+		return
+	}
 
-type peerStatus struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	candidate, err := conf.LoadReceiver(name, string(body))
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("invalid receiver: %w", err),
+		}, nil)
+		return
+	}
+
+	diff := config.DiffReceivers(current, candidate)
+	if diff == nil {
+		diff = []config.FieldDiff{}
+	}
+	api.respond(w, diff)
 }
 
-type clusterStatus struct {
-	Name   string       `json:"name"`
-	Status string       `json:"status"`
-	Peers  []peerStatus `json:"peers"`
+// receiverError is an error of a candidate receiver. Errors of integrations
+// carry the type and index of the integration.
+type receiverError struct {
+	Integration string `json:"integration,omitempty"`
+	Index       int    `json:"index"`
+	Error       string `json:"error"`
 }
 
-func getClusterStatus(p cluster.ClusterPeer) *clusterStatus {
-	if p == nil {
-		return nil
+// validateReceiver validates a candidate receiver along with the current
+// configuration and builds its integrations, without changing either the
+// configuration or the notification pipeline. Existing receivers of the same
+// name are only warned about.
+func (api *API) validateReceiver(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
 	}
-	s := &clusterStatus{Name: p.Name(), Status: p.Status()}
 
-	for _, n := range p.Peers() {
-		s.Peers = append(s.Peers, peerStatus{
-			Name:    n.Name(),
-			Address: n.Address(),
-		})
+	api.mtx.RLock()
+	conf, tmpl := api.config, api.tmpl
+	api.mtx.RUnlock()
+
+	if api.buildReceiver == nil || conf == nil || tmpl == nil {
+		api.respondError(w, apiError{
+			typ: errorUnavailable,
+			err: errors.New("validating receivers is not available"),
+		}, nil)
+		return
 	}
-	return s
-}
 
-func (api *API) listAlerts(w http.ResponseWriter, r *http.Request) {
-	var (
-		err            error
-		receiverFilter *regexp.Regexp
-		// Initialize result slice to prevent api returning `null` when there
-		// are no alerts present
-		res      = []*Alert{}
-		matchers = []*labels.Matcher{}
-		ctx      = r.Context()
-
-		showActive, showInhibited     bool
-		showSilenced, showUnprocessed bool
-	)
-
-	getBoolParam := func(name string) (bool, error) {
-		v := r.FormValue(name)
-		if v == "" {
-			return true, nil
-		}
-		if v == "false" {
-			return false, nil
-		}
-		if v != "true" {
-			err := fmt.Errorf("parameter %q can either be 'true' or 'false', not %q", name, v)
-			api.respondError(w, apiError{
-				typ: errorBadData,
-				err: err,
-			}, nil)
-			return false, err
-		}
-		return true, nil
-	}
-
-	if filter := r.FormValue("filter"); filter != "" {
-		matchers, err = labels.ParseMatchers(filter)
-		if err != nil {
-			api.respondError(w, apiError{
-				typ: errorBadData,
-				err: err,
-			}, nil)
-			return
-		}
-	}
-
-	showActive, err = getBoolParam("active")
+	candidate, exists, err := conf.LoadCandidateReceiver(string(body))
 	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("invalid receiver: %w", err),
+		}, nil)
 		return
 	}
 
-	showSilenced, err = getBoolParam("silenced")
-	if err != nil {
-		return
+	res := struct {
+		Valid  bool            `json:"valid"`
+		Errors []receiverError `json:"errors"`
+	}{
+		Errors: []receiverError{},
+	}
+	if _, err := api.buildReceiver(candidate, tmpl); err != nil {
+		res.Errors = receiverErrors(err)
 	}
+	res.Valid = len(res.Errors) == 0
 
-	showInhibited, err = getBoolParam("inhibited")
-	if err != nil {
-		return
+	var warnings []string
+	if exists {
+		warnings = append(warnings, fmt.Sprintf("receiver %q already exists", candidate.Name))
 	}
+	api.respondWithWarnings(w, res, warnings)
+}
 
-	showUnprocessed, err = getBoolParam("unprocessed")
-	if err != nil {
-		return
+// receiverErrors splits the error of building a receiver into the errors of
+// its integrations.
+func receiverErrors(err error) []receiverError {
+	errs := []error{err}
+	var me *types.MultiError
+	if errors.As(err, &me) {
+		errs = me.Errors()
+	}
+	res := make([]receiverError, 0, len(errs))
+	for _, err := range errs {
+		var ie *notify.IntegrationError
+		if errors.As(err, &ie) {
+			res = append(res, receiverError{Integration: ie.Integration, Index: ie.Index, Error: ie.Err.Error()})
+			continue
+		}
+		res = append(res, receiverError{Error: err.Error()})
 	}
+	return res
+}
 
-	if receiverParam := r.FormValue("receiver"); receiverParam != "" {
-		receiverFilter, err = regexp.Compile("^(?:" + receiverParam + ")$")
-		if err != nil {
-			api.respondError(w, apiError{
-				typ: errorBadData,
-				err: fmt.Errorf(
-					"failed to parse receiver param: %s",
-					receiverParam,
-				),
-			}, nil)
-			return
+// wouldNotify responds with the current alerts that the routing tree directs
+// to the receiver and that are neither silenced nor inhibited. Mute time
+// intervals and maintenance windows aren't taken into account.
+func (api *API) wouldNotify(w http.ResponseWriter, r *http.Request) {
+	name := route.Param(r.Context(), "name")
+
+	api.mtx.RLock()
+	known := false
+	for _, rc := range api.config.Receivers {
+		if rc.Name == name {
+			known = true
+			break
 		}
 	}
+	api.mtx.RUnlock()
+	if !known {
+		api.respondError(w, apiError{
+			typ: errorNotFound,
+			err: fmt.Errorf("receiver %q not found", name),
+		}, nil)
+		return
+	}
+
+	var (
+		err error
+		res = []*Alert{}
+		ctx = r.Context()
+		set = map[string]struct{}{name: {}}
+	)
 
 	alerts := api.alerts.GetPending()
 	defer alerts.Close()
 
+	now := time.Now()
 	api.mtx.RLock()
 	for a := range alerts.Next() {
 		if err = alerts.Err(); err != nil {
@@ -312,41 +983,21 @@ func (api *API) listAlerts(w http.ResponseWriter, r *http.Request) {
 		if err = ctx.Err(); err != nil {
 			break
 		}
+		if a.ResolvedAt(now) {
+			continue
+		}
 
 		routes := api.route.Match(a.Labels)
 		receivers := make([]string, 0, len(routes))
 		for _, r := range routes {
 			receivers = append(receivers, r.RouteOpts.Receiver)
 		}
-
-		if receiverFilter != nil && !receiversMatchFilter(receivers, receiverFilter) {
-			continue
-		}
-
-		if !alertMatchesFilterLabels(&a.Alert, matchers) {
-			continue
-		}
-
-		// Continue if the alert is resolved.
-		if !a.Alert.EndsAt.IsZero() && a.Alert.EndsAt.Before(time.Now()) {
+		if !receiversInSet(receivers, set) {
 			continue
 		}
 
 		status := api.getAlertStatus(a.Fingerprint())
-
-		if !showActive && status.State == types.AlertStateActive {
-			continue
-		}
-
-		if !showUnprocessed && status.State == types.AlertStateUnprocessed {
-			continue
-		}
-
-		if !showSilenced && len(status.SilencedBy) != 0 {
-			continue
-		}
-
-		if !showInhibited && len(status.InhibitedBy) != 0 {
+		if len(status.SilencedBy) != 0 || len(status.InhibitedBy) != 0 {
 			continue
 		}
 
@@ -355,8 +1006,11 @@ func (api *API) listAlerts(w http.ResponseWriter, r *http.Request) {
 			Status:      status,
 			Receivers:   receivers,
 			Fingerprint: a.Fingerprint().String(),
+			Stale:       api.isStale(a, now),
+		}
+		if len(receivers) > 0 {
+			alert.PrimaryReceiver = receivers[0]
 		}
-
 		res = append(res, alert)
 	}
 	api.mtx.RUnlock()
@@ -374,27 +1028,1759 @@ func (api *API) listAlerts(w http.ResponseWriter, r *http.Request) {
 	api.respond(w, res)
 }
 
-func receiversMatchFilter(receivers []string, filter *regexp.Regexp) bool {
-	for _, r := range receivers {
-		if filter.MatchString(r) {
-			return true
+// integrationThrottle is the latest rate limiting of an integration.
+// Throttled is true until the service allows sending again.
+type integrationThrottle struct {
+	Integration string `json:"integration"`
+	Throttled   bool   `json:"throttled"`
+	notify.ThrottleState
+}
+
+type receiverStats struct {
+	Name      string                `json:"name"`
+	Throttles []integrationThrottle `json:"throttles"`
+}
+
+// receiverStats responds with the latest rate limiting of the integrations of
+// each receiver. Integrations that were never rate limited are omitted.
+func (api *API) receiverStats(w http.ResponseWriter, req *http.Request) {
+	api.mtx.RLock()
+	names := make([]string, 0, len(api.config.Receivers))
+	for _, r := range api.config.Receivers {
+		names = append(names, r.Name)
+	}
+	api.mtx.RUnlock()
+
+	now := time.Now()
+	res := make([]receiverStats, 0, len(names))
+	for _, name := range names {
+		rs := receiverStats{Name: name, Throttles: []integrationThrottle{}}
+		if api.throttles != nil {
+			for i, s := range api.throttles.Receiver(name) {
+				rs.Throttles = append(rs.Throttles, integrationThrottle{
+					Integration:   i,
+					Throttled:     now.Before(s.NextAttemptAt),
+					ThrottleState: s,
+				})
+			}
 		}
+		sort.Slice(rs.Throttles, func(i, j int) bool {
+			return rs.Throttles[i].Integration < rs.Throttles[j].Integration
+		})
+		res = append(res, rs)
 	}
 
-	return false
+	api.respond(w, res)
 }
 
-func alertMatchesFilterLabels(a *model.Alert, matchers []*labels.Matcher) bool {
-	sms := make(map[string]string)
-	for name, value := range a.Labels {
-		sms[string(name)] = string(value)
+type dispatchGroup struct {
+	GroupKey  string         `json:"groupKey"`
+	Receiver  string         `json:"receiver"`
+	Labels    model.LabelSet `json:"labels"`
+	Alerts    int            `json:"alerts"`
+	NextFlush time.Time      `json:"nextFlush"`
+}
+
+type alertsPerGroup struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+type dispatchStatus struct {
+	AggrGroups     int             `json:"aggrGroups"`
+	Alerts         int             `json:"alerts"`
+	AlertsPerGroup alertsPerGroup  `json:"alertsPerGroup"`
+	Groups         []dispatchGroup `json:"groups"`
+}
+
+// dispatchStatus responds with the aggregation groups of the dispatcher, the
+// distribution of their alerts and the time each group notifies next.
+func (api *API) dispatchStatus(w http.ResponseWriter, req *http.Request) {
+	if api.dispatchStats == nil {
+		api.respondError(w, apiError{
+			typ: errorUnavailable,
+			err: errors.New("the dispatch status is not available"),
+		}, nil)
+		return
+	}
+
+	stats := api.dispatchStats()
+	res := dispatchStatus{
+		AggrGroups: len(stats),
+		Groups:     make([]dispatchGroup, 0, len(stats)),
+	}
+	for i, s := range stats {
+		res.Alerts += s.Alerts
+		if i == 0 || s.Alerts < res.AlertsPerGroup.Min {
+			res.AlertsPerGroup.Min = s.Alerts
+		}
+		if s.Alerts > res.AlertsPerGroup.Max {
+			res.AlertsPerGroup.Max = s.Alerts
+		}
+		res.Groups = append(res.Groups, dispatchGroup{
+			GroupKey:  s.GroupKey,
+			Receiver:  s.Receiver,
+			Labels:    s.Labels,
+			Alerts:    s.Alerts,
+			NextFlush: s.NextFlush,
+		})
+	}
+	if len(stats) > 0 {
+		res.AlertsPerGroup.Mean = float64(res.Alerts) / float64(len(stats))
+	}
+
+	api.respond(w, res)
+}
+
+// smtpCheckTimeout bounds the time spent on checking a single email
+// configuration.
+const smtpCheckTimeout = 30 * time.Second
+
+type smtpCheckResult struct {
+	Smarthost string `json:"smarthost"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (api *API) testSMTP(w http.ResponseWriter, r *http.Request) {
+	name := route.Param(r.Context(), "name")
+
+	api.mtx.RLock()
+	var rcv *config.Receiver
+	for _, rc := range api.config.Receivers {
+		if rc.Name == name {
+			rcv = rc
+			break
+		}
+	}
+	api.mtx.RUnlock()
+
+	if rcv == nil {
+		api.respondError(w, apiError{
+			typ: errorNotFound,
+			err: fmt.Errorf("receiver %q not found", name),
+		}, nil)
+		return
+	}
+	if len(rcv.EmailConfigs) == 0 {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("receiver %q has no email configurations", name),
+		}, nil)
+		return
+	}
+
+	results := make([]smtpCheckResult, 0, len(rcv.EmailConfigs))
+	for _, ec := range rcv.EmailConfigs {
+		ctx, cancel := context.WithTimeout(r.Context(), smtpCheckTimeout)
+		err := email.Check(ctx, ec, log.With(api.logger, "receiver", name))
+		cancel()
+
+		res := smtpCheckResult{
+			Smarthost: ec.Smarthost.String(),
+			Success:   err == nil,
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+
+	api.respond(w, results)
+}
+
+// testNotificationTimeout bounds the time spent on sending a test
+// notification through a single integration.
+const testNotificationTimeout = 30 * time.Second
+
+// The labels and annotations of test alerts unless given.
+var (
+	defaultTestLabels = model.LabelSet{
+		model.AlertNameLabel: "TestAlert",
+	}
+	defaultTestAnnotations = model.LabelSet{
+		"summary": "Test notification sent through the Alertmanager API",
+	}
+)
+
+type testNotificationResult struct {
+	Integration string         `json:"integration"`
+	Index       int            `json:"index"`
+	Success     bool           `json:"success"`
+	Latency     model.Duration `json:"latency"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// testReceiver sends a notification of a synthetic alert through each
+// integration of the receiver and responds with the results. The alert is
+// neither stored nor dispatched.
+func (api *API) testReceiver(w http.ResponseWriter, r *http.Request) {
+	name := route.Param(r.Context(), "name")
+
+	var req struct {
+		Labels      model.LabelSet `json:"labels"`
+		Annotations model.LabelSet `json:"annotations"`
+	}
+	// The body is optional.
+	if err := api.receive(r, &req); err != nil && err != io.EOF {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+	if len(req.Labels) == 0 {
+		req.Labels = defaultTestLabels
+	}
+	if len(req.Annotations) == 0 {
+		req.Annotations = defaultTestAnnotations
+	}
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:      req.Labels,
+			Annotations: req.Annotations,
+			StartsAt:    time.Now(),
+		},
+		UpdatedAt: time.Now(),
+	}
+	if err := alert.Validate(); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.mtx.RLock()
+	var rcv *config.Receiver
+	for _, rc := range api.config.Receivers {
+		if rc.Name == name {
+			rcv = rc
+			break
+		}
+	}
+	tmpl := api.tmpl
+	api.mtx.RUnlock()
+
+	if rcv == nil {
+		api.respondError(w, apiError{
+			typ: errorNotFound,
+			err: fmt.Errorf("receiver %q not found", name),
+		}, nil)
+		return
+	}
+	if api.buildReceiver == nil || tmpl == nil {
+		api.respondError(w, apiError{
+			typ: errorUnavailable,
+			err: errors.New("testing receivers is not available"),
+		}, nil)
+		return
+	}
+
+	integrations, err := api.buildReceiver(rcv, tmpl)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	if len(integrations) == 0 {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("receiver %q has no integrations", name),
+		}, nil)
+		return
+	}
+
+	level.Info(api.logger).Log("msg", "Sending test notification", "receiver", name, "alert", alert.Name())
+	results := make([]testNotificationResult, 0, len(integrations))
+	for _, in := range integrations {
+		ctx, cancel := context.WithTimeout(r.Context(), testNotificationTimeout)
+		ctx = notify.WithReceiverName(ctx, name)
+		ctx = notify.WithGroupKey(ctx, fmt.Sprintf("test/%s:%s", name, alert.Labels))
+		ctx = notify.WithGroupLabels(ctx, alert.Labels)
+		ctx = notify.WithNow(ctx, time.Now())
+
+		start := time.Now()
+		_, err := in.Notify(ctx, alert)
+		cancel()
+
+		res := testNotificationResult{
+			Integration: in.Name(),
+			Index:       in.Index(),
+			Success:     err == nil,
+			Latency:     model.Duration(time.Since(start)),
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+
+	api.respond(w, results)
+}
+
+type renderRequest struct {
+	Template string `json:"template"`
+	// HTML renders the template as HTML, escaping the data, like the
+	// templates of email bodies.
+	HTML bool `json:"html"`
+	// Receiver, GroupLabels and Alerts are the sample data the template
+	// is rendered with.
+	Receiver    string         `json:"receiver"`
+	GroupLabels model.LabelSet `json:"groupLabels"`
+	Alerts      []model.Alert  `json:"alerts"`
+}
+
+// renderTemplate responds with the output of the given template rendered with
+// the given sample data and the current notification templates, so that
+// template authors can check their templates before loading them.
+func (api *API) renderTemplate(w http.ResponseWriter, r *http.Request) {
+	if !api.renderTemplates {
+		api.respondError(w, apiError{
+			typ: errorForbidden,
+			err: errors.New("rendering templates is disabled"),
+		}, nil)
+		return
+	}
+
+	var req renderRequest
+	if err := api.receive(r, &req); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.mtx.RLock()
+	tmpl := api.tmpl
+	api.mtx.RUnlock()
+
+	if tmpl == nil {
+		api.respondError(w, apiError{
+			typ: errorUnavailable,
+			err: errors.New("templates not loaded yet"),
+		}, nil)
+		return
+	}
+	// The errors of the template packages contain the line of the error.
+	if err := tmpl.CheckText(req.Template); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("invalid template: %w", err),
+		}, nil)
+		return
+	}
+
+	alerts := make([]*types.Alert, 0, len(req.Alerts))
+	for i := range req.Alerts {
+		alerts = append(alerts, &types.Alert{Alert: req.Alerts[i]})
+	}
+	data := tmpl.Data(req.Receiver, req.GroupLabels, alerts...)
+
+	var (
+		out string
+		err error
+	)
+	if req.HTML {
+		out, err = tmpl.ExecuteHTMLString(req.Template, data)
+	} else {
+		out, err = tmpl.ExecuteTextString(req.Template, data)
+	}
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("executing template failed: %w", err),
+		}, nil)
+		return
+	}
+
+	api.respond(w, struct {
+		Output string `json:"output"`
+	}{out})
+}
+
+func (api *API) status(w http.ResponseWriter, req *http.Request) {
+	severityCounts, err := api.severityCounts(req.Context())
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.mtx.RLock()
+
+	var status = struct {
+		ConfigYAML     string            `json:"configYAML"`
+		ConfigJSON     *config.Config    `json:"configJSON"`
+		VersionInfo    map[string]string `json:"versionInfo"`
+		Uptime         time.Time         `json:"uptime"`
+		ClusterStatus  *clusterStatus    `json:"clusterStatus"`
+		SeverityCounts map[string]int    `json:"severityCounts"`
+	}{
+		ConfigYAML: api.config.String(),
+		ConfigJSON: api.config,
+		VersionInfo: map[string]string{
+			"version":   version.Version,
+			"revision":  version.Revision,
+			"branch":    version.Branch,
+			"buildUser": version.BuildUser,
+			"buildDate": version.BuildDate,
+			"goVersion": version.GoVersion,
+		},
+		Uptime:         api.uptime,
+		ClusterStatus:  getClusterStatus(api.peer),
+		SeverityCounts: severityCounts,
+	}
+
+	api.mtx.RUnlock()
+
+	api.respond(w, status)
+}
+
+// severityCounts returns the number of firing alerts per value of the
+// severity label. Alerts without the label are counted under the empty value.
+func (api *API) severityCounts(ctx context.Context) (map[string]int, error) {
+	res := map[string]int{}
+
+	alerts := api.alerts.GetPending()
+	defer alerts.Close()
+
+	now := time.Now()
+	for a := range alerts.Next() {
+		if err := alerts.Err(); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if a.ResolvedAt(now) {
+			continue
+		}
+		res[string(a.Labels[api.severityLabel])]++
+	}
+	return res, alerts.Err()
+}
+
+// statusConfig responds with the configuration in YAML. If the diff
+// parameter is true, fields equal to their defaults are omitted.
+func (api *API) statusConfig(w http.ResponseWriter, r *http.Request) {
+	var diff bool
+	switch v := r.FormValue("diff"); v {
+	case "", "false":
+	case "true":
+		diff = true
+	default:
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("parameter \"diff\" can either be 'true' or 'false', not %q", v),
+		}, nil)
+		return
+	}
+
+	api.mtx.RLock()
+	cfg := api.config
+	api.mtx.RUnlock()
+
+	configYAML := cfg.String()
+	if diff {
+		var err error
+		if configYAML, err = cfg.NonDefaultString(); err != nil {
+			api.respondError(w, apiError{
+				typ: errorInternal,
+				err: err,
+			}, nil)
+			return
+		}
+	}
+
+	api.respond(w, struct {
+		ConfigYAML string `json:"configYAML"`
+	}{
+		ConfigYAML: configYAML,
+	})
+}
+
+type peerStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type clusterStatus struct {
+	Name   string       `json:"name"`
+	Status string       `json:"status"`
+	Peers  []peerStatus `json:"peers"`
+	// Settling is true while the peer is still settling with the cluster.
+	Settling bool `json:"settling"`
+	// SettledAt is the time the peer finished settling.
+	SettledAt *time.Time `json:"settledAt,omitempty"`
+}
+
+func getClusterStatus(p cluster.ClusterPeer) *clusterStatus {
+	if p == nil {
+		return nil
+	}
+	s := &clusterStatus{Name: p.Name(), Status: p.Status()}
+	if at := p.SettledAt(); at.IsZero() {
+		s.Settling = true
+	} else {
+		s.SettledAt = &at
+	}
+
+	for _, n := range p.Peers() {
+		s.Peers = append(s.Peers, peerStatus{
+			Name:    n.Name(),
+			Address: n.Address(),
+		})
+	}
+	return s
+}
+
+// peerRequestTimeout is the timeout for requests to the APIs of peers.
+const peerRequestTimeout = 10 * time.Second
+
+// silenceState summarizes the silences known to an Alertmanager.
+type silenceState struct {
+	// Hash is the hash of all silences.
+	Hash string `json:"hash"`
+	// Silences maps the IDs of the silences to their hashes.
+	Silences map[string]string `json:"silences"`
+}
+
+// peerSilenceState holds the differences between the local silences and the
+// silences of a peer.
+type peerSilenceState struct {
+	URL        string `json:"url"`
+	Hash       string `json:"hash,omitempty"`
+	Consistent bool   `json:"consistent"`
+	// Missing holds the IDs of the silences unknown to the peer.
+	Missing []string `json:"missing,omitempty"`
+	// Extra holds the IDs of the silences only known to the peer.
+	Extra []string `json:"extra,omitempty"`
+	// Different holds the IDs of the silences differing on the peer.
+	Different []string `json:"different,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// silenceConsistency responds with the hashes of the local silences. The
+// silences of the peers given by the peer parameters, which are the URLs of
+// their web interfaces, are compared with the local ones. Only peers of the
+// cluster may be given.
+func (api *API) silenceConsistency(w http.ResponseWriter, r *http.Request) {
+	peers, err := api.parsePeerURLs(r.URL.Query()["peer"])
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	local, err := api.silenceState()
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+
+	res := struct {
+		silenceState
+		Peers []peerSilenceState `json:"peers,omitempty"`
+	}{silenceState: local}
+
+	client := &http.Client{Timeout: peerRequestTimeout}
+	for _, u := range peers {
+		ps := peerSilenceState{URL: u.String()}
+		remote, err := fetchSilenceState(r.Context(), client, u)
+		if err != nil {
+			ps.Error = err.Error()
+			res.Peers = append(res.Peers, ps)
+			continue
+		}
+		ps.Hash = remote.Hash
+		ps.Consistent = remote.Hash == local.Hash
+		for id, h := range local.Silences {
+			rh, ok := remote.Silences[id]
+			switch {
+			case !ok:
+				ps.Missing = append(ps.Missing, id)
+			case rh != h:
+				ps.Different = append(ps.Different, id)
+			}
+		}
+		for id := range remote.Silences {
+			if _, ok := local.Silences[id]; !ok {
+				ps.Extra = append(ps.Extra, id)
+			}
+		}
+		sort.Strings(ps.Missing)
+		sort.Strings(ps.Extra)
+		sort.Strings(ps.Different)
+		res.Peers = append(res.Peers, ps)
+	}
+
+	api.respond(w, res)
+}
+
+// parsePeerURLs parses the URLs of the given peers. To avoid making requests
+// on behalf of clients to arbitrary hosts, the host of each URL must be the
+// host of a member of the cluster.
+func (api *API) parsePeerURLs(peers []string) ([]*url.URL, error) {
+	if len(peers) == 0 {
+		return nil, nil
+	}
+	if api.peer == nil {
+		return nil, errors.New("comparing silences with peers requires clustering")
+	}
+	hosts := map[string]struct{}{}
+	for _, m := range api.peer.Peers() {
+		host, _, err := net.SplitHostPort(m.Address())
+		if err != nil {
+			host = m.Address()
+		}
+		hosts[host] = struct{}{}
+	}
+
+	res := make([]*url.URL, 0, len(peers))
+	for _, p := range peers {
+		u, err := url.Parse(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer URL %q: %w", p, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid peer URL %q: scheme must be http or https", p)
+		}
+		if _, ok := hosts[u.Hostname()]; !ok {
+			return nil, fmt.Errorf("peer URL %q doesn't refer to a member of the cluster", p)
+		}
+		res = append(res, u)
+	}
+	return res, nil
+}
+
+// silenceState returns the hashes of the local silences.
+func (api *API) silenceState() (silenceState, error) {
+	psils, _, err := api.silences.Query()
+	if err != nil {
+		return silenceState{}, err
+	}
+
+	state := silenceState{Silences: make(map[string]string, len(psils))}
+	ids := make([]string, 0, len(psils))
+	for _, ps := range psils {
+		b, err := ps.Marshal()
+		if err != nil {
+			return silenceState{}, err
+		}
+		sum := sha256.Sum256(b)
+		state.Silences[ps.Id] = hex.EncodeToString(sum[:])
+		ids = append(ids, ps.Id)
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(h, "%s:%s\n", id, state.Silences[id])
+	}
+	state.Hash = hex.EncodeToString(h.Sum(nil))
+	return state, nil
+}
+
+// fetchSilenceState requests the hashes of the silences of the peer with the
+// given base URL.
+func fetchSilenceState(ctx context.Context, client *http.Client, base *url.URL) (silenceState, error) {
+	u := *base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v1/status/silences/consistency"
+	u.RawQuery = ""
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return silenceState{}, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return silenceState{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return silenceState{}, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var res struct {
+		Data silenceState `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return silenceState{}, err
+	}
+	return res.Data, nil
+}
+
+func (api *API) listAlerts(w http.ResponseWriter, r *http.Request) {
+	var (
+		err              error
+		receiverFilter   *regexp.Regexp
+		receiverNames    map[string]struct{}
+		routePath        []string
+		receiverLabel    model.LabelName
+		hasAnnotations   []model.LabelName
+		lacksAnnotations []model.LabelName
+		updatedSince     time.Time
+		startsAtAfter    time.Time
+		endsAtBefore     time.Time
+		expandSilences   bool
+		// silences caches the silences inlined into the alerts by ID.
+		silences = map[string]*types.Silence{}
+		// Initialize result slice to prevent api returning `null` when there
+		// are no alerts present
+		res      = []*Alert{}
+		matchers = []*labels.Matcher{}
+		ctx      = r.Context()
+		warnings []string
+
+		showActive, showInhibited     bool
+		showSilenced, showUnprocessed bool
+	)
+
+	getBoolParam := func(name string) (bool, error) {
+		v := r.FormValue(name)
+		if v == "" {
+			return true, nil
+		}
+		if v == "false" {
+			return false, nil
+		}
+		if v != "true" {
+			err := fmt.Errorf("parameter %q can either be 'true' or 'false', not %q", name, v)
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: err,
+			}, nil)
+			return false, err
+		}
+		return true, nil
+	}
+
+	if filter := r.FormValue("filter"); filter != "" {
+		matchers, err = labels.ParseMatchers(filter)
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: err,
+			}, nil)
+			return
+		}
+	}
+
+	showActive, err = getBoolParam("active")
+	if err != nil {
+		return
+	}
+
+	showSilenced, err = getBoolParam("silenced")
+	if err != nil {
+		return
+	}
+
+	showInhibited, err = getBoolParam("inhibited")
+	if err != nil {
+		return
+	}
+
+	showUnprocessed, err = getBoolParam("unprocessed")
+	if err != nil {
+		return
+	}
+
+	if receiverParam := r.FormValue("receiver"); receiverParam != "" {
+		receiverFilter, err = regexp.Compile("^(?:" + receiverParam + ")$")
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf(
+					"failed to parse receiver param: %s",
+					receiverParam,
+				),
+			}, nil)
+			return
+		}
+	}
+
+	// The form is parsed by FormValue above.
+	if names := r.Form["receiverName"]; len(names) > 0 {
+		receiverNames = make(map[string]struct{}, len(names))
+		for _, n := range names {
+			receiverNames[n] = struct{}{}
+		}
+	}
+
+	if p := r.FormValue("routePath"); p != "" {
+		routePath = strings.Split(p, "/")
+	}
+
+	for _, n := range r.Form["hasAnnotation"] {
+		hasAnnotations = append(hasAnnotations, model.LabelName(n))
+	}
+	for _, n := range r.Form["lacksAnnotation"] {
+		lacksAnnotations = append(lacksAnnotations, model.LabelName(n))
+	}
+
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{
+		{"updatedSince", &updatedSince},
+		{"startsAtAfter", &startsAtAfter},
+		{"endsAtBefore", &endsAtBefore},
+	} {
+		v := r.FormValue(p.name)
+		if v == "" {
+			continue
+		}
+		if *p.t, err = time.Parse(time.RFC3339, v); err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("invalid %s %q, must be an RFC3339 timestamp", p.name, v),
+			}, nil)
+			return
+		}
+	}
+
+	for _, e := range r.Form["expand"] {
+		if e != "silences" {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("unknown expand %q, must be silences", e),
+			}, nil)
+			return
+		}
+		if api.silences == nil {
+			api.respondError(w, apiError{
+				typ: errorUnavailable,
+				err: errors.New("silences not available"),
+			}, nil)
+			return
+		}
+		expandSilences = true
+	}
+
+	if l := r.FormValue("receiverLabel"); l != "" {
+		receiverLabel = model.LabelName(l)
+		if !receiverLabel.IsValid() {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("invalid receiverLabel %q", l),
+			}, nil)
+			return
+		}
+	}
+
+	alerts := api.alerts.GetPending()
+	defer alerts.Close()
+
+	now := time.Now()
+	api.mtx.RLock()
+	for a := range alerts.Next() {
+		if err = alerts.Err(); err != nil {
+			if api.partialAlerts {
+				warnings = append(warnings, fmt.Sprintf("listing alerts failed, the result is incomplete: %s", err))
+				err = nil
+			}
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		if a.UpdatedAt.Before(updatedSince) {
+			continue
+		}
+
+		if !startsAtAfter.IsZero() && !a.StartsAt.After(startsAtAfter) {
+			continue
+		}
+
+		// Alerts without an end time never match endsAtBefore.
+		if !endsAtBefore.IsZero() && (a.EndsAt.IsZero() || !a.EndsAt.Before(endsAtBefore)) {
+			continue
+		}
+
+		routes := api.route.Match(a.Labels)
+		receivers := make([]string, 0, len(routes))
+		for _, r := range routes {
+			receivers = append(receivers, r.RouteOpts.Receiver)
+		}
+
+		if receiverFilter != nil && !receiversMatchFilter(receivers, receiverFilter) {
+			continue
+		}
+
+		if receiverNames != nil && !receiversInSet(receivers, receiverNames) {
+			continue
+		}
+
+		if routePath != nil && !routesMatchPath(routes, routePath) {
+			continue
+		}
+
+		if !alertMatchesFilterLabels(&a.Alert, matchers) {
+			continue
+		}
+
+		if !annotationsPresent(a.Annotations, hasAnnotations, lacksAnnotations) {
+			continue
+		}
+
+		// Continue if the alert is resolved.
+		if !a.Alert.EndsAt.IsZero() && a.Alert.EndsAt.Before(time.Now()) {
+			continue
+		}
+
+		status := api.getAlertStatus(a.Fingerprint())
+
+		if !showActive && status.State == types.AlertStateActive {
+			continue
+		}
+
+		if !showUnprocessed && status.State == types.AlertStateUnprocessed {
+			continue
+		}
+
+		if !showSilenced && len(status.SilencedBy) != 0 {
+			continue
+		}
+
+		if !showInhibited && len(status.InhibitedBy) != 0 {
+			continue
+		}
+
+		alert := &Alert{
+			Alert:       &a.Alert,
+			Status:      status,
+			Receivers:   receivers,
+			Fingerprint: a.Fingerprint().String(),
+			Stale:       api.isStale(a, now),
+		}
+		if expandSilences {
+			if alert.Silences, err = api.alertSilences(a.Labels, status.SilencedBy, silences); err != nil {
+				break
+			}
+		}
+		if len(receivers) > 0 {
+			alert.PrimaryReceiver = receivers[0]
+			// The labels of the stored alert must not be modified, and
+			// labels set by its source take precedence.
+			if _, ok := a.Labels[receiverLabel]; receiverLabel != "" && !ok {
+				ma := a.Alert
+				ma.Labels = a.Labels.Clone()
+				ma.Labels[receiverLabel] = model.LabelValue(alert.PrimaryReceiver)
+				alert.Alert = &ma
+			}
+		}
+
+		res = append(res, alert)
+	}
+	api.mtx.RUnlock()
+
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Fingerprint < res[j].Fingerprint
+	})
+	api.respondWithWarnings(w, res, warnings)
+}
+
+// alertSilences returns the silences of the given IDs along with the matchers
+// the label set satisfies. Silences are looked up in the cache before the
+// store, and silences which no longer exist are skipped.
+func (api *API) alertSilences(lset model.LabelSet, ids []string, cache map[string]*types.Silence) ([]*alertSilence, error) {
+	res := make([]*alertSilence, 0, len(ids))
+	for _, id := range ids {
+		sil, ok := cache[id]
+		if !ok {
+			ps, _, err := api.silences.Query(silence.QIDs(id))
+			if err != nil {
+				return nil, err
+			}
+			if len(ps) > 0 {
+				if sil, err = silenceFromProto(ps[0]); err != nil {
+					return nil, err
+				}
+			}
+			cache[id] = sil
+		}
+		if sil == nil {
+			continue
+		}
+		as := &alertSilence{Silence: sil, MatchedMatchers: labels.Matchers{}}
+		for _, m := range sil.Matchers {
+			if m.Matches(string(lset[model.LabelName(m.Name)])) {
+				as.MatchedMatchers = append(as.MatchedMatchers, m)
+			}
+		}
+		res = append(res, as)
+	}
+	return res, nil
+}
+
+// listAlertNames responds with the number of firing alerts per alertname,
+// optionally restricted to the alerts matching the filter parameter. Alerts
+// without an alertname label are counted under the empty name.
+func (api *API) listAlertNames(w http.ResponseWriter, r *http.Request) {
+	var (
+		err      error
+		res      = map[string]int{}
+		matchers = []*labels.Matcher{}
+		ctx      = r.Context()
+	)
+
+	if filter := r.FormValue("filter"); filter != "" {
+		matchers, err = labels.ParseMatchers(filter)
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: err,
+			}, nil)
+			return
+		}
+	}
+
+	alerts := api.alerts.GetPending()
+	defer alerts.Close()
+
+	now := time.Now()
+	for a := range alerts.Next() {
+		if err = alerts.Err(); err != nil {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if a.ResolvedAt(now) || !alertMatchesFilterLabels(&a.Alert, matchers) {
+			continue
+		}
+		res[string(a.Labels[model.AlertNameLabel])]++
+	}
+
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	api.respond(w, res)
+}
+
+// alertStateCounts are the numbers of alerts by state.
+type alertStateCounts struct {
+	Active      int `json:"active"`
+	Suppressed  int `json:"suppressed"`
+	Unprocessed int `json:"unprocessed"`
+}
+
+func (c *alertStateCounts) add(state types.AlertState) {
+	switch state {
+	case types.AlertStateActive:
+		c.Active++
+	case types.AlertStateSuppressed:
+		c.Suppressed++
+	case types.AlertStateUnprocessed:
+		c.Unprocessed++
+	}
+}
+
+type alertCounts struct {
+	alertStateCounts
+	// Receivers are the counts by receiver if requested. Alerts routed to
+	// several receivers are counted for each of them.
+	Receivers map[string]*alertStateCounts `json:"receivers,omitempty"`
+}
+
+// countAlerts counts the current alerts matching the filter by state, and
+// optionally by receiver, without listing them.
+func (api *API) countAlerts(w http.ResponseWriter, r *http.Request) {
+	var (
+		err        error
+		res        alertCounts
+		matchers   = []*labels.Matcher{}
+		byReceiver bool
+		ctx        = r.Context()
+	)
+
+	if filter := r.FormValue("filter"); filter != "" {
+		matchers, err = labels.ParseMatchers(filter)
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: err,
+			}, nil)
+			return
+		}
+	}
+
+	switch v := r.FormValue("byReceiver"); v {
+	case "", "false":
+	case "true":
+		byReceiver = true
+	default:
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("parameter %q can either be 'true' or 'false', not %q", "byReceiver", v),
+		}, nil)
+		return
+	}
+
+	alerts := api.alerts.GetPending()
+	defer alerts.Close()
+
+	api.mtx.RLock()
+	if byReceiver {
+		// All receivers of the routing tree are present, even without alerts.
+		res.Receivers = map[string]*alertStateCounts{}
+		api.route.Walk(func(r *dispatch.Route) {
+			res.Receivers[r.RouteOpts.Receiver] = &alertStateCounts{}
+		})
+	}
+	now := time.Now()
+	for a := range alerts.Next() {
+		if err = alerts.Err(); err != nil {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if a.ResolvedAt(now) || !alertMatchesFilterLabels(&a.Alert, matchers) {
+			continue
+		}
+
+		state := api.getAlertStatus(a.Fingerprint()).State
+		res.add(state)
+		if !byReceiver {
+			continue
+		}
+		seen := map[string]struct{}{}
+		for _, route := range api.route.Match(a.Labels) {
+			recv := route.RouteOpts.Receiver
+			if _, ok := seen[recv]; ok {
+				continue
+			}
+			seen[recv] = struct{}{}
+			c, ok := res.Receivers[recv]
+			if !ok {
+				c = &alertStateCounts{}
+				res.Receivers[recv] = c
+			}
+			c.add(state)
+		}
+	}
+	api.mtx.RUnlock()
+
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	api.respond(w, res)
+}
+
+func receiversMatchFilter(receivers []string, filter *regexp.Regexp) bool {
+	for _, r := range receivers {
+		if filter.MatchString(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// receiversInSet returns true if any of the receivers is in the set.
+func receiversInSet(receivers []string, set map[string]struct{}) bool {
+	for _, r := range receivers {
+		if _, ok := set[r]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationsPresent returns true if the annotations contain all keys of has
+// and none of lacks, regardless of their values.
+func annotationsPresent(annotations model.LabelSet, has, lacks []model.LabelName) bool {
+	for _, n := range has {
+		if _, ok := annotations[n]; !ok {
+			return false
+		}
+	}
+	for _, n := range lacks {
+		if _, ok := annotations[n]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// routesMatchPath returns true if the path from the root of the route tree to
+// any of the routes passes through consecutive nodes with the given receivers.
+func routesMatchPath(routes []*dispatch.Route, receivers []string) bool {
+	for _, r := range routes {
+		path := r.Path()
+		for i := 0; i+len(receivers) <= len(path); i++ {
+			match := true
+			for j, recv := range receivers {
+				if path[i+j].RouteOpts.Receiver != recv {
+					match = false
+					break
+				}
+			}
+			if match {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isStale returns true if the alert wasn't updated for longer than the
+// staleness threshold at the given time.
+func (api *API) isStale(a *types.Alert, now time.Time) bool {
+	return api.staleThreshold > 0 && now.Sub(a.UpdatedAt) > api.staleThreshold
+}
+
+// alertMatchesFilterLabels returns true if the alert matches all matchers.
+// As everywhere else, a missing label is treated like a label with an empty
+// value: `team=""` selects alerts without a team label and `team!=""` selects
+// alerts with one.
+func alertMatchesFilterLabels(a *model.Alert, matchers []*labels.Matcher) bool {
+	return labels.Matchers(matchers).Matches(a.Labels)
+}
+
+// alertStatusResult is the status of an alert looked up by fingerprint.
+// Found is false and the status is omitted if the alert is unknown.
+type alertStatusResult struct {
+	Found bool `json:"found"`
+	*types.AlertStatus
+}
+
+// alertStatuses responds with the statuses of the alerts with the
+// fingerprints posted as an array.
+func (api *API) alertStatuses(w http.ResponseWriter, r *http.Request) {
+	var fps []string
+	if err := api.receive(r, &fps); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	res := make(map[string]alertStatusResult, len(fps))
+	for _, s := range fps {
+		fp, err := model.ParseFingerprint(s)
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("invalid fingerprint %q: %w", s, err),
+			}, nil)
+			return
+		}
+
+		_, err = api.alerts.Get(fp)
+		switch {
+		case errors.Is(err, provider.ErrNotFound), errors.Is(err, store.ErrNotFound):
+			res[s] = alertStatusResult{}
+			continue
+		case err != nil:
+			api.respondError(w, apiError{
+				typ: errorInternal,
+				err: err,
+			}, nil)
+			return
+		}
+		status := api.getAlertStatus(fp)
+		res[s] = alertStatusResult{Found: true, AlertStatus: &status}
+	}
+
+	api.respond(w, res)
+}
+
+// alertBatch is a batch of alerts posted with labels common to all of them.
+type alertBatch struct {
+	CommonLabels model.LabelSet `json:"commonLabels"`
+	Alerts       []*types.Alert `json:"alerts"`
+}
+
+// addAlerts inserts the posted alerts, given either as an array or as an
+// alertBatch. The common labels of a batch are added to each alert unless it
+// has a label with the same name.
+func (api *API) addAlerts(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := api.receive(r, &body); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	var (
+		alerts []*types.Alert
+		err    error
+	)
+	if bytes.HasPrefix(bytes.TrimLeft(body, " \t\r\n"), []byte("{")) {
+		var batch alertBatch
+		if err = json.Unmarshal(body, &batch); err == nil {
+			alerts = batch.Alerts
+			for _, a := range alerts {
+				addCommonLabels(a, batch.CommonLabels)
+			}
+		}
+	} else {
+		err = json.Unmarshal(body, &alerts)
+	}
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.insertAlerts(w, r, alerts...)
+}
+
+// addCommonLabels adds the common labels missing from the labels of the alert.
+func addCommonLabels(a *types.Alert, common model.LabelSet) {
+	if len(common) == 0 {
+		return
+	}
+	if a.Labels == nil {
+		a.Labels = make(model.LabelSet, len(common))
+	}
+	for ln, lv := range common {
+		if _, ok := a.Labels[ln]; !ok {
+			a.Labels[ln] = lv
+		}
+	}
+}
+
+// rejectedAlert is an alert of a request that failed validation. The index is
+// the position of the alert in the request.
+type rejectedAlert struct {
+	Index       int    `json:"index"`
+	Fingerprint string `json:"fingerprint"`
+	Error       string `json:"error"`
+}
+
+func (api *API) insertAlerts(w http.ResponseWriter, r *http.Request, alerts ...*types.Alert) {
+	// The ingestion drops alerts, so the positions in the request are
+	// recorded before.
+	indices := make(map[*types.Alert]int, len(alerts))
+	for i, a := range alerts {
+		indices[a] = i
+	}
+
+	api.mtx.RLock()
+	cfg := api.config
+	api.mtx.RUnlock()
+
+	validAlerts, rejections := api.ingester.Ingest(alerts, cfg, ingest.Options{
+		Mutate:   api.mutateAlert,
+		GraceEnd: api.uptime.Add(api.startupGracePeriod),
+	}, time.Now())
+
+	var (
+		validationErrs = &types.MultiError{}
+		rejected       []rejectedAlert
+	)
+	for _, rj := range rejections {
+		validationErrs.Add(rj.Err)
+		rejected = append(rejected, rejectedAlert{
+			Index:       indices[rj.Alert],
+			Fingerprint: rj.Alert.Fingerprint().String(),
+			Error:       rj.Err.Error(),
+		})
+	}
+	if err := api.alerts.Put(validAlerts...); err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+
+	if validationErrs.Len() > 0 {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: validationErrs,
+		}, rejected)
+		return
+	}
+
+	api.respond(w, nil)
+}
+
+// mutateAlert applies the alert mutators to the alert in order, stopping at
+// the first one rejecting it.
+func (api *API) mutateAlert(a *types.Alert) error {
+	for _, m := range api.alertMutators {
+		if err := m.MutateAlert(a); err != nil {
+			return fmt.Errorf("alert %s rejected: %w", a.Labels, err)
+		}
+	}
+	return nil
+}
+
+func (api *API) setSilence(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		types.Silence
+		// Preset is the name of the silence preset prefilling the fields
+		// omitted by the silence.
+		Preset string `json:"preset,omitempty"`
+	}
+	if err := api.receive(r, &req); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+	sil := req.Silence
+
+	if req.Preset != "" {
+		var preset *config.SilencePreset
+		api.mtx.RLock()
+		for _, sp := range api.config.SilencePresets {
+			if sp.Name == req.Preset {
+				preset = sp
+				break
+			}
+		}
+		api.mtx.RUnlock()
+
+		if preset == nil {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("unknown silence preset %q", req.Preset),
+			}, nil)
+			return
+		}
+		applySilencePreset(&sil, preset, time.Now())
+	}
+
+	if api.maxStartSkew > 0 && sil.ID == "" && !sil.StartsAt.IsZero() {
+		now := time.Now()
+		if sil.StartsAt.Before(now.Add(-api.maxStartSkew)) {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("start time can't be more than %s in the past", api.maxStartSkew),
+			}, nil)
+			return
+		}
+		if sil.StartsAt.Before(now) {
+			sil.StartsAt = now
+		}
+	}
+
+	if api.commentPattern != nil && !api.commentPattern.MatchString(sil.Comment) {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("comment must match %q", api.commentPattern.String()),
+		}, nil)
+		return
+	}
+
+	// This is an API only validation, it cannot be done internally
+	// because the expired silence is semantically important.
+	// But one should not be able to create expired silences, that
+	// won't have any use.
+	if sil.Expired() {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: errors.New("start time must not be equal to end time"),
+		}, nil)
+		return
+	}
+
+	if sil.EndsAt.Before(time.Now()) {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: errors.New("end time can't be in the past"),
+		}, nil)
+		return
+	}
+
+	if err := api.checkSilenceDuration(sil.StartsAt, sil.EndsAt); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	psil, err := silenceToProto(&sil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	sid, err := api.silences.Set(psil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.respondStoredSilence(w, sid)
+}
+
+// checkSilenceDuration returns an error if a silence from start to end would
+// last longer than the maximum duration of silences, if configured. Silences
+// without a start time start now.
+func (api *API) checkSilenceDuration(start, end time.Time) error {
+	api.mtx.RLock()
+	var max time.Duration
+	if api.config != nil && api.config.Global != nil {
+		max = time.Duration(api.config.Global.MaxSilenceDuration)
+	}
+	api.mtx.RUnlock()
+
+	if max == 0 {
+		return nil
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if d := end.Sub(start); d > max {
+		return fmt.Errorf("silence duration %s exceeds the maximum of %s", model.Duration(d.Round(time.Second)), model.Duration(max))
+	}
+	return nil
+}
+
+// respondStoredSilence responds with the ID and the stored silence to show
+// how it was normalized.
+func (api *API) respondStoredSilence(w http.ResponseWriter, sid string) {
+	psil, err := api.silences.QueryOne(silence.QIDs(sid))
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	stored, err := silenceFromProto(psil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.respond(w, struct {
+		SilenceID string         `json:"silenceId"`
+		Silence   *types.Silence `json:"silence"`
+	}{
+		SilenceID: sid,
+		Silence:   stored,
+	})
+}
+
+// updateSilence applies the matchers, comment and end time in the request
+// body to the silence of the given ID. Like when posting a silence with an
+// ID, changing the matchers of an active silence replaces it by a new
+// silence, whose ID is returned.
+func (api *API) updateSilence(w http.ResponseWriter, r *http.Request) {
+	sid := route.Param(r.Context(), "sid")
+
+	var req struct {
+		Matchers labels.Matchers `json:"matchers"`
+		Comment  *string         `json:"comment"`
+		EndsAt   *time.Time      `json:"endsAt"`
+	}
+	if err := api.receive(r, &req); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	psil, err := api.silences.QueryOne(silence.QIDs(sid))
+	if err != nil {
+		typ := errorInternal
+		if err == silence.ErrNotFound {
+			typ = errorNotFound
+		}
+		api.respondError(w, apiError{
+			typ: typ,
+			err: err,
+		}, nil)
+		return
+	}
+	sil, err := silenceFromProto(psil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
+		return
+	}
+	if sil.Status.State == types.SilenceStateExpired {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("silence %s is expired", sid),
+		}, nil)
+		return
+	}
+
+	if len(req.Matchers) > 0 {
+		sil.Matchers = req.Matchers
+	}
+	if req.Comment != nil {
+		sil.Comment = *req.Comment
+		if api.commentPattern != nil && !api.commentPattern.MatchString(sil.Comment) {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: fmt.Errorf("comment must match %q", api.commentPattern.String()),
+			}, nil)
+			return
+		}
+	}
+	if req.EndsAt != nil {
+		if req.EndsAt.Before(time.Now()) {
+			api.respondError(w, apiError{
+				typ: errorBadData,
+				err: errors.New("end time can't be in the past"),
+			}, nil)
+			return
+		}
+		sil.EndsAt = *req.EndsAt
+	}
+	if err := api.checkSilenceDuration(sil.StartsAt, sil.EndsAt); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	psil, err = silenceToProto(sil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+	sid, err = api.silences.Set(psil)
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
+	api.respondStoredSilence(w, sid)
+}
+
+// applySilencePreset fills the fields omitted by the silence from the preset.
+// The matchers of the preset are added unless the silence has a matcher for
+// the same label name. A silence without a start time starts now.
+func applySilencePreset(sil *types.Silence, preset *config.SilencePreset, now time.Time) {
+	names := make(map[string]struct{}, len(sil.Matchers))
+	for _, m := range sil.Matchers {
+		names[m.Name] = struct{}{}
+	}
+	for _, m := range preset.Matchers {
+		if _, ok := names[m.Name]; !ok {
+			sil.Matchers = append(sil.Matchers, m)
+		}
+	}
+	if sil.Comment == "" {
+		sil.Comment = preset.Comment
+	}
+	if sil.StartsAt.IsZero() {
+		sil.StartsAt = now
+	}
+	if sil.EndsAt.IsZero() && preset.Duration > 0 {
+		sil.EndsAt = sil.StartsAt.Add(time.Duration(preset.Duration))
+	}
+}
+
+type silencePreset struct {
+	Name     string          `json:"name"`
+	Matchers labels.Matchers `json:"matchers"`
+	Comment  string          `json:"comment,omitempty"`
+	Duration string          `json:"duration,omitempty"`
+}
+
+func (api *API) listSilencePresets(w http.ResponseWriter, r *http.Request) {
+	res := []silencePreset{}
+
+	api.mtx.RLock()
+	for _, sp := range api.config.SilencePresets {
+		p := silencePreset{
+			Name:     sp.Name,
+			Matchers: labels.Matchers(sp.Matchers),
+			Comment:  sp.Comment,
+		}
+		if p.Matchers == nil {
+			p.Matchers = labels.Matchers{}
+		}
+		if sp.Duration > 0 {
+			p.Duration = sp.Duration.String()
+		}
+		res = append(res, p)
+	}
+	api.mtx.RUnlock()
+
+	api.respond(w, res)
+}
+
+// routeTimings are the effective timings of the notifications of a route.
+type routeTimings struct {
+	Route          string         `json:"route"`
+	Receiver       string         `json:"receiver"`
+	GroupWait      model.Duration `json:"groupWait"`
+	GroupInterval  model.Duration `json:"groupInterval"`
+	RepeatInterval model.Duration `json:"repeatInterval"`
+}
+
+// routeTimings responds with the timings of the notifications of the routes
+// matching the given label set, as inherited through the routing tree. The
+// repeat interval accounts for the override of the receiver.
+func (api *API) routeTimings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Labels model.LabelSet `json:"labels"`
+	}
+	if err := api.receive(r, &req); err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
 	}
-	return matchFilterLabels(matchers, sms)
-}
-
-func (api *API) addAlerts(w http.ResponseWriter, r *http.Request) {
-	var alerts []*types.Alert
-	if err := api.receive(r, &alerts); err != nil {
+	if err := req.Labels.Validate(); err != nil {
 		api.respondError(w, apiError{
 			typ: errorBadData,
 			err: err,
@@ -402,113 +2788,138 @@ func (api *API) addAlerts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.insertAlerts(w, r, alerts...)
-}
-
-func (api *API) insertAlerts(w http.ResponseWriter, r *http.Request, alerts ...*types.Alert) {
-	now := time.Now()
-
 	api.mtx.RLock()
-	resolveTimeout := time.Duration(api.config.Global.ResolveTimeout)
+	rt, conf := api.route, api.config
 	api.mtx.RUnlock()
 
-	for _, alert := range alerts {
-		alert.UpdatedAt = now
-
-		// Ensure StartsAt is set.
-		if alert.StartsAt.IsZero() {
-			if alert.EndsAt.IsZero() {
-				alert.StartsAt = now
-			} else {
-				alert.StartsAt = alert.EndsAt
+	repeatIntervals := map[string]model.Duration{}
+	if conf != nil {
+		for _, rc := range conf.Receivers {
+			if rc.RepeatInterval != nil {
+				repeatIntervals[rc.Name] = *rc.RepeatInterval
 			}
 		}
-		// If no end time is defined, set a timeout after which an alert
-		// is marked resolved if it is not updated.
-		if alert.EndsAt.IsZero() {
-			alert.Timeout = true
-			alert.EndsAt = now.Add(resolveTimeout)
+	}
+
+	res := []routeTimings{}
+	for _, rr := range rt.Match(req.Labels) {
+		t := routeTimings{
+			Route:          rr.Key(),
+			Receiver:       rr.RouteOpts.Receiver,
+			GroupWait:      model.Duration(rr.RouteOpts.GroupWait),
+			GroupInterval:  model.Duration(rr.RouteOpts.GroupInterval),
+			RepeatInterval: model.Duration(rr.RouteOpts.RepeatInterval),
 		}
-		if alert.EndsAt.After(time.Now()) {
-			api.m.Firing().Inc()
-		} else {
-			api.m.Resolved().Inc()
+		if ri, ok := repeatIntervals[t.Receiver]; ok {
+			t.RepeatInterval = ri
 		}
+		res = append(res, t)
 	}
 
-	// Make a best effort to insert all alerts that are valid.
-	var (
-		validAlerts    = make([]*types.Alert, 0, len(alerts))
-		validationErrs = &types.MultiError{}
-	)
-	for _, a := range alerts {
-		removeEmptyLabels(a.Labels)
+	api.respond(w, res)
+}
 
-		if err := a.Validate(); err != nil {
-			validationErrs.Add(err)
-			api.m.Invalid().Inc()
-			continue
-		}
-		validAlerts = append(validAlerts, a)
+// routeImpact is a route that silenced alerts may reach.
+type routeImpact struct {
+	Route    string `json:"route"`
+	Receiver string `json:"receiver"`
+}
+
+// silenceImpact responds with the routes and receivers that alerts matching
+// the given silence matchers may reach. See dispatch.Route.MayMatch for the
+// approximation involved.
+func (api *API) silenceImpact(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Matchers labels.Matchers `json:"matchers"`
 	}
-	if err := api.alerts.Put(validAlerts...); err != nil {
+	if err := api.receive(r, &req); err != nil {
 		api.respondError(w, apiError{
-			typ: errorInternal,
+			typ: errorBadData,
 			err: err,
 		}, nil)
 		return
 	}
-
-	if validationErrs.Len() > 0 {
+	if len(req.Matchers) == 0 {
 		api.respondError(w, apiError{
 			typ: errorBadData,
-			err: validationErrs,
+			err: errors.New("at least one matcher is required"),
 		}, nil)
 		return
 	}
 
-	api.respond(w, nil)
-}
+	api.mtx.RLock()
+	rt := api.route
+	api.mtx.RUnlock()
 
-func removeEmptyLabels(ls model.LabelSet) {
-	for k, v := range ls {
-		if string(v) == "" {
-			delete(ls, k)
+	res := struct {
+		Receivers []string      `json:"receivers"`
+		Routes    []routeImpact `json:"routes"`
+	}{
+		Receivers: []string{},
+		Routes:    []routeImpact{},
+	}
+	seen := map[string]struct{}{}
+	for _, rr := range rt.MayMatch(req.Matchers) {
+		res.Routes = append(res.Routes, routeImpact{
+			Route:    rr.Key(),
+			Receiver: rr.RouteOpts.Receiver,
+		})
+		if _, ok := seen[rr.RouteOpts.Receiver]; !ok {
+			seen[rr.RouteOpts.Receiver] = struct{}{}
+			res.Receivers = append(res.Receivers, rr.RouteOpts.Receiver)
 		}
 	}
+	sort.Strings(res.Receivers)
+
+	api.respond(w, res)
 }
 
-func (api *API) setSilence(w http.ResponseWriter, r *http.Request) {
-	var sil types.Silence
-	if err := api.receive(r, &sil); err != nil {
+func (api *API) getSilence(w http.ResponseWriter, r *http.Request) {
+	sid := route.Param(r.Context(), "sid")
+
+	sils, _, err := api.silences.Query(silence.QIDs(sid))
+	if err != nil || len(sils) == 0 {
+		http.Error(w, fmt.Sprint("Error getting silence: ", err), http.StatusNotFound)
+		return
+	}
+	sil, err := silenceFromProto(sils[0])
+	if err != nil {
 		api.respondError(w, apiError{
-			typ: errorBadData,
+			typ: errorInternal,
 			err: err,
 		}, nil)
 		return
 	}
 
-	// This is an API only validation, it cannot be done internally
-	// because the expired silence is semantically important.
-	// But one should not be able to create expired silences, that
-	// won't have any use.
-	if sil.Expired() {
+	api.respond(w, sil)
+}
+
+func (api *API) delSilence(w http.ResponseWriter, r *http.Request) {
+	sid := route.Param(r.Context(), "sid")
+
+	if err := api.silences.Expire(sid); err != nil {
 		api.respondError(w, apiError{
 			typ: errorBadData,
-			err: errors.New("start time must not be equal to end time"),
+			err: err,
 		}, nil)
 		return
 	}
+	api.respond(w, nil)
+}
 
-	if sil.EndsAt.Before(time.Now()) {
+// delSilences expires the active and pending silences matching the filter
+// parameter and responds with their IDs. The filter is required to prevent
+// expiring all silences by accident.
+func (api *API) delSilences(w http.ResponseWriter, r *http.Request) {
+	filter := r.FormValue("filter")
+	if filter == "" {
 		api.respondError(w, apiError{
 			typ: errorBadData,
-			err: errors.New("end time can't be in the past"),
+			err: errors.New("parameter \"filter\" is required"),
 		}, nil)
 		return
 	}
-
-	psil, err := silenceToProto(&sil)
+	matchers, err := labels.ParseMatchers(filter)
 	if err != nil {
 		api.respondError(w, apiError{
 			typ: errorBadData,
@@ -517,31 +2928,76 @@ func (api *API) setSilence(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sid, err := api.silences.Set(psil)
+	psils, _, err := api.silences.Query(silence.QState(types.SilenceStateActive, types.SilenceStatePending))
 	if err != nil {
 		api.respondError(w, apiError{
-			typ: errorBadData,
+			typ: errorInternal,
 			err: err,
 		}, nil)
 		return
 	}
 
+	expired := []string{}
+	for _, ps := range psils {
+		s, err := silenceFromProto(ps)
+		if err != nil {
+			api.respondError(w, apiError{
+				typ: errorInternal,
+				err: err,
+			}, nil)
+			return
+		}
+		if !silenceMatchesFilterLabels(s, matchers) {
+			continue
+		}
+		if err := api.silences.Expire(s.ID); err != nil {
+			// The silence may have expired in the meantime.
+			level.Warn(api.logger).Log("msg", "Failed to expire silence", "id", s.ID, "err", err)
+			continue
+		}
+		expired = append(expired, s.ID)
+	}
+	sort.Strings(expired)
+	level.Info(api.logger).Log("msg", "Expired silences by filter", "filter", filter, "expired", len(expired))
+
 	api.respond(w, struct {
-		SilenceID string `json:"silenceId"`
+		Expired int      `json:"expired"`
+		IDs     []string `json:"ids"`
 	}{
-		SilenceID: sid,
+		Expired: len(expired),
+		IDs:     expired,
 	})
 }
 
-func (api *API) getSilence(w http.ResponseWriter, r *http.Request) {
-	sid := route.Param(r.Context(), "sid")
+// expireAllSilences expires all active and pending silences and responds
+// with their number. As a safeguard, the confirm parameter must be the name
+// of the instance.
+func (api *API) expireAllSilences(w http.ResponseWriter, r *http.Request) {
+	if !api.expireAll {
+		api.respondError(w, apiError{
+			typ: errorForbidden,
+			err: errors.New("expiring all silences is disabled"),
+		}, nil)
+		return
+	}
 
-	sils, _, err := api.silences.Query(silence.QIDs(sid))
-	if err != nil || len(sils) == 0 {
-		http.Error(w, fmt.Sprint("Error getting silence: ", err), http.StatusNotFound)
+	instance, err := api.instanceName()
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorInternal,
+			err: err,
+		}, nil)
 		return
 	}
-	sil, err := silenceFromProto(sils[0])
+	if confirm := r.FormValue("confirm"); confirm != instance {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: fmt.Errorf("parameter \"confirm\" must be the instance name %q, not %q", instance, confirm),
+		}, nil)
+		return
+	}
+
+	sils, _, err := api.silences.Query(silence.QState(types.SilenceStateActive, types.SilenceStatePending))
 	if err != nil {
 		api.respondError(w, apiError{
 			typ: errorInternal,
@@ -550,23 +3006,214 @@ func (api *API) getSilence(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.respond(w, sil)
+	var requestID string
+	if rw, ok := w.(*requestWriter); ok {
+		requestID = rw.requestID
+	}
+	level.Warn(api.logger).Log("msg", "Expiring all silences", "silences", len(sils), "remote_addr", r.RemoteAddr, "request_id", requestID)
+
+	expired := 0
+	for _, s := range sils {
+		if err := api.silences.Expire(s.Id); err != nil {
+			// The silence may have expired in the meantime.
+			level.Warn(api.logger).Log("msg", "Failed to expire silence", "id", s.Id, "err", err)
+			continue
+		}
+		expired++
+	}
+	level.Warn(api.logger).Log("msg", "Expired all silences", "expired", expired, "request_id", requestID)
+
+	api.respond(w, struct {
+		Expired int `json:"expired"`
+	}{
+		Expired: expired,
+	})
 }
 
-func (api *API) delSilence(w http.ResponseWriter, r *http.Request) {
-	sid := route.Param(r.Context(), "sid")
+// extendResult is the result of extending a single silence.
+type extendResult struct {
+	ID       string `json:"id"`
+	Extended bool   `json:"extended"`
+	// SilenceID is the ID of the extended silence, which differs from the
+	// requested one if the silence couldn't be updated in place.
+	SilenceID string     `json:"silenceId,omitempty"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
 
-	if err := api.silences.Expire(sid); err != nil {
+// extendSilencesByID moves the end of the silences with the given IDs forward
+// by the given duration and responds with the result for each ID. Expired
+// silences are skipped.
+func (api *API) extendSilencesByID(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs      []string       `json:"ids"`
+		Duration model.Duration `json:"duration"`
+	}
+	if err := api.receive(r, &req); err != nil {
 		api.respondError(w, apiError{
 			typ: errorBadData,
 			err: err,
 		}, nil)
 		return
 	}
-	api.respond(w, nil)
+	if len(req.IDs) == 0 {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: errors.New("at least one silence ID is required"),
+		}, nil)
+		return
+	}
+	if req.Duration <= 0 {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: errors.New("duration must be positive"),
+		}, nil)
+		return
+	}
+
+	res := make([]extendResult, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		er := extendResult{ID: id}
+		sil, err := api.silences.QueryOne(silence.QIDs(id))
+		switch {
+		case err != nil:
+			er.Error = err.Error()
+		case types.CalcSilenceState(sil.StartsAt, sil.EndsAt) == types.SilenceStateExpired:
+			er.Error = "silence is expired"
+		default:
+			sil.EndsAt = sil.EndsAt.Add(time.Duration(req.Duration))
+			if err := api.checkSilenceDuration(sil.StartsAt, sil.EndsAt); err != nil {
+				er.Error = err.Error()
+				break
+			}
+			sid, err := api.silences.Set(sil)
+			if err != nil {
+				er.Error = err.Error()
+				break
+			}
+			er.Extended = true
+			er.SilenceID = sid
+			er.EndsAt = &sil.EndsAt
+		}
+		res = append(res, er)
+	}
+
+	api.respond(w, res)
+}
+
+// instanceName returns the name of the peer in the cluster, or the host name
+// if clustering is disabled.
+func (api *API) instanceName() (string, error) {
+	if api.peer != nil {
+		return api.peer.Name(), nil
+	}
+	return os.Hostname()
+}
+
+// nflogEntry is the API representation of a notification log entry.
+type nflogEntry struct {
+	GroupKey       string    `json:"groupKey"`
+	Receiver       string    `json:"receiver"`
+	Integration    string    `json:"integration"`
+	Index          uint32    `json:"index"`
+	Timestamp      time.Time `json:"timestamp"`
+	FiringAlerts   []string  `json:"firingAlerts"`
+	ResolvedAlerts []string  `json:"resolvedAlerts"`
+}
+
+func (api *API) listNotificationLog(w http.ResponseWriter, r *http.Request) {
+	if api.nflog == nil {
+		api.respondError(w, apiError{
+			typ: errorUnavailable,
+			err: errors.New("notification log not available"),
+		}, nil)
+		return
+	}
+
+	entries := api.nflog.Entries(r.FormValue("groupKey"), r.FormValue("receiver"))
+	res := make([]*nflogEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, &nflogEntry{
+			GroupKey:       string(e.GroupKey),
+			Receiver:       e.Receiver.GroupName,
+			Integration:    e.Receiver.Integration,
+			Index:          e.Receiver.Idx,
+			Timestamp:      e.Timestamp,
+			FiringAlerts:   alertHashes(e.FiringAlerts),
+			ResolvedAlerts: alertHashes(e.ResolvedAlerts),
+		})
+	}
+	api.respond(w, res)
+}
+
+// alertHashes formats the alert hashes of a notification log entry like
+// fingerprints, as 64-bit integers don't survive JSON decoding in all clients.
+func alertHashes(hashes []uint64) []string {
+	res := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		res = append(res, model.Fingerprint(h).String())
+	}
+	return res
+}
+
+// silenceSortKeys are the keys by which listed silences can be ordered
+// within each state.
+var silenceSortKeys = map[string]func(a, b *types.Silence) bool{
+	"id":        func(a, b *types.Silence) bool { return a.ID < b.ID },
+	"createdBy": func(a, b *types.Silence) bool { return a.CreatedBy < b.CreatedBy },
+	"startsAt":  func(a, b *types.Silence) bool { return a.StartsAt.Before(b.StartsAt) },
+	"endsAt":    func(a, b *types.Silence) bool { return a.EndsAt.Before(b.EndsAt) },
+	"updatedAt": func(a, b *types.Silence) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+}
+
+// parseSilenceSort parses the sort parameter of listSilences, which is one of
+// the silence sort keys optionally prefixed with "-" for descending order. It
+// returns nil if no sort parameter was given.
+func parseSilenceSort(param string) (func(sils []*types.Silence), error) {
+	if param == "" {
+		return nil, nil
+	}
+	key, desc := param, false
+	if strings.HasPrefix(key, "-") {
+		key, desc = key[1:], true
+	}
+	less, ok := silenceSortKeys[key]
+	if !ok {
+		keys := make([]string, 0, len(silenceSortKeys))
+		for k := range silenceSortKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("invalid sort key %q, must be one of %s", key, strings.Join(keys, ", "))
+	}
+	return func(sils []*types.Silence) {
+		sort.Slice(sils, func(i, j int) bool {
+			a, b := sils[i], sils[j]
+			if desc {
+				a, b = b, a
+			}
+			if less(a, b) {
+				return true
+			}
+			if less(b, a) {
+				return false
+			}
+			// Order silences with equal keys by ID, so that results are stable.
+			return sils[i].ID < sils[j].ID
+		})
+	}, nil
 }
 
 func (api *API) listSilences(w http.ResponseWriter, r *http.Request) {
+	sortSilences, err := parseSilenceSort(r.FormValue("sort"))
+	if err != nil {
+		api.respondError(w, apiError{
+			typ: errorBadData,
+			err: err,
+		}, nil)
+		return
+	}
+
 	psils, _, err := api.silences.Query()
 	if err != nil {
 		api.respondError(w, apiError{
@@ -587,6 +3234,7 @@ func (api *API) listSilences(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	comment := strings.ToLower(r.FormValue("comment"))
 
 	sils := []*types.Silence{}
 	for _, ps := range psils {
@@ -602,6 +3250,9 @@ func (api *API) listSilences(w http.ResponseWriter, r *http.Request) {
 		if !silenceMatchesFilterLabels(s, matchers) {
 			continue
 		}
+		if comment != "" && !strings.Contains(strings.ToLower(s.Comment), comment) {
+			continue
+		}
 		sils = append(sils, s)
 	}
 
@@ -618,15 +3269,21 @@ func (api *API) listSilences(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	sort.Slice(active, func(i int, j int) bool {
-		return active[i].EndsAt.Before(active[j].EndsAt)
-	})
-	sort.Slice(pending, func(i int, j int) bool {
-		return pending[i].StartsAt.Before(pending[j].EndsAt)
-	})
-	sort.Slice(expired, func(i int, j int) bool {
-		return expired[i].EndsAt.After(expired[j].EndsAt)
-	})
+	if sortSilences != nil {
+		sortSilences(active)
+		sortSilences(pending)
+		sortSilences(expired)
+	} else {
+		sort.Slice(active, func(i int, j int) bool {
+			return active[i].EndsAt.Before(active[j].EndsAt)
+		})
+		sort.Slice(pending, func(i int, j int) bool {
+			return pending[i].StartsAt.Before(pending[j].EndsAt)
+		})
+		sort.Slice(expired, func(i int, j int) bool {
+			return expired[i].EndsAt.After(expired[j].EndsAt)
+		})
+	}
 
 	// Initialize silences explicitly to an empty list (instead of nil)
 	// So that it does not get converted to "null" in JSON.
@@ -672,15 +3329,61 @@ func matchFilterLabels(matchers []*labels.Matcher, sms map[string]string) bool {
 }
 
 func silenceToProto(s *types.Silence) (*silencepb.Silence, error) {
-	sil := &silencepb.Silence{
-		Id:        s.ID,
-		StartsAt:  s.StartsAt,
-		EndsAt:    s.EndsAt,
-		UpdatedAt: s.UpdatedAt,
-		Comment:   s.Comment,
-		CreatedBy: s.CreatedBy,
+	// A silence without matchers would silence all alerts.
+	if len(s.Matchers) == 0 {
+		return nil, errors.New("silence must have at least one matcher")
 	}
 	for _, m := range s.Matchers {
+		if err := validateSilenceMatcher(m); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range s.ConditionMatchers {
+		if err := validateSilenceMatcher(m); err != nil {
+			return nil, fmt.Errorf("condition %w", err)
+		}
+	}
+	if s.NotifyBefore > 0 && !s.NotifyExpiry {
+		return nil, errors.New("notifyBefore requires notifyExpiry")
+	}
+
+	sil := &silencepb.Silence{
+		Id:           s.ID,
+		StartsAt:     s.StartsAt,
+		EndsAt:       s.EndsAt,
+		UpdatedAt:    s.UpdatedAt,
+		Comment:      s.Comment,
+		CreatedBy:    s.CreatedBy,
+		NotifyExpiry: s.NotifyExpiry,
+		NotifyBefore: time.Duration(s.NotifyBefore),
+	}
+	sil.Matchers = matchersToProto(s.Matchers)
+	sil.ConditionMatchers = matchersToProto(s.ConditionMatchers)
+	return sil, nil
+}
+
+// validateSilenceMatcher checks that the matcher has a valid label name and,
+// if it is a regular expression matcher, that its pattern compiles. Matchers
+// decoded from JSON are compiled already, but those of presets or built
+// otherwise may not be.
+func validateSilenceMatcher(m *labels.Matcher) error {
+	if m.Name == "" {
+		return fmt.Errorf("matcher %s has an empty label name", m)
+	}
+	if !model.LabelName(m.Name).IsValid() {
+		return fmt.Errorf("matcher %s has an invalid label name", m)
+	}
+	if m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp {
+		if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return fmt.Errorf("matcher %s has an invalid regular expression: %s", m, err)
+		}
+	}
+	return nil
+}
+
+func matchersToProto(ms labels.Matchers) []*silencepb.Matcher {
+	var res []*silencepb.Matcher
+	for _, m := range ms {
 		matcher := &silencepb.Matcher{
 			Name:    m.Name,
 			Pattern: m.Value,
@@ -695,9 +3398,9 @@ func silenceToProto(s *types.Silence) (*silencepb.Silence, error) {
 		case labels.MatchNotRegexp:
 			matcher.Type = silencepb.Matcher_NOT_REGEXP
 		}
-		sil.Matchers = append(sil.Matchers, matcher)
+		res = append(res, matcher)
 	}
-	return sil, nil
+	return res
 }
 
 func silenceFromProto(s *silencepb.Silence) (*types.Silence, error) {
@@ -707,12 +3410,28 @@ func silenceFromProto(s *silencepb.Silence) (*types.Silence, error) {
 		EndsAt:    s.EndsAt,
 		UpdatedAt: s.UpdatedAt,
 		Status: types.SilenceStatus{
-			State: types.CalcSilenceState(s.StartsAt, s.EndsAt),
+			State:       types.CalcSilenceState(s.StartsAt, s.EndsAt),
+			StateReason: types.CalcSilenceStateReason(s.StartsAt, s.EndsAt, time.Now()),
 		},
-		Comment:   s.Comment,
-		CreatedBy: s.CreatedBy,
+		Comment:      s.Comment,
+		CreatedBy:    s.CreatedBy,
+		NotifyExpiry: s.NotifyExpiry,
+		NotifyBefore: model.Duration(s.NotifyBefore),
 	}
-	for _, m := range s.Matchers {
+	var err error
+	if sil.Matchers, err = matchersFromProto(s.Matchers); err != nil {
+		return nil, err
+	}
+	if sil.ConditionMatchers, err = matchersFromProto(s.ConditionMatchers); err != nil {
+		return nil, err
+	}
+
+	return sil, nil
+}
+
+func matchersFromProto(pms []*silencepb.Matcher) (labels.Matchers, error) {
+	var res labels.Matchers
+	for _, m := range pms {
 		var t labels.MatchType
 		switch m.Type {
 		case silencepb.Matcher_EQUAL:
@@ -729,10 +3448,9 @@ func silenceFromProto(s *silencepb.Silence) (*types.Silence, error) {
 			return nil, err
 		}
 
-		sil.Matchers = append(sil.Matchers, matcher)
+		res = append(res, matcher)
 	}
-
-	return sil, nil
+	return res, nil
 }
 
 type status string
@@ -747,22 +3465,71 @@ type response struct {
 	Data      interface{} `json:"data,omitempty"`
 	ErrorType errorType   `json:"errorType,omitempty"`
 	Error     string      `json:"error,omitempty"`
+	Warnings  []string    `json:"warnings,omitempty"`
 }
 
 func (api *API) respond(w http.ResponseWriter, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(200)
+	api.respondWithWarnings(w, data, nil)
+}
 
+// respondWithWarnings responds successfully with data that may be incomplete
+// or otherwise questionable for the given reasons.
+func (api *API) respondWithWarnings(w http.ResponseWriter, data interface{}, warnings []string) {
+	// Marshal the response before writing the header to include the time
+	// spent in the timing headers.
 	b, err := json.Marshal(&response{
-		Status: statusSuccess,
-		Data:   data,
+		Status:   statusSuccess,
+		Data:     data,
+		Warnings: warnings,
 	})
+
+	w.Header().Set("Content-Type", "application/json")
+
 	if err != nil {
+		w.WriteHeader(200)
 		level.Error(api.logger).Log("msg", "Error marshaling JSON", "err", err)
 		return
 	}
 
-	if _, err := w.Write(b); err != nil {
+	api.write(w, 200, b)
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// write writes the header with the given status and the body, compressed
+// with gzip if the body is large enough and the client accepts it.
+func (api *API) write(w http.ResponseWriter, status int, b []byte) {
+	if api.gzipMinSize == 0 || len(b) < api.gzipMinSize {
+		w.WriteHeader(status)
+		if _, err := w.Write(b); err != nil {
+			level.Error(api.logger).Log("msg", "failed to write data to connection", "err", err)
+		}
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	if rw, ok := w.(*requestWriter); !ok || !rw.acceptsGzip {
+		w.WriteHeader(status)
+		if _, err := w.Write(b); err != nil {
+			level.Error(api.logger).Log("msg", "failed to write data to connection", "err", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+
+	gz := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(gz)
+	gz.Reset(w)
+	if _, err := gz.Write(b); err != nil {
+		level.Error(api.logger).Log("msg", "failed to write data to connection", "err", err)
+		return
+	}
+	if err := gz.Close(); err != nil {
 		level.Error(api.logger).Log("msg", "failed to write data to connection", "err", err)
 	}
 }
@@ -770,29 +3537,57 @@ func (api *API) respond(w http.ResponseWriter, data interface{}) {
 func (api *API) respondError(w http.ResponseWriter, apiErr apiError, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
+	var status int
 	switch apiErr.typ {
 	case errorBadData:
-		w.WriteHeader(http.StatusBadRequest)
+		status = http.StatusBadRequest
 	case errorInternal:
-		w.WriteHeader(http.StatusInternalServerError)
+		status = http.StatusInternalServerError
+	case errorNotFound:
+		status = http.StatusNotFound
+	case errorForbidden:
+		status = http.StatusForbidden
+	case errorMethodNotAllowed:
+		status = http.StatusMethodNotAllowed
+	case errorUnavailable:
+		status = http.StatusServiceUnavailable
 	default:
 		panic(fmt.Sprintf("unknown error type %q", apiErr.Error()))
 	}
 
+	var endpoint, remoteAddr, requestID string
+	if rw, ok := w.(*requestWriter); ok {
+		endpoint, remoteAddr, requestID = rw.endpoint, rw.remoteAddr, rw.requestID
+	}
+	msg := apiErr.err.Error()
+	if api.redactInternalErrors && apiErr.typ == errorInternal {
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		msg = fmt.Sprintf("internal error, see the logs for request ID %s", requestID)
+	}
+
 	b, err := json.Marshal(&response{
 		Status:    statusError,
 		ErrorType: apiErr.typ,
-		Error:     apiErr.err.Error(),
+		Error:     msg,
 		Data:      data,
 	})
 	if err != nil {
+		w.WriteHeader(status)
 		return
 	}
-	level.Error(api.logger).Log("msg", "API error", "err", apiErr.Error())
+	level.Error(api.logger).Log(
+		"msg", "API error",
+		"err", apiErr.Error(),
+		"errorType", apiErr.typ,
+		"status", status,
+		"endpoint", endpoint,
+		"remote_addr", remoteAddr,
+		"request_id", requestID,
+	)
 
-	if _, err := w.Write(b); err != nil {
-		level.Error(api.logger).Log("msg", "failed to write data to connection", "err", err)
-	}
+	api.write(w, status, b)
 }
 
 func (api *API) receive(r *http.Request, v interface{}) error {
@@ -15,23 +15,43 @@ package v1
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/route"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
+	"github.com/prometheus/alertmanager/cluster"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/nflog"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/provider"
+	"github.com/prometheus/alertmanager/relabel"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 )
 
@@ -39,7 +59,10 @@ import (
 type fakeAlerts struct {
 	fps    map[model.Fingerprint]int
 	alerts []*types.Alert
+	added  []*types.Alert
 	err    error
+	// gets is the number of calls of Get.
+	gets int
 }
 
 func newFakeAlerts(alerts []*types.Alert, withErr bool) *fakeAlerts {
@@ -57,9 +80,17 @@ func newFakeAlerts(alerts []*types.Alert, withErr bool) *fakeAlerts {
 	return f
 }
 
-func (f *fakeAlerts) Subscribe() provider.AlertIterator           { return nil }
-func (f *fakeAlerts) Get(model.Fingerprint) (*types.Alert, error) { return nil, nil }
+func (f *fakeAlerts) Subscribe() provider.AlertIterator { return nil }
+func (f *fakeAlerts) Get(fp model.Fingerprint) (*types.Alert, error) {
+	f.gets++
+	i, ok := f.fps[fp]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return f.alerts[i], nil
+}
 func (f *fakeAlerts) Put(alerts ...*types.Alert) error {
+	f.added = append(f.added, alerts...)
 	return f.err
 }
 func (f *fakeAlerts) GetPending() provider.AlertIterator {
@@ -155,6 +186,647 @@ func TestAddAlerts(t *testing.T) {
 	}
 }
 
+func TestAddAlertsRejected(t *testing.T) {
+	alerts := []model.Alert{
+		{Labels: model.LabelSet{"alertname": "a"}},
+		{Labels: model.LabelSet{"alertname": "b"}, StartsAt: time.Now(), EndsAt: time.Now().Add(-time.Hour)},
+		{Labels: model.LabelSet{"alertname": "c"}},
+	}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	defaultGlobalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global: &defaultGlobalConfig,
+		Route:  &config.Route{},
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var res struct {
+		ErrorType string          `json:"errorType"`
+		Data      []rejectedAlert `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, string(errorBadData), res.ErrorType)
+	require.Len(t, res.Data, 1)
+	require.Equal(t, 1, res.Data[0].Index)
+	require.Equal(t, alerts[1].Labels.Fingerprint().String(), res.Data[0].Fingerprint)
+	require.Contains(t, res.Data[0].Error, "start time")
+
+	// The valid alerts are inserted nonetheless.
+	require.Len(t, alertsProvider.added, 2)
+}
+
+func TestAddAlertsRelabel(t *testing.T) {
+	var relabelConfigs []*relabel.Config
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+- source_labels: [env]
+  regex: dev
+  action: drop
+- source_labels: [svc]
+  target_label: service
+- regex: svc
+  action: labeldrop
+- source_labels: [instance]
+  regex: '([^:]+):\d+'
+  target_label: instance
+`), &relabelConfigs))
+
+	alerts := []model.Alert{
+		{Labels: model.LabelSet{"alertname": "a", "svc": "api", "instance": "db-1:9100"}},
+		{Labels: model.LabelSet{"alertname": "b", "env": "dev"}},
+		// Left without labels after relabeling.
+		{Labels: model.LabelSet{"svc": ""}},
+	}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	defaultGlobalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global:              &defaultGlobalConfig,
+		Route:               &config.Route{},
+		AlertRelabelConfigs: relabelConfigs,
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	require.Len(t, alertsProvider.added, 1)
+	expected := model.LabelSet{"alertname": "a", "service": "api", "instance": "db-1"}
+	require.Equal(t, expected, alertsProvider.added[0].Labels)
+	// The fingerprint is computed from the relabeled labels.
+	require.Equal(t, expected.Fingerprint(), alertsProvider.added[0].Fingerprint())
+}
+
+func TestAddAlertsIngestionFilter(t *testing.T) {
+	var filter config.IngestionFilter
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+allow:
+- ['env="prod"']
+- ['env="staging"', 'severity="critical"']
+deny:
+- ['team="load-test"']
+`), &filter))
+
+	alerts := []model.Alert{
+		{Labels: model.LabelSet{"alertname": "a", "env": "prod"}},
+		{Labels: model.LabelSet{"alertname": "b", "env": "prod", "team": "load-test"}},
+		{Labels: model.LabelSet{"alertname": "c", "env": "staging", "severity": "warning"}},
+		{Labels: model.LabelSet{"alertname": "d", "env": "staging", "severity": "critical"}},
+		{Labels: model.LabelSet{"alertname": "e"}},
+	}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	defaultGlobalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global:          &defaultGlobalConfig,
+		Route:           &config.Route{},
+		IngestionFilter: &filter,
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var names []string
+	for _, a := range alertsProvider.added {
+		names = append(names, a.Name())
+	}
+	require.Equal(t, []string{"a", "d"}, names)
+	require.Equal(t, 3.0, testutil.ToFloat64(api.m.Filtered()))
+}
+
+func TestAddAlertsMutators(t *testing.T) {
+	alerts := []model.Alert{
+		{Labels: model.LabelSet{"alertname": "a", "host": "db-1"}},
+		{Labels: model.LabelSet{"alertname": "b", "host": "unknown"}},
+		{Labels: model.LabelSet{"alertname": "c"}},
+	}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	var calls []string
+	// enrich adds the team owning the host.
+	enrich := AlertMutatorFunc(func(a *types.Alert) error {
+		calls = append(calls, "enrich "+a.Name())
+		switch a.Labels["host"] {
+		case "unknown":
+			return errors.New("unknown host")
+		case "db-1":
+			a.Labels["team"] = "db"
+		}
+		return nil
+	})
+	// annotate sets the annotations according to the label added by enrich.
+	annotate := AlertMutatorFunc(func(a *types.Alert) error {
+		calls = append(calls, "annotate "+a.Name())
+		if a.Labels["team"] != "" {
+			a.Annotations = model.LabelSet{"channel": "#" + a.Labels["team"]}
+		}
+		// Empty labels are removed after the mutation.
+		a.Labels["empty"] = ""
+		return nil
+	})
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil,
+		WithAlertMutators(enrich),
+		WithAlertMutators(annotate),
+	)
+	defaultGlobalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global: &defaultGlobalConfig,
+		Route:  &config.Route{},
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `rejected: unknown host`)
+
+	// The rejected alert skips the remaining mutators and isn't stored.
+	require.Equal(t, []string{"enrich a", "annotate a", "enrich b", "enrich c", "annotate c"}, calls)
+	require.Len(t, alertsProvider.added, 2)
+	require.Equal(t, model.LabelSet{"alertname": "a", "host": "db-1", "team": "db"}, alertsProvider.added[0].Labels)
+	require.Equal(t, model.LabelSet{"channel": "#db"}, alertsProvider.added[0].Annotations)
+	require.Equal(t, model.LabelSet{"alertname": "c"}, alertsProvider.added[1].Labels)
+	require.Equal(t, 1.0, testutil.ToFloat64(api.m.Invalid()))
+}
+
+func TestAddAlertsTruncateAnnotations(t *testing.T) {
+	alerts := []model.Alert{{
+		Labels: model.LabelSet{"alertname": "a"},
+		Annotations: model.LabelSet{
+			"summary":     "0123456789",
+			"description": "0123456789a",
+			"runbook":     "ääääääääääää",
+			"details":     "0123456789abcdefghij",
+		},
+	}}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	globalConfig := config.DefaultGlobalConfig()
+	globalConfig.AnnotationMaxLength = 10
+	globalConfig.AnnotationMaxLengths = map[string]int{"details": 0}
+	api.Update(&config.Config{
+		Global: &globalConfig,
+		Route:  &config.Route{},
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, alertsProvider.added, 1)
+	require.Equal(t, model.LabelSet{
+		// Values at the limit are kept as is.
+		"summary":     "0123456789",
+		"description": "0123456...",
+		"runbook":     "äääääää...",
+		// A limit of 0 disables truncation.
+		"details": "0123456789abcdefghij",
+	}, alertsProvider.added[0].Annotations)
+	require.Equal(t, 2.0, testutil.ToFloat64(api.m.TruncatedAnnotations()))
+}
+
+func TestAddAlertsNoResolveTimeout(t *testing.T) {
+	alerts := []model.Alert{
+		{Labels: model.LabelSet{"alertname": "a", "source": "prometheus"}},
+		{Labels: model.LabelSet{"alertname": "b", "source": "nagios"}},
+	}
+	b, err := json.Marshal(&alerts)
+	require.NoError(t, err)
+
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	globalConfig := config.DefaultGlobalConfig()
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`['source="nagios"']`), &globalConfig.NoResolveTimeoutMatchers))
+	api.Update(&config.Config{
+		Global: &globalConfig,
+		Route:  &config.Route{},
+	})
+
+	r, err := http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, alertsProvider.added, 2)
+	// The resolve timeout applies to alerts not matching.
+	defaulted := alertsProvider.added[0]
+	require.True(t, defaulted.Timeout)
+	require.Equal(t, defaulted.UpdatedAt.Add(time.Duration(globalConfig.ResolveTimeout)), defaulted.EndsAt)
+	// Matching alerts stay firing until they are resolved explicitly.
+	exempt := alertsProvider.added[1]
+	require.False(t, exempt.Timeout)
+	require.True(t, exempt.EndsAt.IsZero())
+	require.False(t, exempt.Resolved())
+	require.Equal(t, 2.0, testutil.ToFloat64(api.m.Firing()))
+
+	// Labels set by the mutators are taken into account.
+	alertsProvider = newFakeAlerts([]*types.Alert{}, false)
+	api = New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil,
+		WithAlertMutators(AlertMutatorFunc(func(a *types.Alert) error {
+			a.Labels["source"] = "nagios"
+			return nil
+		})),
+	)
+	api.Update(&config.Config{
+		Global: &globalConfig,
+		Route:  &config.Route{},
+	})
+	r, err = http.NewRequest("POST", "/api/v1/alerts", bytes.NewReader(b))
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+
+	api.addAlerts(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, alertsProvider.added, 2)
+	for _, a := range alertsProvider.added {
+		require.False(t, a.Timeout, a.Name())
+		require.True(t, a.EndsAt.IsZero(), a.Name())
+	}
+}
+
+func TestAddAlertsDedup(t *testing.T) {
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	globalConfig := config.DefaultGlobalConfig()
+	globalConfig.AlertDedupWindow = model.Duration(time.Hour)
+	api.Update(&config.Config{
+		Global: &globalConfig,
+		Route:  &config.Route{},
+	})
+
+	post := func(summary string) {
+		t.Helper()
+		b, err := json.Marshal([]model.Alert{{
+			Labels:      model.LabelSet{"alertname": "a"},
+			Annotations: model.LabelSet{"summary": model.LabelValue(summary)},
+		}})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(b)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	post("foo")
+	post("foo")
+	require.Len(t, alertsProvider.added, 1)
+	require.Equal(t, 1.0, testutil.ToFloat64(api.m.Deduplicated()))
+
+	// Updates with changed annotations are no duplicates.
+	post("bar")
+	require.Len(t, alertsProvider.added, 2)
+	require.Equal(t, 1.0, testutil.ToFloat64(api.m.Deduplicated()))
+	// All updates are counted as received.
+	require.Equal(t, 3.0, testutil.ToFloat64(api.m.Firing()))
+}
+
+func TestAddAlertsRefresh(t *testing.T) {
+	now := time.Now()
+	alertsProvider := newFakeAlerts([]*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "firing"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "resolved"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}},
+	}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	globalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global: &globalConfig,
+		Route:  &config.Route{},
+	})
+
+	b, err := json.Marshal([]model.Alert{
+		{Labels: model.LabelSet{"alertname": "firing"}},
+		{Labels: model.LabelSet{"alertname": "resolved"}},
+		{Labels: model.LabelSet{"alertname": "new"}},
+		// Resolved alerts are neither.
+		{Labels: model.LabelSet{"alertname": "firing"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Second)},
+	})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Equal(t, 1.0, testutil.ToFloat64(api.m.Refreshed()))
+	require.Equal(t, 2.0, testutil.ToFloat64(api.m.FirstSeen()))
+}
+
+func TestAddAlertsStartupGracePeriod(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		started time.Duration
+		endsAt  time.Duration
+	}{
+		{
+			name:    "just after startup",
+			started: time.Minute,
+			endsAt:  9 * time.Minute,
+		},
+		{
+			name:    "after the grace period",
+			started: time.Hour,
+			endsAt:  5 * time.Minute,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil, WithStartupGracePeriod(10*time.Minute))
+			now := time.Now()
+			api.uptime = now.Add(-tc.started)
+			globalConfig := config.DefaultGlobalConfig()
+			globalConfig.ResolveTimeout = model.Duration(5 * time.Minute)
+			api.Update(&config.Config{
+				Global: &globalConfig,
+				Route:  &config.Route{},
+			})
+
+			b, err := json.Marshal([]model.Alert{
+				{Labels: model.LabelSet{"alertname": "a"}},
+				// Explicit end times are kept.
+				{Labels: model.LabelSet{"alertname": "b"}, EndsAt: now.Add(time.Minute)},
+			})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(b)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			require.Len(t, alertsProvider.added, 2)
+			require.True(t, alertsProvider.added[0].Timeout)
+			require.WithinDuration(t, now.Add(tc.endsAt), alertsProvider.added[0].EndsAt, time.Second)
+			require.WithinDuration(t, now.Add(time.Minute), alertsProvider.added[1].EndsAt, time.Second)
+		})
+	}
+}
+
+func TestAddAlertsBatch(t *testing.T) {
+	post := func(body string) (*fakeAlerts, int) {
+		t.Helper()
+		alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+		api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+		globalConfig := config.DefaultGlobalConfig()
+		api.Update(&config.Config{
+			Global: &globalConfig,
+			Route:  &config.Route{},
+		})
+		w := httptest.NewRecorder()
+		api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", strings.NewReader(body)))
+		return alertsProvider, w.Code
+	}
+
+	batch, code := post(`{
+  "commonLabels": {"datacenter": "eu-1", "cluster": "a"},
+  "alerts": [
+    {"labels": {"alertname": "a"}},
+    {"labels": {"alertname": "b", "cluster": "b"}},
+    {"labels": {"alertname": "c", "cluster": ""}}
+  ]
+}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, batch.added, 3)
+	require.Equal(t, model.LabelSet{"alertname": "a", "datacenter": "eu-1", "cluster": "a"}, batch.added[0].Labels)
+	// Labels of the alerts take precedence.
+	require.Equal(t, model.LabelSet{"alertname": "b", "datacenter": "eu-1", "cluster": "b"}, batch.added[1].Labels)
+	// Empty labels of the alerts remove common labels.
+	require.Equal(t, model.LabelSet{"alertname": "c", "datacenter": "eu-1"}, batch.added[2].Labels)
+
+	// Alerts of batches have the same fingerprints as alerts posted with all
+	// labels.
+	plain, code := post(`[
+  {"labels": {"alertname": "a", "datacenter": "eu-1", "cluster": "a"}},
+  {"labels": {"alertname": "b", "datacenter": "eu-1", "cluster": "b"}},
+  {"labels": {"alertname": "c", "datacenter": "eu-1"}}
+]`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, plain.added, 3)
+	for i := range plain.added {
+		require.Equal(t, plain.added[i].Fingerprint(), batch.added[i].Fingerprint())
+	}
+
+	empty, code := post(`{"alerts": [{"labels": {"alertname": "a"}}]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, empty.added, 1)
+	require.Equal(t, model.LabelSet{"alertname": "a"}, empty.added[0].Labels)
+
+	for _, body := range []string{
+		`{"commonLabels": {"in-valid": "x"}, "alerts": []}`,
+		`{"alerts": {}}`,
+		`"alerts"`,
+		``,
+	} {
+		_, code := post(body)
+		require.Equal(t, http.StatusBadRequest, code, body)
+	}
+}
+
+func TestAddAlertsAnnotationMerge(t *testing.T) {
+	now := time.Now()
+	lset := model.LabelSet{"alertname": "a"}
+	firing := &types.Alert{Alert: model.Alert{
+		Labels:      lset,
+		Annotations: model.LabelSet{"summary": "disk full", "runbook": "http://runbook"},
+		StartsAt:    now.Add(-time.Hour),
+	}}
+	resolved := &types.Alert{Alert: model.Alert{
+		Labels:      lset,
+		Annotations: firing.Annotations,
+		StartsAt:    now.Add(-time.Hour),
+		EndsAt:      now.Add(-time.Minute),
+	}}
+
+	for _, tc := range []struct {
+		name     string
+		strategy string
+		stored   *types.Alert
+		received model.LabelSet
+
+		code     int
+		expected model.LabelSet
+	}{
+		{
+			name:     "default",
+			stored:   firing,
+			received: model.LabelSet{"summary": "disk almost full", "owner": "db"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"summary": "disk almost full", "owner": "db"},
+		},
+		{
+			name:     "last wins",
+			strategy: config.AnnotationMergeLastWins,
+			stored:   firing,
+			received: model.LabelSet{"summary": "disk almost full", "owner": "db"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"summary": "disk almost full", "owner": "db"},
+		},
+		{
+			name:     "union",
+			strategy: config.AnnotationMergeUnion,
+			stored:   firing,
+			received: model.LabelSet{"summary": "disk almost full", "owner": "db"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"summary": "disk almost full", "owner": "db", "runbook": "http://runbook"},
+		},
+		{
+			name:     "union without annotations",
+			strategy: config.AnnotationMergeUnion,
+			stored:   firing,
+			code:     http.StatusOK,
+			expected: firing.Annotations,
+		},
+		{
+			name:     "union with resolved alert",
+			strategy: config.AnnotationMergeUnion,
+			stored:   resolved,
+			received: model.LabelSet{"owner": "db"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"owner": "db"},
+		},
+		{
+			name:     "reject conflict",
+			strategy: config.AnnotationMergeReject,
+			stored:   firing,
+			received: model.LabelSet{"summary": "disk almost full"},
+			code:     http.StatusBadRequest,
+		},
+		{
+			name:     "reject without conflict",
+			strategy: config.AnnotationMergeReject,
+			stored:   firing,
+			received: model.LabelSet{"summary": "disk full", "owner": "db"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"summary": "disk full", "owner": "db"},
+		},
+		{
+			name:     "reject with resolved alert",
+			strategy: config.AnnotationMergeReject,
+			stored:   resolved,
+			received: model.LabelSet{"summary": "disk almost full"},
+			code:     http.StatusOK,
+			expected: model.LabelSet{"summary": "disk almost full"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			alertsProvider := newFakeAlerts([]*types.Alert{tc.stored}, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			globalConfig := config.DefaultGlobalConfig()
+			globalConfig.AnnotationMergeStrategy = tc.strategy
+			api.Update(&config.Config{
+				Global: &globalConfig,
+				Route:  &config.Route{},
+			})
+
+			b, err := json.Marshal([]model.Alert{{Labels: lset, Annotations: tc.received}})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(b)))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+
+			if tc.code != http.StatusOK {
+				require.Empty(t, alertsProvider.added)
+				require.Contains(t, w.Body.String(), `annotation \"summary\" of alert {alertname=\"a\"} conflicts with the firing alert`)
+				return
+			}
+			require.Len(t, alertsProvider.added, 1)
+			require.Equal(t, tc.expected, alertsProvider.added[0].Annotations)
+			// The stored alert is looked up once.
+			require.Equal(t, 1, alertsProvider.gets)
+		})
+	}
+}
+
+func TestAddAlertsResolveIdempotent(t *testing.T) {
+	now := time.Now()
+	lset := model.LabelSet{"alertname": "a"}
+
+	for _, tc := range []struct {
+		name   string
+		stored []*types.Alert
+		endsAt time.Time
+
+		added    int
+		resolved float64
+	}{
+		{
+			name:     "unknown alert",
+			endsAt:   now.Add(-time.Minute),
+			added:    1,
+			resolved: 1,
+		},
+		{
+			name: "firing alert",
+			stored: []*types.Alert{
+				{Alert: model.Alert{Labels: lset, StartsAt: now.Add(-time.Hour)}},
+			},
+			endsAt:   now.Add(-time.Minute),
+			added:    1,
+			resolved: 1,
+		},
+		{
+			name: "resolved alert",
+			stored: []*types.Alert{
+				{Alert: model.Alert{Labels: lset, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-30 * time.Minute)}},
+			},
+			endsAt: now.Add(-time.Minute),
+		},
+		{
+			name: "resolved alert firing again",
+			stored: []*types.Alert{
+				{Alert: model.Alert{Labels: lset, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-30 * time.Minute)}},
+			},
+			added: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			alertsProvider := newFakeAlerts(tc.stored, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			globalConfig := config.DefaultGlobalConfig()
+			api.Update(&config.Config{
+				Global: &globalConfig,
+				Route:  &config.Route{},
+			})
+
+			b, err := json.Marshal([]model.Alert{{Labels: lset, EndsAt: tc.endsAt}})
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+			api.addAlerts(w, httptest.NewRequest(http.MethodPost, "/api/v1/alerts", bytes.NewReader(b)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			require.Len(t, alertsProvider.added, tc.added)
+			require.Equal(t, tc.resolved, testutil.ToFloat64(api.m.Resolved()))
+		})
+	}
+}
+
 func TestListAlerts(t *testing.T) {
 	now := time.Now()
 	alerts := []*types.Alert{
@@ -316,270 +988,3927 @@ func TestListAlerts(t *testing.T) {
 	}
 }
 
-func TestAlertFiltering(t *testing.T) {
-	type test struct {
-		alert    *model.Alert
-		msg      string
-		expected bool
-	}
+func TestListAlertsRoutePath(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - receiver: db-team
+    matchers: ['team="db"']
+    routes:
+    - receiver: db-oncall
+      matchers: ['severity="critical"']
+    - receiver: db-archive
+      matchers: ['env="dev"']
+  - receiver: web-team
+    matchers: ['team="web"']
+    continue: true
+  - receiver: db-oncall
+    matchers: ['severity="critical"']
+receivers:
+- name: default
+- name: db-team
+- name: db-oncall
+- name: db-archive
+- name: web-team
+`)
+	require.NoError(t, err)
 
-	// Equal
-	equal, err := labels.NewMatcher(labels.MatchEqual, "label1", "test1")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-warning", "team": "db"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-critical", "team": "db", "severity": "critical"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-dev", "team": "db", "env": "dev"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "web-critical", "team": "web", "severity": "critical"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "other"}, StartsAt: now.Add(-time.Minute)}},
 	}
 
-	tests := []test{
-		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1=test1", true},
-		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1=test2", false},
-		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2=test2", false},
-	}
+	for _, tc := range []struct {
+		routePath string
+		anames    []string
+	}{
+		{
+			routePath: "",
+			anames:    []string{"db-critical", "db-dev", "db-warning", "other", "web-critical"},
+		},
+		{
+			routePath: "default",
+			anames:    []string{"db-critical", "db-dev", "db-warning", "other", "web-critical"},
+		},
+		{
+			routePath: "db-team",
+			anames:    []string{"db-critical", "db-dev", "db-warning"},
+		},
+		{
+			routePath: "default/db-team/db-oncall",
+			anames:    []string{"db-critical"},
+		},
+		{
+			// The receiver alone matches both routes using it.
+			routePath: "db-oncall",
+			anames:    []string{"db-critical", "web-critical"},
+		},
+		{
+			routePath: "default/db-oncall",
+			anames:    []string{"web-critical"},
+		},
+		{
+			routePath: "db-team/db-archive",
+			anames:    []string{"db-dev"},
+		},
+		{
+			routePath: "web-team/db-oncall",
+			anames:    []string{},
+		},
+	} {
+		t.Run(tc.routePath, func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.Update(cfg)
 
-	for _, test := range tests {
-		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{equal})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
-	}
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?routePath="+url.QueryEscape(tc.routePath), nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
 
-	// Not Equal
-	notEqual, err := labels.NewMatcher(labels.MatchNotEqual, "label1", "test1")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
+}
 
-	tests = []test{
-		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1!=test1", false},
-		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1!=test2", true},
-		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2!=test2", true},
-	}
+func TestListAlertsReceiverNames(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - receiver: team-db
+    matchers: ['team="db"']
+  - receiver: team-web
+    matchers: ['team="web"']
+    continue: true
+  - receiver: team-frontend
+    matchers: ['service="frontend"']
+receivers:
+- name: default
+- name: team-db
+- name: team-web
+- name: team-frontend
+`)
+	require.NoError(t, err)
 
-	for _, test := range tests {
-		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{notEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db", "team": "db"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "web", "team": "web", "env": "prod"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "frontend", "team": "web", "service": "frontend"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "other"}, StartsAt: now.Add(-time.Minute)}},
 	}
 
-	// Regexp Equal
-	regexpEqual, err := labels.NewMatcher(labels.MatchRegexp, "label1", "tes.*")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
-	}
+	for _, tc := range []struct {
+		query  url.Values
+		anames []string
+	}{
+		{
+			query:  url.Values{},
+			anames: []string{"db", "frontend", "other", "web"},
+		},
+		{
+			query:  url.Values{"receiverName": {"team-db"}},
+			anames: []string{"db"},
+		},
+		{
+			query:  url.Values{"receiverName": {"team-db", "team-frontend"}},
+			anames: []string{"db", "frontend"},
+		},
+		{
+			query:  url.Values{"receiverName": {"team-db", "team-web", "unknown"}},
+			anames: []string{"db", "frontend", "web"},
+		},
+		{
+			// Names are matched exactly.
+			query:  url.Values{"receiverName": {"team-.*"}},
+			anames: []string{},
+		},
+		{
+			query:  url.Values{"receiverName": {"team-db", "team-web"}, "filter": {`{env="prod"}`}},
+			anames: []string{"web"},
+		},
+		{
+			query:  url.Values{"receiverName": {"team-db", "team-web"}, "receiver": {"team-(web|frontend)"}},
+			anames: []string{"frontend", "web"},
+		},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.Update(cfg)
 
-	tests = []test{
-		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1=~test1", true},
-		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1=~test2", true},
-		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2=~test2", false},
-	}
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?"+tc.query.Encode(), nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
 
-	for _, test := range tests {
-		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{regexpEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
+}
 
-	// Regexp Not Equal
-	regexpNotEqual, err := labels.NewMatcher(labels.MatchNotRegexp, "label1", "tes.*")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
-	}
+// midScanErrAlerts fails iterating the pending alerts after failAfter alerts.
+type midScanErrAlerts struct {
+	*fakeAlerts
+	failAfter int
+}
 
-	tests = []test{
-		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1!~test1", false},
-		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1!~test2", false},
-		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2!~test2", true},
-	}
+func (f *midScanErrAlerts) GetPending() provider.AlertIterator {
+	return &failingIterator{AlertIterator: f.fakeAlerts.GetPending(), failAfter: f.failAfter}
+}
 
-	for _, test := range tests {
-		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{regexpNotEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+type failingIterator struct {
+	provider.AlertIterator
+	calls, failAfter int
+}
+
+// Err fails from the call after the given number of alerts on, as Err is
+// checked once per alert.
+func (it *failingIterator) Err() error {
+	it.calls++
+	if it.calls > it.failAfter {
+		return errors.New("store failed")
 	}
+	return nil
 }
 
-func TestSilenceFiltering(t *testing.T) {
-	type test struct {
-		silence  *types.Silence
-		msg      string
-		expected bool
+func TestListAlertsMidScanError(t *testing.T) {
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c"}, StartsAt: now.Add(-time.Minute)}},
 	}
 
-	// Equal
-	equal, err := labels.NewMatcher(labels.MatchEqual, "label1", "test1")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
-	}
+	for _, tc := range []struct {
+		partial bool
 
-	tests := []test{
+		code     int
+		anames   []string
+		warnings int
+	}{
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
-			"label1=test1",
-			true,
+			// Failing entirely is the default.
+			code: http.StatusInternalServerError,
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
-			"label1=test2",
-			false,
+			partial:  true,
+			code:     http.StatusOK,
+			anames:   []string{"a", "b"},
+			warnings: 1,
+		},
+	} {
+		t.Run(fmt.Sprintf("partial=%t", tc.partial), func(t *testing.T) {
+			alertsProvider := &midScanErrAlerts{fakeAlerts: newFakeAlerts(alerts, false), failAfter: 2}
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider.fakeAlerts), nil, nil, nil, WithPartialAlerts(tc.partial))
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts", nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data     []*Alert `json:"data"`
+				Warnings []string `json:"warnings"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+			require.Len(t, res.Warnings, tc.warnings)
+			require.Contains(t, res.Warnings[0], "store failed")
+		})
+	}
+}
+
+func TestRedactInternalErrors(t *testing.T) {
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: time.Now().Add(-time.Minute)}},
+	}
+
+	for _, tc := range []struct {
+		redact    bool
+		requestID string
+
+		err string
+	}{
+		{
+			err: "store failed",
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
-			"label2=test2",
-			false,
+			redact:    true,
+			requestID: "req-1",
+			err:       "internal error, see the logs for request ID req-1",
 		},
-	}
+	} {
+		t.Run(fmt.Sprintf("redact=%t", tc.redact), func(t *testing.T) {
+			var buf bytes.Buffer
+			alertsProvider := &midScanErrAlerts{fakeAlerts: newFakeAlerts(alerts, false), failAfter: 0}
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider.fakeAlerts), nil, log.NewLogfmtLogger(&buf), nil, WithRedactInternalErrors(tc.redact))
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+			r := route.New()
+			api.Register(r)
 
-	for _, test := range tests {
-		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{equal})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+			req.Header.Set(requestIDHeader, tc.requestID)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+			var res response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.err, res.Error)
+
+			// The full error is logged in any case.
+			require.Contains(t, buf.String(), "store failed")
+			require.Contains(t, buf.String(), "request_id="+w.Header().Get(requestIDHeader))
+		})
 	}
 
-	// Not Equal
-	notEqual, err := labels.NewMatcher(labels.MatchNotEqual, "label1", "test1")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+	// Requests without a request ID get one for the error.
+	var buf bytes.Buffer
+	api := New(nil, nil, nil, nil, log.NewLogfmtLogger(&buf), nil, WithRedactInternalErrors(true))
+	w := httptest.NewRecorder()
+	api.respondError(w, apiError{typ: errorInternal, err: errors.New("open /data/silences: permission denied")}, nil)
+	var res response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.NotContains(t, res.Error, "/data")
+	id := strings.TrimPrefix(res.Error, "internal error, see the logs for request ID ")
+	require.NotEqual(t, res.Error, id)
+	require.Contains(t, buf.String(), "request_id="+id)
+	require.Contains(t, buf.String(), "permission denied")
+
+	// Other errors aren't redacted.
+	w = httptest.NewRecorder()
+	api.respondError(w, apiError{typ: errorBadData, err: errors.New("invalid filter")}, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, "invalid filter", res.Error)
+}
+
+func TestListAlertsAnnotationPresence(t *testing.T) {
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "both", "env": "prod"},
+			Annotations: model.LabelSet{"runbook_url": "http://runbook", "summary": "both"},
+			StartsAt:    now.Add(-time.Minute),
+		}},
+		{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "summary", "env": "prod"},
+			Annotations: model.LabelSet{"summary": "summary"},
+			StartsAt:    now.Add(-time.Minute),
+		}},
+		{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "empty-runbook"},
+			Annotations: model.LabelSet{"runbook_url": ""},
+			StartsAt:    now.Add(-time.Minute),
+		}},
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "none"},
+			StartsAt: now.Add(-time.Minute),
+		}},
 	}
 
-	tests = []test{
+	for _, tc := range []struct {
+		query  url.Values
+		anames []string
+	}{
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
-			"label1!=test1",
-			false,
+			query:  url.Values{},
+			anames: []string{"both", "empty-runbook", "none", "summary"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
-			"label1!=test2",
-			true,
+			// Annotations are matched by key regardless of their value.
+			query:  url.Values{"hasAnnotation": {"runbook_url"}},
+			anames: []string{"both", "empty-runbook"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
-			"label2!=test2",
-			true,
+			query:  url.Values{"lacksAnnotation": {"runbook_url"}},
+			anames: []string{"none", "summary"},
+		},
+		{
+			query:  url.Values{"hasAnnotation": {"runbook_url", "summary"}},
+			anames: []string{"both"},
+		},
+		{
+			query:  url.Values{"lacksAnnotation": {"runbook_url", "summary"}},
+			anames: []string{"none"},
+		},
+		{
+			query:  url.Values{"hasAnnotation": {"summary"}, "lacksAnnotation": {"runbook_url"}},
+			anames: []string{"summary"},
+		},
+		{
+			query:  url.Values{"lacksAnnotation": {"runbook_url"}, "filter": {`{env="prod"}`}},
+			anames: []string{"summary"},
 		},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?"+tc.query.Encode(), nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
+}
 
-	for _, test := range tests {
-		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{notEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+func TestListAlertsExpandSilences(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	now := time.Now()
+	set := func(ms ...*silencepb.Matcher) string {
+		id, err := silences.Set(&silencepb.Silence{
+			Matchers:  ms,
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "alice",
+			Comment:   "test",
+		})
+		require.NoError(t, err)
+		return id
 	}
+	db := set(
+		&silencepb.Matcher{Name: "team", Pattern: "db"},
+		&silencepb.Matcher{Name: "env", Pattern: "prod|staging", Type: silencepb.Matcher_REGEXP},
+	)
+	named := set(&silencepb.Matcher{Name: "alertname", Pattern: "a"})
 
-	// Regexp Equal
-	regexpEqual, err := labels.NewMatcher(labels.MatchRegexp, "label1", "tes.*")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a", "team": "db", "env": "prod"}, StartsAt: now}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b", "team": "db", "env": "staging"}, StartsAt: now}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c", "team": "db", "env": "dev"}, StartsAt: now}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "d"}, StartsAt: now}},
+	}
+	// The markers of alert c are outdated, as if the silence was updated
+	// since the alert was last evaluated.
+	silencedBy := map[model.Fingerprint][]string{
+		alerts[0].Fingerprint(): {db, named},
+		alerts[1].Fingerprint(): {db, "gone"},
+		alerts[2].Fingerprint(): {db},
+	}
+	getStatus := func(fp model.Fingerprint) types.AlertStatus {
+		return types.AlertStatus{State: types.AlertStateSuppressed, SilencedBy: silencedBy[fp], InhibitedBy: []string{}}
 	}
 
-	tests = []test{
+	api := New(newFakeAlerts(alerts, false), silences, getStatus, nil, nil, nil)
+	api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+
+	list := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?"+query, nil))
+		return w
+	}
+
+	w := list("expand=receivers")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	var res struct {
+		Data []struct {
+			Labels   model.LabelSet `json:"labels"`
+			Silences []struct {
+				ID              string          `json:"id"`
+				MatchedMatchers labels.Matchers `json:"matchedMatchers"`
+			} `json:"silences"`
+		} `json:"data"`
+	}
+	// Silences aren't inlined unless requested.
+	w = list("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 4)
+	for _, a := range res.Data {
+		require.Nil(t, a.Silences)
+	}
+
+	w = list("expand=silences")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+	matched := map[string]map[string][]string{}
+	for _, a := range res.Data {
+		ms := map[string][]string{}
+		for _, s := range a.Silences {
+			names := []string{}
+			for _, m := range s.MatchedMatchers {
+				names = append(names, m.String())
+			}
+			ms[s.ID] = names
+		}
+		matched[string(a.Labels["alertname"])] = ms
+	}
+	require.Equal(t, map[string]map[string][]string{
+		"a": {
+			db:    {`team="db"`, `env=~"prod|staging"`},
+			named: {`alertname="a"`},
+		},
+		// Silences which no longer exist are skipped.
+		"b": {db: {`team="db"`, `env=~"prod|staging"`}},
+		"c": {db: {`team="db"`}},
+		"d": {},
+	}, matched)
+}
+
+func TestListAlertsUpdatedSince(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	alerts := []*types.Alert{
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "recent", "env": "prod"},
+			StartsAt: now.Add(-time.Hour),
+		}, UpdatedAt: now.Add(-time.Minute)},
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "boundary"},
+			StartsAt: now.Add(-time.Hour),
+		}, UpdatedAt: now.Add(-10 * time.Minute)},
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "old", "env": "prod"},
+			StartsAt: now.Add(-time.Hour),
+		}, UpdatedAt: now.Add(-time.Hour)},
+	}
+	since := func(d time.Duration) string {
+		return now.Add(-d).Format(time.RFC3339)
+	}
+
+	for _, tc := range []struct {
+		query  url.Values
+		code   int
+		anames []string
+	}{
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
-			"label1=~test1",
-			true,
+			query:  url.Values{},
+			code:   http.StatusOK,
+			anames: []string{"boundary", "old", "recent"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
-			"label1=~test2",
-			true,
+			// The timestamp is inclusive.
+			query:  url.Values{"updatedSince": {since(10 * time.Minute)}},
+			code:   http.StatusOK,
+			anames: []string{"boundary", "recent"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
-			"label2=~test2",
-			false,
+			query:  url.Values{"updatedSince": {since(2 * time.Hour)}, "filter": {`{env="prod"}`}},
+			code:   http.StatusOK,
+			anames: []string{"old", "recent"},
 		},
-	}
+		{
+			query:  url.Values{"updatedSince": {since(-time.Minute)}},
+			code:   http.StatusOK,
+			anames: []string{},
+		},
+		{
+			query: url.Values{"updatedSince": {"10m"}},
+			code:  http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
 
-	for _, test := range tests {
-		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{regexpEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?"+tc.query.Encode(), nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
+}
 
-	// Regexp Not Equal
-	regexpNotEqual, err := labels.NewMatcher(labels.MatchNotRegexp, "label1", "tes.*")
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+func TestListAlertsTimeRange(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	alerts := []*types.Alert{
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "new", "env": "prod"},
+			StartsAt: now.Add(-10 * time.Minute),
+			EndsAt:   now.Add(time.Hour),
+		}},
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "old", "env": "prod"},
+			StartsAt: now.Add(-2 * time.Hour),
+			EndsAt:   now.Add(5 * time.Minute),
+		}},
+		{Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "unending"},
+			StartsAt: now.Add(-time.Minute),
+		}},
+	}
+	at := func(d time.Duration) string {
+		return now.Add(d).Format(time.RFC3339)
 	}
 
-	tests = []test{
+	for _, tc := range []struct {
+		query  url.Values
+		code   int
+		anames []string
+	}{
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
-			"label1!~test1",
-			false,
+			query:  url.Values{"startsAtAfter": {at(-time.Hour)}},
+			code:   http.StatusOK,
+			anames: []string{"new", "unending"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
-			"label1!~test2",
-			false,
+			// The timestamps are exclusive.
+			query:  url.Values{"startsAtAfter": {at(-10 * time.Minute)}},
+			code:   http.StatusOK,
+			anames: []string{"unending"},
 		},
 		{
-			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
-			"label2!~test2",
-			true,
+			query:  url.Values{"endsAtBefore": {at(10 * time.Minute)}},
+			code:   http.StatusOK,
+			anames: []string{"old"},
 		},
-	}
+		{
+			query:  url.Values{"startsAtAfter": {at(-3 * time.Hour)}, "endsAtBefore": {at(2 * time.Hour)}, "filter": {`{env="prod"}`}},
+			code:   http.StatusOK,
+			anames: []string{"new", "old"},
+		},
+		{
+			query: url.Values{"startsAtAfter": {"yesterday"}},
+			code:  http.StatusBadRequest,
+		},
+		{
+			query: url.Values{"endsAtBefore": {"1h"}},
+			code:  http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
 
-	for _, test := range tests {
-		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{regexpNotEqual})
-		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
-		require.Equal(t, test.expected, actual, msg)
+			w := httptest.NewRecorder()
+			api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?"+tc.query.Encode(), nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
 }
 
-func TestReceiversMatchFilter(t *testing.T) {
-	receivers := []string{"pagerduty", "slack", "pushover"}
+func TestWouldNotify(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - receiver: team-db
+    matchers: ['team="db"']
+  - receiver: team-web
+    matchers: ['team="web"']
+    continue: true
+  - receiver: team-frontend
+    matchers: ['service="frontend"']
+receivers:
+- name: default
+- name: team-db
+- name: team-web
+- name: team-frontend
+- name: unused
+`)
+	require.NoError(t, err)
 
-	filter, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", "push.*"))
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db", "team": "db"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-silenced", "team": "db", "silenced_by": "abc"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-inhibited", "team": "db", "inhibited_by": "abc"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "db-resolved", "team": "db"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "web", "team": "web"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "frontend", "team": "web", "service": "frontend"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "other"}, StartsAt: now.Add(-time.Minute)}},
 	}
-	require.True(t, receiversMatchFilter(receivers, filter))
 
-	filter, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", "push"))
-	if err != nil {
-		t.Errorf("Unexpected error %v", err)
+	for _, tc := range []struct {
+		receiver string
+
+		code   int
+		anames []string
+	}{
+		{
+			receiver: "unknown",
+			code:     http.StatusNotFound,
+		},
+		{
+			receiver: "team-db",
+			code:     http.StatusOK,
+			anames:   []string{"db"},
+		},
+		{
+			receiver: "team-web",
+			code:     http.StatusOK,
+			anames:   []string{"frontend", "web"},
+		},
+		{
+			receiver: "team-frontend",
+			code:     http.StatusOK,
+			anames:   []string{"frontend"},
+		},
+		{
+			receiver: "default",
+			code:     http.StatusOK,
+			anames:   []string{"other"},
+		},
+		{
+			receiver: "unused",
+			code:     http.StatusOK,
+			anames:   []string{},
+		},
+	} {
+		t.Run(tc.receiver, func(t *testing.T) {
+			alertsProvider := newFakeAlerts(alerts, false)
+			api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+			api.Update(cfg)
+
+			r := httptest.NewRequest(http.MethodPost, "/receivers/"+tc.receiver+"/would-notify", nil)
+			r = r.WithContext(route.WithParam(r.Context(), "name", tc.receiver))
+			w := httptest.NewRecorder()
+			api.wouldNotify(w, r)
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data []*Alert `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			anames := []string{}
+			for _, a := range res.Data {
+				anames = append(anames, string(a.Labels["alertname"]))
+			}
+			sort.Strings(anames)
+			require.Equal(t, tc.anames, anames)
+		})
 	}
-	require.False(t, receiversMatchFilter(receivers, filter))
 }
 
-func TestMatchFilterLabels(t *testing.T) {
-	testCases := []struct {
-		matcher  labels.MatchType
-		expected bool
-	}{
-		{labels.MatchEqual, true},
-		{labels.MatchRegexp, true},
-		{labels.MatchNotEqual, false},
-		{labels.MatchNotRegexp, false},
+func TestListAlertsPrimaryReceiver(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - receiver: team-web
+    matchers: ['team="web"']
+    continue: true
+  - receiver: team-frontend
+    matchers: ['service="frontend"']
+receivers:
+- name: default
+- name: team-web
+- name: team-frontend
+`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "web", "team": "web"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "frontend", "team": "web", "service": "frontend"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "other"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "labeled", "receiver": "own"}, StartsAt: now.Add(-time.Minute)}},
 	}
+	alertsProvider := newFakeAlerts(alerts, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+	api.Update(cfg)
 
-	for _, tc := range testCases {
-		l, err := labels.NewMatcher(tc.matcher, "foo", "")
-		require.NoError(t, err)
-		sms := map[string]string{
-			"baz": "bar",
+	list := func(query string) map[string]*Alert {
+		w := httptest.NewRecorder()
+		api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res struct {
+			Data []*Alert `json:"data"`
 		}
-		ls := []*labels.Matcher{l}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		byName := map[string]*Alert{}
+		for _, a := range res.Data {
+			byName[string(a.Labels["alertname"])] = a
+		}
+		return byName
+	}
 
-		require.Equal(t, tc.expected, matchFilterLabels(ls, sms))
+	res := list("")
+	require.Equal(t, "team-web", res["web"].PrimaryReceiver)
+	require.Equal(t, []string{"team-web"}, res["web"].Receivers)
+	// The first of multiple receivers is the primary one.
+	require.Equal(t, "team-web", res["frontend"].PrimaryReceiver)
+	require.Equal(t, []string{"team-web", "team-frontend"}, res["frontend"].Receivers)
+	require.Equal(t, "default", res["other"].PrimaryReceiver)
+	_, ok := res["web"].Labels["receiver"]
+	require.False(t, ok)
 
-		l, err = labels.NewMatcher(tc.matcher, "foo", "")
-		require.NoError(t, err)
-		sms = map[string]string{
-			"baz": "bar",
-			"foo": "quux",
-		}
-		ls = []*labels.Matcher{l}
-		require.NotEqual(t, tc.expected, matchFilterLabels(ls, sms))
+	res = list("?receiverLabel=receiver")
+	require.Equal(t, model.LabelValue("team-web"), res["web"].Labels["receiver"])
+	require.Equal(t, model.LabelValue("team-web"), res["frontend"].Labels["receiver"])
+	require.Equal(t, []string{"team-web", "team-frontend"}, res["frontend"].Receivers)
+	require.Equal(t, model.LabelValue("default"), res["other"].Labels["receiver"])
+	// Labels of the alert take precedence.
+	require.Equal(t, model.LabelValue("own"), res["labeled"].Labels["receiver"])
+	// The stored alerts are left untouched.
+	for _, a := range alerts[:3] {
+		_, ok := a.Labels["receiver"]
+		require.False(t, ok)
 	}
+
+	w := httptest.NewRecorder()
+	api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/alerts?receiverLabel=0invalid", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
 }
 
-func newMatcher(labelSet model.LabelSet) labels.Matchers {
-	matchers := make([]*labels.Matcher, 0, len(labelSet))
-	for key, val := range labelSet {
-		matchers = append(matchers, &labels.Matcher{
-			Type:  labels.MatchEqual,
-			Name:  string(key),
-			Value: string(val),
+func TestAlertStaleness(t *testing.T) {
+	now := time.Now()
+	api := New(nil, nil, nil, nil, nil, nil, WithStaleThreshold(time.Minute))
+	for _, tc := range []struct {
+		updatedAgo time.Duration
+		stale      bool
+	}{
+		{updatedAgo: 0, stale: false},
+		{updatedAgo: time.Minute - time.Nanosecond, stale: false},
+		{updatedAgo: time.Minute, stale: false},
+		{updatedAgo: time.Minute + time.Nanosecond, stale: true},
+		{updatedAgo: time.Hour, stale: true},
+	} {
+		a := &types.Alert{UpdatedAt: now.Add(-tc.updatedAgo)}
+		require.Equal(t, tc.stale, api.isStale(a, now), "updated %s ago", tc.updatedAgo)
+	}
+
+	// Without threshold alerts are never stale.
+	api = New(nil, nil, nil, nil, nil, nil)
+	require.False(t, api.isStale(&types.Alert{UpdatedAt: now.Add(-24 * time.Hour)}, now))
+
+	// Stale alerts are marked in the listing.
+	alerts := []*types.Alert{
+		{
+			Alert:     model.Alert{Labels: model.LabelSet{"alertname": "fresh"}, StartsAt: now.Add(-time.Hour)},
+			UpdatedAt: now,
+		},
+		{
+			Alert:     model.Alert{Labels: model.LabelSet{"alertname": "stale"}, StartsAt: now.Add(-time.Hour)},
+			UpdatedAt: now.Add(-10 * time.Minute),
+		},
+	}
+	alertsProvider := newFakeAlerts(alerts, false)
+	api = New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil, WithStaleThreshold(5*time.Minute))
+	api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+
+	w := httptest.NewRecorder()
+	api.listAlerts(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data []*Alert `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	stale := map[string]bool{}
+	for _, a := range res.Data {
+		stale[string(a.Labels["alertname"])] = a.Stale
+	}
+	require.Equal(t, map[string]bool{"fresh": false, "stale": true}, stale)
+}
+
+func TestAlertStatuses(t *testing.T) {
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a", "state": "active"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b", "state": "suppressed", "silenced_by": "abc"}}},
+	}
+	alertsProvider := newFakeAlerts(alerts, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil)
+
+	active, silenced := alerts[0].Fingerprint().String(), alerts[1].Fingerprint().String()
+	unknown := model.LabelSet{"alertname": "c"}.Fingerprint().String()
+	b, err := json.Marshal([]string{active, silenced, unknown})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	api.alertStatuses(w, httptest.NewRequest(http.MethodPost, "/alerts/status", bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 3)
+	require.JSONEq(t, `{"found":true,"state":"active","silencedBy":[],"inhibitedBy":[]}`, string(res.Data[active]))
+	require.JSONEq(t, `{"found":true,"state":"suppressed","silencedBy":["abc"],"inhibitedBy":[]}`, string(res.Data[silenced]))
+	require.JSONEq(t, `{"found":false}`, string(res.Data[unknown]))
+
+	// Invalid fingerprints are rejected.
+	w = httptest.NewRecorder()
+	api.alertStatuses(w, httptest.NewRequest(http.MethodPost, "/alerts/status", strings.NewReader(`["xyz"]`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `invalid fingerprint \"xyz\"`)
+}
+
+func TestReceiverStats(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+- name: team-Y
+`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	throttles := notify.NewThrottles()
+	throttles.Record("team-X", "slack[1]", now.Add(-time.Hour), time.Minute)
+	throttles.Record("team-X", "slack[0]", now, time.Hour)
+	// Receivers removed from the configuration are omitted.
+	throttles.Record("team-Z", "slack[0]", now, time.Hour)
+
+	api := New(nil, nil, nil, nil, nil, nil, WithThrottles(throttles))
+	api.Update(cfg)
+
+	w := httptest.NewRecorder()
+	api.receiverStats(w, httptest.NewRequest(http.MethodGet, "/status/receivers", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data []struct {
+			Name      string `json:"name"`
+			Throttles []struct {
+				Integration     string    `json:"integration"`
+				Throttled       bool      `json:"throttled"`
+				LastThrottledAt time.Time `json:"lastThrottledAt"`
+				NextAttemptAt   time.Time `json:"nextAttemptAt"`
+			} `json:"throttles"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 2)
+
+	require.Equal(t, "team-X", res.Data[0].Name)
+	require.Len(t, res.Data[0].Throttles, 2)
+	require.Equal(t, "slack[0]", res.Data[0].Throttles[0].Integration)
+	require.True(t, res.Data[0].Throttles[0].Throttled)
+	require.True(t, now.Equal(res.Data[0].Throttles[0].LastThrottledAt))
+	require.True(t, now.Add(time.Hour).Equal(res.Data[0].Throttles[0].NextAttemptAt))
+	require.Equal(t, "slack[1]", res.Data[0].Throttles[1].Integration)
+	require.False(t, res.Data[0].Throttles[1].Throttled)
+
+	require.Equal(t, "team-Y", res.Data[1].Name)
+	require.Empty(t, res.Data[1].Throttles)
+}
+
+func TestDispatchStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(nil, nil, nil, nil, nil, nil).dispatchStatus(w, httptest.NewRequest(http.MethodGet, "/status/dispatch", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	next := time.Now().Add(time.Minute).UTC()
+	stats := []dispatch.GroupStats{
+		{GroupKey: `{}:{alertname="a"}`, Receiver: "team-X", Labels: model.LabelSet{"alertname": "a"}, Alerts: 3, NextFlush: next},
+		{GroupKey: `{}:{alertname="b"}`, Receiver: "team-X", Labels: model.LabelSet{"alertname": "b"}, Alerts: 1, NextFlush: next},
+	}
+	api := New(nil, nil, nil, nil, nil, nil, WithDispatchStats(func() []dispatch.GroupStats { return stats }))
+
+	w = httptest.NewRecorder()
+	api.dispatchStatus(w, httptest.NewRequest(http.MethodGet, "/status/dispatch", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data dispatchStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, 2, res.Data.AggrGroups)
+	require.Equal(t, 4, res.Data.Alerts)
+	require.Equal(t, alertsPerGroup{Min: 1, Max: 3, Mean: 2}, res.Data.AlertsPerGroup)
+	require.Len(t, res.Data.Groups, 2)
+	require.Equal(t, "team-X", res.Data.Groups[0].Receiver)
+	require.Equal(t, 3, res.Data.Groups[0].Alerts)
+	require.True(t, next.Equal(res.Data.Groups[0].NextFlush))
+
+	// Without aggregation groups, the groups are an empty list.
+	stats = nil
+	w = httptest.NewRecorder()
+	api.dispatchStatus(w, httptest.NewRequest(http.MethodGet, "/status/dispatch", nil))
+	require.JSONEq(t, `{"status":"success","data":{"aggrGroups":0,"alerts":0,"alertsPerGroup":{"min":0,"max":0,"mean":0},"groups":[]}}`, w.Body.String())
+}
+
+func TestStatusConfig(t *testing.T) {
+	cfg, err := config.Load(`
+global:
+  resolve_timeout: 5m
+  opsgenie_api_key: xyz
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+`)
+	require.NoError(t, err)
+	api := New(nil, nil, nil, nil, nil, nil)
+	api.Update(cfg)
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		diff  bool
+	}{
+		{query: "", code: http.StatusOK},
+		{query: "?diff=false", code: http.StatusOK},
+		{query: "?diff=true", code: http.StatusOK, diff: true},
+		{query: "?diff=yes", code: http.StatusBadRequest},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			api.statusConfig(w, httptest.NewRequest(http.MethodGet, "/status/config"+tc.query, nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					ConfigYAML string `json:"configYAML"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.NotContains(t, res.Data.ConfigYAML, "xyz")
+			if !tc.diff {
+				require.Equal(t, cfg.String(), res.Data.ConfigYAML)
+				return
+			}
+			require.Equal(t, `global:
+  opsgenie_api_key: <secret>
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+`, res.Data.ConfigYAML)
+		})
+	}
+}
+
+func TestStatusSeverityCounts(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"severity": "critical", "priority": "P1"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"severity": "critical", "priority": "P2"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"severity": "warning"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}, StartsAt: now.Add(-time.Minute)}},
+		// Resolved alerts aren't counted.
+		{Alert: model.Alert{Labels: model.LabelSet{"severity": "info"}, StartsAt: now.Add(-2 * time.Minute), EndsAt: now.Add(-time.Minute)}},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		label model.LabelName
+		err   bool
+
+		code int
+		exp  map[string]int
+	}{
+		{
+			name: "default",
+			code: 200,
+			exp:  map[string]int{"critical": 2, "warning": 1, "": 1},
+		},
+		{
+			name:  "custom label",
+			label: "priority",
+			code:  200,
+			exp:   map[string]int{"P1": 1, "P2": 1, "": 2},
+		},
+		{
+			name: "error",
+			err:  true,
+			code: 500,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api := New(newFakeAlerts(alerts, tc.err), nil, nil, nil, nil, nil, WithSeverityLabel(tc.label))
+			api.Update(cfg)
+
+			w := httptest.NewRecorder()
+			api.status(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if w.Code != 200 {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					SeverityCounts map[string]int `json:"severityCounts"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.exp, res.Data.SeverityCounts)
+		})
+	}
+}
+
+func TestCountAlerts(t *testing.T) {
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"state": "active", "team": "a"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"state": "active", "team": "b"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"state": "suppressed", "team": "a"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"state": "unprocessed", "team": "b"}, StartsAt: now.Add(-time.Minute)}},
+		// Resolved alerts aren't counted.
+		{Alert: model.Alert{Labels: model.LabelSet{"state": "active", "team": "a"}, StartsAt: now.Add(-2 * time.Minute), EndsAt: now.Add(-time.Minute)}},
+	}
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - receiver: team-a
+    matchers: ['team="a"']
+  - receiver: team-b
+    matchers: ['team="b"']
+  - receiver: unused
+    matchers: ['team="c"']
+receivers:
+- name: default
+- name: team-a
+- name: team-b
+- name: unused
+`)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		query string
+		err   bool
+
+		code int
+		exp  alertCounts
+	}{
+		{
+			code: 200,
+			exp:  alertCounts{alertStateCounts: alertStateCounts{Active: 2, Suppressed: 1, Unprocessed: 1}},
+		},
+		{
+			query: "filter=" + url.QueryEscape(`{team="a"}`),
+			code:  200,
+			exp:   alertCounts{alertStateCounts: alertStateCounts{Active: 1, Suppressed: 1}},
+		},
+		{
+			query: "byReceiver=true",
+			code:  200,
+			exp: alertCounts{
+				alertStateCounts: alertStateCounts{Active: 2, Suppressed: 1, Unprocessed: 1},
+				Receivers: map[string]*alertStateCounts{
+					"default": {},
+					"team-a":  {Active: 1, Suppressed: 1},
+					"team-b":  {Active: 1, Unprocessed: 1},
+					"unused":  {},
+				},
+			},
+		},
+		{
+			query: "filter=" + url.QueryEscape(`{team="c"}`),
+			code:  200,
+		},
+		{
+			query: "filter=" + url.QueryEscape("{team"),
+			code:  400,
+		},
+		{
+			query: "byReceiver=yes",
+			code:  400,
+		},
+		{
+			err:  true,
+			code: 500,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			fa := newFakeAlerts(alerts, tc.err)
+			api := New(fa, nil, newGetAlertStatus(fa), nil, nil, nil)
+			api.route = dispatch.NewRoute(cfg.Route, nil)
+			r := route.New()
+			api.Register(r)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/alerts/count?"+tc.query, nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if w.Code != 200 {
+				return
+			}
+
+			var res struct {
+				Data alertCounts `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.exp, res.Data)
+
+			// Counts are zero rather than null without alerts.
+			require.Contains(t, w.Body.String(), `"unprocessed":`)
+		})
+	}
+}
+
+func TestListAlertNames(t *testing.T) {
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a", "env": "prod"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a", "env": "dev"}, StartsAt: now.Add(-time.Minute)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b", "env": "prod"}, StartsAt: now.Add(-time.Minute)}},
+		// Resolved alerts aren't counted.
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "c", "env": "prod"}, StartsAt: now.Add(-2 * time.Minute), EndsAt: now.Add(-time.Minute)}},
+	}
+
+	for _, tc := range []struct {
+		filter string
+		err    bool
+
+		code int
+		exp  map[string]int
+	}{
+		{
+			code: 200,
+			exp:  map[string]int{"a": 2, "b": 1},
+		},
+		{
+			filter: `{env="prod"}`,
+			code:   200,
+			exp:    map[string]int{"a": 1, "b": 1},
+		},
+		{
+			filter: `{env="test"}`,
+			code:   200,
+			exp:    map[string]int{},
+		},
+		{
+			filter: "{env",
+			code:   400,
+		},
+		{
+			err:  true,
+			code: 500,
+		},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			api := New(newFakeAlerts(alerts, tc.err), nil, nil, nil, nil, nil)
+			r := route.New()
+			api.Register(r)
+
+			u := "/alerts/alertnames"
+			if tc.filter != "" {
+				u += "?filter=" + url.QueryEscape(tc.filter)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, u, nil))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if w.Code != 200 {
+				return
+			}
+
+			var res struct {
+				Data map[string]int `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.exp, res.Data)
+		})
+	}
+}
+
+func TestAlertFilteringLabelAbsence(t *testing.T) {
+	var (
+		absent       = &model.Alert{Labels: model.LabelSet{"alertname": "a"}}
+		presentEmpty = &model.Alert{Labels: model.LabelSet{"alertname": "a", "team": ""}}
+		present      = &model.Alert{Labels: model.LabelSet{"alertname": "a", "team": "db"}}
+	)
+
+	for _, tc := range []struct {
+		filter string
+		// Expected matches of the absent, present but empty, and present label.
+		expected [3]bool
+	}{
+		{`{team=""}`, [3]bool{true, true, false}},
+		{`{team!=""}`, [3]bool{false, false, true}},
+		{`{team=~""}`, [3]bool{true, true, false}},
+		{`{team!~""}`, [3]bool{false, false, true}},
+		{`{team=~".+"}`, [3]bool{false, false, true}},
+		{`{team!="db"}`, [3]bool{true, true, false}},
+		{`{team=~"db|"}`, [3]bool{true, true, true}},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			matchers, err := labels.ParseMatchers(tc.filter)
+			require.NoError(t, err)
+			for i, a := range []*model.Alert{absent, presentEmpty, present} {
+				require.Equal(t, tc.expected[i], alertMatchesFilterLabels(a, matchers), "alert %s", a.Labels)
+			}
+		})
+	}
+}
+
+func TestAlertFiltering(t *testing.T) {
+	type test struct {
+		alert    *model.Alert
+		msg      string
+		expected bool
+	}
+
+	// Equal
+	equal, err := labels.NewMatcher(labels.MatchEqual, "label1", "test1")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests := []test{
+		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1=test1", true},
+		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1=test2", false},
+		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2=test2", false},
+	}
+
+	for _, test := range tests {
+		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{equal})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Not Equal
+	notEqual, err := labels.NewMatcher(labels.MatchNotEqual, "label1", "test1")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1!=test1", false},
+		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1!=test2", true},
+		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2!=test2", true},
+	}
+
+	for _, test := range tests {
+		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{notEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Regexp Equal
+	regexpEqual, err := labels.NewMatcher(labels.MatchRegexp, "label1", "tes.*")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1=~test1", true},
+		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1=~test2", true},
+		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2=~test2", false},
+	}
+
+	for _, test := range tests {
+		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{regexpEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Regexp Not Equal
+	regexpNotEqual, err := labels.NewMatcher(labels.MatchNotRegexp, "label1", "tes.*")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{&model.Alert{Labels: model.LabelSet{"label1": "test1"}}, "label1!~test1", false},
+		{&model.Alert{Labels: model.LabelSet{"label1": "test2"}}, "label1!~test2", false},
+		{&model.Alert{Labels: model.LabelSet{"label2": "test2"}}, "label2!~test2", true},
+	}
+
+	for _, test := range tests {
+		actual := alertMatchesFilterLabels(test.alert, []*labels.Matcher{regexpNotEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+}
+
+func TestSilenceFiltering(t *testing.T) {
+	type test struct {
+		silence  *types.Silence
+		msg      string
+		expected bool
+	}
+
+	// Equal
+	equal, err := labels.NewMatcher(labels.MatchEqual, "label1", "test1")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests := []test{
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
+			"label1=test1",
+			true,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
+			"label1=test2",
+			false,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
+			"label2=test2",
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{equal})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Not Equal
+	notEqual, err := labels.NewMatcher(labels.MatchNotEqual, "label1", "test1")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
+			"label1!=test1",
+			false,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
+			"label1!=test2",
+			true,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
+			"label2!=test2",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{notEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Regexp Equal
+	regexpEqual, err := labels.NewMatcher(labels.MatchRegexp, "label1", "tes.*")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
+			"label1=~test1",
+			true,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
+			"label1=~test2",
+			true,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
+			"label2=~test2",
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{regexpEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+
+	// Regexp Not Equal
+	regexpNotEqual, err := labels.NewMatcher(labels.MatchNotRegexp, "label1", "tes.*")
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	tests = []test{
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test1"})},
+			"label1!~test1",
+			false,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label1": "test2"})},
+			"label1!~test2",
+			false,
+		},
+		{
+			&types.Silence{Matchers: newMatcher(model.LabelSet{"label2": "test2"})},
+			"label2!~test2",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		actual := silenceMatchesFilterLabels(test.silence, []*labels.Matcher{regexpNotEqual})
+		msg := fmt.Sprintf("Expected %t for %s", test.expected, test.msg)
+		require.Equal(t, test.expected, actual, msg)
+	}
+}
+
+func TestReceiversMatchFilter(t *testing.T) {
+	receivers := []string{"pagerduty", "slack", "pushover"}
+
+	filter, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", "push.*"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	require.True(t, receiversMatchFilter(receivers, filter))
+
+	filter, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", "push"))
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	require.False(t, receiversMatchFilter(receivers, filter))
+}
+
+func TestMatchFilterLabels(t *testing.T) {
+	testCases := []struct {
+		matcher  labels.MatchType
+		expected bool
+	}{
+		{labels.MatchEqual, true},
+		{labels.MatchRegexp, true},
+		{labels.MatchNotEqual, false},
+		{labels.MatchNotRegexp, false},
+	}
+
+	for _, tc := range testCases {
+		l, err := labels.NewMatcher(tc.matcher, "foo", "")
+		require.NoError(t, err)
+		sms := map[string]string{
+			"baz": "bar",
+		}
+		ls := []*labels.Matcher{l}
+
+		require.Equal(t, tc.expected, matchFilterLabels(ls, sms))
+
+		l, err = labels.NewMatcher(tc.matcher, "foo", "")
+		require.NoError(t, err)
+		sms = map[string]string{
+			"baz": "bar",
+			"foo": "quux",
+		}
+		ls = []*labels.Matcher{l}
+		require.NotEqual(t, tc.expected, matchFilterLabels(ls, sms))
+	}
+}
+
+func newMatcher(labelSet model.LabelSet) labels.Matchers {
+	matchers := make([]*labels.Matcher, 0, len(labelSet))
+	for key, val := range labelSet {
+		matchers = append(matchers, &labels.Matcher{
+			Type:  labels.MatchEqual,
+			Name:  string(key),
+			Value: string(val),
+		})
+	}
+	return matchers
+}
+
+func TestTestSMTP(t *testing.T) {
+	// Reserve a port and close the listener to get a refused connection.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	ln.Close()
+
+	requireTLS := false
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	api.Update(&config.Config{
+		Route: &config.Route{Receiver: "email"},
+		Receivers: []*config.Receiver{
+			{
+				Name: "email",
+				EmailConfigs: []*config.EmailConfig{
+					{
+						Smarthost:  config.HostPort{Host: host, Port: port},
+						RequireTLS: &requireTLS,
+					},
+				},
+			},
+			{Name: "webhook"},
+		},
+	})
+
+	for _, tc := range []struct {
+		receiver string
+
+		code    int
+		results []smtpCheckResult
+	}{
+		{
+			receiver: "unknown",
+			code:     404,
+		},
+		{
+			receiver: "webhook",
+			code:     400,
+		},
+		{
+			receiver: "email",
+			code:     200,
+			results: []smtpCheckResult{
+				{Smarthost: net.JoinHostPort(host, port), Success: false},
+			},
+		},
+	} {
+		r, err := http.NewRequest("POST", "/api/v1/receivers/"+tc.receiver+"/test-smtp", nil)
+		require.NoError(t, err)
+		r = r.WithContext(route.WithParam(r.Context(), "name", tc.receiver))
+		w := httptest.NewRecorder()
+
+		api.testSMTP(w, r)
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		require.Equal(t, tc.code, w.Code, string(body))
+		if w.Code != 200 {
+			continue
+		}
+
+		var res struct {
+			Data []smtpCheckResult `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &res))
+		require.Len(t, res.Data, len(tc.results))
+		for i := range tc.results {
+			require.Equal(t, tc.results[i].Smarthost, res.Data[i].Smarthost)
+			require.Equal(t, tc.results[i].Success, res.Data[i].Success)
+			require.Contains(t, res.Data[i].Error, "establish connection to server")
+		}
+	}
+}
+
+func TestGetReceiver(t *testing.T) {
+	cfg, err := config.Load(`
+global:
+  send_resolved: false
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  webhook_configs:
+  - url: http://example.com/
+  - url: http://example.com/
+    send_resolved: true
+- name: team-Y
+- name: team-Z
+  slack_configs:
+  - api_url: http://slack.example.com/secret-path
+    channel: '#alerts'
+    http_config:
+      basic_auth:
+        username: user
+        password: s3cr3t
+`)
+	require.NoError(t, err)
+
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	api.Update(cfg)
+
+	for _, tc := range []struct {
+		receiver string
+
+		code int
+		res  resolvedReceiver
+	}{
+		{
+			receiver: "unknown",
+			code:     404,
+		},
+		{
+			receiver: "team-X",
+			code:     200,
+			res: resolvedReceiver{
+				Name: "team-X",
+				Integrations: []receiverIntegration{
+					{Integration: "webhook", Index: 0, SendResolved: false},
+					{Integration: "webhook", Index: 1, SendResolved: true},
+				},
+			},
+		},
+		{
+			receiver: "team-Y",
+			code:     200,
+			res:      resolvedReceiver{Name: "team-Y", Integrations: []receiverIntegration{}},
+		},
+		{
+			receiver: "team-Z",
+			code:     200,
+			res: resolvedReceiver{
+				Name: "team-Z",
+				Integrations: []receiverIntegration{
+					{Integration: "slack", Index: 0, SendResolved: false},
+				},
+			},
+		},
+	} {
+		r, err := http.NewRequest("GET", "/api/v1/receivers/"+tc.receiver, nil)
+		require.NoError(t, err)
+		r = r.WithContext(route.WithParam(r.Context(), "name", tc.receiver))
+		w := httptest.NewRecorder()
+
+		api.getReceiver(w, r)
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		require.Equal(t, tc.code, w.Code, string(body))
+		if w.Code != 200 {
+			continue
+		}
+
+		var res struct {
+			Data struct {
+				resolvedReceiver
+				Config map[string]interface{} `json:"config"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &res))
+		require.Equal(t, tc.res, res.Data.resolvedReceiver)
+		require.Equal(t, tc.receiver, res.Data.Config["name"])
+	}
+
+	// Secrets are masked in the configuration.
+	r, err := http.NewRequest("GET", "/api/v1/receivers/team-Z", nil)
+	require.NoError(t, err)
+	r = r.WithContext(route.WithParam(r.Context(), "name", "team-Z"))
+	w := httptest.NewRecorder()
+	api.getReceiver(w, r)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	require.NotContains(t, w.Body.String(), "secret-path")
+	require.NotContains(t, w.Body.String(), "s3cr3t")
+	require.Contains(t, w.Body.String(), `"api_url":"\u003csecret\u003e"`)
+	require.Contains(t, w.Body.String(), `"channel":"#alerts"`)
+}
+
+func TestValidateReceiver(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+`)
+	require.NoError(t, err)
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+
+	var built []string
+	// The builder fails the webhooks to fail.example.com like
+	// buildReceiverIntegrations.
+	build := func(rc *config.Receiver, _ *template.Template) ([]notify.Integration, error) {
+		built = append(built, rc.Name)
+		var errs types.MultiError
+		for i, c := range rc.WebhookConfigs {
+			if c.URL.Host == "fail.example.com" {
+				errs.Add(&notify.IntegrationError{Integration: "webhook", Index: i, Err: errors.New("unreachable")})
+			}
+		}
+		if errs.Len() > 0 {
+			return nil, fmt.Errorf("receiver %q: %w", rc.Name, &errs)
+		}
+		return nil, nil
+	}
+
+	validate := func(api *API, body string) *httptest.ResponseRecorder {
+		r := route.New()
+		api.Register(r)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/receiver/validate", strings.NewReader(body)))
+		return w
+	}
+
+	// Receivers can't be validated without a builder.
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	api.Update(cfg)
+	api.UpdateTemplate(tmpl)
+	w := validate(api, "name: team-Y\n")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+	api = New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil, WithReceiverBuilder(build))
+	api.Update(cfg)
+	api.UpdateTemplate(tmpl)
+
+	type result struct {
+		Data struct {
+			Valid  bool            `json:"valid"`
+			Errors []receiverError `json:"errors"`
+		} `json:"data"`
+		Warnings []string `json:"warnings"`
+	}
+	for _, tc := range []struct {
+		name string
+		body string
+
+		code     int
+		valid    bool
+		errors   []receiverError
+		warnings []string
+	}{
+		{
+			name: "invalid configuration",
+			body: `
+name: team-Y
+webhook_configs:
+- send_resolved: true
+`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "new receiver",
+			body: `
+name: team-Y
+webhook_configs:
+- url: http://example.com/
+`,
+			code:   http.StatusOK,
+			valid:  true,
+			errors: []receiverError{},
+		},
+		{
+			name: "failed integrations",
+			body: `
+name: team-Y
+webhook_configs:
+- url: http://fail.example.com/
+- url: http://example.com/
+- url: http://fail.example.com/other
+`,
+			code:  http.StatusOK,
+			valid: false,
+			errors: []receiverError{
+				{Integration: "webhook", Index: 0, Error: "unreachable"},
+				{Integration: "webhook", Index: 2, Error: "unreachable"},
+			},
+		},
+		{
+			name: "existing receiver",
+			body: `
+name: team-X
+webhook_configs:
+- url: http://example.com/
+`,
+			code:     http.StatusOK,
+			valid:    true,
+			errors:   []receiverError{},
+			warnings: []string{`receiver "team-X" already exists`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := validate(api, tc.body)
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+			var res result
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.valid, res.Data.Valid)
+			require.Equal(t, tc.errors, res.Data.Errors)
+			require.Equal(t, tc.warnings, res.Warnings)
+		})
+	}
+	require.Equal(t, []string{"team-Y", "team-Y", "team-X"}, built)
+
+	// The configuration is left untouched.
+	require.Len(t, api.config.Receivers, 1)
+	require.Empty(t, api.config.Receivers[0].WebhookConfigs)
+}
+
+// fakeNotifier records the notifications sent through it.
+type fakeNotifier struct {
+	err    error
+	alerts []*types.Alert
+	ctx    context.Context
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	n.ctx = ctx
+	n.alerts = append(n.alerts, alerts...)
+	return false, n.err
+}
+
+func (n *fakeNotifier) SendResolved() bool { return false }
+
+func TestTestReceiver(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  webhook_configs:
+  - url: http://example.com/
+  - url: http://example.com/other
+- name: team-Y
+`)
+	require.NoError(t, err)
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+
+	ok, failing := &fakeNotifier{}, &fakeNotifier{err: errors.New("unreachable")}
+	build := func(rc *config.Receiver, _ *template.Template) ([]notify.Integration, error) {
+		if rc.Name != "team-X" {
+			return nil, nil
+		}
+		return []notify.Integration{
+			notify.NewIntegration(ok, ok, "webhook", 0),
+			notify.NewIntegration(failing, failing, "webhook", 1),
+		}, nil
+	}
+
+	alerts := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alerts, nil, nil, nil, nil, nil, WithReceiverBuilder(build))
+	api.Update(cfg)
+	api.UpdateTemplate(tmpl)
+	r := route.New()
+	api.Register(r)
+	test := func(receiver, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/receivers/"+receiver+"/test", strings.NewReader(body)))
+		return w
+	}
+
+	w := test("unknown", "")
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = test("team-Y", "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = test("team-X", `{"labels":{"invalid-name":"a"}}`)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Empty(t, ok.alerts)
+
+	// Without a body, the canned test alert is sent.
+	w = test("team-X", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res struct {
+		Data []testNotificationResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 2)
+	require.Equal(t, "webhook", res.Data[0].Integration)
+	require.Equal(t, 0, res.Data[0].Index)
+	require.True(t, res.Data[0].Success)
+	require.Empty(t, res.Data[0].Error)
+	require.Equal(t, 1, res.Data[1].Index)
+	require.False(t, res.Data[1].Success)
+	require.Equal(t, "unreachable", res.Data[1].Error)
+
+	require.Len(t, ok.alerts, 1)
+	require.Equal(t, defaultTestLabels, ok.alerts[0].Labels)
+	require.Equal(t, defaultTestAnnotations, ok.alerts[0].Annotations)
+	rcv, _ := notify.ReceiverName(ok.ctx)
+	require.Equal(t, "team-X", rcv)
+	_, err = notify.ExtractGroupKey(ok.ctx)
+	require.NoError(t, err)
+
+	w = test("team-X", `{"labels":{"alertname":"Custom","team":"x"},"annotations":{"runbook":"http://example.com/"}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, ok.alerts, 2)
+	require.Equal(t, model.LabelSet{"alertname": "Custom", "team": "x"}, ok.alerts[1].Labels)
+	require.Equal(t, model.LabelSet{"runbook": "http://example.com/"}, ok.alerts[1].Annotations)
+
+	// The test alerts aren't stored.
+	require.Empty(t, alerts.added)
+}
+
+func TestDiffReceiver(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  webhook_configs:
+  - url: http://example.com/
+    http_config:
+      basic_auth:
+        username: user
+        password: s3cr3t
+`)
+	require.NoError(t, err)
+
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	api.Update(cfg)
+
+	for _, tc := range []struct {
+		receiver string
+		body     string
+
+		code int
+		diff []config.FieldDiff
+	}{
+		{
+			receiver: "unknown",
+			code:     404,
+		},
+		{
+			receiver: "team-X",
+			body:     "webhook_configs: [{}]",
+			code:     400,
+		},
+		{
+			receiver: "team-X",
+			body: `
+webhook_configs:
+- url: http://example.com/
+  http_config:
+    basic_auth:
+      username: user
+      password: s3cr3t
+`,
+			code: 200,
+			diff: []config.FieldDiff{},
+		},
+		{
+			receiver: "team-X",
+			body: `
+webhook_configs:
+- url: http://example.com/other
+  http_config:
+    basic_auth:
+      username: user
+      password: hunter2
+`,
+			code: 200,
+			diff: []config.FieldDiff{
+				{Path: "webhook_configs[0].http_config.basic_auth.password", Change: config.FieldChanged, Secret: true},
+				{Path: "webhook_configs[0].url", Change: config.FieldChanged, Old: "http://example.com/", New: "http://example.com/other"},
+			},
+		},
+	} {
+		r, err := http.NewRequest("POST", "/api/v1/receivers/"+tc.receiver+"/diff", strings.NewReader(tc.body))
+		require.NoError(t, err)
+		r = r.WithContext(route.WithParam(r.Context(), "name", tc.receiver))
+		w := httptest.NewRecorder()
+
+		api.diffReceiver(w, r)
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		require.Equal(t, tc.code, w.Code, string(body))
+		if w.Code != 200 {
+			continue
+		}
+
+		var res struct {
+			Data []config.FieldDiff `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &res))
+		require.Equal(t, tc.diff, res.Data)
+		require.NotContains(t, string(body), "s3cr3t")
+		require.NotContains(t, string(body), "hunter2")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	alertsProvider := newFakeAlerts([]*types.Alert{}, false)
+	api := New(alertsProvider, nil, newGetAlertStatus(alertsProvider), nil, nil, nil, WithReadOnly(true), WithTemplateRendering(true))
+	defaultGlobalConfig := config.DefaultGlobalConfig()
+	api.Update(&config.Config{
+		Global:    &defaultGlobalConfig,
+		Route:     &config.Route{Receiver: "team-X"},
+		Receivers: []*config.Receiver{{Name: "team-X"}},
+	})
+	r := route.New()
+	api.Register(r)
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/receivers", http.StatusOK},
+		{http.MethodGet, "/alerts", http.StatusOK},
+		{http.MethodOptions, "/alerts", http.StatusOK},
+		{http.MethodPost, "/alerts", http.StatusForbidden},
+		{http.MethodPost, "/silences", http.StatusForbidden},
+		{http.MethodDelete, "/silence/foo", http.StatusForbidden},
+		{http.MethodPost, "/receivers/team-X/test-smtp", http.StatusForbidden},
+		{http.MethodPost, "/receivers/team-X/test", http.StatusForbidden},
+		{http.MethodPut, "/silence/foo", http.StatusForbidden},
+		{http.MethodPost, "/silences/extend-by-id", http.StatusForbidden},
+		{http.MethodPost, "/silences/expire-all", http.StatusForbidden},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewReader([]byte("[]")))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code == http.StatusForbidden {
+				require.Contains(t, w.Body.String(), `"errorType":"forbidden"`)
+				require.Contains(t, w.Body.String(), "read-only")
+			}
+		})
+	}
+
+	// Queries using POST are served, whatever their outcome.
+	for _, path := range []string{
+		"/alerts/status",
+		"/silences/impact",
+		"/receivers/team-X/diff",
+		"/receivers/team-X/would-notify",
+		"/templates/render",
+		"/routes/timings",
+		"/receiver/validate",
+	} {
+		t.Run("POST "+path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}")))
+			require.NotEqual(t, http.StatusForbidden, w.Code, w.Body.String())
+			require.NotContains(t, w.Body.String(), "read-only")
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+
+	for _, tc := range []struct {
+		method, path string
+		allow        string
+	}{
+		{http.MethodPost, "/", "GET, OPTIONS"},
+		{http.MethodPost, "/status", "GET, OPTIONS"},
+		{http.MethodDelete, "/alerts", "GET, OPTIONS, POST"},
+		{http.MethodPut, "/silences", "DELETE, GET, OPTIONS, POST"},
+		{http.MethodPost, "/silence/foo", "DELETE, GET, OPTIONS, PUT"},
+		{http.MethodGet, "/receivers/team-X/test-smtp", "OPTIONS, POST"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+			require.Equal(t, tc.allow, w.Header().Get("Allow"))
+			require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+			var res response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, statusError, res.Status)
+			require.Equal(t, errorMethodNotAllowed, res.ErrorType)
+		})
+	}
+}
+
+func TestGzipResponses(t *testing.T) {
+	var alerts []*types.Alert
+	for i := 0; i < 20; i++ {
+		alerts = append(alerts, &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": model.LabelValue(fmt.Sprintf("alert-%d", i))},
+				StartsAt: time.Now().Add(-time.Minute),
+			},
+		})
+	}
+
+	for _, tc := range []struct {
+		name           string
+		acceptEncoding string
+		opts           []Option
+
+		gzip bool
+		vary bool
+	}{
+		{
+			name:           "accepted",
+			acceptEncoding: "deflate, gzip;q=0.8",
+			gzip:           true,
+			vary:           true,
+		},
+		{
+			name:           "wildcard",
+			acceptEncoding: "*",
+			gzip:           true,
+			vary:           true,
+		},
+		{
+			name: "not accepted",
+			vary: true,
+		},
+		{
+			name:           "refused",
+			acceptEncoding: "gzip;q=0",
+			vary:           true,
+		},
+		{
+			name:           "below minimum size",
+			acceptEncoding: "gzip",
+			opts:           []Option{WithGzipMinSize(1 << 20)},
+		},
+		{
+			name:           "disabled",
+			acceptEncoding: "gzip",
+			opts:           []Option{WithGzipMinSize(-1)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeAlerts := newFakeAlerts(alerts, false)
+			api := New(fakeAlerts, nil, newGetAlertStatus(fakeAlerts), nil, nil, nil, tc.opts...)
+			api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+			r := route.New()
+			api.Register(r)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+			require.Equal(t, tc.vary, w.Header().Get("Vary") == "Accept-Encoding")
+
+			body := w.Body.Bytes()
+			if tc.gzip {
+				require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+				gz, err := gzip.NewReader(w.Body)
+				require.NoError(t, err)
+				body, err = ioutil.ReadAll(gz)
+				require.NoError(t, err)
+			} else {
+				require.Empty(t, w.Header().Get("Content-Encoding"))
+			}
+
+			var res response
+			require.NoError(t, json.Unmarshal(body, &res))
+			require.Equal(t, statusSuccess, res.Status)
+			require.Len(t, res.Data, len(alerts))
+		})
+	}
+
+	// Small responses, including errors, aren't compressed.
+	api := New(newFakeAlerts(nil, false), nil, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader("{"))
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestIndex(t *testing.T) {
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+	var res struct {
+		Data struct {
+			Version   string     `json:"version"`
+			Endpoints []endpoint `json:"endpoints"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, "v1", res.Data.Version)
+	require.Contains(t, res.Data.Endpoints, endpoint{Path: "/", Methods: []string{"GET"}})
+	require.Contains(t, res.Data.Endpoints, endpoint{Path: "/alerts", Methods: []string{"GET", "POST"}})
+	require.Contains(t, res.Data.Endpoints, endpoint{Path: "/silence/:sid", Methods: []string{"DELETE", "GET", "PUT"}})
+	require.True(t, sort.SliceIsSorted(res.Data.Endpoints, func(i, j int) bool {
+		return res.Data.Endpoints[i].Path < res.Data.Endpoints[j].Path
+	}))
+
+	// The index doesn't shadow the preflight handler, which allows all the
+	// methods of the endpoints.
+	for _, path := range []string{"/", "/alerts", "/silence/foo"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Body.String())
+
+		allowed := strings.Split(w.Header().Get("Access-Control-Allow-Methods"), ", ")
+		for _, ep := range res.Data.Endpoints {
+			for _, m := range ep.Methods {
+				require.Contains(t, allowed, m, "method of %s", ep.Path)
+			}
+		}
+	}
+}
+
+func TestTimingHeaders(t *testing.T) {
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil)
+	api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+	r := route.New()
+	api.Register(r)
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/alerts", http.StatusOK},
+		{http.MethodGet, "/alerts?filter=foo", http.StatusBadRequest},
+		{http.MethodPost, "/status", http.StatusMethodNotAllowed},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			require.Equal(t, tc.code, w.Code)
+
+			ms, err := strconv.ParseFloat(w.Header().Get(processingTimeHeader), 64)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, ms, 0.0)
+
+			timing := w.Header().Get(serverTimingHeader)
+			require.True(t, strings.HasPrefix(timing, "app;dur="), timing)
+			dur, err := strconv.ParseFloat(strings.TrimPrefix(timing, "app;dur="), 64)
+			require.NoError(t, err)
+			require.Equal(t, ms, dur)
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, log.NewLogfmtLogger(&buf), nil)
+	r := route.New()
+	api.Register(r)
+
+	// The request ID of the client is echoed and logged with errors.
+	req := httptest.NewRequest(http.MethodGet, "/alerts?filter=foo", nil)
+	req.Header.Set(requestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "abc-123", w.Header().Get(requestIDHeader))
+	require.Contains(t, buf.String(), "request_id=abc-123")
+	require.Contains(t, buf.String(), "endpoint=/alerts")
+	require.Contains(t, buf.String(), "status=400")
+	require.Contains(t, buf.String(), "errorType=bad_data")
+	require.Contains(t, buf.String(), "remote_addr="+req.RemoteAddr)
+
+	// A request ID is generated if absent or invalid.
+	for _, id := range []string{"", "a b", strings.Repeat("a", maxRequestIDLength+1)} {
+		req = httptest.NewRequest(http.MethodPost, "/status", nil)
+		req.Header.Set(requestIDHeader, id)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		got := w.Header().Get(requestIDHeader)
+		require.NotEmpty(t, got)
+		require.NotEqual(t, id, got)
+		require.True(t, validRequestID(got))
+		require.Contains(t, buf.String(), "request_id="+got)
+	}
+
+	// Successful requests echo the request ID too.
+	req = httptest.NewRequest(http.MethodGet, "/alerts", nil)
+	req.Header.Set(requestIDHeader, "abc-456")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "abc-456", w.Header().Get(requestIDHeader))
+}
+
+func TestListNotificationLog(t *testing.T) {
+	nl, err := nflog.New(nflog.WithRetention(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, nl.Log(&nflogpb.Receiver{GroupName: "team-X", Integration: "email"}, "{}:{alertname=\"a\"}", []uint64{1, 2}, nil))
+	require.NoError(t, nl.Log(&nflogpb.Receiver{GroupName: "team-Y", Integration: "webhook", Idx: 1}, "{}:{alertname=\"a\"}", nil, []uint64{3}))
+	require.NoError(t, nl.Log(&nflogpb.Receiver{GroupName: "team-X", Integration: "email"}, "{}:{alertname=\"b\"}", []uint64{4}, nil))
+
+	api := New(nil, nil, nil, nil, nil, nil, WithNotificationLog(nl))
+
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query: "",
+			expected: []string{
+				`{}:{alertname="a"} team-X/email/0 [0000000000000001 0000000000000002] []`,
+				`{}:{alertname="a"} team-Y/webhook/1 [] [0000000000000003]`,
+				`{}:{alertname="b"} team-X/email/0 [0000000000000004] []`,
+			},
+		},
+		{
+			query: `groupKey={}:{alertname="a"}`,
+			expected: []string{
+				`{}:{alertname="a"} team-X/email/0 [0000000000000001 0000000000000002] []`,
+				`{}:{alertname="a"} team-Y/webhook/1 [] [0000000000000003]`,
+			},
+		},
+		{
+			query: `groupKey={}:{alertname="a"}&receiver=team-Y`,
+			expected: []string{
+				`{}:{alertname="a"} team-Y/webhook/1 [] [0000000000000003]`,
+			},
+		},
+		{
+			query:    `receiver=team-Z`,
+			expected: []string{},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "/api/v1/nflog?"+url.PathEscape(tc.query), nil)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+
+			api.listNotificationLog(w, r)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var res struct {
+				Data []nflogEntry `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			entries := []string{}
+			for _, e := range res.Data {
+				entries = append(entries, fmt.Sprintf("%s %s/%s/%d %v %v", e.GroupKey, e.Receiver, e.Integration, e.Index, e.FiringAlerts, e.ResolvedAlerts))
+			}
+			require.Equal(t, tc.expected, entries)
+		})
+	}
+
+	// Without a notification log, the endpoint is temporarily unavailable.
+	w := httptest.NewRecorder()
+	New(nil, nil, nil, nil, nil, nil).listNotificationLog(w, httptest.NewRequest(http.MethodGet, "/api/v1/nflog", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+}
+
+func TestListSilencesSort(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, s := range []struct {
+		createdBy        string
+		startsIn, endsIn time.Duration
+	}{
+		{"bob", 0, 3 * time.Hour},
+		{"carol", 0, 1 * time.Hour},
+		{"alice", 0, 2 * time.Hour},
+		// Pending silences are still listed after active ones.
+		{"aaron", time.Hour, 2 * time.Hour},
+	} {
+		_, err := silences.Set(&silencepb.Silence{
+			Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+			StartsAt:  now.Add(s.startsIn),
+			EndsAt:    now.Add(s.endsIn),
+			CreatedBy: s.createdBy,
+			Comment:   "test",
+		})
+		require.NoError(t, err)
+	}
+
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	for _, tc := range []struct {
+		sort     string
+		expected []string
+		err      string
+	}{
+		{
+			sort:     "",
+			expected: []string{"carol", "alice", "bob", "aaron"},
+		},
+		{
+			sort:     "createdBy",
+			expected: []string{"alice", "bob", "carol", "aaron"},
+		},
+		{
+			sort:     "-createdBy",
+			expected: []string{"carol", "bob", "alice", "aaron"},
+		},
+		{
+			sort:     "endsAt",
+			expected: []string{"carol", "alice", "bob", "aaron"},
+		},
+		{
+			sort:     "-endsAt",
+			expected: []string{"bob", "alice", "carol", "aaron"},
+		},
+		{
+			sort:     "updatedAt",
+			expected: []string{"bob", "carol", "alice", "aaron"},
+		},
+		{
+			sort:     "-startsAt",
+			expected: []string{"alice", "carol", "bob", "aaron"},
+		},
+		{
+			sort: "comment",
+			err:  `invalid sort key "comment", must be one of createdBy, endsAt, id, startsAt, updatedAt`,
+		},
+	} {
+		t.Run(tc.sort, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "/api/v1/silences?sort="+tc.sort, nil)
+			require.NoError(t, err)
+			w := httptest.NewRecorder()
+
+			api.listSilences(w, r)
+
+			var res struct {
+				Error string           `json:"error"`
+				Data  []*types.Silence `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			if tc.err != "" {
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				require.Equal(t, tc.err, res.Error)
+				return
+			}
+			require.Equal(t, http.StatusOK, w.Code)
+			createdBy := []string{}
+			for _, s := range res.Data {
+				createdBy = append(createdBy, s.CreatedBy)
+			}
+			require.Equal(t, tc.expected, createdBy)
+		})
+	}
+}
+
+func TestListSilencesComment(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, s := range []struct {
+		createdBy, alertname, comment string
+	}{
+		{"alice", "a", "Maintenance, see OPS-1234"},
+		{"bob", "b", "maintenance for ops-1234 follow-up"},
+		{"carol", "a", "Deploying OPS-5678"},
+	} {
+		_, err := silences.Set(&silencepb.Silence{
+			Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: s.alertname}},
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: s.createdBy,
+			Comment:   s.comment,
+		})
+		require.NoError(t, err)
+	}
+
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	for _, tc := range []struct {
+		query    url.Values
+		expected []string
+	}{
+		{
+			query:    url.Values{},
+			expected: []string{"alice", "bob", "carol"},
+		},
+		{
+			query:    url.Values{"comment": {"ops-1234"}},
+			expected: []string{"alice", "bob"},
+		},
+		{
+			query:    url.Values{"comment": {"DEPLOY"}},
+			expected: []string{"carol"},
+		},
+		{
+			query:    url.Values{"comment": {"OPS-9999"}},
+			expected: []string{},
+		},
+		{
+			query:    url.Values{"comment": {"OPS-1234"}, "filter": {`{alertname="a"}`}},
+			expected: []string{"alice"},
+		},
+	} {
+		t.Run(tc.query.Encode(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			api.listSilences(w, httptest.NewRequest(http.MethodGet, "/api/v1/silences?"+tc.query.Encode(), nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var res struct {
+				Data []*types.Silence `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			createdBy := []string{}
+			for _, s := range res.Data {
+				createdBy = append(createdBy, s.CreatedBy)
+			}
+			sort.Strings(createdBy)
+			require.Equal(t, tc.expected, createdBy)
+		})
+	}
+}
+
+func TestListSilencesStateReason(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, s := range []struct {
+		createdBy        string
+		startsAt, endsAt time.Time
+	}{
+		{"alice", now.Add(2 * time.Hour), now.Add(3 * time.Hour)},
+		{"bob", now, now.Add(time.Hour)},
+	} {
+		_, err := silences.Set(&silencepb.Silence{
+			Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+			StartsAt:  s.startsAt,
+			EndsAt:    s.endsAt,
+			CreatedBy: s.createdBy,
+		})
+		require.NoError(t, err)
+	}
+
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	api.listSilences(w, httptest.NewRequest(http.MethodGet, "/api/v1/silences", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data []*types.Silence `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	reasons := map[string]string{}
+	for _, s := range res.Data {
+		reasons[s.CreatedBy] = s.Status.StateReason
+	}
+	require.Equal(t, map[string]string{"alice": "starts in 2h", "bob": "ends in 1h"}, reasons)
+}
+
+func TestSilenceSetMatcherRoundTrip(t *testing.T) {
+	matchers, err := labels.ParseMatchers(`{instance in (db-1, "db.2", "a|b"), env="prod"}`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	sp, err := silenceToProto(&types.Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alice",
+		Comment:   "maintenance",
+	})
+	require.NoError(t, err)
+	require.Equal(t, silencepb.Matcher_REGEXP, sp.Matchers[0].Type)
+
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	id, err := silences.Set(sp)
+	require.NoError(t, err)
+
+	sils, _, err := silences.Query(silence.QIDs(id))
+	require.NoError(t, err)
+	sil, err := silenceFromProto(sils[0])
+	require.NoError(t, err)
+	require.Equal(t, labels.Matchers(matchers).String(), sil.Matchers.String())
+
+	for instance, silenced := range map[model.LabelValue]bool{
+		"db.2": true,
+		"a|b":  true,
+		"dbx2": false,
+		"a":    false,
+	} {
+		lset := model.LabelSet{"instance": instance, "env": "prod"}
+		sils, _, err := silences.Query(silence.QState(types.SilenceStateActive), silence.QMatches(lset))
+		require.NoError(t, err)
+		require.Equal(t, silenced, len(sils) == 1, instance)
+	}
+}
+
+func TestSilencePresets(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+silence_presets:
+- name: db-maintenance
+  matchers: ['service="db"', 'env="prod"']
+  comment: Database maintenance
+  duration: 2h
+`)
+	require.NoError(t, err)
+
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+	api.Update(cfg)
+	r := route.New()
+	api.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/silences/presets", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var presets struct {
+		Data []silencePreset `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &presets))
+	require.Len(t, presets.Data, 1)
+	require.Equal(t, "db-maintenance", presets.Data[0].Name)
+	require.Equal(t, `{env="prod",service="db"}`, presets.Data[0].Matchers.String())
+	require.Equal(t, "Database maintenance", presets.Data[0].Comment)
+	require.Equal(t, "2h", presets.Data[0].Duration)
+
+	now := time.Now()
+	for _, tc := range []struct {
+		name string
+		body string
+
+		code     int
+		matchers string
+		comment  string
+		endsAt   func(startsAt time.Time) time.Time
+	}{
+		{
+			name:     "expansion",
+			body:     `{"preset": "db-maintenance", "createdBy": "alice"}`,
+			code:     http.StatusOK,
+			matchers: `{env="prod",service="db"}`,
+			comment:  "Database maintenance",
+			endsAt:   func(startsAt time.Time) time.Time { return startsAt.Add(2 * time.Hour) },
+		},
+		{
+			name: "override",
+			body: fmt.Sprintf(`{"preset": "db-maintenance", "createdBy": "alice", "comment": "Upgrade",
+				"matchers": [{"name": "env", "value": "staging"}, {"name": "instance", "value": "db-1"}],
+				"endsAt": %q}`, now.Add(time.Hour).Format(time.RFC3339Nano)),
+			code:     http.StatusOK,
+			matchers: `{env="staging",instance="db-1",service="db"}`,
+			comment:  "Upgrade",
+			endsAt:   func(time.Time) time.Time { return now.Add(time.Hour) },
+		},
+		{
+			name: "unknown preset",
+			body: `{"preset": "foo", "createdBy": "alice"}`,
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(tc.body)))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					SilenceID string `json:"silenceId"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			sils, _, err := silences.Query(silence.QIDs(res.Data.SilenceID))
+			require.NoError(t, err)
+			require.Len(t, sils, 1)
+
+			sil, err := silenceFromProto(sils[0])
+			require.NoError(t, err)
+			sort.Sort(sil.Matchers)
+			require.Equal(t, tc.matchers, sil.Matchers.String())
+			require.Equal(t, tc.comment, sil.Comment)
+			require.Equal(t, "alice", sil.CreatedBy)
+			// The silence may start slightly later than requested.
+			require.WithinDuration(t, tc.endsAt(sil.StartsAt), sil.EndsAt, time.Second)
+		})
+	}
+}
+
+func TestSetSilenceCommentPattern(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil, WithSilenceCommentPattern(regexp.MustCompile(`[A-Z]+-[0-9]+`)))
+
+	for _, tc := range []struct {
+		comment string
+		code    int
+	}{
+		{comment: "OPS-123", code: http.StatusOK},
+		{comment: "Maintenance, see OPS-123.", code: http.StatusOK},
+		{comment: "Maintenance", code: http.StatusBadRequest},
+		{comment: "", code: http.StatusBadRequest},
+	} {
+		now := time.Now()
+		b, err := json.Marshal(&types.Silence{
+			Matchers:  labels.Matchers{{Type: labels.MatchEqual, Name: "alertname", Value: "a"}},
+			StartsAt:  now,
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "alice",
+			Comment:   tc.comment,
+		})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", bytes.NewReader(b)))
+		require.Equal(t, tc.code, w.Code, "%q: %s", tc.comment, w.Body.String())
+		if tc.code != http.StatusOK {
+			require.Contains(t, w.Body.String(), `"errorType":"bad_data"`)
+			require.Contains(t, w.Body.String(), `comment must match`)
+		}
+	}
+
+	// Without a pattern, any comment is accepted.
+	api = New(nil, silences, nil, nil, nil, nil)
+	now := time.Now()
+	b, err := json.Marshal(&types.Silence{
+		Matchers:  labels.Matchers{{Type: labels.MatchEqual, Name: "alertname", Value: "a"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alice",
+		Comment:   "Maintenance",
+	})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestSetSilenceStartSkew(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil, WithMaxSilenceStartSkew(time.Minute))
+
+	for _, tc := range []struct {
+		name     string
+		startsIn time.Duration
+		code     int
+	}{
+		{name: "future", startsIn: time.Hour, code: http.StatusOK},
+		{name: "within skew", startsIn: -time.Minute + 5*time.Second, code: http.StatusOK},
+		{name: "beyond skew", startsIn: -time.Minute - 5*time.Second, code: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			b, err := json.Marshal(&types.Silence{
+				Matchers:  labels.Matchers{{Type: labels.MatchEqual, Name: "alertname", Value: "a"}},
+				StartsAt:  now.Add(tc.startsIn),
+				EndsAt:    now.Add(2 * time.Hour),
+				CreatedBy: "alice",
+				Comment:   "test",
+			})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", bytes.NewReader(b)))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				require.Contains(t, w.Body.String(), "start time can't be more than 1m0s in the past")
+				return
+			}
+
+			var res struct {
+				Data struct {
+					SilenceID string `json:"silenceId"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			sils, _, err := silences.Query(silence.QIDs(res.Data.SilenceID))
+			require.NoError(t, err)
+			require.Len(t, sils, 1)
+			if tc.startsIn < 0 {
+				// The start time is clamped to now.
+				require.False(t, sils[0].StartsAt.Before(now))
+			} else {
+				require.True(t, sils[0].StartsAt.Equal(now.Add(tc.startsIn)))
+			}
+		})
+	}
+}
+
+func TestSetSilenceResponse(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	now := time.Now()
+	b, err := json.Marshal(&types.Silence{
+		Matchers:  labels.Matchers{{Type: labels.MatchRegexp, Name: "alertname", Value: "a|b"}},
+		StartsAt:  now.Add(-time.Hour),
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alice",
+		Comment:   "test",
+	})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data struct {
+			SilenceID string        `json:"silenceId"`
+			Silence   types.Silence `json:"silence"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.NotEmpty(t, res.Data.SilenceID)
+
+	sil := res.Data.Silence
+	require.Equal(t, res.Data.SilenceID, sil.ID)
+	require.Equal(t, "alertname=~\"a|b\"", sil.Matchers[0].String())
+	require.Equal(t, "alice", sil.CreatedBy)
+	require.Equal(t, types.SilenceStateActive, sil.Status.State)
+	// The start time in the past is set to the time the silence was stored.
+	require.False(t, sil.StartsAt.Before(now))
+	require.True(t, sil.EndsAt.Equal(now.Add(time.Hour)))
+
+	// Silences starting in the future are pending.
+	b, err = json.Marshal(&types.Silence{
+		Matchers:  labels.Matchers{{Type: labels.MatchEqual, Name: "alertname", Value: "c"}},
+		StartsAt:  now.Add(time.Hour),
+		EndsAt:    now.Add(2 * time.Hour),
+		CreatedBy: "alice",
+		Comment:   "test",
+	})
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", bytes.NewReader(b)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+	sil = res.Data.Silence
+	require.Equal(t, res.Data.SilenceID, sil.ID)
+	require.Equal(t, types.SilenceStatePending, sil.Status.State)
+	require.True(t, sil.StartsAt.Equal(now.Add(time.Hour)))
+	require.False(t, sil.UpdatedAt.IsZero())
+}
+
+func TestSilenceImpact(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  routes:
+  - matchers: ['team="db"']
+    receiver: db
+    routes:
+    - matchers: ['severity="critical"']
+      receiver: db-pager
+  - matchers: ['team="frontend"']
+    receiver: frontend
+receivers:
+- name: default
+- name: db
+- name: db-pager
+- name: frontend
+`)
+	require.NoError(t, err)
+	api := New(nil, nil, nil, nil, nil, nil)
+	api.Update(cfg)
+
+	for _, tc := range []struct {
+		body      string
+		code      int
+		receivers []string
+	}{
+		{
+			body:      `{"matchers":[{"name":"team","value":"db","isRegex":false}]}`,
+			code:      http.StatusOK,
+			receivers: []string{"db", "db-pager"},
+		},
+		{
+			body:      `{"matchers":[{"name":"team","value":"db","isRegex":false},{"name":"severity","value":"critical","isRegex":false}]}`,
+			code:      http.StatusOK,
+			receivers: []string{"db-pager"},
+		},
+		{
+			body:      `{"matchers":[{"name":"alertname","value":"Foo","isRegex":false}]}`,
+			code:      http.StatusOK,
+			receivers: []string{"db", "db-pager", "default", "frontend"},
+		},
+		{
+			body: `{"matchers":[]}`,
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.body, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			api.silenceImpact(w, httptest.NewRequest(http.MethodPost, "/silences/impact", strings.NewReader(tc.body)))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					Receivers []string      `json:"receivers"`
+					Routes    []routeImpact `json:"routes"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.receivers, res.Data.Receivers)
+			require.NotEmpty(t, res.Data.Routes)
 		})
 	}
-	return matchers
+}
+
+func TestRouteTimings(t *testing.T) {
+	cfg, err := config.Load(`
+route:
+  receiver: default
+  group_wait: 10s
+  routes:
+  - matchers: ['team="db"']
+    receiver: db
+    group_interval: 1m
+    routes:
+    - matchers: ['severity="critical"']
+      receiver: db-pager
+      group_wait: 0s
+      repeat_interval: 1h
+  - matchers: ['team="frontend"']
+    receiver: frontend
+    continue: true
+  - matchers: ['team="frontend"']
+    receiver: frontend-chat
+    repeat_interval: 30m
+receivers:
+- name: default
+- name: db
+- name: db-pager
+- name: frontend
+  repeat_interval: 2h
+- name: frontend-chat
+`)
+	require.NoError(t, err)
+	api := New(nil, nil, nil, nil, nil, nil)
+	api.Update(cfg)
+
+	d := func(s string) model.Duration {
+		d, err := model.ParseDuration(s)
+		require.NoError(t, err)
+		return d
+	}
+	type timings struct {
+		receiver                                 string
+		groupWait, groupInterval, repeatInterval model.Duration
+	}
+	for _, tc := range []struct {
+		body    string
+		code    int
+		timings []timings
+	}{
+		{
+			// The defaults apply below the root route.
+			body:    `{"labels":{"alertname":"Foo"}}`,
+			code:    http.StatusOK,
+			timings: []timings{{"default", d("10s"), d("5m"), d("4h")}},
+		},
+		{
+			body:    `{"labels":{"alertname":"Foo","team":"db"}}`,
+			code:    http.StatusOK,
+			timings: []timings{{"db", d("10s"), d("1m"), d("4h")}},
+		},
+		{
+			body:    `{"labels":{"alertname":"Foo","team":"db","severity":"critical"}}`,
+			code:    http.StatusOK,
+			timings: []timings{{"db-pager", d("0s"), d("1m"), d("1h")}},
+		},
+		{
+			// The repeat interval of the receiver overrides that of the route.
+			body: `{"labels":{"alertname":"Foo","team":"frontend"}}`,
+			code: http.StatusOK,
+			timings: []timings{
+				{"frontend", d("10s"), d("5m"), d("2h")},
+				{"frontend-chat", d("10s"), d("5m"), d("30m")},
+			},
+		},
+		{
+			body: `{"labels":{"invalid-name":"Foo"}}`,
+			code: http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.body, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			api.routeTimings(w, httptest.NewRequest(http.MethodPost, "/routes/timings", strings.NewReader(tc.body)))
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data []routeTimings `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			got := make([]timings, 0, len(res.Data))
+			for _, rt := range res.Data {
+				require.NotEmpty(t, rt.Route)
+				got = append(got, timings{rt.Receiver, rt.GroupWait, rt.GroupInterval, rt.RepeatInterval})
+			}
+			require.Equal(t, tc.timings, got)
+		})
+	}
+}
+
+type fakeClusterMember struct {
+	name, address string
+}
+
+func (m fakeClusterMember) Name() string    { return m.name }
+func (m fakeClusterMember) Address() string { return m.address }
+
+type fakeClusterPeer struct {
+	members   []cluster.ClusterMember
+	settledAt time.Time
+}
+
+func (p *fakeClusterPeer) Name() string                   { return "local" }
+func (p *fakeClusterPeer) Peers() []cluster.ClusterMember { return p.members }
+func (p *fakeClusterPeer) SettledAt() time.Time           { return p.settledAt }
+
+func (p *fakeClusterPeer) Status() string {
+	if p.settledAt.IsZero() {
+		return "settling"
+	}
+	return "ready"
+}
+
+func TestSilenceConsistency(t *testing.T) {
+	newSilence := func(name string) *silencepb.Silence {
+		now := time.Now()
+		return &silencepb.Silence{
+			Matchers: []*silencepb.Matcher{{Type: silencepb.Matcher_EQUAL, Name: "alertname", Pattern: name}},
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+			Comment:  name,
+		}
+	}
+
+	localSilences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	remoteSilences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	// Both sides know a, only the local side knows b and only the remote
+	// side knows c.
+	_, err = localSilences.Set(newSilence("a"))
+	require.NoError(t, err)
+	b, err := localSilences.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, remoteSilences.Merge(b))
+	onlyLocal, err := localSilences.Set(newSilence("b"))
+	require.NoError(t, err)
+	onlyRemote, err := remoteSilences.Set(newSilence("c"))
+	require.NoError(t, err)
+
+	remote := New(nil, remoteSilences, nil, nil, nil, nil)
+	r := route.New()
+	remote.Register(r.WithPrefix("/api/v1"))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	peer := &fakeClusterPeer{members: []cluster.ClusterMember{fakeClusterMember{name: "remote", address: u.Hostname() + ":9094"}}}
+	api := New(nil, localSilences, nil, peer, nil, nil)
+
+	var res struct {
+		Data struct {
+			Hash     string             `json:"hash"`
+			Silences map[string]string  `json:"silences"`
+			Peers    []peerSilenceState `json:"peers"`
+		} `json:"data"`
+	}
+	w := httptest.NewRecorder()
+	api.silenceConsistency(w, httptest.NewRequest(http.MethodGet, "/status/silences/consistency?peer="+url.QueryEscape(srv.URL), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+	require.NotEmpty(t, res.Data.Hash)
+	require.Len(t, res.Data.Silences, 2)
+	require.Len(t, res.Data.Peers, 1)
+	ps := res.Data.Peers[0]
+	require.Empty(t, ps.Error)
+	require.False(t, ps.Consistent)
+	require.NotEqual(t, res.Data.Hash, ps.Hash)
+	require.Equal(t, []string{onlyLocal}, ps.Missing)
+	require.Equal(t, []string{onlyRemote}, ps.Extra)
+	require.Empty(t, ps.Different)
+
+	for _, tc := range []struct {
+		name string
+		api  *API
+		peer string
+		err  string
+	}{
+		{name: "no cluster", api: New(nil, localSilences, nil, nil, nil, nil), peer: srv.URL, err: "requires clustering"},
+		{name: "unknown host", api: api, peer: "http://example.com:9093", err: "doesn't refer to a member of the cluster"},
+		{name: "invalid scheme", api: api, peer: "file://" + u.Hostname(), err: "scheme must be http or https"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.api.silenceConsistency(w, httptest.NewRequest(http.MethodGet, "/status/silences/consistency?peer="+url.QueryEscape(tc.peer), nil))
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Contains(t, w.Body.String(), tc.err)
+		})
+	}
+}
+
+func TestExpireAllSilences(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, s := range []struct{ startsIn, endsIn time.Duration }{
+		{0, time.Hour},
+		{time.Hour, 2 * time.Hour},
+		{0, 2 * time.Hour},
+	} {
+		_, err := silences.Set(&silencepb.Silence{
+			Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+			StartsAt:  now.Add(s.startsIn),
+			EndsAt:    now.Add(s.endsIn),
+			CreatedBy: "alice",
+			Comment:   "test",
+		})
+		require.NoError(t, err)
+	}
+	countActive := func() int {
+		sils, _, err := silences.Query(silence.QState(types.SilenceStateActive, types.SilenceStatePending))
+		require.NoError(t, err)
+		return len(sils)
+	}
+	require.Equal(t, 3, countActive())
+
+	expireAll := func(api *API, query string) *httptest.ResponseRecorder {
+		r := route.New()
+		api.Register(r)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences/expire-all"+query, nil))
+		return w
+	}
+
+	// The endpoint is disabled by default.
+	w := expireAll(New(nil, silences, nil, &fakeClusterPeer{}, nil, nil), "?confirm=local")
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	require.Equal(t, 3, countActive())
+
+	api := New(nil, silences, nil, &fakeClusterPeer{}, nil, nil, WithSilenceExpireAll(true))
+	for _, query := range []string{"", "?confirm=", "?confirm=other"} {
+		w = expireAll(api, query)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		require.Contains(t, w.Body.String(), `must be the instance name \"local\"`)
+		require.Equal(t, 3, countActive())
+	}
+
+	w = expireAll(api, "?confirm=local")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res struct {
+		Data struct {
+			Expired int `json:"expired"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, 3, res.Data.Expired)
+	require.Equal(t, 0, countActive())
+
+	// Without clustering, the host name confirms.
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	w = expireAll(New(nil, silences, nil, nil, nil, nil, WithSilenceExpireAll(true)), "?confirm="+url.QueryEscape(hostname))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, 0, res.Data.Expired)
+
+	// The read-only mode takes precedence.
+	w = expireAll(New(nil, silences, nil, &fakeClusterPeer{}, nil, nil, WithSilenceExpireAll(true), WithReadOnly(true)), "?confirm=local")
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+}
+
+func TestMaxSilenceDuration(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	// Silences created before the limit was set are kept.
+	now := time.Now()
+	long, err := silences.Set(&silencepb.Silence{
+		Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(72 * time.Hour),
+		CreatedBy: "alice",
+		Comment:   "test",
+	})
+	require.NoError(t, err)
+
+	cfg, err := config.Load(`
+global:
+  max_silence_duration: 1d
+route:
+  receiver: default
+receivers:
+- name: default
+`)
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+	api.Update(cfg)
+	r := route.New()
+	api.Register(r)
+
+	set := func(d time.Duration) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"createdBy": "alice", "comment": "c", "matchers": [{"name": "alertname", "value": "b"}], "startsAt": %q, "endsAt": %q}`,
+			now.Format(time.RFC3339Nano), now.Add(d).Format(time.RFC3339Nano))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+		return w
+	}
+
+	w := set(2 * time.Hour)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = set(48 * time.Hour)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	var res response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, "silence duration 2d exceeds the maximum of 1d", res.Error)
+
+	// Edits of silences exceeding the limit are rejected.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/silence/"+long, strings.NewReader(`{"comment":"other"}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "exceeds the maximum of 1d")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences/extend-by-id", strings.NewReader(fmt.Sprintf(`{"ids":[%q],"duration":"1h"}`, long))))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "exceeds the maximum of 1d")
+
+	sil, err := silences.QueryOne(silence.QIDs(long))
+	require.NoError(t, err)
+	require.Equal(t, types.SilenceStateActive, types.CalcSilenceState(sil.StartsAt, sil.EndsAt))
+}
+
+func TestUpdateSilence(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	sid, err := silences.Set(&silencepb.Silence{
+		Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alice",
+		Comment:   "test",
+	})
+	require.NoError(t, err)
+	expired, err := silences.Set(&silencepb.Silence{
+		Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "b"}},
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "alice",
+		Comment:   "test",
+	})
+	require.NoError(t, err)
+	require.NoError(t, silences.Expire(expired))
+
+	api := New(nil, silences, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+	update := func(id, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/silence/"+id, strings.NewReader(body)))
+		return w
+	}
+	type result struct {
+		Data struct {
+			SilenceID string         `json:"silenceId"`
+			Silence   *types.Silence `json:"silence"`
+		} `json:"data"`
+	}
+
+	w := update("unknown", `{}`)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	w = update(expired, `{"comment":"other"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "is expired")
+	endsAt, _ := json.Marshal(now.Add(-time.Minute))
+	w = update(sid, `{"endsAt":`+string(endsAt)+`}`)
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "end time can't be in the past")
+
+	// Changing the comment and end time updates the silence in place.
+	endsAt, _ = json.Marshal(now.Add(2 * time.Hour))
+	w = update(sid, `{"comment":"extended","endsAt":`+string(endsAt)+`}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res result
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, sid, res.Data.SilenceID)
+	require.Equal(t, sid, res.Data.Silence.ID)
+	require.Equal(t, "extended", res.Data.Silence.Comment)
+	require.Equal(t, "alice", res.Data.Silence.CreatedBy)
+	require.True(t, res.Data.Silence.EndsAt.Equal(now.Add(2*time.Hour)))
+	require.Equal(t, `alertname="a"`, res.Data.Silence.Matchers[0].String())
+
+	// Changing the matchers of the active silence replaces it.
+	w = update(sid, `{"matchers":[{"name":"alertname","value":"c","isRegex":false}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.NotEqual(t, sid, res.Data.SilenceID)
+	require.Equal(t, `alertname="c"`, res.Data.Silence.Matchers[0].String())
+	require.Equal(t, "extended", res.Data.Silence.Comment)
+	require.Equal(t, types.SilenceStateActive, res.Data.Silence.Status.State)
+
+	old, err := silences.QueryOne(silence.QIDs(sid))
+	require.NoError(t, err)
+	require.Equal(t, types.SilenceStateExpired, types.CalcSilenceState(old.StartsAt, old.EndsAt))
+}
+
+func TestDelSilences(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	set := func(startsIn time.Duration, ms ...*silencepb.Matcher) string {
+		id, err := silences.Set(&silencepb.Silence{
+			Matchers:  ms,
+			StartsAt:  now.Add(startsIn),
+			EndsAt:    now.Add(startsIn + time.Hour),
+			CreatedBy: "alice",
+			Comment:   "test",
+		})
+		require.NoError(t, err)
+		return id
+	}
+	active := set(0, &silencepb.Matcher{Name: "service", Pattern: "legacy"})
+	pending := set(time.Hour, &silencepb.Matcher{Name: "service", Pattern: "legacy"}, &silencepb.Matcher{Name: "env", Pattern: "prod"})
+	other := set(0, &silencepb.Matcher{Name: "service", Pattern: "api"})
+	expired := set(0, &silencepb.Matcher{Name: "service", Pattern: "legacy"})
+	require.NoError(t, silences.Expire(expired))
+
+	api := New(nil, silences, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+	del := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/silences"+query, nil))
+		return w
+	}
+
+	for _, query := range []string{"", "?filter=", "?filter=" + url.QueryEscape(`{service=~"(}`)} {
+		w := del(query)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	var res struct {
+		Data struct {
+			Expired int      `json:"expired"`
+			IDs     []string `json:"ids"`
+		} `json:"data"`
+	}
+	w := del("?filter=" + url.QueryEscape(`{service="legacy"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	ids := []string{active, pending}
+	sort.Strings(ids)
+	require.Equal(t, 2, res.Data.Expired)
+	require.Equal(t, ids, res.Data.IDs)
+
+	sil, err := silences.QueryOne(silence.QIDs(other))
+	require.NoError(t, err)
+	require.Equal(t, types.SilenceStateActive, types.CalcSilenceState(sil.StartsAt, sil.EndsAt))
+
+	// Expiring again is a no-op.
+	w = del("?filter=" + url.QueryEscape(`{service="legacy"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, 0, res.Data.Expired)
+	require.Equal(t, []string{}, res.Data.IDs)
+}
+
+func TestExtendSilencesByID(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	set := func(startsIn, endsIn time.Duration) string {
+		id, err := silences.Set(&silencepb.Silence{
+			Matchers:  []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+			StartsAt:  now.Add(startsIn),
+			EndsAt:    now.Add(endsIn),
+			CreatedBy: "alice",
+			Comment:   "test",
+		})
+		require.NoError(t, err)
+		return id
+	}
+	active := set(0, time.Hour)
+	pending := set(time.Hour, 2*time.Hour)
+	expired := set(0, time.Hour)
+	require.NoError(t, silences.Expire(expired))
+
+	api := New(nil, silences, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+	extend := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences/extend-by-id", strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`{"ids":[],"duration":"1h"}`,
+		`{"ids":["` + active + `"]}`,
+		`{"ids":["` + active + `"],"duration":"-1h"}`,
+	} {
+		w := extend(body)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	w := extend(`{"ids":["` + active + `","unknown","` + expired + `","` + pending + `"],"duration":"30m"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res struct {
+		Data []extendResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 4)
+
+	require.Equal(t, active, res.Data[0].ID)
+	require.True(t, res.Data[0].Extended)
+	require.Equal(t, active, res.Data[0].SilenceID)
+	require.True(t, res.Data[0].EndsAt.Equal(now.Add(90*time.Minute)))
+
+	require.Equal(t, extendResult{ID: "unknown", Error: "silence not found"}, res.Data[1])
+	require.Equal(t, extendResult{ID: expired, Error: "silence is expired"}, res.Data[2])
+
+	require.Equal(t, pending, res.Data[3].ID)
+	require.True(t, res.Data[3].Extended)
+	require.True(t, res.Data[3].EndsAt.Equal(now.Add(150*time.Minute)))
+
+	sil, err := silences.QueryOne(silence.QIDs(active))
+	require.NoError(t, err)
+	require.True(t, sil.EndsAt.Equal(now.Add(90*time.Minute)))
+}
+
+func TestExtendSilenceResetsExpiryNotified(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	id, err := silences.Set(&silencepb.Silence{
+		Matchers:     []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+		StartsAt:     time.Now(),
+		EndsAt:       time.Now().Add(time.Hour),
+		CreatedBy:    "alice",
+		Comment:      "test",
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, silences.MarkExpiryNotified(id))
+
+	api := New(nil, silences, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences/extend-by-id", strings.NewReader(`{"ids":["`+id+`"],"duration":"1h"}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The extended silence is notified about again before its new end.
+	sil, err := silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+	require.True(t, sil.NotifyExpiry)
+	require.False(t, sil.ExpiryNotified)
+}
+
+func TestEditSilenceKeepsExpiryNotified(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	id, err := silences.Set(&silencepb.Silence{
+		Matchers:     []*silencepb.Matcher{{Name: "alertname", Pattern: "a"}},
+		StartsAt:     now,
+		EndsAt:       now.Add(time.Hour),
+		CreatedBy:    "alice",
+		Comment:      "test",
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, silences.MarkExpiryNotified(id))
+	sil, err := silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+
+	api := New(nil, silences, nil, nil, nil, nil)
+	r := route.New()
+	api.Register(r)
+
+	// Editing the comment keeps the end, so the silence isn't notified about
+	// again. The silences use the wall clock, so the update must come after
+	// the marking.
+	time.Sleep(time.Millisecond)
+	body := fmt.Sprintf(`{"id": %q, "createdBy": "alice", "comment": "still ongoing", "notifyExpiry": true,
+		"matchers": [{"name": "alertname", "value": "a"}], "startsAt": %q, "endsAt": %q}`,
+		id, sil.StartsAt.Format(time.RFC3339Nano), sil.EndsAt.Format(time.RFC3339Nano))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	sil, err = silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+	require.Equal(t, "still ongoing", sil.Comment)
+	require.True(t, sil.ExpiryNotified)
+}
+
+func TestHeavyReadConcurrency(t *testing.T) {
+	api := New(newFakeAlerts([]*types.Alert{}, false), nil, nil, nil, nil, nil, WithHeavyReadConcurrency(2))
+	api.route = dispatch.NewRoute(&config.Route{Receiver: "def-receiver"}, nil)
+	r := route.New()
+	api.Register(r)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// Saturate the semaphore as if two requests were being processed.
+	api.heavyReads <- struct{}{}
+	api.heavyReads <- struct{}{}
+
+	for _, path := range []string{"/alerts", "/alerts/alertnames", "/status"} {
+		w := get(path)
+		require.Equal(t, http.StatusServiceUnavailable, w.Code, path)
+		require.Equal(t, "1", w.Header().Get("Retry-After"), path)
+		require.Contains(t, w.Body.String(), `"errorType":"unavailable"`, path)
+	}
+
+	// Other requests aren't limited.
+	w := get("/")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	<-api.heavyReads
+	w = get("/alerts")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Empty(t, w.Header().Get("Retry-After"))
+
+	// The request released its slot.
+	require.Len(t, api.heavyReads, 1)
+}
+
+func TestClusterStatusSettling(t *testing.T) {
+	settledAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		title string
+		peer  *fakeClusterPeer
+
+		expStatus    string
+		expSettling  bool
+		expSettledAt *time.Time
+	}{
+		{
+			title:       "settling",
+			peer:        &fakeClusterPeer{},
+			expStatus:   "settling",
+			expSettling: true,
+		},
+		{
+			title:        "settled",
+			peer:         &fakeClusterPeer{settledAt: settledAt},
+			expStatus:    "ready",
+			expSettledAt: &settledAt,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			s := getClusterStatus(tc.peer)
+			require.Equal(t, tc.expStatus, s.Status)
+			require.Equal(t, tc.expSettling, s.Settling)
+			require.Equal(t, tc.expSettledAt, s.SettledAt)
+
+			b, err := json.Marshal(s)
+			require.NoError(t, err)
+			var res map[string]interface{}
+			require.NoError(t, json.Unmarshal(b, &res))
+			require.Equal(t, tc.expSettling, res["settling"])
+			_, ok := res["settledAt"]
+			require.Equal(t, !tc.expSettling, ok)
+		})
+	}
+
+	require.Nil(t, getClusterStatus(nil))
+}
+
+func TestSetSilenceConditionMatchers(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	now := time.Now()
+	body := fmt.Sprintf(`{"createdBy": "alice", "comment": "Downstream of the outage",
+		"matchers": [{"name": "alertname", "value": "InstanceDown"}],
+		"conditionMatchers": [{"name": "alertname", "value": "NetworkOutage"}],
+		"startsAt": %q, "endsAt": %q}`,
+		now.Format(time.RFC3339Nano), now.Add(time.Hour).Format(time.RFC3339Nano))
+	w := httptest.NewRecorder()
+	api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var res struct {
+		Data struct {
+			SilenceID string `json:"silenceId"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	sils, _, err := silences.Query(silence.QIDs(res.Data.SilenceID))
+	require.NoError(t, err)
+	require.Len(t, sils, 1)
+	require.Equal(t, []*silencepb.Matcher{{Type: silencepb.Matcher_EQUAL, Name: "alertname", Pattern: "NetworkOutage"}}, sils[0].ConditionMatchers)
+
+	sil, err := silenceFromProto(sils[0])
+	require.NoError(t, err)
+	require.Equal(t, `{alertname="NetworkOutage"}`, sil.ConditionMatchers.String())
+
+	// Invalid condition matchers are rejected.
+	body = fmt.Sprintf(`{"createdBy": "alice", "comment": "c",
+		"matchers": [{"name": "alertname", "value": "InstanceDown"}],
+		"conditionMatchers": [{"name": "alertname", "value": ".*", "isRegex": true}],
+		"startsAt": %q, "endsAt": %q}`,
+		now.Format(time.RFC3339Nano), now.Add(time.Hour).Format(time.RFC3339Nano))
+	w = httptest.NewRecorder()
+	api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "condition matcher")
+}
+
+func TestSetSilenceNotifyBefore(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	now := time.Now()
+	set := func(fields string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := fmt.Sprintf(`{"createdBy": "alice", "comment": "c",
+			"matchers": [{"name": "alertname", "value": "InstanceDown"}],
+			"startsAt": %q, "endsAt": %q, %s}`,
+			now.Format(time.RFC3339Nano), now.Add(4*time.Hour).Format(time.RFC3339Nano), fields)
+		w := httptest.NewRecorder()
+		api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+		return w
+	}
+
+	w := set(`"notifyExpiry": true, "notifyBefore": "3h"`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var res struct {
+		Data struct {
+			SilenceID string `json:"silenceId"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	sils, _, err := silences.Query(silence.QIDs(res.Data.SilenceID))
+	require.NoError(t, err)
+	require.Len(t, sils, 1)
+	require.Equal(t, 3*time.Hour, sils[0].NotifyBefore)
+
+	sil, err := silenceFromProto(sils[0])
+	require.NoError(t, err)
+	require.Equal(t, model.Duration(3*time.Hour), sil.NotifyBefore)
+
+	for fields, msg := range map[string]string{
+		`"notifyExpiry": true, "notifyBefore": "-1h"`: "not a valid duration string",
+		`"notifyBefore": "1h"`:                        "notifyBefore requires notifyExpiry",
+	} {
+		w := set(fields)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		require.Contains(t, w.Body.String(), msg)
+	}
+}
+
+func TestSetSilenceInvalidMatchers(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+	api := New(nil, silences, nil, nil, nil, nil)
+
+	now := time.Now()
+	for _, tc := range []struct {
+		matchers string
+		err      string
+	}{
+		{
+			matchers: `[]`,
+			err:      "silence must have at least one matcher",
+		},
+		{
+			matchers: `[{"name": "", "value": "a"}]`,
+			err:      `matcher ="a" has an empty label name`,
+		},
+		{
+			matchers: `[{"name": "alertname", "value": "a"}, {"name": "1env", "value": "prod"}]`,
+			err:      `matcher 1env="prod" has an invalid label name`,
+		},
+	} {
+		t.Run(tc.matchers, func(t *testing.T) {
+			body := fmt.Sprintf(`{"createdBy": "alice", "comment": "c", "matchers": %s, "startsAt": %q, "endsAt": %q}`,
+				tc.matchers, now.Format(time.RFC3339Nano), now.Add(time.Hour).Format(time.RFC3339Nano))
+			w := httptest.NewRecorder()
+			api.setSilence(w, httptest.NewRequest(http.MethodPost, "/silences", strings.NewReader(body)))
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var res response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			require.Equal(t, tc.err, res.Error)
+		})
+	}
+
+	// Matchers which weren't compiled when decoded are checked too.
+	_, err = silenceToProto(&types.Silence{
+		Matchers: labels.Matchers{{Type: labels.MatchRegexp, Name: "instance", Value: "db-("}},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Hour),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `matcher instance=~"db-(" has an invalid regular expression`)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+	tmpl.ExternalURL, _ = url.Parse("http://am.example.com")
+
+	render := func(api *API, body string) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		api.renderTemplate(w, httptest.NewRequest(http.MethodPost, "/templates/render", strings.NewReader(body)))
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return w.Code, res
+	}
+
+	code, res := render(New(nil, nil, nil, nil, nil, nil), `{"template": "{{ .Receiver }}"}`)
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, "rendering templates is disabled", res["error"])
+
+	api := New(nil, nil, nil, nil, nil, nil, WithTemplateRendering(true))
+	code, res = render(api, `{"template": "{{ .Receiver }}"}`)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	api.UpdateTemplate(tmpl)
+	for _, tc := range []struct {
+		name string
+		body string
+
+		code   int
+		output string
+		err    string
+	}{
+		{
+			name: "text",
+			body: `{
+  "template": "{{ .Receiver }}: {{ .GroupLabels.alertname }} ({{ len .Alerts.Firing }} firing) <{{ .ExternalURL }}>",
+  "receiver": "team-X",
+  "groupLabels": {"alertname": "HighLatency"},
+  "alerts": [{"labels": {"alertname": "HighLatency"}}, {"labels": {"alertname": "HighLatency", "instance": "b"}}]
+}`,
+			code:   http.StatusOK,
+			output: "team-X: HighLatency (2 firing) <http://am.example.com>",
+		},
+		{
+			name: "html",
+			body: `{
+  "template": "<b>{{ .CommonAnnotations.summary }}</b>",
+  "html": true,
+  "alerts": [{"labels": {"alertname": "a"}, "annotations": {"summary": "x < y"}}]
+}`,
+			code:   http.StatusOK,
+			output: "<b>x &lt; y</b>",
+		},
+		{
+			name:   "named template",
+			body:   `{"template": "{{ template \"__subject\" . }}", "groupLabels": {"alertname": "a"}, "alerts": [{"labels": {"alertname": "a"}}]}`,
+			code:   http.StatusOK,
+			output: "[FIRING:1] a ",
+		},
+		{
+			name: "parse error",
+			body: `{"template": "line 1\n{{ .Receiver"}`,
+			code: http.StatusBadRequest,
+			err:  "invalid template: template: :2: unclosed action",
+		},
+		{
+			name: "undefined template",
+			body: `{"template": "{{ template \"missing\" . }}"}`,
+			code: http.StatusBadRequest,
+			err:  "invalid template",
+		},
+		{
+			name: "execution error",
+			body: `{"template": "{{ index .Alerts 3 }}"}`,
+			code: http.StatusBadRequest,
+			err:  "executing template failed: template: :1:3: executing",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			code, res := render(api, tc.body)
+			require.Equal(t, tc.code, code, res)
+			if tc.err != "" {
+				require.Contains(t, res["error"], tc.err)
+				return
+			}
+			require.Equal(t, tc.output, res["data"].(map[string]interface{})["output"])
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	cfg, err := config.Load("route:\n  receiver: default\nreceivers:\n- name: default\n")
+	require.NoError(t, err)
+
+	api := New(nil, nil, nil, nil, nil, nil)
+	require.False(t, api.Ready())
+	api.Update(cfg)
+	require.True(t, api.Ready())
+
+	peer := &fakeClusterPeer{}
+	api = New(nil, nil, nil, peer, nil, nil)
+	api.Update(cfg)
+	require.False(t, api.Ready())
+	peer.settledAt = time.Now()
+	require.True(t, api.Ready())
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/prometheus/common/version"
 	"github.com/rs/cors"
 
+	"github.com/prometheus/alertmanager/api/internal/ingest"
 	"github.com/prometheus/alertmanager/api/metrics"
 	open_api_models "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/api/v2/restapi"
@@ -68,7 +69,9 @@ type API struct {
 	setAlertStatus     setAlertStatusFn
 
 	logger log.Logger
-	m      *metrics.Alerts
+
+	// ingester prepares the received alerts for the alert store.
+	ingester *ingest.Ingester
 
 	Handler http.Handler
 }
@@ -94,7 +97,7 @@ func NewAPI(
 		peer:           peer,
 		silences:       silences,
 		logger:         l,
-		m:              metrics.NewAlerts("v2", r),
+		ingester:       ingest.NewIngester(alerts, metrics.NewAlerts("v2", r)),
 		uptime:         time.Now(),
 	}
 
@@ -291,50 +294,18 @@ func (api *API) postAlertsHandler(params alert_ops.PostAlertsParams) middleware.
 	logger := api.requestLogger(params.HTTPRequest)
 
 	alerts := OpenAPIAlertsToAlerts(params.Alerts)
-	now := time.Now()
 
 	api.mtx.RLock()
-	resolveTimeout := time.Duration(api.alertmanagerConfig.Global.ResolveTimeout)
+	cfg := api.alertmanagerConfig
 	api.mtx.RUnlock()
 
-	for _, alert := range alerts {
-		alert.UpdatedAt = now
+	// The alert mutators and the startup grace period are only configured
+	// for APIv1.
+	validAlerts, rejections := api.ingester.Ingest(alerts, cfg, ingest.Options{}, time.Now())
 
-		// Ensure StartsAt is set.
-		if alert.StartsAt.IsZero() {
-			if alert.EndsAt.IsZero() {
-				alert.StartsAt = now
-			} else {
-				alert.StartsAt = alert.EndsAt
-			}
-		}
-		// If no end time is defined, set a timeout after which an alert
-		// is marked resolved if it is not updated.
-		if alert.EndsAt.IsZero() {
-			alert.Timeout = true
-			alert.EndsAt = now.Add(resolveTimeout)
-		}
-		if alert.EndsAt.After(time.Now()) {
-			api.m.Firing().Inc()
-		} else {
-			api.m.Resolved().Inc()
-		}
-	}
-
-	// Make a best effort to insert all alerts that are valid.
-	var (
-		validAlerts    = make([]*types.Alert, 0, len(alerts))
-		validationErrs = &types.MultiError{}
-	)
-	for _, a := range alerts {
-		removeEmptyLabels(a.Labels)
-
-		if err := a.Validate(); err != nil {
-			validationErrs.Add(err)
-			api.m.Invalid().Inc()
-			continue
-		}
-		validAlerts = append(validAlerts, a)
+	validationErrs := &types.MultiError{}
+	for _, rj := range rejections {
+		validationErrs.Add(rj.Err)
 	}
 	if err := api.alerts.Put(validAlerts...); err != nil {
 		level.Error(logger).Log("msg", "Failed to create alerts", "err", err)
@@ -434,14 +405,6 @@ func (api *API) alertFilter(matchers []*labels.Matcher, silenced, inhibited, act
 	}
 }
 
-func removeEmptyLabels(ls prometheus_model.LabelSet) {
-	for k, v := range ls {
-		if string(v) == "" {
-			delete(ls, k)
-		}
-	}
-}
-
 func receiversMatchFilter(receivers []string, filter *regexp.Regexp) bool {
 	for _, r := range receivers {
 		if filter.MatchString(r) {
@@ -452,12 +415,12 @@ func receiversMatchFilter(receivers []string, filter *regexp.Regexp) bool {
 	return false
 }
 
+// alertMatchesFilterLabels returns true if the alert matches all matchers.
+// As everywhere else, a missing label is treated like a label with an empty
+// value: `team=""` selects alerts without a team label and `team!=""` selects
+// alerts with one.
 func alertMatchesFilterLabels(a *prometheus_model.Alert, matchers []*labels.Matcher) bool {
-	sms := make(map[string]string)
-	for name, value := range a.Labels {
-		sms[string(name)] = string(value)
-	}
-	return matchFilterLabels(matchers, sms)
+	return labels.Matchers(matchers).Matches(a.Labels)
 }
 
 func matchFilterLabels(matchers []*labels.Matcher, sms map[string]string) bool {
@@ -646,6 +609,18 @@ func (api *API) postSilencesHandler(params silence_ops.PostSilencesParams) middl
 		return silence_ops.NewPostSilencesBadRequest().WithPayload(msg)
 	}
 
+	api.mtx.RLock()
+	var maxDuration time.Duration
+	if api.alertmanagerConfig != nil && api.alertmanagerConfig.Global != nil {
+		maxDuration = time.Duration(api.alertmanagerConfig.Global.MaxSilenceDuration)
+	}
+	api.mtx.RUnlock()
+	if d := sil.EndsAt.Sub(sil.StartsAt); maxDuration > 0 && d > maxDuration {
+		msg := fmt.Sprintf("Failed to create silence: silence duration %s exceeds the maximum of %s", prometheus_model.Duration(d.Round(time.Second)), prometheus_model.Duration(maxDuration))
+		level.Error(logger).Log("msg", msg, "starts_at", sil.StartsAt, "ends_at", sil.EndsAt)
+		return silence_ops.NewPostSilencesBadRequest().WithPayload(msg)
+	}
+
 	sid, err := api.silences.Set(sil)
 	if err != nil {
 		level.Error(logger).Log("msg", "Failed to create silence", "err", err)
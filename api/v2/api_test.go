@@ -14,18 +14,28 @@
 package v2
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/prometheus/alertmanager/api/internal/ingest"
+	"github.com/prometheus/alertmanager/api/metrics"
 	open_api_models "github.com/prometheus/alertmanager/api/v2/models"
+	alert_ops "github.com/prometheus/alertmanager/api/v2/restapi/operations/alert"
 	general_ops "github.com/prometheus/alertmanager/api/v2/restapi/operations/general"
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/silence/silencepb"
 	"github.com/prometheus/alertmanager/types"
 )
@@ -314,3 +324,99 @@ func TestMatchFilterLabels(t *testing.T) {
 		require.Equal(t, tc.expected, matchFilterLabels(ms, sms))
 	}
 }
+
+func TestSilenceRoundTrip(t *testing.T) {
+	silences, err := silence.New(silence.Options{})
+	require.NoError(t, err)
+
+	// The API carries timestamps with millisecond precision.
+	now := time.Now().Truncate(time.Millisecond)
+	id, err := silences.Set(&silencepb.Silence{
+		Matchers:          []*silencepb.Matcher{createSilenceMatcher("alertname", "InstanceDown", silencepb.Matcher_EQUAL)},
+		ConditionMatchers: []*silencepb.Matcher{createSilenceMatcher("alertname", "Network.*", silencepb.Matcher_REGEXP)},
+		StartsAt:          now.Add(time.Hour),
+		EndsAt:            now.Add(5 * time.Hour),
+		CreatedBy:         "alice",
+		Comment:           "downstream of the outage",
+		NotifyExpiry:      true,
+		NotifyBefore:      3 * time.Hour,
+	})
+	require.NoError(t, err)
+	sil, err := silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+
+	gettable, err := GettableSilenceFromProto(sil)
+	require.NoError(t, err)
+	require.Len(t, gettable.ConditionMatchers, 1)
+	require.True(t, gettable.NotifyExpiry)
+	require.Equal(t, "3h", gettable.NotifyBefore)
+
+	// A client updates the silence with what it got.
+	b, err := json.Marshal(&gettable)
+	require.NoError(t, err)
+	var postable open_api_models.PostableSilence
+	require.NoError(t, json.Unmarshal(b, &postable))
+	postable.ID = id
+	require.NoError(t, postable.Validate(strfmt.Default))
+	*postable.Comment = "still ongoing"
+
+	updated, err := PostableSilenceToProto(&postable)
+	require.NoError(t, err)
+	require.Equal(t, sil.Matchers, updated.Matchers)
+	require.Equal(t, sil.ConditionMatchers, updated.ConditionMatchers)
+	require.True(t, updated.NotifyExpiry)
+	require.Equal(t, 3*time.Hour, updated.NotifyBefore)
+
+	// The silence is updated in place rather than replaced.
+	time.Sleep(time.Millisecond)
+	newID, err := silences.Set(updated)
+	require.NoError(t, err)
+	require.Equal(t, id, newID)
+
+	// A lead time is only accepted along with the notification.
+	postable.NotifyExpiry = false
+	_, err = PostableSilenceToProto(&postable)
+	require.Error(t, err)
+}
+
+func TestPostAlertsHandlerIngestionFilter(t *testing.T) {
+	alerts, err := mem.NewAlerts(context.Background(), types.NewMarker(prometheus.NewRegistry()), 30*time.Minute, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	defer alerts.Close()
+
+	cfg, err := config.Load(`
+route:
+  receiver: default
+receivers:
+- name: default
+ingestion_filter:
+  deny:
+  - ['team="load-test"']
+`)
+	require.NoError(t, err)
+	api := API{
+		alerts:             alerts,
+		alertmanagerConfig: cfg,
+		logger:             log.NewNopLogger(),
+		ingester:           ingest.NewIngester(alerts, metrics.NewAlerts("v2", nil)),
+	}
+
+	r, err := http.NewRequest("POST", "/api/v2/alerts", nil)
+	require.NoError(t, err)
+	resp := api.postAlertsHandler(alert_ops.PostAlertsParams{
+		HTTPRequest: r,
+		Alerts: open_api_models.PostableAlerts{
+			{Alert: open_api_models.Alert{Labels: open_api_models.LabelSet{"alertname": "a"}}},
+			{Alert: open_api_models.Alert{Labels: open_api_models.LabelSet{"alertname": "b", "team": "load-test"}}},
+		},
+	})
+	require.IsType(t, &alert_ops.PostAlertsOK{}, resp)
+
+	var names []string
+	it := alerts.GetPending()
+	defer it.Close()
+	for a := range it.Next() {
+		names = append(names, a.Name())
+	}
+	require.Equal(t, []string{"a"}, names)
+}
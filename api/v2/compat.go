@@ -14,6 +14,7 @@
 package v2
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -32,10 +33,11 @@ func GettableSilenceFromProto(s *silencepb.Silence) (open_api_models.GettableSil
 	state := string(types.CalcSilenceState(s.StartsAt, s.EndsAt))
 	sil := open_api_models.GettableSilence{
 		Silence: open_api_models.Silence{
-			StartsAt:  &start,
-			EndsAt:    &end,
-			Comment:   &s.Comment,
-			CreatedBy: &s.CreatedBy,
+			StartsAt:     &start,
+			EndsAt:       &end,
+			Comment:      &s.Comment,
+			CreatedBy:    &s.CreatedBy,
+			NotifyExpiry: s.NotifyExpiry,
 		},
 		ID:        &s.Id,
 		UpdatedAt: &updated,
@@ -44,77 +46,114 @@ func GettableSilenceFromProto(s *silencepb.Silence) (open_api_models.GettableSil
 		},
 	}
 
+	if s.NotifyBefore > 0 {
+		sil.NotifyBefore = prometheus_model.Duration(s.NotifyBefore).String()
+	}
+
 	for _, m := range s.Matchers {
-		matcher := &open_api_models.Matcher{
-			Name:  &m.Name,
-			Value: &m.Pattern,
-		}
-		f := false
-		t := true
-		switch m.Type {
-		case silencepb.Matcher_EQUAL:
-			matcher.IsEqual = &t
-			matcher.IsRegex = &f
-		case silencepb.Matcher_NOT_EQUAL:
-			matcher.IsEqual = &f
-			matcher.IsRegex = &f
-		case silencepb.Matcher_REGEXP:
-			matcher.IsEqual = &t
-			matcher.IsRegex = &t
-		case silencepb.Matcher_NOT_REGEXP:
-			matcher.IsEqual = &f
-			matcher.IsRegex = &t
-		default:
-			return sil, fmt.Errorf(
-				"unknown matcher type for matcher '%v' in silence '%v'",
-				m.Name,
-				s.Id,
-			)
+		matcher, err := matcherFromProto(m, s.Id)
+		if err != nil {
+			return sil, err
 		}
 		sil.Matchers = append(sil.Matchers, matcher)
 	}
+	for _, m := range s.ConditionMatchers {
+		matcher, err := matcherFromProto(m, s.Id)
+		if err != nil {
+			return sil, err
+		}
+		sil.ConditionMatchers = append(sil.ConditionMatchers, matcher)
+	}
 
 	return sil, nil
 }
 
+func matcherFromProto(m *silencepb.Matcher, silenceID string) (*open_api_models.Matcher, error) {
+	matcher := &open_api_models.Matcher{
+		Name:  &m.Name,
+		Value: &m.Pattern,
+	}
+	f := false
+	t := true
+	switch m.Type {
+	case silencepb.Matcher_EQUAL:
+		matcher.IsEqual = &t
+		matcher.IsRegex = &f
+	case silencepb.Matcher_NOT_EQUAL:
+		matcher.IsEqual = &f
+		matcher.IsRegex = &f
+	case silencepb.Matcher_REGEXP:
+		matcher.IsEqual = &t
+		matcher.IsRegex = &t
+	case silencepb.Matcher_NOT_REGEXP:
+		matcher.IsEqual = &f
+		matcher.IsRegex = &t
+	default:
+		return nil, fmt.Errorf(
+			"unknown matcher type for matcher '%v' in silence '%v'",
+			m.Name,
+			silenceID,
+		)
+	}
+	return matcher, nil
+}
+
 // PostableSilenceToProto converts *open_api_models.PostableSilenc to *silencepb.Silence.
 func PostableSilenceToProto(s *open_api_models.PostableSilence) (*silencepb.Silence, error) {
 	sil := &silencepb.Silence{
-		Id:        s.ID,
-		StartsAt:  time.Time(*s.StartsAt),
-		EndsAt:    time.Time(*s.EndsAt),
-		Comment:   *s.Comment,
-		CreatedBy: *s.CreatedBy,
+		Id:           s.ID,
+		StartsAt:     time.Time(*s.StartsAt),
+		EndsAt:       time.Time(*s.EndsAt),
+		Comment:      *s.Comment,
+		CreatedBy:    *s.CreatedBy,
+		NotifyExpiry: s.NotifyExpiry,
 	}
-	for _, m := range s.Matchers {
-		matcher := &silencepb.Matcher{
-			Name:    *m.Name,
-			Pattern: *m.Value,
-		}
-		isEqual := true
-		if m.IsEqual != nil {
-			isEqual = *m.IsEqual
-		}
-		isRegex := false
-		if m.IsRegex != nil {
-			isRegex = *m.IsRegex
+	if s.NotifyBefore != "" {
+		d, err := prometheus_model.ParseDuration(s.NotifyBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notifyBefore: %w", err)
 		}
-
-		switch {
-		case isEqual && !isRegex:
-			matcher.Type = silencepb.Matcher_EQUAL
-		case !isEqual && !isRegex:
-			matcher.Type = silencepb.Matcher_NOT_EQUAL
-		case isEqual && isRegex:
-			matcher.Type = silencepb.Matcher_REGEXP
-		case !isEqual && isRegex:
-			matcher.Type = silencepb.Matcher_NOT_REGEXP
+		if !s.NotifyExpiry {
+			return nil, errors.New("notifyBefore requires notifyExpiry")
 		}
-		sil.Matchers = append(sil.Matchers, matcher)
+		sil.NotifyBefore = time.Duration(d)
+	}
+	for _, m := range s.Matchers {
+		sil.Matchers = append(sil.Matchers, matcherToProto(m))
+	}
+	for _, m := range s.ConditionMatchers {
+		sil.ConditionMatchers = append(sil.ConditionMatchers, matcherToProto(m))
 	}
 	return sil, nil
 }
 
+func matcherToProto(m *open_api_models.Matcher) *silencepb.Matcher {
+	matcher := &silencepb.Matcher{
+		Name:    *m.Name,
+		Pattern: *m.Value,
+	}
+	isEqual := true
+	if m.IsEqual != nil {
+		isEqual = *m.IsEqual
+	}
+	isRegex := false
+	if m.IsRegex != nil {
+		isRegex = *m.IsRegex
+	}
+
+	switch {
+	case isEqual && !isRegex:
+		matcher.Type = silencepb.Matcher_EQUAL
+	case !isEqual && !isRegex:
+		matcher.Type = silencepb.Matcher_NOT_EQUAL
+	case isEqual && isRegex:
+		matcher.Type = silencepb.Matcher_REGEXP
+	case !isEqual && isRegex:
+		matcher.Type = silencepb.Matcher_NOT_REGEXP
+	}
+	return matcher
+}
+
 // AlertToOpenAPIAlert converts internal alerts, alert types, and receivers to *open_api_models.GettableAlert.
 func AlertToOpenAPIAlert(alert *types.Alert, status types.AlertStatus, receivers []string) *open_api_models.GettableAlert {
 	startsAt := strfmt.DateTime(alert.StartsAt)
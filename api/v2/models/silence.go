@@ -20,6 +20,8 @@ package models
 // Editing this file might prove futile when you re-run the swagger generate command
 
 import (
+	"strconv"
+
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
@@ -35,6 +37,9 @@ type Silence struct {
 	// Required: true
 	Comment *string `json:"comment"`
 
+	// condition matchers
+	ConditionMatchers []*Matcher `json:"conditionMatchers"`
+
 	// created by
 	// Required: true
 	CreatedBy *string `json:"createdBy"`
@@ -48,6 +53,12 @@ type Silence struct {
 	// Required: true
 	Matchers Matchers `json:"matchers"`
 
+	// notify before
+	NotifyBefore string `json:"notifyBefore,omitempty"`
+
+	// notify expiry
+	NotifyExpiry bool `json:"notifyExpiry,omitempty"`
+
 	// starts at
 	// Required: true
 	// Format: date-time
@@ -62,6 +73,10 @@ func (m *Silence) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateConditionMatchers(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateCreatedBy(formats); err != nil {
 		res = append(res, err)
 	}
@@ -93,6 +108,31 @@ func (m *Silence) validateComment(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Silence) validateConditionMatchers(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ConditionMatchers) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.ConditionMatchers); i++ {
+		if swag.IsZero(m.ConditionMatchers[i]) { // not required
+			continue
+		}
+
+		if m.ConditionMatchers[i] != nil {
+			if err := m.ConditionMatchers[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("conditionMatchers" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
 func (m *Silence) validateCreatedBy(formats strfmt.Registry) error {
 
 	if err := validate.Required("createdBy", "body", m.CreatedBy); err != nil {
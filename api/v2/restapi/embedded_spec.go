@@ -717,6 +717,12 @@ func init() {
         "comment": {
           "type": "string"
         },
+        "conditionMatchers": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/matcher"
+          }
+        },
         "createdBy": {
           "type": "string"
         },
@@ -727,6 +733,12 @@ func init() {
         "matchers": {
           "$ref": "#/definitions/matchers"
         },
+        "notifyBefore": {
+          "type": "string"
+        },
+        "notifyExpiry": {
+          "type": "boolean"
+        },
         "startsAt": {
           "type": "string",
           "format": "date-time"
@@ -1530,6 +1542,12 @@ func init() {
         "comment": {
           "type": "string"
         },
+        "conditionMatchers": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/matcher"
+          }
+        },
         "createdBy": {
           "type": "string"
         },
@@ -1540,6 +1558,12 @@ func init() {
         "matchers": {
           "$ref": "#/definitions/matchers"
         },
+        "notifyBefore": {
+          "type": "string"
+        },
+        "notifyExpiry": {
+          "type": "boolean"
+        },
         "startsAt": {
           "type": "string",
           "format": "date-time"
@@ -162,9 +162,9 @@ var Assets = func() http.FileSystem {
 		"/templates/default.tmpl": &vfsgen۰CompressedFileInfo{
 			name:             "default.tmpl",
 			modTime:          time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC),
-			uncompressedSize: 17526,
+			uncompressedSize: 17873,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xec\x1b\xfd\x6f\xdb\xb6\xf2\x77\xfd\x15\x37\x0d\x0f\x6b\x00\x7f\xa5\xdd\x8a\xc5\xb1\xf3\xe0\x3a\x4a\x23\x3c\xc7\x0e\x6c\xa5\x5d\x31\x0c\x01\x2d\x9d\x6d\xb6\x12\xa9\x91\x54\x1c\x2f\xf5\xff\xfe\x40\x4a\xfe\x90\x3f\x12\xa7\xe8\x92\xec\x3d\x2f\xd8\x66\x51\xf7\x7d\xc7\xbb\xa3\x48\xde\xdd\x41\x80\x03\xca\x10\xec\xeb\x6b\x12\xa2\x50\x11\x61\x64\x88\xc2\x86\xe9\xb4\xb1\xf4\x7c\x77\x07\xc8\x02\x98\x4e\xad\xad\x28\x57\xdd\x96\xc6\xba\xbb\x83\x92\x73\xab\x50\x30\x12\x5e\x75\x5b\x30\x9d\x96\x7f\x2c\x1b\x38\xf9\x6f\x81\x3e\xd2\x1b\x14\x75\x0d\xd4\xcd\x1e\xe0\x2b\x24\x22\xfc\x33\x41\x31\x49\xd1\x33\x46\x79\x4e\x32\xe9\x7f\x46\x5f\x69\x0e\xbf\x6b\xec\x9e\x22\x2a\x91\xf0\x15\x14\xbf\x8a\x63\x14\x29\x2a\x1d\x00\xfe\x39\x7f\x69\x0f\xa8\xa0\x6c\xa8\x71\xaa\x1a\xc7\x28\x24\x4b\x67\x66\x14\xbe\x42\x88\x6c\x99\xe3\x1f\xa0\x81\xde\x0b\x9e\xc4\x2d\xd2\xc7\x50\x96\x7a\x5c\x28\x0c\x2e\x09\x15\xb2\xf4\x81\x84\x09\x6a\x86\x9f\x39\x65\x60\x83\xa6\x0a\x29\xcb\xa1\x82\x57\x9a\x56\xa9\xc9\xa3\x88\xb3\x14\xf9\x20\x1b\x5b\xa2\x77\x00\xd3\xe9\xab\xbb\x3b\x18\x53\x35\xca\x03\x97\xba\x18\xf1\x1b\xcc\x73\x6f\x93\x08\x65\x66\xd1\x4d\xdc\xe7\x82\x1f\xcc\x7f\x6d\x71\x53\x80\xd2\x17\x34\x56\x94\x33\xfb\x1e\x1b\x2b\xbc\x55\xa9\x4b\xaf\x43\x2a\x55\x06\x2a\x08\x1b\x22\x94\x60\x3a\x4d\xe5\xaa\x5a\x8b\xc1\x75\x3b\x69\xab\x14\x8d\x21\xb5\xf8\xfa\xa9\x0e\x73\x05\x32\xc1\x52\xe6\x0d\xc6\xb8\x22\x5a\xa6\x1c\xc9\xa5\xe1\x6f\xa3\xdb\xe3\x89\xf0\xb1\x9a\x3a\x13\x19\x0a\xa2\xb8\x48\x23\xd1\xda\x60\xa8\x9c\x0d\x64\x48\xfc\x2f\xa5\x00\x07\x24\x09\x55\x49\x51\x15\x62\x66\x05\x85\x51\x1c\x12\x95\x8f\xc5\xd2\x36\x93\xe7\xe9\x24\x52\xcf\x86\x68\x13\xa9\xfc\x9c\xdb\x91\xde\x80\x84\x61\x9f\xf8\x5f\xd6\xe8\x6d\x14\x5f\x13\x85\xaf\xf0\x10\x60\x48\xd9\x97\x9d\x25\xf0\x33\x09\x68\x60\xef\x86\x10\x0b\xd4\xd1\xb5\x23\xf4\x92\x40\xf7\x5a\xcc\xa4\x9c\x1d\x45\xa6\x3e\x67\x18\xf1\xcf\xd4\xde\x1d\x3e\x11\xe1\xae\x12\xef\xae\xdc\x80\x73\x95\x26\xd8\x2d\x41\x18\x6b\xd5\x82\x44\x4d\xe6\x28\xeb\xf3\xf7\x71\xe1\xb8\x4e\xd1\x0f\x29\x32\xf5\xed\x01\xb9\x8d\xe2\xa2\x08\x7c\x9b\xcf\xd6\xe9\x52\x26\x15\x61\x3e\xca\x0d\x74\xd7\x12\x56\x69\xbb\x55\x79\x2c\x87\xc8\x28\xce\x09\x47\x28\x25\x19\x7e\xdb\xfc\x5e\x23\xb6\xee\xa1\x2c\xbf\x6f\x49\x67\x1b\x13\xba\xb5\x52\x4e\x72\xf5\xea\x00\x2a\x50\x9c\x4e\xad\x74\x10\xd2\x41\x93\x38\xef\xb7\x48\xbe\xe8\x19\x26\xc5\x25\x8d\x36\xf0\xeb\xa2\xe4\xe1\x0d\x06\x2b\x1c\x67\xc3\xbb\xf3\x9c\x61\xac\x71\x2d\xee\x62\x52\x69\xf2\xf8\xe3\xa3\x29\xe7\xf5\x31\xfa\x23\xa2\x1e\xeb\x73\x6b\xef\xbf\x7b\xfc\xb7\xdc\x17\x5e\x89\x70\x8d\xde\x46\xff\x6c\xf1\xfa\x8a\x7f\x14\xbf\xd6\xc5\x72\x6b\x26\x5d\x07\x8f\x89\x50\x93\x47\xc0\x2b\x32\xdc\x15\x9a\x0c\x91\xa9\xeb\xd5\x12\x97\x8f\xaf\x1b\xea\x2b\x2e\x78\x2c\x17\x61\xab\x88\xc2\xeb\x7c\xa0\xed\x63\xe9\x71\xb9\x60\xdd\xaa\xc8\x14\x55\x93\xeb\x80\xca\x38\x24\x93\xeb\x2d\xdd\xd4\xc3\x89\x7b\x9d\x72\xc4\x19\x55\x5c\x1b\xe4\x5a\x71\x1e\x3e\xb2\x24\x2e\xd3\xc6\x88\xd0\x70\x11\x07\x8b\x05\xcb\xa3\xa5\xcc\x53\x1a\xa9\xc8\x88\x65\xd5\x7e\x38\xed\x34\xbd\x4f\x97\x0e\xe8\x21\xb8\xbc\x7a\xd7\x72\x9b\x60\x17\xcb\xe5\x8f\x6f\x9a\xe5\xf2\xa9\x77\x0a\xbf\x9d\x7b\x17\x2d\x38\x2c\x55\xc0\x13\x84\x49\xaa\x83\x8d\x84\xe5\xb2\xd3\xb6\xc1\x1e\x29\x15\x57\xcb\xe5\xf1\x78\x5c\x1a\xbf\x29\x71\x31\x2c\x7b\xdd\xf2\xad\xa6\x75\xa8\x91\xb3\x9f\x45\xb5\x84\x59\x0a\x54\x60\x9f\x58\xb5\x1f\x8a\x45\xab\xa7\x26\x21\x02\x61\x01\x18\x26\x01\x0a\xaa\x1d\x3a\x10\x3c\x02\x4d\x5a\x56\xcb\xe5\x21\x55\xa3\xa4\x5f\xf2\x79\x54\xd6\x3a\x0c\x13\x56\x36\xe4\x88\x9f\xd2\x2b\x1a\xd5\x8a\x33\x73\x48\xcb\xb2\xbc\x11\xc2\x85\xeb\x41\x8b\xfa\xc8\x24\xc2\xab\x0b\xd7\x3b\xb0\xac\x26\x8f\x27\x82\x0e\x47\x0a\x5e\xf9\x07\xf0\xba\x72\xf8\x33\x5c\xa4\x14\x2d\xeb\x12\x45\x44\xa5\xa4\x9c\x01\x95\x30\x42\x81\xfd\x09\x0c\x05\x61\x0a\x83\x02\x0c\x04\x22\xf0\x01\xf8\x23\x22\x86\x58\x00\xc5\x81\xb0\x09\xc4\x28\x24\x67\xc0\xfb\x8a\x50\xa6\xe3\x9f\x80\xcf\xe3\x89\xc5\x07\xa0\x46\x54\x82\xe4\x03\x35\x26\x22\xd5\x90\x48\xc9\x7d\x4a\x14\x06\x10\x70\x3f\x89\x90\xa5\x13\x17\x06\x34\x44\x09\xaf\xd4\x08\xc1\xee\x65\x18\xf6\x81\x61\x12\x20\x09\x2d\xca\x40\xbf\x9b\xbd\x32\x6b\x3d\x9e\x28\x10\x28\x95\xa0\xc6\x0a\x05\xa0\xcc\x0f\x93\x40\xcb\x30\x7b\x1d\xd2\x88\x66\x1c\x34\xba\x51\x5c\x5a\x8a\x43\x22\xb1\x60\xe4\x2c\x40\xc4\x03\x3a\xd0\xff\x47\xa3\x56\x9c\xf4\x43\x2a\x47\x05\x08\xa8\x26\xdd\x4f\x14\x16\x40\xea\x41\x63\xc7\x82\xd6\xa3\xcc\x05\x48\x0c\x43\xcb\xe7\x31\x45\x09\x46\xd7\x85\x74\x06\x46\x8b\x1e\x6b\x83\xaa\xcc\x44\x52\x8f\x8c\x47\x3c\xca\x6b\x42\xa5\x35\x48\x04\xa3\x72\x84\x06\x27\xe0\x20\xb9\xe1\xa8\xa3\x59\x8f\x68\xf0\x01\x0f\x43\x3e\xd6\xaa\xf9\x9c\x05\x34\x5b\xde\x19\x27\x93\xbe\x5e\xe2\xfa\x73\xbf\x32\xae\xa8\x9f\x9a\xdb\x38\x20\x5e\x78\x35\x7b\x25\x47\x24\x0c\xa1\x8f\x99\xc1\x30\x00\xca\x80\x2c\xa9\x23\x34\x7b\xdd\x1f\x2a\x4a\x42\x88\xb9\x30\xfc\x56\xd5\x2c\x59\x96\x77\xee\x40\xaf\x73\xe6\x7d\x6c\x74\x1d\x70\x7b\x70\xd9\xed\x7c\x70\x4f\x9d\x53\xb0\x1b\x3d\x70\x7b\x76\x01\x3e\xba\xde\x79\xe7\xca\x83\x8f\x8d\x6e\xb7\xd1\xf6\x3e\x41\xe7\x0c\x1a\xed\x4f\xf0\x1f\xb7\x7d\x5a\x00\xe7\xb7\xcb\xae\xd3\xeb\x41\xa7\x6b\xb9\x17\x97\x2d\xd7\x39\x2d\x80\xdb\x6e\xb6\xae\x4e\xdd\xf6\x7b\x78\x77\xe5\x41\xbb\xe3\x41\xcb\xbd\x70\x3d\xe7\x14\xbc\x0e\x68\x86\x19\x29\xd7\xe9\x69\x62\x17\x4e\xb7\x79\xde\x68\x7b\x8d\x77\x6e\xcb\xf5\x3e\x15\xac\x33\xd7\x6b\x6b\x9a\x67\x9d\x2e\x34\xe0\xb2\xd1\xf5\xdc\xe6\x55\xab\xd1\x85\xcb\xab\xee\x65\xa7\xe7\x40\xa3\x7d\x0a\xed\x4e\xdb\x6d\x9f\x75\xdd\xf6\x7b\xe7\xc2\x69\x7b\x25\x70\xdb\xd0\xee\x80\xf3\xc1\x69\x7b\xd0\x3b\x6f\xb4\x5a\x9a\x95\xd5\xb8\xf2\xce\x3b\x5d\x2d\x1f\x34\x3b\x97\x9f\xba\xee\xfb\x73\x0f\xce\x3b\xad\x53\xa7\xdb\x83\x77\x0e\xb4\xdc\xc6\xbb\x96\x93\xb2\x6a\x7f\x82\x66\xab\xe1\x5e\x14\xe0\xb4\x71\xd1\x78\xef\x18\xac\x8e\x77\xee\x74\x2d\x0d\x96\x4a\x07\x1f\xcf\x1d\x3d\xa4\xf9\x35\xda\xd0\x68\x7a\x6e\xa7\xad\xd5\x68\x76\xda\x5e\xb7\xd1\xf4\x0a\xe0\x75\xba\xde\x1c\xf5\xa3\xdb\x73\x0a\xd0\xe8\xba\x3d\x6d\x90\xb3\x6e\xe7\xa2\x60\x69\x73\x76\xce\x34\x88\xdb\xd6\x78\x6d\x27\xa5\xa2\x4d\x0d\x39\x8f\x74\xba\xe6\xf9\xaa\xe7\xcc\x09\xc2\xa9\xd3\x68\xb9\xed\xf7\x3d\x8d\xac\x55\x9c\x01\x97\xac\x62\xf1\xc4\xaa\x99\x14\x78\x1b\x85\x4c\xd6\x37\x24\xb6\xc3\xa3\xa3\xa3\x34\x9f\xd9\xbb\x01\x49\x9d\xdc\xea\xf6\x80\x33\x55\x1c\x90\x88\x86\x93\x2a\xfc\x74\x8e\xe1\x0d\x2a\xea\x13\x68\x63\x82\x3f\x15\x60\x3e\x50\x80\x86\xa0\x24\x2c\x80\x24\x4c\x16\x25\x0a\x3a\x38\x86\x3e\xbf\x2d\x4a\xfa\x97\xae\xc5\xd0\xe7\x22\x40\x51\xec\xf3\xdb\x63\x30\x44\x25\xfd\x0b\xab\x70\xf8\x73\x7c\x7b\x0c\x11\x11\x43\xca\xaa\x50\x39\xd6\xb9\x75\x84\x24\x78\x4e\xfe\x11\x2a\x02\xba\xa2\xd6\xed\x1b\x8a\x63\x3d\x8b\x6c\x3d\x7b\x15\x32\x55\xb7\xc7\x34\x50\xa3\x7a\x80\x37\xd4\xc7\xa2\x79\x78\x3e\x63\x41\x79\x26\xae\x76\x66\x11\xff\x4c\xe8\x4d\xdd\x6e\xa6\xa2\x16\xbd\x49\x8c\x4b\x82\xeb\x56\xa4\xac\x9d\x7b\x6c\x2a\x81\x44\x55\xbf\xf2\xce\x8a\xbf\x3e\xb3\xf8\xe6\xdb\xc6\xf3\xb9\xfb\xbe\x5e\xa4\x56\x36\xc2\x9d\x58\x56\xad\xac\x83\x52\xff\xe8\xf3\x60\x02\x54\x61\x24\x7d\x1e\x63\xdd\xb6\xcd\x83\x9a\xe8\xdf\xd9\x8c\x92\xfe\x08\x23\x62\x66\x94\xa3\xab\xfb\xc5\xac\xf7\x7d\x52\x25\x8b\x63\xec\x7f\xa1\xaa\x98\xbe\x88\x38\x57\x23\x83\x94\xd6\x06\x4a\x24\x06\x0b\x20\x1d\x1b\x06\xbb\x48\x82\xcf\x89\x54\x55\x60\x9c\xe1\x31\x8c\x50\x57\xa6\x2a\x1c\x56\x2a\xff\x3a\x86\x90\x32\x2c\xce\x87\x4a\x6f\x31\x3a\x06\x33\x03\x52\x00\xf8\x81\x46\x7a\xb2\x10\xa6\x8e\xa1\x4f\xfc\x2f\x43\xc1\x13\x16\x14\x7d\x1e\x72\x51\x85\x1f\x07\x6f\xf5\xdf\xb2\xf9\x21\x26\x41\x60\xa4\xd2\xd1\xd0\x1f\x1a\xc8\xba\x9d\x41\xda\xda\xde\x8a\xf4\x9f\x3a\x3c\x96\x54\xda\x51\x8f\x8d\xb2\x03\xd4\x94\x78\xc6\x3c\x06\xa0\x25\x78\xe2\x4c\x7a\x83\x42\x13\x09\x8b\x24\xa4\x43\x56\x05\xc5\xe3\xbc\xa1\x6e\xcc\x8b\xba\xad\x78\x6c\x9f\xd4\xca\x2a\x58\x08\x9a\x66\x56\xfb\x6d\xa5\x62\xbf\x00\xa1\xb3\xa5\x55\x15\xfa\x21\xf7\xbf\xe4\x62\x3b\x22\xb7\xc5\x2c\x48\xde\x56\x2a\xf1\x6d\xee\xa5\x1f\x22\x11\x9a\xa1\x1a\xe5\xc6\xb7\x4d\x94\xb9\x71\x80\x24\x8a\xaf\x4c\x89\x9c\xb5\x8c\xa1\x00\x6a\x01\xbd\x79\xea\xb0\xca\xeb\xbb\x6a\x9c\xfb\x95\x98\xc9\xad\x9d\x6c\x26\x73\xe6\x67\x6d\x09\x1b\x7c\x0c\xc3\x0c\xba\x6e\x57\xd2\x67\x19\x13\x7f\xf6\xfc\xa4\x8a\x66\x2f\x05\x09\x68\x22\xab\xf0\xc6\x8c\x6d\x48\x00\x83\x41\x2e\x8b\xa5\x68\x55\x38\x8c\x6f\x41\xf2\x90\x06\xf0\x23\x1e\xe9\xbf\x7c\x62\x18\x0c\x96\x6c\xf1\x12\xb2\xc3\x42\x92\xa7\xcb\x12\x6f\xb7\x4e\xb8\x9c\x75\x0d\xca\x38\x2b\x35\xbf\x54\x2a\xc7\x60\x4a\x54\x06\xef\x23\x53\x28\x36\xf9\xcb\xfc\x5b\x31\x4e\x59\xf7\x9b\xf3\xf6\x97\xd7\xaf\x9b\x9b\x0b\xd0\x6b\x1d\xd7\x36\x64\xf3\x2d\x65\xb0\xec\xbd\x14\x77\xf3\x8c\x9c\xfd\xb3\xd8\x53\x9d\x6f\xa6\x82\xf9\x58\xb2\xf1\x5b\xd2\x01\x1c\xc2\x74\x2a\xe7\x1f\x3c\x60\xc0\x05\x2c\xf6\xfd\xb6\xec\xbb\xc2\x74\xba\xc2\x15\x96\x77\x01\xeb\xb9\x3d\xc0\x35\xb0\xec\xd3\x4a\xce\xf9\xf3\x1c\x3c\x7f\x16\xfb\x30\xdd\xa5\x98\x2d\x82\xe7\x30\x0d\x9e\xfb\x62\xe3\xc5\xe7\xbe\xad\x66\x7f\x59\x41\xf0\xd2\x43\xa1\x02\x95\x59\x2e\xb9\x2f\x1c\x32\x35\x08\x8c\x04\x0e\xea\xf6\x2e\x3b\x06\x4f\x1c\x0f\xb3\xa4\x79\x76\x76\x96\x25\xdf\x00\x7d\x2e\xcc\x37\xb9\xd9\xf2\x20\xb7\x20\x78\xad\x97\x03\xb9\xbc\xdd\xe7\x61\xb0\x39\x71\xfb\x89\x90\x9a\x7a\xcc\x69\x3a\x30\x6f\x28\x28\x33\x44\xb3\xbe\x62\x25\xc1\xff\xa2\x05\x33\xf4\xcc\x47\xd4\x01\x17\x51\x15\x7c\x12\x53\x45\x42\xfa\x17\x6e\x4c\xfa\x6f\x7e\xfe\x15\x03\xb2\xa1\x5e\xaf\x41\x64\xc3\xc6\xca\xd5\xb4\x90\xcf\x07\xe7\xdd\x5b\x7c\x9b\xb9\xf7\xe4\x03\xc5\x31\x50\x06\x0f\x7e\x1d\xaf\x95\xc9\xc6\x18\x5e\x49\xbc\x9b\xd3\xef\x3c\x75\xdf\xbb\xf9\xb1\xa1\x28\xec\xa7\xec\xdf\x33\x65\xa5\x12\x9c\x0d\x9f\xcf\xb4\xbf\x6f\x3f\xb9\xf5\x47\xb6\xf3\x55\x2b\xa7\x42\x7e\x87\xa8\xdb\xd0\x30\x64\x6f\x66\xc7\x93\x56\xb7\xd0\xf6\x71\xf8\xff\x11\x87\x69\x6b\x3a\x0f\xb5\x5a\x5f\x3c\xeb\x77\xc4\x4d\x36\x7a\xe0\x5c\xde\xf6\xc3\x73\xcf\xac\xcc\xf6\x79\xb7\xa9\x16\x2c\x36\xd1\xd3\x4a\xf0\xec\x91\xb1\x24\xd1\x4b\x09\x8f\x07\x2d\xfa\xe0\x61\xcb\x7f\x68\xb0\x2c\x77\x98\xab\xa7\x3f\x9f\xa9\xa1\x9c\xb5\x5b\x6b\x3d\x65\xc2\x02\x14\xba\xfb\xcb\x87\x53\x7a\x7e\x55\x37\x51\x2f\x2f\xc7\x7c\x5b\x35\xdd\xb1\xbd\x5b\x3e\x6b\xb2\xd1\xbd\xfb\xae\xf0\xc5\x54\xe3\x17\x58\xfd\x6a\xa3\x17\x28\xd3\x3f\x7a\x06\xdf\xd7\x11\xef\x27\xd6\xff\xfe\x72\x6b\x7e\x66\x6f\xb1\xe0\x9a\x0d\x3d\xc3\x92\x6b\xf9\x04\xe1\x3e\x1a\xf7\x8b\xae\xfd\xa2\x6b\xbf\xe8\xda\x2f\xba\xf6\x8b\xae\xfd\xa2\x6b\x87\x7a\x5a\x2b\x9b\xfd\xb8\x93\x47\x6c\x85\xce\x51\x16\x23\x4f\x7e\x12\x23\x77\x34\x69\xe9\xa4\xc9\xc2\xd1\x47\x47\x47\xf7\x6d\x70\xe7\x77\x76\xd7\xb7\x24\x5f\xca\x4e\xef\xcb\x69\x5f\x9e\xb2\x75\x79\xbd\xb5\x75\xd9\xb8\x89\xf6\x90\xcb\x97\x7a\x9b\x95\x73\x0d\xf9\x53\x58\xcb\xe9\x2a\x7f\x55\xdd\x7e\x5a\xd5\x73\x1a\xed\x9c\xaa\x90\x29\xe8\x4f\x76\xdb\x87\x5b\xcf\x1d\x6b\xe7\x1d\x56\x33\x43\xad\x1c\xd0\x9b\x93\xf4\xbf\x56\x3e\x4d\xfc\x43\x8e\xd7\xa5\x2a\x2e\xf2\x57\xad\xdc\xe7\xc1\x44\x8f\x8c\x54\x14\x9e\x58\xd6\xe6\xfb\x3b\x71\x22\x47\xfc\x06\xc5\x77\xb8\xff\xbd\x46\xea\xef\xbf\x0f\xf6\x7d\xae\x83\xed\x7e\x1b\xec\xfb\x5d\x06\x5b\xe2\xb9\x83\x25\x17\x77\xb2\x1f\x73\x27\x74\xf9\x36\x36\x93\xdf\xe5\x92\xd6\x32\x9d\xbd\x7b\x1f\xe3\xde\xff\x06\x00\x00\xff\xff\x0a\xd2\x4d\x62\x76\x44\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xec\x1b\x7f\x6f\xdb\xb6\xf2\x7f\x7d\x8a\x9b\x86\x87\x35\x80\x7f\xa5\xdd\x8a\xc5\xb1\xf3\xe0\x3a\x4a\x23\x3c\xc7\x0e\x6c\xa5\x5d\x31\x0c\x01\x2d\x9d\x6d\xb6\x12\xa9\x91\x54\x1c\x2f\xf5\x77\x7f\xa0\x24\xdb\x92\x2d\x27\x4e\xd1\x97\x64\x6f\x5e\xb0\xcd\xa2\xee\xf7\x1d\xef\x8e\x22\x79\x77\x07\x1e\x8e\x28\x43\x30\xaf\xaf\x89\x8f\x42\x05\x84\x91\x31\x0a\x13\xe6\xf3\x56\xe6\xf9\xee\x0e\x90\x79\x30\x9f\x1b\x5b\x51\xae\xfa\x1d\x8d\x75\x77\x07\x15\xeb\x56\xa1\x60\xc4\xbf\xea\x77\x60\x3e\xaf\xfe\x58\x8d\xe1\xe4\xbf\x05\xba\x48\x6f\x50\x34\x35\x50\x3f\x7d\x80\xaf\x10\x09\xff\xcf\x08\xc5\x2c\x41\x4f\x19\xe5\x39\xc9\x68\xf8\x19\x5d\xa5\x39\xfc\xae\xb1\x07\x8a\xa8\x48\xc2\x57\x50\xfc\x2a\x0c\x51\x24\xa8\x74\x04\xf8\xe7\xf2\xa5\x39\xa2\x82\xb2\xb1\xc6\xa9\x6b\x9c\x58\x21\x59\x39\x8b\x47\xe1\x2b\xf8\xc8\xb2\x1c\xff\x00\x0d\xf4\x5e\xf0\x28\xec\x90\x21\xfa\xb2\x32\xe0\x42\xa1\x77\x49\xa8\x90\x95\x0f\xc4\x8f\x50\x33\xfc\xcc\x29\x03\x13\x34\x55\x48\x58\x8e\x15\xbc\xd2\xb4\x2a\x6d\x1e\x04\x9c\x25\xc8\x07\xe9\x58\x86\xde\x01\xcc\xe7\xaf\xee\xee\x60\x4a\xd5\x24\x0f\x5c\xe9\x63\xc0\x6f\x30\xcf\xbd\x4b\x02\x94\xa9\x45\x8b\xb8\x2f\x05\x3f\x58\xfe\xda\xe2\x26\x0f\xa5\x2b\x68\xa8\x28\x67\xe6\x3d\x36\x56\x78\xab\x12\x97\x5e\xfb\x54\xaa\x14\x54\x10\x36\x46\xa8\xc0\x7c\x9e\xc8\x55\x37\x56\x83\x9b\x76\xd2\x56\x29\xc7\x86\xd4\xe2\xeb\xa7\x26\x2c\x15\x48\x05\x4b\x98\xb7\x18\xe3\x8a\x68\x99\x72\x24\x33\xc3\xdf\x46\x77\xc0\x23\xe1\x62\x3d\x71\x26\x32\x14\x44\x71\x91\x44\xa2\x51\x60\xa8\x9c\x0d\xa4\x4f\xdc\x2f\x15\x0f\x47\x24\xf2\x55\x45\x51\xe5\x63\x6a\x05\x85\x41\xe8\x13\x95\x8f\xc5\xca\x36\x93\xe7\xe9\x44\x52\xcf\x86\xa0\x88\x54\x7e\xce\xed\x48\x6f\x44\x7c\x7f\x48\xdc\x2f\x1b\xf4\x0a\xc5\xd7\x44\xe1\x2b\x3c\x04\xe8\x53\xf6\x65\x67\x09\xdc\x54\x02\xea\x99\xbb\x21\x84\x02\x75\x74\xed\x08\x9d\x11\xe8\x5e\x8b\xc5\x29\x67\x47\x91\xa9\xcb\x19\x06\xfc\x33\x35\x77\x87\x8f\x84\xbf\xab\xc4\xbb\x2b\x37\xe2\x5c\xa1\xc8\x03\xe7\x82\x30\xd4\xaa\x79\x91\x9a\x2d\x51\x36\xe7\xef\xe3\xc2\x71\x93\xa2\xeb\x53\x64\xea\xdb\x03\x72\x1b\xc5\x55\x11\xf8\x36\x9f\x6d\xd2\xa5\x4c\x2a\xc2\x5c\x94\x05\x74\x37\x12\x56\x65\xbb\x55\x79\x28\xc7\xc8\x28\x2e\x09\x07\x28\x25\x19\x7f\xdb\xfc\xde\x20\xb6\xe9\xa1\x34\xbf\x6f\x49\x67\x85\x09\xdd\x58\x2b\x27\xb9\x7a\x75\x00\x35\x28\xcf\xe7\x46\x32\x08\xc9\x60\xdd\x58\x13\x7d\xd3\x22\x39\x22\x09\x93\x72\x46\xa3\x02\x7e\x7d\x94\xdc\xbf\x41\x6f\x8d\xe3\x62\x78\x77\x9e\x0b\x8c\x0d\xae\xe5\x5d\x4c\x2a\xe3\x3c\xfe\xf8\x68\xca\x79\x7d\x8a\xee\x84\xa8\xc7\xfa\xdc\xd8\xfb\xef\x1e\xff\x65\xfb\xc2\x2b\xe1\x6f\xd0\x2b\xf4\xcf\x16\xaf\xaf\xf9\x47\xf1\x6b\x5d\x2c\xb7\x66\xd2\x4d\xf0\x90\x08\x35\x7b\x04\xbc\x22\xe3\x5d\xa1\xc9\x18\x99\xba\x5e\x2f\x71\xf9\xf8\xba\xa1\xae\xe2\x82\x87\x72\x15\xb6\x8a\x28\xbc\xce\x07\xda\x3e\x96\x1e\x97\x0b\x36\xad\x8a\x4c\x51\x35\xbb\xf6\xa8\x0c\x7d\x32\xbb\xde\xd2\x4d\x3d\x9c\xb8\x37\x29\x07\x9c\x51\xc5\xb5\x41\xae\x15\xe7\xfe\x23\x4b\x62\x96\x36\x06\x84\xfa\xab\x38\x58\x2d\x58\x1e\x2d\x65\x9e\xd2\x44\x05\xb1\x58\x46\xe3\x87\xd3\x5e\xdb\xf9\x74\x69\x81\x1e\x82\xcb\xab\x77\x1d\xbb\x0d\x66\xb9\x5a\xfd\xf8\xa6\x5d\xad\x9e\x3a\xa7\xf0\xdb\xb9\x73\xd1\x81\xc3\x4a\x0d\x1c\x41\x98\xa4\x3a\xd8\x88\x5f\xad\x5a\x5d\x13\xcc\x89\x52\x61\xbd\x5a\x9d\x4e\xa7\x95\xe9\x9b\x0a\x17\xe3\xaa\xd3\xaf\xde\x6a\x5a\x87\x1a\x39\xfd\x59\x56\x19\xcc\x8a\xa7\x3c\xf3\xc4\x68\xfc\x50\x2e\x1b\x03\x35\xf3\x11\x08\xf3\x20\x66\xe2\xa1\xa0\xda\xa1\x23\xc1\x03\xd0\xa4\x65\xbd\x5a\x1d\x53\x35\x89\x86\x15\x97\x07\x55\xad\xc3\x38\x62\xd5\x98\x1c\x71\x13\x7a\xe5\x58\xb5\xf2\xc2\x1c\xd2\x30\x0c\x67\x82\x70\x61\x3b\xd0\xa1\x2e\x32\x89\xf0\xea\xc2\x76\x0e\x0c\xa3\xcd\xc3\x99\xa0\xe3\x89\x82\x57\xee\x01\xbc\xae\x1d\xfe\x0c\x17\x09\x45\xc3\xb8\x44\x11\x50\x29\x29\x67\x40\x25\x4c\x50\xe0\x70\x06\x63\x41\x98\x42\xaf\x04\x23\x81\x08\x7c\x04\xee\x84\x88\x31\x96\x40\x71\x20\x6c\x06\x21\x0a\xc9\x19\xf0\xa1\x22\x94\xe9\xf8\x27\xe0\xf2\x70\x66\xf0\x11\xa8\x09\x95\x20\xf9\x48\x4d\x89\x48\x34\x24\x52\x72\x97\x12\x85\x1e\x78\xdc\x8d\x02\x64\xc9\xc4\x85\x11\xf5\x51\xc2\x2b\x35\x41\x30\x07\x29\x86\x79\x10\x33\xf1\x90\xf8\x06\x65\xa0\xdf\x2d\x5e\xc5\x6b\x3d\x1e\x29\x10\x28\x95\xa0\xb1\x15\x4a\x40\x99\xeb\x47\x9e\x96\x61\xf1\xda\xa7\x01\x4d\x39\x68\xf4\x58\x71\x69\x28\x0e\x91\xc4\x52\x2c\x67\x09\x02\xee\xd1\x91\xfe\x3f\xc6\x6a\x85\xd1\xd0\xa7\x72\x52\x02\x8f\x6a\xd2\xc3\x48\x61\x09\xa4\x1e\x8c\xed\x58\xd2\x7a\x54\xb9\x00\x89\xbe\x6f\xb8\x3c\xa4\x28\x81\x8f\x72\xd2\xc5\x30\x5a\xf4\x50\x1b\x54\xa5\x26\x92\x7a\x64\x3a\xe1\x41\x5e\x13\x2a\x8d\x51\x24\x18\x95\x13\x8c\x71\x3c\x0e\x92\x97\x20\x8d\x66\x3d\xa2\xc1\x47\xdc\xf7\xf9\x54\xab\xe6\x72\xe6\xd1\x74\x79\x17\x3b\x99\x0c\xf5\x12\xd7\x5d\xfa\x95\x71\x45\xdd\xc4\xdc\xb1\x03\xc2\x95\x57\xd3\x57\x72\x42\x7c\x1f\x86\x98\x1a\x0c\x3d\xa0\x0c\x48\x46\x1d\xa1\xd9\xeb\xfe\x50\x51\xe2\x43\xc8\x45\xcc\x6f\x5d\xcd\x8a\x61\x38\xe7\x16\x0c\x7a\x67\xce\xc7\x56\xdf\x02\x7b\x00\x97\xfd\xde\x07\xfb\xd4\x3a\x05\xb3\x35\x00\x7b\x60\x96\xe0\xa3\xed\x9c\xf7\xae\x1c\xf8\xd8\xea\xf7\x5b\x5d\xe7\x13\xf4\xce\xa0\xd5\xfd\x04\xff\xb1\xbb\xa7\x25\xb0\x7e\xbb\xec\x5b\x83\x01\xf4\xfa\x86\x7d\x71\xd9\xb1\xad\xd3\x12\xd8\xdd\x76\xe7\xea\xd4\xee\xbe\x87\x77\x57\x0e\x74\x7b\x0e\x74\xec\x0b\xdb\xb1\x4e\xc1\xe9\x81\x66\x98\x92\xb2\xad\x81\x26\x76\x61\xf5\xdb\xe7\xad\xae\xd3\x7a\x67\x77\x6c\xe7\x53\xc9\x38\xb3\x9d\xae\xa6\x79\xd6\xeb\x43\x0b\x2e\x5b\x7d\xc7\x6e\x5f\x75\x5a\x7d\xb8\xbc\xea\x5f\xf6\x06\x16\xb4\xba\xa7\xd0\xed\x75\xed\xee\x59\xdf\xee\xbe\xb7\x2e\xac\xae\x53\x01\xbb\x0b\xdd\x1e\x58\x1f\xac\xae\x03\x83\xf3\x56\xa7\xa3\x59\x19\xad\x2b\xe7\xbc\xd7\xd7\xf2\x41\xbb\x77\xf9\xa9\x6f\xbf\x3f\x77\xe0\xbc\xd7\x39\xb5\xfa\x03\x78\x67\x41\xc7\x6e\xbd\xeb\x58\x09\xab\xee\x27\x68\x77\x5a\xf6\x45\x09\x4e\x5b\x17\xad\xf7\x56\x8c\xd5\x73\xce\xad\xbe\xd1\xb1\x17\xd2\xc1\xc7\x73\x4b\x0f\x69\x7e\xad\x2e\xb4\xda\x8e\xdd\xeb\x6a\x35\xda\xbd\xae\xd3\x6f\xb5\x9d\x12\x38\xbd\xbe\xb3\x44\xfd\x68\x0f\xac\x12\xb4\xfa\xf6\x40\x1b\xe4\xac\xdf\xbb\x28\x19\xda\x9c\xbd\x33\x0d\x62\x77\x35\x5e\xd7\x4a\xa8\x68\x53\x43\xce\x23\xbd\x7e\xfc\x7c\x35\xb0\x96\x04\xe1\xd4\x6a\x75\xec\xee\xfb\x81\x46\xd6\x2a\x2e\x80\x2b\x46\xb9\x7c\x62\x34\xe2\x14\x78\x1b\xf8\x4c\x36\x0b\x12\xdb\xe1\xd1\xd1\x51\x92\xcf\xcc\xdd\x80\xa4\x4e\x6e\x4d\x73\xc4\x99\x2a\x8f\x48\x40\xfd\x59\x1d\x7e\x3a\x47\xff\x06\x15\x75\x09\x74\x31\xc2\x9f\x4a\xb0\x1c\x28\x41\x4b\x50\xe2\x97\x40\x12\x26\xcb\x12\x05\x1d\x1d\xc3\x90\xdf\x96\x25\xfd\x4b\xd7\x62\x18\x72\xe1\xa1\x28\x0f\xf9\xed\x31\xc4\x44\x25\xfd\x0b\xeb\x70\xf8\x73\x78\x7b\x0c\x01\x11\x63\xca\xea\x50\x3b\xd6\xb9\x75\x82\xc4\x7b\x4e\xfe\x01\x2a\x02\xba\xa2\x36\xcd\x1b\x8a\x53\x3d\x8b\x4c\x3d\x7b\x15\x32\xd5\x34\xa7\xd4\x53\x93\xa6\x87\x37\xd4\xc5\x72\xfc\xf0\x7c\xc6\x82\xea\x42\x5c\xed\xcc\x32\xfe\x19\xd1\x9b\xa6\xd9\x4e\x44\x2d\x3b\xb3\x10\x33\x82\xeb\x56\xa4\xaa\x9d\x7b\x1c\x57\x02\x89\xaa\x79\xe5\x9c\x95\x7f\x7d\x66\xf1\xe3\x6f\x1b\xcf\xe7\xee\xfb\x7a\x91\x46\x35\x16\xee\xc4\x30\x1a\x55\x1d\x94\xfa\xc7\x90\x7b\x33\xa0\x0a\x03\xe9\xf2\x10\x9b\xa6\x19\x3f\xa8\x99\xfe\x9d\xce\x28\xe9\x4e\x30\x20\xf1\x8c\xb2\x74\x75\xbf\x58\xf4\xbe\x4f\xaa\x64\x79\x8a\xc3\x2f\x54\x95\x93\x17\x01\xe7\x6a\x12\x23\x25\xb5\x81\x12\x89\xde\x0a\x48\xc7\x46\x8c\x5d\x26\xde\xe7\x48\xaa\x3a\x30\xce\xf0\x18\x26\xa8\x2b\x53\x1d\x0e\x6b\xb5\x7f\x1d\x83\x4f\x19\x96\x97\x43\x95\xb7\x18\x1c\x43\x3c\x03\x12\x00\xf8\x81\x06\x7a\xb2\x10\xa6\x8e\x41\x7f\x0d\x1b\x0b\x1e\x31\xaf\xec\x72\x9f\x8b\x3a\xfc\x38\x7a\xab\xff\xb2\xe6\x87\x90\x78\x5e\x2c\x95\x8e\x86\xe1\x38\x86\x6c\x9a\x29\xa4\xa9\xed\xad\xc8\xf0\xa9\xc3\x23\xa3\xd2\x8e\x7a\x14\xca\x0e\xd0\x50\xe2\x19\xf3\x18\x80\x96\xe0\x89\x33\xe9\x0d\x0a\x4d\xc4\x2f\x13\x9f\x8e\x59\x1d\x14\x0f\xf3\x86\xba\x89\x5f\x34\x4d\xc5\x43\xf3\xa4\x51\x55\xde\x4a\xd0\x24\xb3\x9a\x6f\x6b\x35\xf3\x05\x08\x9d\x2e\xad\xea\x30\xf4\xb9\xfb\x25\x17\xdb\x01\xb9\x2d\xa7\x41\xf2\xb6\x56\x0b\x6f\x73\x2f\x5d\x1f\x89\xd0\x0c\xd5\x24\x37\xbe\x6d\xa2\x2c\x8d\x03\x24\x52\x7c\x6d\x4a\xe4\xac\x15\x1b\x0a\xa0\xe1\xd1\x9b\xa7\x0e\xab\xbc\xbe\xeb\xc6\xb9\x5f\x89\x85\xdc\x00\xe9\x64\x4e\xfd\xac\x2d\x61\x82\x8b\xbe\x9f\x42\x37\xcd\x5a\xf2\x2c\x43\xe2\x2e\x9e\x9f\x54\xd1\xf4\xa5\x20\x1e\x8d\x64\x1d\xde\x84\xb7\xc5\x09\x60\x34\xca\x65\xb1\x04\xad\x0e\x87\xe1\x2d\x48\xee\x53\x0f\x7e\xc4\x23\xfd\x97\x4f\x0c\xa3\x51\xc6\x16\x2f\x21\x3b\xac\x24\x79\xba\x2c\xf1\x76\xeb\x84\xcb\x59\x37\x46\x99\xa6\xa5\xe6\x97\x5a\xed\x18\xe2\x12\x95\xc2\xbb\xc8\x14\x8a\x22\x7f\xc5\xff\xd6\xa0\x56\xe8\x37\xeb\xed\x2f\xaf\x5f\xb7\x8b\x0b\xd0\x6b\x1d\xd7\x26\xa4\xf3\x2d\x61\x90\xf5\x5e\x82\x5b\x3c\x23\x17\xff\xac\xf6\x54\x97\x9b\xa9\x10\x7f\x2c\x29\xfc\x96\x74\x00\x87\x30\x9f\xcb\xe5\x07\x0f\x18\x71\x01\xab\x7d\xbf\x2d\xfb\xae\xfa\xbb\x07\xc0\x26\xdf\x74\x17\xb0\x99\xdb\x03\xdc\x00\x4b\x38\xe5\x9d\xbf\xcc\xc1\xcb\x67\xb1\x0f\xd3\x5d\x8a\xd9\x2a\x78\x0e\x93\xe0\xb9\x2f\x36\x5e\x7c\xee\xdb\x6a\xf6\x97\x15\x04\x2f\x3d\x14\x6a\x50\x83\xd7\x0f\x87\x43\xaa\x06\x81\x89\xc0\x51\xd3\xdc\x65\xc7\xe0\x89\xe3\x61\x91\x34\xcf\xce\xce\xd2\xe4\xeb\xa1\xcb\x45\xfc\x4d\x6e\xb1\x3c\xc8\x2d\x08\x5e\x63\x90\x92\x59\xe4\xed\x21\xf7\xbd\xe2\xc4\xed\x46\x42\x6a\xea\x21\xa7\xc9\xc0\xb2\xa1\xa0\x2c\x26\x9a\xf6\x15\x6b\x09\xfe\x97\xf0\x36\xa5\x17\x7f\x44\x1d\x71\x11\xd4\xc1\x25\x21\x55\xc4\xa7\x7f\x61\x61\xd2\x7f\xf3\xf3\xaf\xe8\x91\x82\x7a\xbd\x01\x91\x0e\xc7\x56\xae\x27\x85\x7c\x39\xb8\xec\xde\xc2\xdb\xd4\xbd\x27\x1f\x28\x4e\x81\x32\x78\xf0\xeb\x78\xa3\x4a\x0a\x63\x78\x2d\xf1\x16\xa7\xdf\x65\xea\xbe\x77\xf3\x63\x3e\xdf\x4f\xd9\x27\x9a\xb2\x52\x09\xce\xc6\xcf\x67\xda\xdf\xb7\x9f\xdc\xfa\x23\xdd\xf9\x6a\x54\x13\x21\xbf\x43\xd4\x15\x34\x0c\xe9\x9b\xc5\xf1\xa4\xf5\x2d\xb4\x7d\x1c\xfe\x33\xe2\x30\x69\x4d\x97\xa1\xd6\x18\x8a\x67\xfd\x8e\x58\x64\xa3\x07\xce\xe5\x6d\x3f\x3c\xf7\xcc\xca\x6c\x9f\x77\x45\xb5\x60\xb5\x89\x9e\x54\x82\x67\x8f\x8c\x8c\x44\x2f\x25\x3c\x1e\xb4\xe8\x83\x87\x2d\xff\xa6\xc1\x92\xed\x30\xd7\x4f\x7f\x3e\x53\x43\xb9\x68\xb7\x36\x7a\xca\x88\x79\x28\x74\xf7\x97\x0f\xa7\xe4\xfc\xaa\x6e\xa2\x5e\x5e\x8e\xf9\xb6\x6a\xba\x63\x7b\x97\x3d\x6b\x52\xe8\xde\x7d\x57\xf8\x62\xaa\xf1\x0b\xac\x7e\x8d\xc9\x0b\x94\xe9\x6f\x3d\x83\xef\xeb\x88\xf7\x13\xeb\xff\x7f\xb9\xb5\x48\xc8\x99\x05\xd7\x62\xe8\x19\x96\x5c\xd9\x13\x84\xfb\x68\xdc\x2f\xba\xf6\x8b\xae\xfd\xa2\x6b\xbf\xe8\xda\x2f\xba\xf6\x8b\xae\x1d\xea\x69\xa3\x1a\xef\xc7\x9d\x3c\x62\x2b\x74\x89\xb2\x1a\x79\xf2\x93\x18\xb9\xa3\x49\x99\x93\x26\x2b\x47\x1f\x1d\x1d\xdd\xb7\xc1\x9d\x53\xaf\x60\x4b\xf2\xa5\xec\xf4\xbe\x9c\xf6\xe5\x29\x5b\x97\xd7\x5b\x5b\x97\xc2\x4d\xb4\x87\x5c\x9e\xe9\x6d\xd6\xce\x35\xe4\x4f\x61\x65\xd3\x55\xfe\xaa\xba\xf9\xb4\xaa\xe7\x34\xda\x39\x55\x21\x53\x30\x9c\xed\xb6\x0f\xb7\x99\x3b\x36\xce\x3b\xac\x67\x86\x46\xd5\xa3\x37\x27\xc9\x7f\x8d\x7c\x9a\xf8\x9b\x1c\xaf\x4b\x54\x5c\xe5\xaf\x46\x55\x9f\x62\xd5\x23\xfa\x38\xf0\x89\x61\x14\xdf\xdf\x09\x23\x39\xe1\x37\x28\xbe\xc3\xfd\xef\x0d\x52\xff\xfb\xfb\x60\xdf\xe7\x3a\xd8\xee\xb7\xc1\xbe\xdf\x65\xb0\x0c\xcf\x1d\x2c\xb9\xba\x93\xfd\x98\x3b\xa1\x19\x8a\x92\xc9\xef\x72\x49\x2b\x4b\x67\xef\xde\xc7\xb8\x37\x6b\xc5\x40\x2a\x24\x81\xfc\x0e\x73\x6e\x83\x52\x7a\x25\xff\x1f\x65\xda\xff\x0e\x00\xd5\xfd\x9c\xfb\xd1\x45\x00\x00"),
 		},
 	}
 	fs["/"].(*vfsgen۰DirInfo).entries = []os.FileInfo{
@@ -40,6 +40,9 @@ type ClusterPeer interface {
 	Status() string
 	// Peers returns the peer nodes in the cluster.
 	Peers() []ClusterMember
+	// SettledAt returns the time the peer finished settling, or the zero
+	// time while it is still settling.
+	SettledAt() time.Time
 }
 
 // ClusterMember interface that represents node peers in a cluster
@@ -66,6 +69,8 @@ type Peer struct {
 	states map[string]State
 	stopc  chan struct{}
 	readyc chan struct{}
+	// settledAt is set before readyc is closed.
+	settledAt time.Time
 
 	peerLock    sync.RWMutex
 	peers       map[string]peer
@@ -604,6 +609,15 @@ func (p *Peer) WaitReady(ctx context.Context) error {
 	}
 }
 
+// SettledAt returns the time Settle() finished, or the zero time if it hasn't
+// yet.
+func (p *Peer) SettledAt() time.Time {
+	if !p.Ready() {
+		return time.Time{}
+	}
+	return p.settledAt
+}
+
 // Return a status string representing the peer state.
 func (p *Peer) Status() string {
 	if p.Ready() {
@@ -686,6 +700,7 @@ func (p *Peer) Settle(ctx context.Context, interval time.Duration) {
 		case <-ctx.Done():
 			elapsed := time.Since(start)
 			level.Info(p.logger).Log("msg", "gossip not settled but continuing anyway", "polls", totalPolls, "elapsed", elapsed)
+			p.settledAt = time.Now()
 			close(p.readyc)
 			return
 		case <-time.After(interval):
@@ -706,6 +721,7 @@ func (p *Peer) Settle(ctx context.Context, interval time.Duration) {
 		nPeers = n
 		totalPolls++
 	}
+	p.settledAt = time.Now()
 	close(p.readyc)
 }
 
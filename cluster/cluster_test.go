@@ -68,9 +68,11 @@ func testJoinLeave(t *testing.T) {
 		require.Equal(t, context.Canceled, p.WaitReady(ctx))
 	}
 	require.Equal(t, p.Status(), "settling")
+	require.True(t, p.SettledAt().IsZero())
 	go p.Settle(context.Background(), 0*time.Second)
 	require.NoError(t, p.WaitReady(context.Background()))
 	require.Equal(t, p.Status(), "ready")
+	require.False(t, p.SettledAt().IsZero())
 
 	// Create the peer who joins the first.
 	p2, err := Create(
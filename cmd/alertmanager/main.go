@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"sync"
@@ -33,6 +34,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	commoncfg "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/promlog"
 	promlogflag "github.com/prometheus/common/promlog/flag"
@@ -47,9 +49,11 @@ import (
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/dispatch"
 	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/maintenance"
 	"github.com/prometheus/alertmanager/nflog"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/notify/email"
+	"github.com/prometheus/alertmanager/notify/msteams"
 	"github.com/prometheus/alertmanager/notify/opsgenie"
 	"github.com/prometheus/alertmanager/notify/pagerduty"
 	"github.com/prometheus/alertmanager/notify/pushover"
@@ -129,24 +133,56 @@ const defaultClusterAddr = "0.0.0.0:9094"
 // buildReceiverIntegrations builds a list of integration notifiers off of a
 // receiver config.
 func buildReceiverIntegrations(nc *config.Receiver, tmpl *template.Template, logger log.Logger) ([]notify.Integration, error) {
+	var limiter *notify.RateLimiter
+	if rl := nc.RateLimit; rl != nil {
+		limiter = notify.NewRateLimiter(rl.Limit, time.Duration(rl.Interval), rl.Action == config.RateLimitDrop)
+	}
 	var (
 		errs         types.MultiError
 		integrations []notify.Integration
 		add          = func(name string, i int, rs notify.ResolvedSender, f func(l log.Logger) (notify.Notifier, error)) {
 			n, err := f(log.With(logger, "integration", name))
 			if err != nil {
-				errs.Add(err)
+				errs.Add(&notify.IntegrationError{Integration: name, Index: i, Err: err})
 				return
 			}
-			integrations = append(integrations, notify.NewIntegration(n, rs, name, i))
+			if nc.MaxAlertsPerNotification > 0 {
+				n = notify.LimitAlerts(n, nc.MaxAlertsPerNotification)
+			}
+			in := notify.NewIntegration(n, rs, name, i)
+			in.SetRetryBudget(nc.RetryBudget)
+			if nc.RepeatInterval != nil {
+				in.SetRepeatInterval(time.Duration(*nc.RepeatInterval))
+			}
+			in.SetRateLimiter(limiter)
+			in.SetSendJitter(time.Duration(nc.SendJitter))
+			integrations = append(integrations, in)
 		}
 	)
 
+	if len(nc.TemplateFunctions) > 0 {
+		rt, err := tmpl.Restrict(nc.TemplateFunctions)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTemplateFuncs(reflect.ValueOf(nc), rt); err != nil {
+			return nil, errors.Wrapf(err, "receiver %q", nc.Name)
+		}
+		tmpl = rt
+	}
+
 	for i, c := range nc.WebhookConfigs {
 		add("webhook", i, c, func(l log.Logger) (notify.Notifier, error) { return webhook.New(c, tmpl, l) })
 	}
 	for i, c := range nc.EmailConfigs {
-		add("email", i, c, func(l log.Logger) (notify.Notifier, error) { return email.New(c, tmpl, l), nil })
+		add("email", i, c, func(l log.Logger) (notify.Notifier, error) {
+			// The email notifier only loads its TLS configuration when
+			// connecting, so make sure certificates and keys can be loaded.
+			if _, err := commoncfg.NewTLSConfig(&c.TLSConfig); err != nil {
+				return nil, err
+			}
+			return email.New(c, tmpl, l), nil
+		})
 	}
 	for i, c := range nc.PagerdutyConfigs {
 		add("pagerduty", i, c, func(l log.Logger) (notify.Notifier, error) { return pagerduty.New(c, tmpl, l) })
@@ -169,12 +205,57 @@ func buildReceiverIntegrations(nc *config.Receiver, tmpl *template.Template, log
 	for i, c := range nc.SNSConfigs {
 		add("sns", i, c, func(l log.Logger) (notify.Notifier, error) { return sns.New(c, tmpl, l) })
 	}
+	for i, c := range nc.MSTeamsConfigs {
+		add("msteams", i, c, func(l log.Logger) (notify.Notifier, error) { return msteams.New(c, tmpl, l) })
+	}
 	if errs.Len() > 0 {
-		return nil, &errs
+		return nil, errors.Wrapf(&errs, "receiver %q", nc.Name)
 	}
 	return integrations, nil
 }
 
+// checkTemplateFuncs walks the given receiver configuration and verifies that
+// its templated string fields only call functions allowed by tmpl.
+func checkTemplateFuncs(v reflect.Value, tmpl *template.Template) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkTemplateFuncs(v.Elem(), tmpl)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// Skip unexported fields.
+				continue
+			}
+			if err := checkTemplateFuncs(v.Field(i), tmpl); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkTemplateFuncs(v.Index(i), tmpl); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkTemplateFuncs(iter.Value(), tmpl); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		// Named string types such as secrets are never templated.
+		if v.Type() != reflect.TypeOf("") {
+			return nil
+		}
+		return tmpl.CheckFuncs(v.String())
+	}
+	return nil
+}
+
 func main() {
 	os.Exit(run())
 }
@@ -190,13 +271,26 @@ func run() int {
 		dataDir         = kingpin.Flag("storage.path", "Base path for data storage.").Default("data/").String()
 		retention       = kingpin.Flag("data.retention", "How long to keep data for.").Default("120h").Duration()
 		alertGCInterval = kingpin.Flag("alerts.gc-interval", "Interval between alert GC.").Default("30m").Duration()
+		maxNotifies     = kingpin.Flag("notification.max-concurrency", "Maximum number of notification requests in flight across all receivers. Further notifications wait until others complete. If zero, no limit is applied.").Default("512").Int()
 
 		webConfig      = webflag.AddFlags(kingpin.CommandLine)
 		externalURL    = kingpin.Flag("web.external-url", "The URL under which Alertmanager is externally reachable (for example, if Alertmanager is served via a reverse proxy). Used for generating relative and absolute links back to Alertmanager itself. If the URL has a path portion, it will be used to prefix all HTTP endpoints served by Alertmanager. If omitted, relevant URL components will be derived automatically.").String()
 		routePrefix    = kingpin.Flag("web.route-prefix", "Prefix for the internal routes of web endpoints. Defaults to path of --web.external-url.").String()
 		listenAddress  = kingpin.Flag("web.listen-address", "Address to listen on for the web interface and API.").Default(":9093").String()
 		getConcurrency = kingpin.Flag("web.get-concurrency", "Maximum number of GET requests processed concurrently. If negative or zero, the limit is GOMAXPROC or 8, whichever is larger.").Default("0").Int()
+		heavyReads     = kingpin.Flag("web.heavy-read-concurrency", "Maximum number of APIv1 requests listing or counting alerts, silences or notifications processed concurrently. Requests exceeding the limit are rejected with 503 Service Unavailable. If zero, no limit is applied.").Default("0").Int()
+		partialAlerts  = kingpin.Flag("web.partial-alert-results", "Respond to APIv1 requests listing alerts with the alerts collected so far and a warning if iterating the alert store fails, rather than with an error.").Default("false").Bool()
 		httpTimeout    = kingpin.Flag("web.timeout", "Timeout for HTTP requests. If negative or zero, no timeout is set.").Default("0").Duration()
+		readOnly       = kingpin.Flag("web.read-only", "Reject all API requests that change state, such as adding alerts or managing silences. Queries are served normally.").Default("false").Bool()
+		silenceSkew    = kingpin.Flag("web.silence-start-skew", "Maximum time new silences created through APIv1 may start in the past due to clock skew. Their start time is set to now, while silences starting earlier are rejected. If zero, no limit is applied.").Default("0").Duration()
+		commentRegex   = kingpin.Flag("web.silence-comment-regex", "Regular expression the comments of silences created through APIv1 must match, e.g. to require a ticket reference. The comment may contain other text around the match. If empty, comments aren't checked.").Regexp()
+		staleThreshold = kingpin.Flag("web.alert-stale-threshold", "Time after which alerts listed by APIv1 that weren't updated are marked as stale, which may indicate that their source is down. If zero, alerts are never marked as stale.").Default("0").Duration()
+		expireAll      = kingpin.Flag("web.enable-silence-expire-all", "Enable the APIv1 endpoint expiring all active and pending silences at once. Requests must pass the cluster peer name, or the host name if clustering is disabled, as confirmation.").Default("false").Bool()
+		gracePeriod    = kingpin.Flag("web.startup-grace-period", "Time after startup during which alerts received through APIv1 aren't resolved by the resolve timeout, giving their sources time to re-send them. If zero, no grace period is applied.").Default("0").Duration()
+		renderTmpl     = kingpin.Flag("web.enable-template-rendering", "Enable the APIv1 endpoint rendering arbitrary templates with sample data and the current notification templates.").Default("false").Bool()
+		redactErrors   = kingpin.Flag("web.redact-internal-errors", "Respond to APIv1 requests failing with internal errors with a generic message and the request ID, under which the full error is logged, rather than with the error itself.").Default("false").Bool()
+		gzipMinSize    = kingpin.Flag("web.gzip-min-size", "Size in bytes from which APIv1 responses are compressed with gzip for clients accepting it. If negative, responses are never compressed.").Default("1024").Int()
+		severityLabel  = kingpin.Flag("web.severity-label", "Label by which the current alerts are counted in the status returned by APIv1.").Default("severity").String()
 
 		clusterBindAddr = kingpin.Flag("cluster.listen-address", "Listen address for cluster. Set to empty string to disable HA mode.").
 				Default(defaultClusterAddr).String()
@@ -345,6 +439,9 @@ func run() int {
 	groupFn := func(routeFilter func(*dispatch.Route) bool, alertFilter func(*types.Alert, time.Time) bool) (dispatch.AlertGroups, map[model.Fingerprint][]string) {
 		return disp.Groups(routeFilter, alertFilter)
 	}
+	dispatchStatsFn := func() []dispatch.GroupStats {
+		return disp.Stats()
+	}
 
 	// An interface value that holds a nil concrete value is non-nil.
 	// Therefore we explicly pass an empty interface, to detect if the
@@ -354,16 +451,37 @@ func run() int {
 		clusterPeer = peer
 	}
 
+	pipelineBuilder := notify.NewPipelineBuilder(prometheus.DefaultRegisterer)
+	pipelineBuilder.SetMaxConcurrency(*maxNotifies)
+
 	api, err := api.New(api.Options{
-		Alerts:      alerts,
-		Silences:    silences,
-		StatusFunc:  marker.Status,
-		Peer:        clusterPeer,
-		Timeout:     *httpTimeout,
-		Concurrency: *getConcurrency,
-		Logger:      log.With(logger, "component", "api"),
-		Registry:    prometheus.DefaultRegisterer,
-		GroupFunc:   groupFn,
+		Alerts:                  alerts,
+		Silences:                silences,
+		NotificationLog:         notificationLog,
+		StatusFunc:              marker.Status,
+		Peer:                    clusterPeer,
+		Timeout:                 *httpTimeout,
+		Concurrency:             *getConcurrency,
+		Logger:                  log.With(logger, "component", "api"),
+		Registry:                prometheus.DefaultRegisterer,
+		GroupFunc:               groupFn,
+		ReadOnly:                *readOnly,
+		MaxSilenceStartSkew:     *silenceSkew,
+		AlertStaleThreshold:     *staleThreshold,
+		SeverityLabel:           model.LabelName(*severityLabel),
+		Throttles:               pipelineBuilder.Throttles(),
+		DispatchStatsFunc:       dispatchStatsFn,
+		EnableSilenceExpireAll:  *expireAll,
+		SilenceCommentPattern:   *commentRegex,
+		HeavyReadConcurrency:    *heavyReads,
+		PartialAlerts:           *partialAlerts,
+		StartupGracePeriod:      *gracePeriod,
+		EnableTemplateRendering: *renderTmpl,
+		RedactInternalErrors:    *redactErrors,
+		GzipMinSize:             *gzipMinSize,
+		ReceiverBuilder: func(rc *config.Receiver, tmpl *template.Template) ([]notify.Integration, error) {
+			return buildReceiverIntegrations(rc, tmpl, logger)
+		},
 	})
 
 	if err != nil {
@@ -390,12 +508,13 @@ func run() int {
 	}
 
 	var (
-		inhibitor *inhibit.Inhibitor
-		tmpl      *template.Template
+		inhibitor       *inhibit.Inhibitor
+		tmpl            *template.Template
+		stopMaintenance = func() {}
+		stopExpiry      = func() {}
 	)
 
 	dispMetrics := dispatch.NewDispatcherMetrics(false, prometheus.DefaultRegisterer)
-	pipelineBuilder := notify.NewPipelineBuilder(prometheus.DefaultRegisterer)
 	configLogger := log.With(logger, "component", "configuration")
 	configCoordinator := config.NewCoordinator(
 		*configFile,
@@ -411,27 +530,12 @@ func run() int {
 
 		// Build the routing tree and record which receivers are used.
 		routes := dispatch.NewRoute(conf.Route, nil)
-		activeReceivers := make(map[string]struct{})
-		routes.Walk(func(r *dispatch.Route) {
-			activeReceivers[r.RouteOpts.Receiver] = struct{}{}
-		})
+		activeReceivers := routeReceivers(routes)
 
 		// Build the map of receiver to integrations.
-		receivers := make(map[string][]notify.Integration, len(activeReceivers))
-		var integrationsNum int
-		for _, rcv := range conf.Receivers {
-			if _, found := activeReceivers[rcv.Name]; !found {
-				// No need to build a receiver if no route is using it.
-				level.Info(configLogger).Log("msg", "skipping creation of receiver not referenced by any route", "receiver", rcv.Name)
-				continue
-			}
-			integrations, err := buildReceiverIntegrations(rcv, tmpl, logger)
-			if err != nil {
-				return err
-			}
-			// rcv.Name is guaranteed to be unique across all receivers.
-			receivers[rcv.Name] = integrations
-			integrationsNum += len(integrations)
+		receivers, integrationsNum, err := buildActiveReceivers(conf.Receivers, activeReceivers, tmpl, logger, configLogger)
+		if err != nil {
+			return err
 		}
 
 		// Build the map of time interval names to mute time definitions.
@@ -440,11 +544,64 @@ func run() int {
 			muteTimes[ti.Name] = ti.TimeIntervals
 		}
 
+		// Build the maintenance calendars before stopping anything, so that
+		// invalid calendars leave the running configuration in place.
+		var (
+			calendars          = make([]*maintenance.Calendar, 0, len(conf.MaintenanceCalendars))
+			maintenanceSources = make([]maintenance.Source, 0, len(conf.MaintenanceCalendars))
+		)
+		for _, mc := range conf.MaintenanceCalendars {
+			c, err := maintenance.NewCalendar(mc, log.With(logger, "component", "maintenance"))
+			if err != nil {
+				return errors.Wrap(err, "failed to create maintenance calendar")
+			}
+			calendars = append(calendars, c)
+			maintenanceSources = append(maintenanceSources, c)
+		}
+
+		// The receiver of silence expiry notifications needn't be used by a route.
+		var expiryIntegrations []notify.Integration
+		if sen := conf.SilenceExpiryNotification; sen != nil {
+			expiryIntegrations = receivers[sen.Receiver]
+			if expiryIntegrations == nil {
+				for _, rcv := range conf.Receivers {
+					if rcv.Name != sen.Receiver {
+						continue
+					}
+					if expiryIntegrations, err = buildReceiverIntegrations(rcv, tmpl, logger); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
 		inhibitor.Stop()
 		disp.Stop()
+		stopMaintenance()
+		stopExpiry()
 
 		inhibitor = inhibit.NewInhibitor(alerts, conf.InhibitRules, marker, logger)
-		silencer := silence.NewSilencer(silences, marker, logger)
+		silencer := silence.NewSilencer(silences, alerts, marker, logger)
+
+		maintenanceCtx, cancelMaintenance := context.WithCancel(context.Background())
+		stopMaintenance = cancelMaintenance
+		for _, c := range calendars {
+			go c.Run(maintenanceCtx)
+		}
+
+		expiryCtx, cancelExpiry := context.WithCancel(context.Background())
+		stopExpiry = cancelExpiry
+		if sen := conf.SilenceExpiryNotification; sen != nil {
+			n := notify.NewSilenceExpiryNotifier(
+				silences,
+				sen.Receiver,
+				expiryIntegrations,
+				time.Duration(sen.Before),
+				waitFunc,
+				log.With(logger, "component", "silence_expiry"),
+			)
+			go n.Run(expiryCtx, time.Minute)
+		}
 
 		// An interface value that holds a nil concrete value is non-nil.
 		// Therefore we explicly pass an empty interface, to detect if the
@@ -459,6 +616,7 @@ func run() int {
 			waitFunc,
 			inhibitor,
 			silencer,
+			maintenance.NewMuter(maintenanceSources...),
 			muteTimes,
 			notificationLog,
 			pipelinePeer,
@@ -466,12 +624,16 @@ func run() int {
 		configuredReceivers.Set(float64(len(activeReceivers)))
 		configuredIntegrations.Set(float64(integrationsNum))
 
+		api.UpdateTemplate(tmpl)
 		api.Update(conf, func(labels model.LabelSet) {
 			inhibitor.Mutes(labels)
 			silencer.Mutes(labels)
 		})
 
 		disp = dispatch.NewDispatcher(alerts, routes, pipeline, marker, timeoutFunc, nil, logger, dispMetrics)
+		if conf.DispatchPriority != nil {
+			disp.SetPriorities(dispatch.NewPriorities(conf.DispatchPriority))
+		}
 		routes.Walk(func(r *dispatch.Route) {
 			if r.RouteOpts.RepeatInterval > *retention {
 				level.Warn(configLogger).Log(
@@ -487,6 +649,21 @@ func run() int {
 			}
 		})
 
+		for _, rcv := range conf.Receivers {
+			if rcv.RepeatInterval != nil && time.Duration(*rcv.RepeatInterval) > *retention {
+				level.Warn(configLogger).Log(
+					"msg",
+					"repeat_interval is greater than the data retention period. It can lead to notifications being repeated more often than expected.",
+					"repeat_interval",
+					*rcv.RepeatInterval,
+					"retention",
+					*retention,
+					"receiver",
+					rcv.Name,
+				)
+			}
+		}
+
 		go disp.Run()
 		go inhibitor.Run()
 
@@ -514,7 +691,7 @@ func run() int {
 
 	webReload := make(chan chan error)
 
-	ui.Register(router, webReload, logger)
+	ui.Register(router, webReload, api.Ready, logger)
 
 	mux := api.Register(router, *routePrefix)
 
@@ -569,6 +746,41 @@ func run() int {
 	}
 }
 
+// routeReceivers returns the receivers notified by the routing tree, either
+// as the receiver of a route or as the receiver of an escalation.
+func routeReceivers(routes *dispatch.Route) map[string]struct{} {
+	receivers := make(map[string]struct{})
+	routes.Walk(func(r *dispatch.Route) {
+		receivers[r.RouteOpts.Receiver] = struct{}{}
+		for _, e := range r.RouteOpts.Escalations {
+			receivers[e.Receiver] = struct{}{}
+		}
+	})
+	return receivers
+}
+
+// buildActiveReceivers builds the integrations of the active receivers. It
+// also returns the total number of integrations.
+func buildActiveReceivers(rcvs []*config.Receiver, active map[string]struct{}, tmpl *template.Template, logger, configLogger log.Logger) (map[string][]notify.Integration, int, error) {
+	receivers := make(map[string][]notify.Integration, len(active))
+	var integrationsNum int
+	for _, rcv := range rcvs {
+		if _, found := active[rcv.Name]; !found {
+			// No need to build a receiver if no route is using it.
+			level.Info(configLogger).Log("msg", "skipping creation of receiver not referenced by any route", "receiver", rcv.Name)
+			continue
+		}
+		integrations, err := buildReceiverIntegrations(rcv, tmpl, logger)
+		if err != nil {
+			return nil, 0, err
+		}
+		// rcv.Name is guaranteed to be unique across all receivers.
+		receivers[rcv.Name] = integrations
+		integrationsNum += len(integrations)
+	}
+	return receivers, integrationsNum, nil
+}
+
 // clusterWait returns a function that inspects the current peer state and returns
 // a duration of one base timeout for each peer with a higher ID than ourselves.
 func clusterWait(p *cluster.Peer, timeout time.Duration) func() time.Duration {
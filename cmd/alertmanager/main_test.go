@@ -18,11 +18,14 @@ import (
 	"testing"
 
 	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
 	commoncfg "github.com/prometheus/common/config"
 	"github.com/stretchr/testify/require"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
 	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
 )
 
 type sendResolved bool
@@ -30,6 +33,9 @@ type sendResolved bool
 func (s sendResolved) SendResolved() bool { return bool(s) }
 
 func TestBuildReceiverIntegrations(t *testing.T) {
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+
 	for _, tc := range []struct {
 		receiver *config.Receiver
 		err      bool
@@ -70,10 +76,47 @@ func TestBuildReceiverIntegrations(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			receiver: &config.Receiver{
+				Name: "foo",
+				SlackConfigs: []*config.SlackConfig{
+					&config.SlackConfig{
+						HTTPConfig: &commoncfg.HTTPClientConfig{},
+						Title:      `{{ .CommonLabels.alertname | toUpper }}`,
+					},
+				},
+				TemplateFunctions: []string{"toUpper"},
+			},
+			exp: []notify.Integration{
+				notify.NewIntegration(nil, sendResolved(false), "slack", 0),
+			},
+		},
+		{
+			receiver: &config.Receiver{
+				Name: "foo",
+				SlackConfigs: []*config.SlackConfig{
+					&config.SlackConfig{
+						HTTPConfig: &commoncfg.HTTPClientConfig{},
+						Fields: []*config.SlackField{
+							&config.SlackField{Title: "foo", Value: `{{ reReplaceAll "a" "b" .CommonLabels.alertname }}`},
+						},
+					},
+				},
+				TemplateFunctions: []string{"toUpper"},
+			},
+			err: true,
+		},
+		{
+			receiver: &config.Receiver{
+				Name:              "foo",
+				TemplateFunctions: []string{"notAFunction"},
+			},
+			err: true,
+		},
 	} {
 		tc := tc
 		t.Run("", func(t *testing.T) {
-			integrations, err := buildReceiverIntegrations(tc.receiver, nil, nil)
+			integrations, err := buildReceiverIntegrations(tc.receiver, tmpl, nil)
 			if tc.err {
 				require.Error(t, err)
 				return
@@ -89,6 +132,115 @@ func TestBuildReceiverIntegrations(t *testing.T) {
 	}
 }
 
+func TestBuildReceiverIntegrationsTLS(t *testing.T) {
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		receiver *config.Receiver
+		err      string
+	}{
+		{
+			name: "webhook with missing client certificate",
+			receiver: &config.Receiver{
+				Name: "foo",
+				WebhookConfigs: []*config.WebhookConfig{
+					{
+						HTTPConfig: &commoncfg.HTTPClientConfig{
+							TLSConfig: commoncfg.TLSConfig{
+								CertFile: "not_existing.crt",
+								KeyFile:  "not_existing.key",
+							},
+						},
+					},
+				},
+			},
+			err: `receiver "foo": webhook/0: unable to use specified client cert (not_existing.crt) & key (not_existing.key)`,
+		},
+		{
+			name: "slack with client certificate but no key",
+			receiver: &config.Receiver{
+				Name: "foo",
+				SlackConfigs: []*config.SlackConfig{
+					{
+						HTTPConfig: &commoncfg.HTTPClientConfig{
+							TLSConfig: commoncfg.TLSConfig{
+								CertFile: "not_existing.crt",
+							},
+						},
+					},
+				},
+			},
+			err: `slack/0: client cert file "not_existing.crt" specified without client key file`,
+		},
+		{
+			name: "email with missing CA",
+			receiver: &config.Receiver{
+				Name: "foo",
+				EmailConfigs: []*config.EmailConfig{
+					{Headers: map[string]string{}},
+					{
+						Headers: map[string]string{},
+						TLSConfig: commoncfg.TLSConfig{
+							CAFile: "not_existing.crt",
+						},
+					},
+				},
+			},
+			err: "email/1: unable to load specified CA cert not_existing.crt",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := buildReceiverIntegrations(tc.receiver, tmpl, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestActiveReceiversIncludeEscalations(t *testing.T) {
+	conf, err := config.Load(`
+route:
+  receiver: team
+  routes:
+  - match:
+      severity: critical
+    receiver: team
+    escalations:
+    - after: 30m
+      receiver: oncall
+receivers:
+- name: team
+  webhook_configs:
+  - url: http://team.example.com/
+- name: oncall
+  webhook_configs:
+  - url: http://oncall.example.com/
+- name: unused
+  webhook_configs:
+  - url: http://unused.example.com/
+`)
+	require.NoError(t, err)
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+
+	active := routeReceivers(dispatch.NewRoute(conf.Route, nil))
+	require.Equal(t, map[string]struct{}{"team": {}, "oncall": {}}, active)
+
+	receivers, n, err := buildActiveReceivers(conf.Receivers, active, tmpl, log.NewNopLogger(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Len(t, receivers["oncall"], 1)
+
+	// The pipeline has a stage for the escalation receiver, so escalated
+	// notifications aren't dropped for a missing stage.
+	pipeline := notify.NewPipelineBuilder(prometheus.NewRegistry()).New(receivers, nil, nil, nil, nil, nil, nil, nil)
+	require.Contains(t, pipeline, "oncall")
+	require.NotContains(t, pipeline, "unused")
+}
+
 func TestExternalURL(t *testing.T) {
 	hostname := "foo"
 	for _, tc := range []struct {
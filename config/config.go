@@ -20,6 +20,7 @@ import (
 	"net"
 	"net/url"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/relabel"
 	"github.com/prometheus/alertmanager/timeinterval"
 )
 
@@ -245,6 +247,9 @@ func resolveFilepaths(baseDir string, cfg *Config) {
 		for _, cfg := range receiver.SNSConfigs {
 			cfg.HTTPConfig.SetDirectory(baseDir)
 		}
+		for _, cfg := range receiver.MSTeamsConfigs {
+			cfg.HTTPConfig.SetDirectory(baseDir)
+		}
 	}
 }
 
@@ -266,6 +271,179 @@ func (mt *MuteTimeInterval) UnmarshalYAML(unmarshal func(interface{}) error) err
 	return nil
 }
 
+// DefaultMaintenanceCalendar provides default values for maintenance calendars.
+var DefaultMaintenanceCalendar = MaintenanceCalendar{
+	RefreshInterval: model.Duration(5 * time.Minute),
+}
+
+// MaintenanceCalendar configures an iCalendar feed of planned maintenance
+// windows during which matching alerts are suppressed.
+type MaintenanceCalendar struct {
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+
+	// URL from which the iCalendar feed is fetched.
+	URL *URL `yaml:"url" json:"url"`
+	// RefreshInterval is the interval at which the feed is fetched.
+	RefreshInterval model.Duration `yaml:"refresh_interval,omitempty" json:"refresh_interval,omitempty"`
+	// Matchers select the alerts that are suppressed during the events of
+	// the feed. If empty, all alerts are suppressed.
+	Matchers Matchers `yaml:"matchers,omitempty" json:"matchers,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for MaintenanceCalendar.
+func (mc *MaintenanceCalendar) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*mc = DefaultMaintenanceCalendar
+	type plain MaintenanceCalendar
+	if err := unmarshal((*plain)(mc)); err != nil {
+		return err
+	}
+	if mc.URL == nil {
+		return fmt.Errorf("missing URL in maintenance calendar")
+	}
+	if mc.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval of maintenance calendar must be positive")
+	}
+	return nil
+}
+
+// SilencePreset is a template for silences. Silences created through the API
+// may reference a preset by name to prefill the fields they omit.
+type SilencePreset struct {
+	Name string `yaml:"name" json:"name"`
+	// Matchers are added to the matchers of the silence, unless the silence
+	// has a matcher for the same label name.
+	Matchers Matchers `yaml:"matchers,omitempty" json:"matchers,omitempty"`
+	// Comment is the default comment of the silence.
+	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
+	// Duration is the default duration of the silence.
+	Duration model.Duration `yaml:"duration,omitempty" json:"duration,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for SilencePreset.
+func (sp *SilencePreset) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain SilencePreset
+	if err := unmarshal((*plain)(sp)); err != nil {
+		return err
+	}
+	if sp.Name == "" {
+		return fmt.Errorf("missing name in silence preset")
+	}
+	return nil
+}
+
+// DispatchPriority orders the notifications of aggregation groups by the
+// value of a label of their alerts when the number of concurrent
+// notifications is limited.
+type DispatchPriority struct {
+	// Label is the label of the alerts determining their priority.
+	Label model.LabelName `yaml:"label" json:"label"`
+	// Values are the values of the label in descending order of priority.
+	// Other values have the lowest priority.
+	Values []model.LabelValue `yaml:"values" json:"values"`
+	// MaxConcurrentNotifications is the maximum number of aggregation
+	// groups notifying at the same time.
+	MaxConcurrentNotifications int `yaml:"max_concurrent_notifications" json:"max_concurrent_notifications"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for DispatchPriority.
+func (dp *DispatchPriority) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain DispatchPriority
+	if err := unmarshal((*plain)(dp)); err != nil {
+		return err
+	}
+	if dp.Label == "" {
+		return fmt.Errorf("missing label in dispatch priority")
+	}
+	if len(dp.Values) == 0 {
+		return fmt.Errorf("missing values in dispatch priority")
+	}
+	seen := map[model.LabelValue]struct{}{}
+	for _, v := range dp.Values {
+		if _, ok := seen[v]; ok {
+			return fmt.Errorf("value %q of dispatch priority is not unique", v)
+		}
+		seen[v] = struct{}{}
+	}
+	if dp.MaxConcurrentNotifications <= 0 {
+		return fmt.Errorf("max_concurrent_notifications of dispatch priority must be positive")
+	}
+	return nil
+}
+
+// SilenceExpiryNotification configures the notifications sent about silences
+// that are about to expire.
+type SilenceExpiryNotification struct {
+	// Receiver is the receiver the notifications are sent to.
+	Receiver string `yaml:"receiver" json:"receiver"`
+	// Before is how long before their end silences are notified about.
+	Before model.Duration `yaml:"before,omitempty" json:"before,omitempty"`
+}
+
+// DefaultSilenceExpiryNotification provides the default values of a
+// silence expiry notification.
+var DefaultSilenceExpiryNotification = SilenceExpiryNotification{
+	Before: model.Duration(time.Hour),
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for SilenceExpiryNotification.
+func (n *SilenceExpiryNotification) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*n = DefaultSilenceExpiryNotification
+	type plain SilenceExpiryNotification
+	if err := unmarshal((*plain)(n)); err != nil {
+		return err
+	}
+	if n.Receiver == "" {
+		return fmt.Errorf("missing receiver in silence expiry notification")
+	}
+	if n.Before <= 0 {
+		return fmt.Errorf("before of silence expiry notification must be positive")
+	}
+	return nil
+}
+
+// IngestionFilter drops alerts received through the API by their labels.
+type IngestionFilter struct {
+	// Allow are the sets of matchers of which an alert must match all
+	// matchers of at least one set to be accepted. If empty, alerts are
+	// accepted unless denied.
+	Allow []Matchers `yaml:"allow,omitempty" json:"allow,omitempty"`
+	// Deny are the sets of matchers of which an alert matching all matchers
+	// of any set is dropped.
+	Deny []Matchers `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for IngestionFilter.
+func (f *IngestionFilter) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain IngestionFilter
+	if err := unmarshal((*plain)(f)); err != nil {
+		return err
+	}
+	for _, ms := range append(f.Allow, f.Deny...) {
+		if len(ms) == 0 {
+			return fmt.Errorf("empty set of matchers in ingestion filter")
+		}
+	}
+	return nil
+}
+
+// Accepts returns true if alerts with the given labels pass the filter.
+func (f *IngestionFilter) Accepts(lset model.LabelSet) bool {
+	for _, ms := range f.Deny {
+		if labels.Matchers(ms).Matches(lset) {
+			return false
+		}
+	}
+	if len(f.Allow) == 0 {
+		return true
+	}
+	for _, ms := range f.Allow {
+		if labels.Matchers(ms).Matches(lset) {
+			return true
+		}
+	}
+	return false
+}
+
 // Config is the top-level configuration for Alertmanager's config files.
 type Config struct {
 	Global            *GlobalConfig      `yaml:"global,omitempty" json:"global,omitempty"`
@@ -274,6 +452,23 @@ type Config struct {
 	Receivers         []*Receiver        `yaml:"receivers,omitempty" json:"receivers,omitempty"`
 	Templates         []string           `yaml:"templates" json:"templates"`
 	MuteTimeIntervals []MuteTimeInterval `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty"`
+	// AlertRelabelConfigs are applied to the labels of every alert received
+	// through the API before it is validated and fingerprinted.
+	AlertRelabelConfigs []*relabel.Config `yaml:"alert_relabel_configs,omitempty" json:"alert_relabel_configs,omitempty"`
+	// MaintenanceCalendars are consulted to suppress alerts during planned
+	// maintenance windows.
+	MaintenanceCalendars []*MaintenanceCalendar `yaml:"maintenance_calendars,omitempty" json:"maintenance_calendars,omitempty"`
+	// SilencePresets are templates for silences created through the API.
+	SilencePresets []*SilencePreset `yaml:"silence_presets,omitempty" json:"silence_presets,omitempty"`
+	// DispatchPriority orders the notifications of aggregation groups. If
+	// nil, groups notify as soon as they are due.
+	DispatchPriority *DispatchPriority `yaml:"dispatch_priority,omitempty" json:"dispatch_priority,omitempty"`
+	// SilenceExpiryNotification configures notifications about silences
+	// that are about to expire. Only silences asking for it are notified.
+	SilenceExpiryNotification *SilenceExpiryNotification `yaml:"silence_expiry_notification,omitempty" json:"silence_expiry_notification,omitempty"`
+	// IngestionFilter drops alerts received through the API after they
+	// were relabeled. If nil, all alerts are accepted.
+	IngestionFilter *IngestionFilter `yaml:"ingestion_filter,omitempty" json:"ingestion_filter,omitempty"`
 
 	// original is the input from which the config was parsed.
 	original string
@@ -287,6 +482,178 @@ func (c Config) String() string {
 	return string(b)
 }
 
+// notifierDefaults are the default values of the notifier configurations by
+// their key in receivers.
+var notifierDefaults = map[string]interface{}{
+	"email_configs":     DefaultEmailConfig,
+	"pagerduty_configs": DefaultPagerdutyConfig,
+	"slack_configs":     DefaultSlackConfig,
+	"webhook_configs":   DefaultWebhookConfig,
+	"opsgenie_configs":  DefaultOpsGenieConfig,
+	"wechat_configs":    DefaultWechatConfig,
+	"pushover_configs":  DefaultPushoverConfig,
+	"victorops_configs": DefaultVictorOpsConfig,
+	"sns_configs":       DefaultSNSConfig,
+	"msteams_configs":   DefaultMSTeamsConfig,
+}
+
+// NonDefaultString returns the configuration in YAML like String, but omits
+// the fields equal to their default values. The HTTP client configuration of
+// notifiers defaults to the global one, while other values inherited from the
+// global configuration are included.
+func (c Config) NonDefaultString() (string, error) {
+	cfg, err := toMapSlice(c)
+	if err != nil {
+		return "", err
+	}
+	global := DefaultGlobalConfig()
+	defaults, err := toMapSlice(Config{Global: &global})
+	if err != nil {
+		return "", err
+	}
+
+	var httpConfig interface{}
+	if c.Global != nil && c.Global.HTTPConfig != nil {
+		if httpConfig, err = toMapSlice(c.Global.HTTPConfig); err != nil {
+			return "", err
+		}
+	}
+	notifiers := make(map[string]yaml.MapSlice, len(notifierDefaults))
+	for key, d := range notifierDefaults {
+		ms, err := toMapSlice(d)
+		if err != nil {
+			return "", err
+		}
+		if httpConfig != nil {
+			ms = setMapItem(ms, "http_config", httpConfig)
+		}
+		notifiers[key] = ms
+	}
+	details, err := toMapSlice(DefaultPagerdutyDetails)
+	if err != nil {
+		return "", err
+	}
+	notifiers["pagerduty_configs"] = setMapItem(notifiers["pagerduty_configs"], "details", details)
+	routeDefaults, err := toMapSlice(Route{})
+	if err != nil {
+		return "", err
+	}
+
+	cfg = pruneDefaults(cfg, defaults)
+	for i, item := range cfg {
+		switch item.Key {
+		case "route":
+			if r, ok := item.Value.(yaml.MapSlice); ok {
+				cfg[i].Value = pruneRouteDefaults(r, routeDefaults)
+			}
+			continue
+		case "receivers":
+		default:
+			continue
+		}
+		receivers, _ := item.Value.([]interface{})
+		for _, r := range receivers {
+			rcv, _ := r.(yaml.MapSlice)
+			for _, ritem := range rcv {
+				d, ok := notifiers[fmt.Sprint(ritem.Key)]
+				if !ok {
+					continue
+				}
+				ncs, _ := ritem.Value.([]interface{})
+				for i, nc := range ncs {
+					if ms, ok := nc.(yaml.MapSlice); ok {
+						ncs[i] = pruneDefaults(ms, d)
+					}
+				}
+			}
+		}
+	}
+
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// pruneRouteDefaults prunes the defaults of the route and its child routes.
+func pruneRouteDefaults(r, defaults yaml.MapSlice) yaml.MapSlice {
+	r = pruneDefaults(r, defaults)
+	for _, item := range r {
+		if item.Key != "routes" {
+			continue
+		}
+		routes, _ := item.Value.([]interface{})
+		for i, cr := range routes {
+			if ms, ok := cr.(yaml.MapSlice); ok {
+				routes[i] = pruneRouteDefaults(ms, defaults)
+			}
+		}
+	}
+	return r
+}
+
+// toMapSlice converts v to its generic YAML representation.
+func toMapSlice(v interface{}) (yaml.MapSlice, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(b, &ms); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// setMapItem sets the value of the key in the map.
+func setMapItem(ms yaml.MapSlice, key string, v interface{}) yaml.MapSlice {
+	for i := range ms {
+		if ms[i].Key == key {
+			ms[i].Value = v
+			return ms
+		}
+	}
+	return append(ms, yaml.MapItem{Key: key, Value: v})
+}
+
+// pruneDefaults returns the items of the map whose values differ from those
+// in the defaults. Maps are compared recursively and left out if all of their
+// items equal the defaults.
+func pruneDefaults(ms, defaults yaml.MapSlice) yaml.MapSlice {
+	res := yaml.MapSlice{}
+	for _, item := range ms {
+		var (
+			d     interface{}
+			found bool
+		)
+		for _, ditem := range defaults {
+			if ditem.Key == item.Key {
+				d, found = ditem.Value, true
+				break
+			}
+		}
+		if !found {
+			res = append(res, item)
+			continue
+		}
+		if reflect.DeepEqual(item.Value, d) {
+			continue
+		}
+		if v, ok := item.Value.(yaml.MapSlice); ok {
+			if dv, ok := d.(yaml.MapSlice); ok {
+				v = pruneDefaults(v, dv)
+				if len(v) == 0 {
+					continue
+				}
+				item.Value = v
+			}
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Config.
 func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// We want to set c to the defaults and then overwrite it with the input.
@@ -312,6 +679,52 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("at most one of opsgenie_api_key & opsgenie_api_key_file must be configured")
 	}
 
+	if c.Global.OpsGeniePriorityMapping != nil {
+		if err := c.Global.OpsGeniePriorityMapping.check(opsgeniePriorities); err != nil {
+			return fmt.Errorf("invalid opsgenie_priority_mapping: %w", err)
+		}
+	}
+	if c.Global.PagerdutySeverityMapping != nil {
+		if err := c.Global.PagerdutySeverityMapping.check(pagerdutySeverities); err != nil {
+			return fmt.Errorf("invalid pagerduty_severity_mapping: %w", err)
+		}
+	}
+
+	if c.Global.SendResolved != nil {
+		// Whether an integration sets send_resolved is only known from the
+		// input, as the integrations default it when unmarshaling.
+		var raw map[string]interface{}
+		if err := unmarshal(&raw); err != nil {
+			return err
+		}
+		rawReceivers, _ := raw["receivers"].([]interface{})
+		for i, rcv := range c.Receivers {
+			var rawReceiver interface{}
+			if i < len(rawReceivers) {
+				rawReceiver = rawReceivers[i]
+			}
+			for _, ic := range rcv.Integrations() {
+				if !sendResolvedSet(rawReceiver, ic.Name+"_configs", ic.Index) {
+					ic.NotifierConfig.VSendResolved = *c.Global.SendResolved
+				}
+			}
+		}
+	}
+
+	for _, mc := range c.MaintenanceCalendars {
+		if mc.HTTPConfig == nil {
+			mc.HTTPConfig = c.Global.HTTPConfig
+		}
+	}
+
+	presets := map[string]struct{}{}
+	for _, sp := range c.SilencePresets {
+		if _, ok := presets[sp.Name]; ok {
+			return fmt.Errorf("silence preset %q is not unique", sp.Name)
+		}
+		presets[sp.Name] = struct{}{}
+	}
+
 	names := map[string]struct{}{}
 
 	for _, rcv := range c.Receivers {
@@ -322,6 +735,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if wh.HTTPConfig == nil {
 				wh.HTTPConfig = c.Global.HTTPConfig
 			}
+			if wh.Metadata == nil {
+				wh.Metadata = c.Global.WebhookMetadata
+			}
 		}
 		for _, ec := range rcv.EmailConfigs {
 			if ec.Smarthost.String() == "" {
@@ -355,6 +771,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 				ec.RequireTLS = new(bool)
 				*ec.RequireTLS = c.Global.SMTPRequireTLS
 			}
+			if err := ec.checkTLS(); err != nil {
+				return fmt.Errorf("email config of receiver %q: %w", rcv.Name, err)
+			}
 		}
 		for _, sc := range rcv.SlackConfigs {
 			if sc.HTTPConfig == nil {
@@ -383,6 +802,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 				}
 				pdc.URL = c.Global.PagerdutyURL
 			}
+			if pdc.SeverityMapping == nil {
+				pdc.SeverityMapping = c.Global.PagerdutySeverityMapping
+			}
 		}
 		for _, ogc := range rcv.OpsGenieConfigs {
 			if ogc.HTTPConfig == nil {
@@ -397,6 +819,9 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 			if !strings.HasSuffix(ogc.APIURL.Path, "/") {
 				ogc.APIURL.Path += "/"
 			}
+			if ogc.PriorityMapping == nil {
+				ogc.PriorityMapping = c.Global.OpsGeniePriorityMapping
+			}
 			if ogc.APIKey == "" && len(ogc.APIKeyFile) == 0 {
 				if c.Global.OpsGenieAPIKey == "" && len(c.Global.OpsGenieAPIKeyFile) == 0 {
 					return fmt.Errorf("no global OpsGenie API Key set either inline or in a file")
@@ -460,6 +885,11 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 				sns.HTTPConfig = c.Global.HTTPConfig
 			}
 		}
+		for _, msc := range rcv.MSTeamsConfigs {
+			if msc.HTTPConfig == nil {
+				msc.HTTPConfig = c.Global.HTTPConfig
+			}
+		}
 		names[rcv.Name] = struct{}{}
 	}
 
@@ -482,6 +912,20 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := checkReceiver(c.Route, names); err != nil {
 		return err
 	}
+	jitters := map[string]model.Duration{}
+	for _, rcv := range c.Receivers {
+		if rcv.SendJitter > 0 {
+			jitters[rcv.Name] = rcv.SendJitter
+		}
+	}
+	if err := checkSendJitter(c.Route, "", defaultGroupInterval, jitters); err != nil {
+		return err
+	}
+	if sen := c.SilenceExpiryNotification; sen != nil {
+		if _, ok := names[sen.Receiver]; !ok {
+			return fmt.Errorf("undefined receiver %q used in silence_expiry_notification", sen.Receiver)
+		}
+	}
 
 	tiNames := make(map[string]struct{})
 	for _, mt := range c.MuteTimeIntervals {
@@ -501,6 +945,11 @@ func checkReceiver(r *Route, receivers map[string]struct{}) error {
 			return err
 		}
 	}
+	for _, e := range r.Escalations {
+		if _, ok := receivers[e.Receiver]; !ok {
+			return fmt.Errorf("undefined receiver %q used in escalation", e.Receiver)
+		}
+	}
 	if r.Receiver == "" {
 		return nil
 	}
@@ -510,6 +959,31 @@ func checkReceiver(r *Route, receivers map[string]struct{}) error {
 	return nil
 }
 
+// defaultGroupInterval is the group interval of routes which neither set one
+// nor inherit one.
+const defaultGroupInterval = model.Duration(5 * time.Minute)
+
+// checkSendJitter returns an error if the send jitter of a receiver isn't
+// shorter than the group interval of a route using it, which bounds the time
+// available to notify.
+func checkSendJitter(r *Route, receiver string, groupInterval model.Duration, jitters map[string]model.Duration) error {
+	if r.Receiver != "" {
+		receiver = r.Receiver
+	}
+	if r.GroupInterval != nil {
+		groupInterval = *r.GroupInterval
+	}
+	if j, ok := jitters[receiver]; ok && j >= groupInterval {
+		return fmt.Errorf("send_jitter %s of receiver %q must be shorter than the group_interval %s of the routes using it", j, receiver, groupInterval)
+	}
+	for _, sr := range r.Routes {
+		if err := checkSendJitter(sr, receiver, groupInterval, jitters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func checkTimeInterval(r *Route, timeIntervals map[string]struct{}) error {
 	for _, sr := range r.Routes {
 		if err := checkTimeInterval(sr, timeIntervals); err != nil {
@@ -638,6 +1112,24 @@ type GlobalConfig struct {
 	// ResolveTimeout is the time after which an alert is declared resolved
 	// if it has not been updated.
 	ResolveTimeout model.Duration `yaml:"resolve_timeout" json:"resolve_timeout"`
+	// NoResolveTimeoutMatchers selects alerts to which ResolveTimeout doesn't
+	// apply. Such alerts stay firing until they are explicitly resolved.
+	NoResolveTimeoutMatchers Matchers `yaml:"no_resolve_timeout_matchers,omitempty" json:"no_resolve_timeout_matchers,omitempty"`
+	// AlertDedupWindow is the time during which updates of an alert with
+	// unchanged labels and annotations are dropped on receipt. The zero value
+	// disables the deduplication.
+	AlertDedupWindow model.Duration `yaml:"alert_dedup_window,omitempty" json:"alert_dedup_window,omitempty"`
+	// AnnotationMergeStrategy defines how the annotations of received alerts
+	// are combined with those of the firing alert with the same labels. The
+	// empty value keeps the received annotations.
+	AnnotationMergeStrategy string `yaml:"annotation_merge_strategy,omitempty" json:"annotation_merge_strategy,omitempty"`
+	// MaxSilenceDuration is the maximum time between the start and the end of
+	// silences created or updated through the API. The zero value means no
+	// limit.
+	MaxSilenceDuration model.Duration `yaml:"max_silence_duration,omitempty" json:"max_silence_duration,omitempty"`
+	// SendResolved is the default of send_resolved for the integrations not
+	// setting it explicitly. If nil, the default of each integration applies.
+	SendResolved *bool `yaml:"send_resolved,omitempty" json:"send_resolved,omitempty"`
 
 	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
 
@@ -660,13 +1152,72 @@ type GlobalConfig struct {
 	WeChatAPICorpID    string     `yaml:"wechat_api_corp_id,omitempty" json:"wechat_api_corp_id,omitempty"`
 	VictorOpsAPIURL    *URL       `yaml:"victorops_api_url,omitempty" json:"victorops_api_url,omitempty"`
 	VictorOpsAPIKey    Secret     `yaml:"victorops_api_key,omitempty" json:"victorops_api_key,omitempty"`
+
+	// AnnotationMaxLength is the maximum length in characters of the
+	// annotation values of received alerts. Longer values are truncated.
+	// The zero value means no limit.
+	AnnotationMaxLength int `yaml:"annotation_max_length,omitempty" json:"annotation_max_length,omitempty"`
+	// AnnotationMaxLengths overrides AnnotationMaxLength for the annotations
+	// with the given names.
+	AnnotationMaxLengths map[string]int `yaml:"annotation_max_lengths,omitempty" json:"annotation_max_lengths,omitempty"`
+
+	// WebhookMetadata is the default metadata of webhook messages.
+	WebhookMetadata *WebhookMetadata `yaml:"webhook_metadata,omitempty" json:"webhook_metadata,omitempty"`
+
+	// OpsGeniePriorityMapping and PagerdutySeverityMapping are the default
+	// mappings of the severity of alerts to the priority of OpsGenie alerts
+	// and the severity of PagerDuty events.
+	OpsGeniePriorityMapping  *PriorityMapping `yaml:"opsgenie_priority_mapping,omitempty" json:"opsgenie_priority_mapping,omitempty"`
+	PagerdutySeverityMapping *PriorityMapping `yaml:"pagerduty_severity_mapping,omitempty" json:"pagerduty_severity_mapping,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for GlobalConfig.
 func (c *GlobalConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	*c = DefaultGlobalConfig()
 	type plain GlobalConfig
-	return unmarshal((*plain)(c))
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.AnnotationMaxLength < 0 {
+		return fmt.Errorf("annotation_max_length must not be negative")
+	}
+	for name, l := range c.AnnotationMaxLengths {
+		if l < 0 {
+			return fmt.Errorf("annotation_max_lengths for %q must not be negative", name)
+		}
+	}
+	switch c.AnnotationMergeStrategy {
+	case "", AnnotationMergeLastWins, AnnotationMergeUnion, AnnotationMergeReject:
+	default:
+		return fmt.Errorf("unknown annotation_merge_strategy %q", c.AnnotationMergeStrategy)
+	}
+	return nil
+}
+
+const (
+	// AnnotationMergeLastWins keeps the annotations of the received alert.
+	AnnotationMergeLastWins = "last_wins"
+	// AnnotationMergeUnion adds the annotations of the firing alert that
+	// are missing from the received alert.
+	AnnotationMergeUnion = "union"
+	// AnnotationMergeReject rejects received alerts with annotations whose
+	// values differ from those of the firing alert.
+	AnnotationMergeReject = "reject"
+)
+
+// AnnotationLimit returns the maximum length of values of the annotation with
+// the given name. Zero means no limit.
+func (c *GlobalConfig) AnnotationLimit(name string) int {
+	if l, ok := c.AnnotationMaxLengths[name]; ok {
+		return l
+	}
+	return c.AnnotationMaxLength
+}
+
+// HasResolveTimeout returns true if the resolve timeout applies to alerts with
+// the given labels.
+func (c *GlobalConfig) HasResolveTimeout(lset model.LabelSet) bool {
+	return len(c.NoResolveTimeoutMatchers) == 0 || !labels.Matchers(c.NoResolveTimeoutMatchers).Matches(lset)
 }
 
 // A Route is a node that contains definitions of how to handle alerts.
@@ -688,6 +1239,8 @@ type Route struct {
 	GroupWait      *model.Duration `yaml:"group_wait,omitempty" json:"group_wait,omitempty"`
 	GroupInterval  *model.Duration `yaml:"group_interval,omitempty" json:"group_interval,omitempty"`
 	RepeatInterval *model.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+
+	Escalations []*Escalation `yaml:"escalations,omitempty" json:"escalations,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Route.
@@ -738,6 +1291,28 @@ func (r *Route) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// Escalation additionally notifies a receiver about the alerts of a route
+// that have been firing for longer than a given duration.
+type Escalation struct {
+	After    model.Duration `yaml:"after" json:"after"`
+	Receiver string         `yaml:"receiver" json:"receiver"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for Escalation.
+func (e *Escalation) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Escalation
+	if err := unmarshal((*plain)(e)); err != nil {
+		return err
+	}
+	if e.After <= 0 {
+		return fmt.Errorf("after of escalation must be positive")
+	}
+	if e.Receiver == "" {
+		return fmt.Errorf("missing receiver in escalation")
+	}
+	return nil
+}
+
 // InhibitRule defines an inhibition rule that mutes alerts that match the
 // target labels if an alert matching the source labels exists.
 // Both alerts have to have a set of labels being equal.
@@ -761,6 +1336,10 @@ type InhibitRule struct {
 	// A set of labels that must be equal between the source and target alert
 	// for them to be a match.
 	Equal model.LabelNames `yaml:"equal,omitempty" json:"equal,omitempty"`
+	// EqualRegex defines labels whose values must be equal between the source
+	// and target alert after extracting the capture groups of the regular
+	// expression, or the whole value if it has none.
+	EqualRegex MatchRegexps `yaml:"equal_regex,omitempty" json:"equal_regex,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for InhibitRule.
@@ -782,6 +1361,12 @@ func (r *InhibitRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
+	for _, ln := range r.Equal {
+		if _, ok := r.EqualRegex[string(ln)]; ok {
+			return fmt.Errorf("label %q must not be in both equal and equal_regex", ln)
+		}
+	}
+
 	return nil
 }
 
@@ -799,6 +1384,132 @@ type Receiver struct {
 	PushoverConfigs  []*PushoverConfig  `yaml:"pushover_configs,omitempty" json:"pushover_configs,omitempty"`
 	VictorOpsConfigs []*VictorOpsConfig `yaml:"victorops_configs,omitempty" json:"victorops_configs,omitempty"`
 	SNSConfigs       []*SNSConfig       `yaml:"sns_configs,omitempty" json:"sns_configs,omitempty"`
+	MSTeamsConfigs   []*MSTeamsConfig   `yaml:"msteams_configs,omitempty" json:"msteams_configs,omitempty"`
+
+	// TemplateFunctions restricts the template functions which the
+	// notification templates of this receiver may call. If empty, all
+	// functions are allowed.
+	TemplateFunctions []string `yaml:"template_functions,omitempty" json:"template_functions,omitempty"`
+
+	// MaxAlertsPerNotification limits the number of alerts sent in a single
+	// notification. Alerts exceeding the limit are replaced by a summary
+	// alert. If zero, notifications aren't limited.
+	MaxAlertsPerNotification int `yaml:"max_alerts_per_notification,omitempty" json:"max_alerts_per_notification,omitempty"`
+
+	// RetryBudget is the maximum number of attempts per notification of each
+	// integration, after which a failed notification is dropped and logged
+	// as a dead letter. If zero, notifications are retried until they time
+	// out.
+	RetryBudget int `yaml:"retry_budget,omitempty" json:"retry_budget,omitempty"`
+
+	// RepeatInterval overrides the repeat interval of the routes for the
+	// integrations of this receiver. If nil, the interval of the route
+	// applies.
+	RepeatInterval *model.Duration `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty"`
+
+	// RateLimit limits the notification attempts of all integrations of
+	// this receiver together. If nil, notifications aren't limited.
+	RateLimit *RateLimit `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+
+	// SendJitter is the maximum random delay before each notification of
+	// the integrations of this receiver, which spreads the notifications of
+	// groups flushed at the same time. It must be shorter than the group
+	// interval of the routes using the receiver. If zero, notifications
+	// aren't delayed.
+	SendJitter model.Duration `yaml:"send_jitter,omitempty" json:"send_jitter,omitempty"`
+}
+
+// Actions for notifications exceeding a rate limit.
+const (
+	RateLimitDelay = "delay"
+	RateLimitDrop  = "drop"
+)
+
+// RateLimit is the configuration of a rate limit of notifications.
+type RateLimit struct {
+	// Limit is the number of notification attempts allowed per interval,
+	// which may all be made at once.
+	Limit    int            `yaml:"limit" json:"limit"`
+	Interval model.Duration `yaml:"interval" json:"interval"`
+	// Action is either delay, to wait until the notification may be sent,
+	// or drop. It defaults to delay.
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for RateLimit.
+func (r *RateLimit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*r = RateLimit{Action: RateLimitDelay}
+	type plain RateLimit
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+	if r.Limit <= 0 {
+		return fmt.Errorf("limit of rate limit must be positive")
+	}
+	if r.Interval <= 0 {
+		return fmt.Errorf("interval of rate limit must be positive")
+	}
+	switch r.Action {
+	case RateLimitDelay, RateLimitDrop:
+	default:
+		return fmt.Errorf("unknown action %q of rate limit, must be %s or %s", r.Action, RateLimitDelay, RateLimitDrop)
+	}
+	return nil
+}
+
+// IntegrationConfig is the configuration of an integration of a receiver.
+type IntegrationConfig struct {
+	// Name is the name of the integration, such as "slack".
+	Name string
+	// Index is the position of the integration among those of the same name.
+	Index          int
+	NotifierConfig *NotifierConfig
+}
+
+// Integrations returns the configurations of all integrations of the receiver.
+func (c *Receiver) Integrations() []IntegrationConfig {
+	var (
+		res []IntegrationConfig
+		v   = reflect.ValueOf(c).Elem()
+	)
+	for f := 0; f < v.NumField(); f++ {
+		key := strings.Split(v.Type().Field(f).Tag.Get("yaml"), ",")[0]
+		if !strings.HasSuffix(key, "_configs") {
+			continue
+		}
+		configs := v.Field(f)
+		for i := 0; i < configs.Len(); i++ {
+			if configs.Index(i).IsNil() {
+				continue
+			}
+			nc := configs.Index(i).Elem().FieldByName("NotifierConfig")
+			res = append(res, IntegrationConfig{
+				Name:           strings.TrimSuffix(key, "_configs"),
+				Index:          i,
+				NotifierConfig: nc.Addr().Interface().(*NotifierConfig),
+			})
+		}
+	}
+	return res
+}
+
+// sendResolvedSet returns true if the i-th integration under the given key of
+// the raw receiver input sets send_resolved.
+func sendResolvedSet(rawReceiver interface{}, key string, i int) bool {
+	rcv, ok := rawReceiver.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	configs, ok := rcv[key].([]interface{})
+	if !ok || i >= len(configs) {
+		return false
+	}
+	ic, ok := configs[i].(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = ic["send_resolved"]
+	return ok
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface for Receiver.
@@ -810,6 +1521,15 @@ func (c *Receiver) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if c.Name == "" {
 		return fmt.Errorf("missing name in receiver")
 	}
+	if c.MaxAlertsPerNotification < 0 {
+		return fmt.Errorf("max_alerts_per_notification of receiver %q must not be negative", c.Name)
+	}
+	if c.RetryBudget < 0 {
+		return fmt.Errorf("retry_budget of receiver %q must not be negative", c.Name)
+	}
+	if c.RepeatInterval != nil && *c.RepeatInterval <= 0 {
+		return fmt.Errorf("repeat_interval of receiver %q must be positive", c.Name)
+	}
 	return nil
 }
 
@@ -19,6 +19,7 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -1063,6 +1064,103 @@ func TestInvalidSNSConfig(t *testing.T) {
 	}
 }
 
+func TestMSTeamsConfig(t *testing.T) {
+	conf, err := Load(`
+global:
+  http_config:
+    proxy_url: http://proxy.example.com/
+route:
+  receiver: teams
+receivers:
+- name: teams
+  msteams_configs:
+  - webhook_url: https://example.webhook.office.com/webhookb2/secret
+`)
+	require.NoError(t, err)
+	msc := conf.Receivers[0].MSTeamsConfigs[0]
+	require.Equal(t, conf.Global.HTTPConfig, msc.HTTPConfig)
+	require.Equal(t, DefaultMSTeamsConfig.Title, msc.Title)
+	require.True(t, msc.SendResolved())
+
+	_, err = Load(`
+route:
+  receiver: teams
+receivers:
+- name: teams
+  msteams_configs:
+  - title: no URL
+`)
+	require.EqualError(t, err, "missing webhook URL in Microsoft Teams config")
+}
+
+func TestPriorityMapping(t *testing.T) {
+	conf, err := Load(`
+global:
+  opsgenie_api_key: secret
+  opsgenie_priority_mapping:
+    priorities:
+    - severity: critical
+      priority: P1
+    - severity: warning
+      priority: P3
+    default: P5
+route:
+  receiver: ops
+receivers:
+- name: ops
+  opsgenie_configs:
+  - {}
+  - priority_mapping:
+      label: level
+      default: P4
+  pagerduty_configs:
+  - routing_key: secret
+`)
+	require.NoError(t, err)
+	ogcs := conf.Receivers[0].OpsGenieConfigs
+	require.Equal(t, conf.Global.OpsGeniePriorityMapping, ogcs[0].PriorityMapping)
+	require.Equal(t, model.LabelName("severity"), ogcs[0].PriorityMapping.Label)
+	require.Equal(t, &PriorityMapping{Label: "level", Default: "P4"}, ogcs[1].PriorityMapping)
+	require.Nil(t, conf.Receivers[0].PagerdutyConfigs[0].SeverityMapping)
+
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{
+			in:  "global:\n  opsgenie_priority_mapping:\n    label: severity\n",
+			err: "priority mapping must set priorities or a default",
+		},
+		{
+			in:  "global:\n  opsgenie_priority_mapping:\n    priorities:\n    - severity: critical\n",
+			err: "priorities of priority mapping must set a severity and a priority",
+		},
+		{
+			in:  "global:\n  opsgenie_priority_mapping:\n    priorities:\n    - {severity: critical, priority: P1}\n    - {severity: critical, priority: P2}\n",
+			err: `severity "critical" of priority mapping is not unique`,
+		},
+		{
+			in:  "global:\n  opsgenie_priority_mapping:\n    label: 0bad\n    default: P1\n",
+			err: `"0bad" is not a valid label name`,
+		},
+		{
+			in:  "global:\n  opsgenie_priority_mapping:\n    priorities:\n    - {severity: critical, priority: critical}\n",
+			err: `invalid opsgenie_priority_mapping: priority "critical" of severity "critical" must be one of P1, P2, P3, P4, P5`,
+		},
+		{
+			in:  "global:\n  pagerduty_severity_mapping:\n    default: P1\n",
+			err: `invalid pagerduty_severity_mapping: default priority "P1" must be one of critical, error, warning, info`,
+		},
+		{
+			in:  "receivers:\n- name: pd\n  pagerduty_configs:\n  - routing_key: secret\n    severity_mapping:\n      default: P1\n",
+			err: `invalid severity_mapping in PagerDuty config: default priority "P1" must be one of critical, error, warning, info`,
+		},
+	} {
+		_, err := Load("route:\n  receiver: pd\n" + tc.in)
+		require.EqualError(t, err, tc.err, tc.in)
+	}
+}
+
 func TestUnmarshalHostPort(t *testing.T) {
 	for _, tc := range []struct {
 		in string
@@ -1152,3 +1250,671 @@ func TestNilRegexp(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobalAnnotationMaxLength(t *testing.T) {
+	var c GlobalConfig
+	err := yaml.UnmarshalStrict([]byte(`
+annotation_max_length: 100
+annotation_max_lengths:
+  description: 1000
+  runbook: 0
+`), &c)
+	require.NoError(t, err)
+	require.Equal(t, 100, c.AnnotationLimit("summary"))
+	require.Equal(t, 1000, c.AnnotationLimit("description"))
+	require.Equal(t, 0, c.AnnotationLimit("runbook"))
+
+	err = yaml.UnmarshalStrict([]byte(`annotation_max_length: -1`), &c)
+	require.EqualError(t, err, "annotation_max_length must not be negative")
+
+	err = yaml.UnmarshalStrict([]byte(`
+annotation_max_lengths:
+  description: -1
+`), &c)
+	require.EqualError(t, err, `annotation_max_lengths for "description" must not be negative`)
+}
+
+func TestGlobalAnnotationMergeStrategy(t *testing.T) {
+	for _, strategy := range []string{"last_wins", "union", "reject"} {
+		var c GlobalConfig
+		err := yaml.UnmarshalStrict([]byte("annotation_merge_strategy: "+strategy), &c)
+		require.NoError(t, err)
+		require.Equal(t, strategy, c.AnnotationMergeStrategy)
+	}
+
+	var c GlobalConfig
+	err := yaml.UnmarshalStrict([]byte(`annotation_merge_strategy: first_wins`), &c)
+	require.EqualError(t, err, `unknown annotation_merge_strategy "first_wins"`)
+}
+
+func TestMaintenanceCalendar(t *testing.T) {
+	in := `
+url: 'https://calendar.example.com/maintenance.ics'
+matchers:
+  - env="prod"
+`
+	var mc MaintenanceCalendar
+	require.NoError(t, yaml.UnmarshalStrict([]byte(in), &mc))
+	require.Equal(t, "https://calendar.example.com/maintenance.ics", mc.URL.String())
+	require.Equal(t, model.Duration(5*time.Minute), mc.RefreshInterval)
+	require.Len(t, mc.Matchers, 1)
+
+	err := yaml.UnmarshalStrict([]byte(`refresh_interval: 1m`), &mc)
+	require.EqualError(t, err, "missing URL in maintenance calendar")
+
+	err = yaml.UnmarshalStrict([]byte(`
+url: 'https://calendar.example.com/maintenance.ics'
+refresh_interval: 0s
+`), &mc)
+	require.EqualError(t, err, "refresh_interval of maintenance calendar must be positive")
+}
+
+func TestReceiverMaxAlertsPerNotification(t *testing.T) {
+	var r Receiver
+	err := yaml.UnmarshalStrict([]byte(`
+name: team-X
+max_alerts_per_notification: 10
+`), &r)
+	require.NoError(t, err)
+	require.Equal(t, 10, r.MaxAlertsPerNotification)
+
+	err = yaml.UnmarshalStrict([]byte(`
+name: team-X
+max_alerts_per_notification: -1
+`), &r)
+	require.EqualError(t, err, `max_alerts_per_notification of receiver "team-X" must not be negative`)
+}
+
+func TestReceiverRetryBudget(t *testing.T) {
+	var r Receiver
+	err := yaml.UnmarshalStrict([]byte(`
+name: team-X
+retry_budget: 5
+`), &r)
+	require.NoError(t, err)
+	require.Equal(t, 5, r.RetryBudget)
+
+	err = yaml.UnmarshalStrict([]byte(`
+name: team-X
+retry_budget: -1
+`), &r)
+	require.EqualError(t, err, `retry_budget of receiver "team-X" must not be negative`)
+}
+
+func TestReceiverRepeatInterval(t *testing.T) {
+	var r Receiver
+	err := yaml.UnmarshalStrict([]byte(`
+name: team-X
+repeat_interval: 12h
+`), &r)
+	require.NoError(t, err)
+	require.Equal(t, model.Duration(12*time.Hour), *r.RepeatInterval)
+
+	err = yaml.UnmarshalStrict([]byte(`
+name: team-X
+repeat_interval: 0s
+`), &r)
+	require.EqualError(t, err, `repeat_interval of receiver "team-X" must be positive`)
+}
+
+func TestReceiverRateLimit(t *testing.T) {
+	var r Receiver
+	err := yaml.UnmarshalStrict([]byte(`
+name: team-X
+rate_limit:
+  limit: 10
+  interval: 1m
+`), &r)
+	require.NoError(t, err)
+	require.Equal(t, &RateLimit{Limit: 10, Interval: model.Duration(time.Minute), Action: RateLimitDelay}, r.RateLimit)
+
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{
+			in: `
+limit: 0
+interval: 1m`,
+			err: "limit of rate limit must be positive",
+		},
+		{
+			in: `
+limit: 10`,
+			err: "interval of rate limit must be positive",
+		},
+		{
+			in: `
+limit: 10
+interval: 1m
+action: queue`,
+			err: `unknown action "queue" of rate limit, must be delay or drop`,
+		},
+	} {
+		var rl RateLimit
+		require.EqualError(t, yaml.UnmarshalStrict([]byte(tc.in), &rl), tc.err)
+	}
+}
+
+func TestReceiverSendJitter(t *testing.T) {
+	c, err := Load(`
+route:
+    receiver: team-X
+    routes:
+    - receiver: team-Y
+      group_interval: 1m
+receivers:
+- name: team-X
+  send_jitter: 30s
+- name: team-Y
+  send_jitter: 30s
+`)
+	require.NoError(t, err)
+	require.Equal(t, model.Duration(30*time.Second), c.Receivers[0].SendJitter)
+
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{
+			// The default group interval applies.
+			in: `
+route:
+    receiver: team-X
+receivers:
+- name: team-X
+  send_jitter: 5m
+`,
+			err: `send_jitter 5m of receiver "team-X" must be shorter than the group_interval 5m of the routes using it`,
+		},
+		{
+			// Child routes inherit the receiver and group interval.
+			in: `
+route:
+    receiver: team-X
+    group_interval: 10m
+    routes:
+    - match:
+        severity: critical
+      group_interval: 30s
+receivers:
+- name: team-X
+  send_jitter: 1m
+`,
+			err: `send_jitter 1m of receiver "team-X" must be shorter than the group_interval 30s of the routes using it`,
+		},
+	} {
+		_, err := Load(tc.in)
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestEscalation(t *testing.T) {
+	c, err := Load(`
+route:
+    receiver: team-X
+    escalations:
+    - after: 30m
+      receiver: pager
+
+receivers:
+- name: 'team-X'
+- name: 'pager'
+`)
+	require.NoError(t, err)
+	require.Equal(t, []*Escalation{{After: model.Duration(30 * time.Minute), Receiver: "pager"}}, c.Route.Escalations)
+
+	_, err = Load(`
+route:
+    receiver: team-X
+    escalations:
+    - after: 30m
+      receiver: pager
+
+receivers:
+- name: 'team-X'
+`)
+	require.EqualError(t, err, `undefined receiver "pager" used in escalation`)
+
+	_, err = Load(`
+route:
+    receiver: team-X
+    escalations:
+    - after: 0s
+      receiver: team-X
+
+receivers:
+- name: 'team-X'
+`)
+	require.EqualError(t, err, "after of escalation must be positive")
+}
+
+func TestGlobalNoResolveTimeoutMatchers(t *testing.T) {
+	var c GlobalConfig
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`resolve_timeout: 5m`), &c))
+	require.True(t, c.HasResolveTimeout(model.LabelSet{"source": "nagios"}))
+
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+no_resolve_timeout_matchers:
+- source=~"nagios|zabbix"
+`), &c))
+	require.False(t, c.HasResolveTimeout(model.LabelSet{"source": "nagios"}))
+	require.False(t, c.HasResolveTimeout(model.LabelSet{"source": "zabbix"}))
+	require.True(t, c.HasResolveTimeout(model.LabelSet{"source": "prometheus"}))
+}
+
+func TestEmailTLSContradictions(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		email string
+		err   string
+	}{
+		{
+			name: "tls_config without require_tls",
+			email: `
+    smarthost: smtp.example.com:587
+    require_tls: false
+    tls_config:
+      insecure_skip_verify: true`,
+			err: `email config of receiver "team-X": tls_config has no effect unless require_tls is true or port 465 is used`,
+		},
+		{
+			name: "tls_config with implicit TLS",
+			email: `
+    smarthost: smtp.example.com:465
+    require_tls: false
+    tls_config:
+      insecure_skip_verify: true`,
+		},
+		{
+			name: "password over plaintext",
+			email: `
+    smarthost: smtp.example.com:25
+    require_tls: false
+    auth_username: alertmanager
+    auth_password: secret`,
+			err: `email config of receiver "team-X": auth_password would be sent over an unencrypted connection to "smtp.example.com", require_tls must be true or port 465 must be used`,
+		},
+		{
+			name: "password over plaintext to localhost",
+			email: `
+    smarthost: localhost:25
+    require_tls: false
+    auth_username: alertmanager
+    auth_password: secret`,
+		},
+		{
+			name: "password with require_tls",
+			email: `
+    smarthost: smtp.example.com:587
+    auth_username: alertmanager
+    auth_password: secret`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(`
+global:
+  smtp_from: alertmanager@example.com
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  email_configs:
+  - to: team-X@example.com` + tc.email + "\n")
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestSilencePresets(t *testing.T) {
+	for _, tc := range []struct {
+		presets string
+		err     string
+	}{
+		{
+			presets: `
+- name: maintenance
+  matchers: ['env="prod"']
+  duration: 1h`,
+		},
+		{
+			presets: `
+- matchers: ['env="prod"']`,
+			err: "missing name in silence preset",
+		},
+		{
+			presets: `
+- name: maintenance
+- name: maintenance`,
+			err: `silence preset "maintenance" is not unique`,
+		},
+	} {
+		_, err := Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+silence_presets:` + tc.presets + "\n")
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestDispatchPriority(t *testing.T) {
+	for _, tc := range []struct {
+		priority string
+		err      string
+	}{
+		{
+			priority: `
+  label: severity
+  values: [critical, warning]
+  max_concurrent_notifications: 10`,
+		},
+		{
+			priority: `
+  values: [critical, warning]
+  max_concurrent_notifications: 10`,
+			err: "missing label in dispatch priority",
+		},
+		{
+			priority: `
+  label: severity
+  max_concurrent_notifications: 10`,
+			err: "missing values in dispatch priority",
+		},
+		{
+			priority: `
+  label: severity
+  values: [critical, critical]
+  max_concurrent_notifications: 10`,
+			err: `value "critical" of dispatch priority is not unique`,
+		},
+		{
+			priority: `
+  label: severity
+  values: [critical]`,
+			err: "max_concurrent_notifications of dispatch priority must be positive",
+		},
+	} {
+		_, err := Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+dispatch_priority:` + tc.priority + "\n")
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestSilenceExpiryNotification(t *testing.T) {
+	for _, tc := range []struct {
+		notification string
+		before       model.Duration
+		err          string
+	}{
+		{
+			notification: `
+  receiver: team-X`,
+			before: model.Duration(time.Hour),
+		},
+		{
+			notification: `
+  receiver: team-X
+  before: 30m`,
+			before: model.Duration(30 * time.Minute),
+		},
+		{
+			notification: `
+  before: 30m`,
+			err: "missing receiver in silence expiry notification",
+		},
+		{
+			notification: `
+  receiver: team-X
+  before: 0s`,
+			err: "before of silence expiry notification must be positive",
+		},
+		{
+			notification: `
+  receiver: team-Y`,
+			err: `undefined receiver "team-Y" used in silence_expiry_notification`,
+		},
+	} {
+		c, err := Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+silence_expiry_notification:` + tc.notification + "\n")
+		if tc.err == "" {
+			require.NoError(t, err)
+			require.Equal(t, tc.before, c.SilenceExpiryNotification.Before)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestInhibitRuleEqualRegex(t *testing.T) {
+	for _, tc := range []struct {
+		rule string
+		err  string
+	}{
+		{
+			rule: `
+  equal: [cluster]
+  equal_regex:
+    instance: '([^:]+):.*'`,
+		},
+		{
+			rule: `
+  equal: [instance]
+  equal_regex:
+    instance: '([^:]+):.*'`,
+			err: `label "instance" must not be in both equal and equal_regex`,
+		},
+		{
+			rule: `
+  equal_regex:
+    instance: '(['`,
+			err: "error parsing regexp: missing closing ]: `[)$`",
+		},
+		{
+			rule: `
+  equal_regex:
+    "not-a-label": '.*'`,
+			err: `invalid label name "not-a-label"`,
+		},
+	} {
+		_, err := Load(`
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+inhibit_rules:
+- source_matchers: ['severity="critical"']
+  target_matchers: ['severity="warning"']` + tc.rule + "\n")
+		if tc.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func TestNonDefaultString(t *testing.T) {
+	c, err := Load(`
+global:
+  resolve_timeout: 5m
+  smtp_require_tls: true
+  slack_api_url: 'http://slack.example.com/'
+route:
+  receiver: team-X
+  group_by: [alertname]
+  routes:
+  - receiver: team-X
+    continue: false
+    matchers: ['severity="critical"']
+receivers:
+- name: team-X
+  email_configs:
+  - to: team-X@example.com
+    from: alertmanager@example.com
+    smarthost: smtp.example.com:25
+    send_resolved: false
+  slack_configs:
+  - channel: '#alerts'
+    send_resolved: true
+`)
+	require.NoError(t, err)
+
+	s, err := c.NonDefaultString()
+	require.NoError(t, err)
+	// Values inherited from the global configuration are kept, except for
+	// the HTTP client configuration.
+	require.Equal(t, `global:
+  slack_api_url: <secret>
+route:
+  receiver: team-X
+  group_by:
+  - alertname
+  routes:
+  - receiver: team-X
+    matchers:
+    - severity="critical"
+receivers:
+- name: team-X
+  email_configs:
+  - to: team-X@example.com
+    from: alertmanager@example.com
+    hello: localhost
+    smarthost: smtp.example.com:25
+    require_tls: true
+  slack_configs:
+  - send_resolved: true
+    api_url: <secret>
+    channel: '#alerts'
+`, s)
+}
+
+func TestGlobalSendResolved(t *testing.T) {
+	for _, tc := range []struct {
+		global string
+		want   map[string]bool
+	}{
+		{
+			// The defaults of the integrations apply.
+			global: "",
+			want: map[string]bool{
+				"webhook[0]":  true,
+				"webhook[1]":  false,
+				"email[0]":    false,
+				"pushover[0]": true,
+			},
+		},
+		{
+			global: "global:\n  send_resolved: false\n",
+			want: map[string]bool{
+				"webhook[0]":  false,
+				"webhook[1]":  false,
+				"email[0]":    false,
+				"pushover[0]": true,
+			},
+		},
+		{
+			global: "global:\n  send_resolved: true\n",
+			want: map[string]bool{
+				"webhook[0]":  true,
+				"webhook[1]":  false,
+				"email[0]":    true,
+				"pushover[0]": true,
+			},
+		},
+	} {
+		c, err := Load(tc.global + `
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  webhook_configs:
+  - url: http://example.com/
+  - url: http://example.com/
+    send_resolved: false
+  email_configs:
+  - to: team-x@example.com
+    from: alertmanager@example.com
+    smarthost: smtp.example.com:25
+  pushover_configs:
+  - user_key: key
+    token: token
+    send_resolved: true
+`)
+		require.NoError(t, err)
+
+		got := map[string]bool{}
+		for _, ic := range c.Receivers[0].Integrations() {
+			got[ic.Name+"["+strconv.Itoa(ic.Index)+"]"] = ic.NotifierConfig.SendResolved()
+		}
+		require.Equal(t, tc.want, got, tc.global)
+	}
+}
+
+func TestIngestionFilter(t *testing.T) {
+	var f IngestionFilter
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+allow:
+- ['env="prod"']
+deny:
+- ['env="prod"', 'team="load-test"']
+`), &f))
+	require.True(t, f.Accepts(model.LabelSet{"env": "prod"}))
+	require.False(t, f.Accepts(model.LabelSet{"env": "prod", "team": "load-test"}))
+	require.False(t, f.Accepts(model.LabelSet{"env": "dev"}))
+
+	f = IngestionFilter{}
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+deny:
+- ['env="dev"']
+`), &f))
+	require.True(t, f.Accepts(model.LabelSet{"env": "prod"}))
+	require.False(t, f.Accepts(model.LabelSet{"env": "dev"}))
+
+	err := yaml.UnmarshalStrict([]byte(`
+deny:
+- []
+`), &IngestionFilter{})
+	require.EqualError(t, err, "empty set of matchers in ingestion filter")
+}
+
+func TestGlobalWebhookMetadata(t *testing.T) {
+	c, err := Load(`
+global:
+  webhook_metadata:
+    cluster: eu-west
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  webhook_configs:
+  - url: http://example.com/default
+  - url: http://example.com/override
+    metadata:
+      instance: am-1
+`)
+	require.NoError(t, err)
+	whs := c.Receivers[0].WebhookConfigs
+	require.Equal(t, &WebhookMetadata{Cluster: "eu-west"}, whs[0].Metadata)
+	require.Equal(t, &WebhookMetadata{Instance: "am-1"}, whs[1].Metadata)
+}
@@ -92,11 +92,22 @@ func (c *Coordinator) notifySubscribers() error {
 	return nil
 }
 
+// LoadError is returned by Reload if the configuration file can't be read or
+// is invalid, in which case the current configuration is kept.
+type LoadError struct {
+	Err error
+}
+
+func (e *LoadError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *LoadError) Unwrap() error { return e.Err }
+
 // loadFromFile triggers a configuration load, discarding the old configuration.
 func (c *Coordinator) loadFromFile() error {
 	conf, err := LoadFile(c.configFilePath)
 	if err != nil {
-		return err
+		return &LoadError{Err: err}
 	}
 
 	c.config = conf
@@ -81,3 +81,21 @@ func TestCoordinatorFailReloadWhenSubscriberFails(t *testing.T) {
 		t.Fatalf("expected error message %q but got %q", errMessage, err)
 	}
 }
+
+func TestCoordinatorKeepsConfigWhenLoadFails(t *testing.T) {
+	c := NewCoordinator("testdata/conf.good.yml", prometheus.NewRegistry(), log.NewNopLogger())
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	good := c.config
+
+	c.configFilePath = "testdata/conf.sns-invalid.yml"
+	err := c.Reload()
+	var le *LoadError
+	if !errors.As(err, &le) {
+		t.Fatalf("expected a load error but got %v", err)
+	}
+	if c.config != good {
+		t.Fatal("expected the configuration to be kept")
+	}
+}
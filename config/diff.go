@@ -0,0 +1,336 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	commoncfg "github.com/prometheus/common/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Kinds of changes of a field.
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// FieldDiff is a difference of a field between two configurations.
+type FieldDiff struct {
+	// Path is the path of the field in YAML notation, such as
+	// "slack_configs[0].channel".
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	// Old and New are the values of the field. They are omitted for secrets.
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+	Secret bool        `json:"secret,omitempty"`
+}
+
+// LoadReceiver parses the given receiver as if it replaced the receiver of the
+// same name in the input of the configuration, so that it inherits the global
+// settings and is validated along with the rest of the configuration. The
+// name of the candidate defaults to the given one.
+func (c *Config) LoadReceiver(name, s string) (*Receiver, error) {
+	if c.original == "" {
+		return nil, errors.New("input of the configuration is unknown")
+	}
+
+	var candidate yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(s), &candidate); err != nil {
+		return nil, err
+	}
+	named := false
+	for _, it := range candidate {
+		if it.Key != "name" {
+			continue
+		}
+		if it.Value != name {
+			return nil, fmt.Errorf("name %v of the receiver doesn't match %q", it.Value, name)
+		}
+		named = true
+	}
+	if !named {
+		candidate = append(yaml.MapSlice{{Key: "name", Value: name}}, candidate...)
+	}
+
+	r, found, err := c.loadWithReceiver(name, candidate, false)
+	if err == nil && !found {
+		err = fmt.Errorf("receiver %q not found", name)
+	}
+	return r, err
+}
+
+// LoadCandidateReceiver parses the given receiver as if it replaced the
+// receiver of the same name in the input of the configuration, or was added
+// to it if there is none. Like LoadReceiver, the candidate inherits the global
+// settings and is validated along with the rest of the configuration. It also
+// returns whether a receiver of the same name exists.
+func (c *Config) LoadCandidateReceiver(s string) (*Receiver, bool, error) {
+	if c.original == "" {
+		return nil, false, errors.New("input of the configuration is unknown")
+	}
+
+	var candidate yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(s), &candidate); err != nil {
+		return nil, false, err
+	}
+	var name string
+	for _, it := range candidate {
+		if it.Key == "name" {
+			name, _ = it.Value.(string)
+		}
+	}
+	if name == "" {
+		return nil, false, errors.New("missing name in receiver")
+	}
+
+	return c.loadWithReceiver(name, candidate, true)
+}
+
+// loadWithReceiver loads the input of the configuration with the receiver of
+// the given name replaced by the candidate and returns the loaded candidate.
+// If there is no such receiver, the candidate is added if add is true, and
+// nothing is loaded otherwise. It also returns whether the receiver exists.
+func (c *Config) loadWithReceiver(name string, candidate yaml.MapSlice, add bool) (*Receiver, bool, error) {
+	var input yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(c.original), &input); err != nil {
+		return nil, false, err
+	}
+	found, hasReceivers := false, false
+	for i, it := range input {
+		if it.Key != "receivers" {
+			continue
+		}
+		hasReceivers = true
+		receivers, _ := it.Value.([]interface{})
+		for j, r := range receivers {
+			rcv, _ := r.(yaml.MapSlice)
+			for _, f := range rcv {
+				if f.Key == "name" && f.Value == name {
+					receivers[j] = candidate
+					found = true
+				}
+			}
+		}
+		if !found && add {
+			input[i].Value = append(receivers, candidate)
+		}
+	}
+	if !found && !add {
+		return nil, false, nil
+	}
+	if !hasReceivers {
+		input = append(input, yaml.MapItem{Key: "receivers", Value: []interface{}{candidate}})
+	}
+
+	b, err := yaml.Marshal(input)
+	if err != nil {
+		return nil, found, err
+	}
+	conf, err := Load(string(b))
+	if err != nil {
+		return nil, found, err
+	}
+	for _, r := range conf.Receivers {
+		if r.Name == name {
+			return r, found, nil
+		}
+	}
+	return nil, found, fmt.Errorf("receiver %q not found", name)
+}
+
+// DiffReceivers returns the differences of the fields of receiver b compared
+// to receiver a, ordered by path. The values of secrets are never included.
+func DiffReceivers(a, b *Receiver) []FieldDiff {
+	var res []FieldDiff
+	diffValues(reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), "", &res)
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	secretTypes       = []reflect.Type{
+		reflect.TypeOf(Secret("")),
+		reflect.TypeOf(SecretURL{}),
+		reflect.TypeOf(commoncfg.Secret("")),
+	}
+)
+
+// isSecret returns true if values of the type, or those it points to, are
+// secrets.
+func isSecret(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, st := range secretTypes {
+		if t == st {
+			return true
+		}
+	}
+	return false
+}
+
+// isLeaf returns true if values of the type are compared as a whole.
+func isLeaf(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+// isZero returns true if the value is the zero value of its type or an empty
+// slice or map.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// leafEqual compares leaves by their JSON representation, which ignores the
+// internals of types such as compiled regular expressions. Secrets are masked
+// in JSON and therefore compared by value.
+func leafEqual(a, b reflect.Value) bool {
+	if isSecret(a.Type()) {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	ja, erra := json.Marshal(a.Interface())
+	jb, errb := json.Marshal(b.Interface())
+	if erra != nil || errb != nil {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return bytes.Equal(ja, jb)
+}
+
+func appendDiff(res *[]FieldDiff, path, change string, a, b reflect.Value) {
+	d := FieldDiff{Path: path, Change: change}
+	if isSecret(a.Type()) {
+		d.Secret = true
+	} else {
+		if change != FieldAdded {
+			d.Old = a.Interface()
+		}
+		if change != FieldRemoved {
+			d.New = b.Interface()
+		}
+	}
+	*res = append(*res, d)
+}
+
+func diffValues(a, b reflect.Value, path string, res *[]FieldDiff) {
+	t := a.Type()
+	switch {
+	case t.Kind() == reflect.Ptr && !isSecret(t):
+		switch {
+		case a.IsNil() && b.IsNil():
+		case a.IsNil():
+			appendDiff(res, path, FieldAdded, a, b)
+		case b.IsNil():
+			appendDiff(res, path, FieldRemoved, a, b)
+		default:
+			diffValues(a.Elem(), b.Elem(), path, res)
+		}
+
+	case isLeaf(t) || isSecret(t):
+		if leafEqual(a, b) {
+			return
+		}
+		switch {
+		case isZero(a):
+			appendDiff(res, path, FieldAdded, a, b)
+		case isZero(b):
+			appendDiff(res, path, FieldRemoved, a, b)
+		default:
+			appendDiff(res, path, FieldChanged, a, b)
+		}
+
+	case t.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			tag := strings.Split(f.Tag.Get("yaml"), ",")
+			if tag[0] == "-" {
+				continue
+			}
+			p := path
+			if len(tag) < 2 || tag[1] != "inline" {
+				name := tag[0]
+				if name == "" {
+					name = strings.ToLower(f.Name)
+				}
+				p = joinPath(path, name)
+			}
+			diffValues(a.Field(i), b.Field(i), p, res)
+		}
+
+	case t.Kind() == reflect.Slice:
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= b.Len():
+				appendDiff(res, p, FieldRemoved, a.Index(i), a.Index(i))
+			case i >= a.Len():
+				appendDiff(res, p, FieldAdded, b.Index(i), b.Index(i))
+			default:
+				diffValues(a.Index(i), b.Index(i), p, res)
+			}
+		}
+
+	case t.Kind() == reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for s, k := range keys {
+			p := joinPath(path, s)
+			va, vb := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !vb.IsValid():
+				appendDiff(res, p, FieldRemoved, va, va)
+			case !va.IsValid():
+				appendDiff(res, p, FieldAdded, vb, vb)
+			default:
+				diffValues(va, vb, p, res)
+			}
+		}
+
+	case t.Kind() == reflect.Interface:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			appendDiff(res, path, FieldChanged, a, b)
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const diffTestConfig = `
+global:
+  slack_api_url: http://slack.example.com/secret
+route:
+  receiver: team-X
+receivers:
+- name: team-X
+  slack_configs:
+  - channel: '#alerts'
+  webhook_configs:
+  - url: http://example.com/
+- name: team-Y
+`
+
+func TestLoadReceiver(t *testing.T) {
+	c, err := Load(diffTestConfig)
+	require.NoError(t, err)
+
+	// The candidate inherits the global settings.
+	r, err := c.LoadReceiver("team-X", `
+slack_configs:
+- channel: '#other'
+`)
+	require.NoError(t, err)
+	require.Equal(t, "team-X", r.Name)
+	require.Len(t, r.SlackConfigs, 1)
+	require.Equal(t, "http://slack.example.com/secret", r.SlackConfigs[0].APIURL.String())
+
+	_, err = c.LoadReceiver("team-X", "name: team-Y\n")
+	require.EqualError(t, err, `name team-Y of the receiver doesn't match "team-X"`)
+
+	_, err = c.LoadReceiver("unknown", "")
+	require.EqualError(t, err, `receiver "unknown" not found`)
+
+	_, err = c.LoadReceiver("team-X", `
+webhook_configs:
+- send_resolved: true
+`)
+	require.EqualError(t, err, "missing URL in webhook config")
+
+	_, err = (&Config{}).LoadReceiver("team-X", "")
+	require.EqualError(t, err, "input of the configuration is unknown")
+}
+
+func TestLoadCandidateReceiver(t *testing.T) {
+	c, err := Load(diffTestConfig)
+	require.NoError(t, err)
+
+	// New receivers are added to the configuration.
+	r, exists, err := c.LoadCandidateReceiver(`
+name: team-Z
+slack_configs:
+- channel: '#other'
+`)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, "team-Z", r.Name)
+	require.Equal(t, "http://slack.example.com/secret", r.SlackConfigs[0].APIURL.String())
+
+	r, exists, err = c.LoadCandidateReceiver("name: team-X\n")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Empty(t, r.SlackConfigs)
+
+	_, _, err = c.LoadCandidateReceiver("slack_configs: []\n")
+	require.EqualError(t, err, "missing name in receiver")
+
+	_, _, err = c.LoadCandidateReceiver(`
+name: team-Z
+webhook_configs:
+- send_resolved: true
+`)
+	require.EqualError(t, err, "missing URL in webhook config")
+}
+
+func TestDiffReceivers(t *testing.T) {
+	c, err := Load(diffTestConfig)
+	require.NoError(t, err)
+	current := c.Receivers[0]
+
+	same, err := c.LoadReceiver("team-X", `
+slack_configs:
+- channel: '#alerts'
+webhook_configs:
+- url: http://example.com/
+`)
+	require.NoError(t, err)
+	require.Empty(t, DiffReceivers(current, same))
+
+	other, err := c.LoadReceiver("team-X", `
+slack_configs:
+- channel: '#other'
+  api_url: http://slack.example.com/other
+email_configs:
+- to: team-x@example.com
+  from: alertmanager@example.com
+  smarthost: smtp.example.com:25
+`)
+	require.NoError(t, err)
+	diff := DiffReceivers(current, other)
+
+	byPath := map[string]FieldDiff{}
+	for _, d := range diff {
+		byPath[d.Path] = d
+	}
+	require.Len(t, byPath, len(diff))
+
+	require.Equal(t, FieldDiff{Path: "slack_configs[0].channel", Change: FieldChanged, Old: "#alerts", New: "#other"}, byPath["slack_configs[0].channel"])
+	require.Equal(t, FieldDiff{Path: "slack_configs[0].api_url", Change: FieldChanged, Secret: true}, byPath["slack_configs[0].api_url"])
+	require.Equal(t, FieldRemoved, byPath["webhook_configs[0]"].Change)
+	require.Nil(t, byPath["webhook_configs[0]"].New)
+	require.Equal(t, FieldAdded, byPath["email_configs[0]"].Change)
+	require.Nil(t, byPath["email_configs[0]"].Old)
+	require.Len(t, diff, 4)
+
+	// Secrets are never revealed.
+	b, err := json.Marshal(diff)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "slack.example.com")
+}
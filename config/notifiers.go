@@ -15,6 +15,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"reflect"
 	"regexp"
 	"strings"
 	"time"
@@ -22,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 
 	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/sigv4"
 )
 
@@ -137,6 +140,15 @@ var (
 		Subject: `{{ template "sns.default.subject" . }}`,
 		Message: `{{ template "sns.default.message" . }}`,
 	}
+
+	// DefaultMSTeamsConfig defines default values for Microsoft Teams configurations.
+	DefaultMSTeamsConfig = MSTeamsConfig{
+		NotifierConfig: NotifierConfig{
+			VSendResolved: true,
+		},
+		Title: `{{ template "msteams.default.title" . }}`,
+		Text:  `{{ template "msteams.default.text" . }}`,
+	}
 )
 
 // NotifierConfig contains base options common across all notifier configurations.
@@ -192,6 +204,32 @@ func (c *EmailConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// checkTLS returns an error if the TLS settings contradict each other. It must
+// be called once the global defaults were applied.
+func (c *EmailConfig) checkTLS() error {
+	// Port 465 uses implicit TLS regardless of require_tls.
+	if *c.RequireTLS || c.Smarthost.Port == "465" {
+		return nil
+	}
+	if !reflect.DeepEqual(c.TLSConfig, commoncfg.TLSConfig{}) {
+		return fmt.Errorf("tls_config has no effect unless require_tls is true or port 465 is used")
+	}
+	// Passwords must not be sent in plaintext to remote hosts. The PLAIN
+	// mechanism refuses to do so anyway, which would only fail when sending.
+	if c.AuthUsername != "" && c.AuthPassword != "" && c.AuthSecret == "" && !isLocalhost(c.Smarthost.Host) {
+		return fmt.Errorf("auth_password would be sent over an unencrypted connection to %q, require_tls must be true or port 465 must be used", c.Smarthost.Host)
+	}
+	return nil
+}
+
+func isLocalhost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // PagerdutyConfig configures notifications via PagerDuty.
 type PagerdutyConfig struct {
 	NotifierConfig `yaml:",inline" json:",inline"`
@@ -211,8 +249,19 @@ type PagerdutyConfig struct {
 	Class       string            `yaml:"class,omitempty" json:"class,omitempty"`
 	Component   string            `yaml:"component,omitempty" json:"component,omitempty"`
 	Group       string            `yaml:"group,omitempty" json:"group,omitempty"`
+	// DedupKeyLabel is the label whose value is used as the deduplication
+	// key of events instead of the key of the alert group, provided all
+	// alerts of a notification have the same value.
+	DedupKeyLabel model.LabelName `yaml:"dedup_key_label,omitempty" json:"dedup_key_label,omitempty"`
+	// SeverityMapping derives the severity of events from the severity of
+	// the alerts. It takes precedence over Severity and defaults to the
+	// global one.
+	SeverityMapping *PriorityMapping `yaml:"severity_mapping,omitempty" json:"severity_mapping,omitempty"`
 }
 
+// pagerdutySeverities are the severities of events accepted by PagerDuty.
+var pagerdutySeverities = []string{"critical", "error", "warning", "info"}
+
 // PagerdutyLink is a link
 type PagerdutyLink struct {
 	Href string `yaml:"href,omitempty" json:"href,omitempty"`
@@ -236,6 +285,11 @@ func (c *PagerdutyConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 	if c.RoutingKey == "" && c.ServiceKey == "" {
 		return fmt.Errorf("missing service or routing key in PagerDuty config")
 	}
+	if c.SeverityMapping != nil {
+		if err := c.SeverityMapping.check(pagerdutySeverities); err != nil {
+			return fmt.Errorf("invalid severity_mapping in PagerDuty config: %w", err)
+		}
+	}
 	if c.Details == nil {
 		c.Details = make(map[string]string)
 	}
@@ -362,6 +416,26 @@ type SlackConfig struct {
 	LinkNames   bool           `yaml:"link_names" json:"link_names,omitempty"`
 	MrkdwnIn    []string       `yaml:"mrkdwn_in,omitempty" json:"mrkdwn_in,omitempty"`
 	Actions     []*SlackAction `yaml:"actions,omitempty" json:"actions,omitempty"`
+
+	// TemplateLabel is the label whose value, common to all alerts of a
+	// notification, selects the message template among Templates. The
+	// fields of the configuration itself are used if the alerts don't share
+	// a value or no template is configured for it.
+	TemplateLabel string                    `yaml:"template_label,omitempty" json:"template_label,omitempty"`
+	Templates     map[string]*SlackTemplate `yaml:"templates,omitempty" json:"templates,omitempty"`
+}
+
+// SlackTemplate is a message template of a Slack configuration, selected by
+// the value of an alert label. Empty fields fall back to the fields of the
+// configuration.
+type SlackTemplate struct {
+	Color     string `yaml:"color,omitempty" json:"color,omitempty"`
+	Title     string `yaml:"title,omitempty" json:"title,omitempty"`
+	TitleLink string `yaml:"title_link,omitempty" json:"title_link,omitempty"`
+	Pretext   string `yaml:"pretext,omitempty" json:"pretext,omitempty"`
+	Text      string `yaml:"text,omitempty" json:"text,omitempty"`
+	Footer    string `yaml:"footer,omitempty" json:"footer,omitempty"`
+	Fallback  string `yaml:"fallback,omitempty" json:"fallback,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
@@ -376,9 +450,29 @@ func (c *SlackConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("at most one of api_url & api_url_file must be configured")
 	}
 
+	if len(c.Templates) > 0 {
+		if c.TemplateLabel == "" {
+			return fmt.Errorf("template_label must be configured with templates")
+		}
+		if !model.LabelName(c.TemplateLabel).IsValid() {
+			return fmt.Errorf("invalid template_label %q", c.TemplateLabel)
+		}
+		for v, t := range c.Templates {
+			if t == nil {
+				return fmt.Errorf("missing template for %s=%q", c.TemplateLabel, v)
+			}
+		}
+	}
+
 	return nil
 }
 
+// Strategies selecting the first URL of a webhook tried for a notification.
+const (
+	WebhookURLStrategyRoundRobin = "round_robin"
+	WebhookURLStrategyRandom     = "random"
+)
+
 // WebhookConfig configures notifications via a generic webhook.
 type WebhookConfig struct {
 	NotifierConfig `yaml:",inline" json:",inline"`
@@ -387,10 +481,63 @@ type WebhookConfig struct {
 
 	// URL to send POST request to.
 	URL *URL `yaml:"url" json:"url"`
+	// URLs to spread the POST requests across, instead of a single URL. For
+	// each notification, the URLs are tried in the order given by
+	// URLStrategy until one succeeds.
+	URLs []*URL `yaml:"urls,omitempty" json:"urls,omitempty"`
+	// URLStrategy selects the first URL tried for a notification, either
+	// round_robin (the default) or random.
+	URLStrategy string `yaml:"url_strategy,omitempty" json:"url_strategy,omitempty"`
 	// MaxAlerts is the maximum number of alerts to be sent per webhook message.
 	// Alerts exceeding this threshold will be truncated. Setting this to 0
 	// allows an unlimited number of alerts.
 	MaxAlerts uint64 `yaml:"max_alerts" json:"max_alerts"`
+	// IncludeLabels and ExcludeLabels restrict the labels sent in the
+	// webhook message. At most one of them may be set.
+	IncludeLabels []string `yaml:"include_labels,omitempty" json:"include_labels,omitempty"`
+	ExcludeLabels []string `yaml:"exclude_labels,omitempty" json:"exclude_labels,omitempty"`
+	// IncludeAnnotations and ExcludeAnnotations restrict the annotations
+	// sent in the webhook message. At most one of them may be set.
+	IncludeAnnotations []string `yaml:"include_annotations,omitempty" json:"include_annotations,omitempty"`
+	ExcludeAnnotations []string `yaml:"exclude_annotations,omitempty" json:"exclude_annotations,omitempty"`
+	// Metadata describes the sending Alertmanager in the webhook message.
+	// It defaults to the global webhook metadata.
+	Metadata *WebhookMetadata `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// WebhookMetadata is static information about the Alertmanager sending
+// webhook messages, such as for receivers aggregating the messages of many
+// Alertmanagers.
+type WebhookMetadata struct {
+	Instance       string         `yaml:"instance,omitempty" json:"instance,omitempty"`
+	Cluster        string         `yaml:"cluster,omitempty" json:"cluster,omitempty"`
+	ExternalLabels model.LabelSet `yaml:"external_labels,omitempty" json:"external_labels,omitempty"`
+	// SendHeaders sends the instance and cluster in the
+	// X-Alertmanager-Instance and X-Alertmanager-Cluster headers as well.
+	SendHeaders bool `yaml:"send_headers,omitempty" json:"send_headers,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (m *WebhookMetadata) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain WebhookMetadata
+	if err := unmarshal((*plain)(m)); err != nil {
+		return err
+	}
+	if m.Instance == "" && m.Cluster == "" && len(m.ExternalLabels) == 0 {
+		return fmt.Errorf("webhook metadata must set one of instance, cluster or external_labels")
+	}
+	if err := m.ExternalLabels.Validate(); err != nil {
+		return fmt.Errorf("invalid external_labels of webhook metadata: %w", err)
+	}
+	for _, v := range []string{m.Instance, m.Cluster} {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("instance and cluster of webhook metadata must not contain line breaks")
+		}
+	}
+	if m.SendHeaders && m.Instance == "" && m.Cluster == "" {
+		return fmt.Errorf("send_headers of webhook metadata requires an instance or cluster")
+	}
+	return nil
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
@@ -400,11 +547,43 @@ func (c *WebhookConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if err := unmarshal((*plain)(c)); err != nil {
 		return err
 	}
-	if c.URL == nil {
+	if c.URL != nil && len(c.URLs) > 0 {
+		return fmt.Errorf("at most one of url & urls must be configured")
+	}
+	if c.URL == nil && len(c.URLs) == 0 {
 		return fmt.Errorf("missing URL in webhook config")
 	}
-	if c.URL.Scheme != "https" && c.URL.Scheme != "http" {
-		return fmt.Errorf("scheme required for webhook url")
+	for _, u := range append([]*URL{c.URL}, c.URLs...) {
+		if u == nil {
+			continue
+		}
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("scheme required for webhook url")
+		}
+	}
+	switch c.URLStrategy {
+	case "", WebhookURLStrategyRoundRobin, WebhookURLStrategyRandom:
+	default:
+		return fmt.Errorf("unknown url_strategy %q in webhook config", c.URLStrategy)
+	}
+	if err := checkPayloadKeys("labels", c.IncludeLabels, c.ExcludeLabels); err != nil {
+		return err
+	}
+	return checkPayloadKeys("annotations", c.IncludeAnnotations, c.ExcludeAnnotations)
+}
+
+// checkPayloadKeys validates the lists of keys to include in or exclude from
+// a webhook message.
+func checkPayloadKeys(kind string, include, exclude []string) error {
+	if len(include) > 0 && len(exclude) > 0 {
+		return fmt.Errorf("at most one of include_%s & exclude_%s must be configured", kind, kind)
+	}
+	for _, keys := range [][]string{include, exclude} {
+		for _, k := range keys {
+			if !model.LabelName(k).IsValid() {
+				return fmt.Errorf("invalid key %q in webhook %s filter", k, kind)
+			}
+		}
 	}
 	return nil
 }
@@ -467,8 +646,19 @@ type OpsGenieConfig struct {
 	Note         string                    `yaml:"note,omitempty" json:"note,omitempty"`
 	Priority     string                    `yaml:"priority,omitempty" json:"priority,omitempty"`
 	UpdateAlerts bool                      `yaml:"update_alerts,omitempty" json:"update_alerts,omitempty"`
+	// AliasLabel is the label whose value is used as the alias of alerts
+	// instead of the key of the alert group, provided all alerts of a
+	// notification have the same value.
+	AliasLabel model.LabelName `yaml:"alias_label,omitempty" json:"alias_label,omitempty"`
+	// PriorityMapping derives the priority of alerts from the severity of
+	// the alerts of the notification. It takes precedence over Priority and
+	// defaults to the global one.
+	PriorityMapping *PriorityMapping `yaml:"priority_mapping,omitempty" json:"priority_mapping,omitempty"`
 }
 
+// opsgeniePriorities are the priorities of alerts accepted by OpsGenie.
+var opsgeniePriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+
 const opsgenieValidTypesRe = `^(team|user|escalation|schedule)$`
 
 var opsgenieTypeMatcher = regexp.MustCompile(opsgenieValidTypesRe)
@@ -496,6 +686,12 @@ func (c *OpsGenieConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 		}
 	}
 
+	if c.PriorityMapping != nil {
+		if err := c.PriorityMapping.check(opsgeniePriorities); err != nil {
+			return fmt.Errorf("invalid priority_mapping in OpsGenie config: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -509,6 +705,72 @@ type OpsGenieConfigResponder struct {
 	Type string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
+// PriorityMapping derives the priority of notifications from the severity
+// label of their alerts, so that integrations prioritize alerts consistently.
+type PriorityMapping struct {
+	// Label is the label holding the severity of alerts.
+	Label model.LabelName `yaml:"label,omitempty" json:"label,omitempty"`
+	// Priorities are the priorities of the severities, ordered from the most
+	// to the least severe. The most severe alert of a notification
+	// determines its priority.
+	Priorities []SeverityPriority `yaml:"priorities,omitempty" json:"priorities,omitempty"`
+	// Default is the priority of notifications without alerts of a mapped
+	// severity. If empty, the priority configured by the integration applies.
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+}
+
+// SeverityPriority is the priority of alerts of a severity.
+type SeverityPriority struct {
+	Severity string `yaml:"severity" json:"severity"`
+	Priority string `yaml:"priority" json:"priority"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (m *PriorityMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain PriorityMapping
+	if err := unmarshal((*plain)(m)); err != nil {
+		return err
+	}
+	if m.Label == "" {
+		m.Label = "severity"
+	}
+	if len(m.Priorities) == 0 && m.Default == "" {
+		return fmt.Errorf("priority mapping must set priorities or a default")
+	}
+	seen := make(map[string]struct{}, len(m.Priorities))
+	for _, sp := range m.Priorities {
+		if sp.Severity == "" || sp.Priority == "" {
+			return fmt.Errorf("priorities of priority mapping must set a severity and a priority")
+		}
+		if _, ok := seen[sp.Severity]; ok {
+			return fmt.Errorf("severity %q of priority mapping is not unique", sp.Severity)
+		}
+		seen[sp.Severity] = struct{}{}
+	}
+	return nil
+}
+
+// check returns an error if the mapping derives priorities not in valid.
+func (m *PriorityMapping) check(valid []string) error {
+	isValid := func(p string) bool {
+		for _, v := range valid {
+			if p == v {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sp := range m.Priorities {
+		if !isValid(sp.Priority) {
+			return fmt.Errorf("priority %q of severity %q must be one of %s", sp.Priority, sp.Severity, strings.Join(valid, ", "))
+		}
+	}
+	if m.Default != "" && !isValid(m.Default) {
+		return fmt.Errorf("default priority %q must be one of %s", m.Default, strings.Join(valid, ", "))
+	}
+	return nil
+}
+
 // VictorOpsConfig configures notifications via VictorOps.
 type VictorOpsConfig struct {
 	NotifierConfig `yaml:",inline" json:",inline"`
@@ -626,3 +888,28 @@ func (c *SNSConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	}
 	return nil
 }
+
+// MSTeamsConfig configures notifications via Microsoft Teams.
+type MSTeamsConfig struct {
+	NotifierConfig `yaml:",inline" json:",inline"`
+
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+
+	// WebhookURL is the incoming webhook URL of the channel.
+	WebhookURL *SecretURL `yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"`
+	Title      string     `yaml:"title,omitempty" json:"title,omitempty"`
+	Text       string     `yaml:"text,omitempty" json:"text,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *MSTeamsConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*c = DefaultMSTeamsConfig
+	type plain MSTeamsConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+	if c.WebhookURL == nil {
+		return fmt.Errorf("missing webhook URL in Microsoft Teams config")
+	}
+	return nil
+}
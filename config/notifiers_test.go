@@ -183,6 +183,188 @@ http_config:
 	}
 }
 
+func TestWebhookOAuth2IsValid(t *testing.T) {
+	in := `
+url: 'http://example.com'
+http_config:
+  oauth2:
+    client_id: foo
+    client_secret: bar
+`
+	var cfg WebhookConfig
+	err := yaml.UnmarshalStrict([]byte(in), &cfg)
+
+	expected := "oauth2 token_url must be configured"
+
+	if err == nil {
+		t.Fatalf("no error returned, expected:\n%v", expected)
+	}
+	if err.Error() != expected {
+		t.Errorf("\nexpected:\n%v\ngot:\n%v", expected, err.Error())
+	}
+}
+
+func TestWebhookPayloadFilterIsValid(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected string
+	}{
+		{
+			in: `
+url: 'http://example.com'
+include_labels: [alertname, severity]
+exclude_annotations: [description]
+`,
+		},
+		{
+			in: `
+url: 'http://example.com'
+include_labels: [alertname]
+exclude_labels: [severity]
+`,
+			expected: "at most one of include_labels & exclude_labels must be configured",
+		},
+		{
+			in: `
+url: 'http://example.com'
+include_annotations: [summary]
+exclude_annotations: [description]
+`,
+			expected: "at most one of include_annotations & exclude_annotations must be configured",
+		},
+		{
+			in: `
+url: 'http://example.com'
+exclude_annotations: [runbook-url]
+`,
+			expected: `invalid key "runbook-url" in webhook annotations filter`,
+		},
+	} {
+		var cfg WebhookConfig
+		err := yaml.UnmarshalStrict([]byte(tc.in), &cfg)
+		if tc.expected == "" {
+			if err != nil {
+				t.Fatalf("no error expected, returned:\n%v", err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("no error returned, expected:\n%v", tc.expected)
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("\nexpected:\n%v\ngot:\n%v", tc.expected, err.Error())
+		}
+	}
+}
+
+func TestWebhookMetadataIsValid(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected string
+	}{
+		{
+			in: `
+url: 'http://example.com'
+metadata:
+  instance: am-1
+  external_labels:
+    region: eu
+  send_headers: true
+`,
+		},
+		{
+			in: `
+url: 'http://example.com'
+metadata:
+  send_headers: true
+`,
+			expected: "webhook metadata must set one of instance, cluster or external_labels",
+		},
+		{
+			in: `
+url: 'http://example.com'
+metadata:
+  external_labels:
+    region: eu
+  send_headers: true
+`,
+			expected: "send_headers of webhook metadata requires an instance or cluster",
+		},
+		{
+			in: `
+url: 'http://example.com'
+metadata:
+  cluster: "eu\nwest"
+`,
+			expected: "instance and cluster of webhook metadata must not contain line breaks",
+		},
+	} {
+		var cfg WebhookConfig
+		err := yaml.UnmarshalStrict([]byte(tc.in), &cfg)
+		if tc.expected == "" {
+			if err != nil {
+				t.Fatalf("no error expected, returned:\n%v", err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("no error returned, expected:\n%v", tc.expected)
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("\nexpected:\n%v\ngot:\n%v", tc.expected, err.Error())
+		}
+	}
+}
+
+func TestWebhookURLsAreValid(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected string
+	}{
+		{
+			in: `
+urls: ['http://a.example.com', 'https://b.example.com']
+url_strategy: random
+`,
+		},
+		{
+			in: `
+url: 'http://example.com'
+urls: ['http://a.example.com']
+`,
+			expected: "at most one of url & urls must be configured",
+		},
+		{
+			in: `
+urls: ['http://a.example.com', 'ftp://b.example.com']
+`,
+			expected: `unsupported scheme "ftp" for URL`,
+		},
+		{
+			in: `
+urls: ['http://a.example.com']
+url_strategy: weighted
+`,
+			expected: `unknown url_strategy "weighted" in webhook config`,
+		},
+	} {
+		var cfg WebhookConfig
+		err := yaml.UnmarshalStrict([]byte(tc.in), &cfg)
+		if tc.expected == "" {
+			if err != nil {
+				t.Fatalf("no error expected, returned:\n%v", err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("no error returned, expected:\n%v", tc.expected)
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("\nexpected:\n%v\ngot:\n%v", tc.expected, err.Error())
+		}
+	}
+}
+
 func TestWebhookHttpConfigIsOptional(t *testing.T) {
 	in := `
 url: 'http://example.com'
@@ -608,3 +790,80 @@ func TestWeChatTypeMatcher(t *testing.T) {
 func newBoolPointer(b bool) *bool {
 	return &b
 }
+
+func TestDedupKeyLabelIsValid(t *testing.T) {
+	expected := `"in-valid" is not a valid label name`
+
+	in := `
+routing_key: 'xyz'
+dedup_key_label: 'in-valid'
+`
+	var pdcfg PagerdutyConfig
+	err := yaml.UnmarshalStrict([]byte(in), &pdcfg)
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nexpected:\n%v\ngot:\n%v", expected, err)
+	}
+
+	in = `
+api_key: 'xyz'
+alias_label: 'in-valid'
+`
+	var ogcfg OpsGenieConfig
+	err = yaml.UnmarshalStrict([]byte(in), &ogcfg)
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nexpected:\n%v\ngot:\n%v", expected, err)
+	}
+}
+
+func TestSlackTemplatesValidation(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{
+			in: `
+template_label: service
+templates:
+  db:
+    title: database
+`,
+		},
+		{
+			in: `
+templates:
+  db:
+    title: database
+`,
+			err: "template_label must be configured with templates",
+		},
+		{
+			in: `
+template_label: 0service
+templates:
+  db:
+    title: database
+`,
+			err: `invalid template_label "0service"`,
+		},
+		{
+			in: `
+template_label: service
+templates:
+  db:
+`,
+			err: `missing template for service="db"`,
+		},
+	} {
+		var cfg SlackConfig
+		err := yaml.UnmarshalStrict([]byte(tc.in), &cfg)
+		if tc.err == "" {
+			if err != nil {
+				t.Fatalf("\nerror returned when none expected, error:\n%v", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("\nexpected:\n%v\ngot:\n%v", tc.err, err)
+		}
+	}
+}
@@ -83,6 +83,10 @@ type Dispatcher struct {
 	marker  types.Marker
 	timeout func(time.Duration) time.Duration
 
+	// priorities orders the notifications of aggregation groups. If nil,
+	// groups notify as soon as they are due.
+	priorities *Priorities
+
 	mtx                sync.RWMutex
 	aggrGroupsPerRoute map[*Route]map[model.Fingerprint]*aggrGroup
 	aggrGroupsNum      int
@@ -130,6 +134,12 @@ func NewDispatcher(
 	return disp
 }
 
+// SetPriorities sets the priorities ordering the notifications of aggregation
+// groups. It must be called before Run.
+func (d *Dispatcher) SetPriorities(p *Priorities) {
+	d.priorities = p
+}
+
 // Run starts dispatching alerts incoming via the updates channel.
 func (d *Dispatcher) Run() {
 	d.done = make(chan struct{})
@@ -280,6 +290,47 @@ func (d *Dispatcher) Groups(routeFilter func(*Route) bool, alertFilter func(*typ
 	return groups, receivers
 }
 
+// GroupStats is a snapshot of the state of an aggregation group.
+type GroupStats struct {
+	GroupKey string
+	Receiver string
+	Labels   model.LabelSet
+	// Alerts is the number of alerts in the group.
+	Alerts int
+	// NextFlush is the time the group is due to notify next.
+	NextFlush time.Time
+}
+
+// Stats returns a snapshot of the aggregation groups ordered by group key. It
+// is safe to call while the dispatcher is running.
+func (d *Dispatcher) Stats() []GroupStats {
+	if d == nil {
+		return nil
+	}
+
+	d.mtx.RLock()
+	res := make([]GroupStats, 0, d.aggrGroupsNum)
+	for route, ags := range d.aggrGroupsPerRoute {
+		for _, ag := range ags {
+			ag.mtx.RLock()
+			nextFlush := ag.nextFlush
+			ag.mtx.RUnlock()
+
+			res = append(res, GroupStats{
+				GroupKey:  ag.GroupKey(),
+				Receiver:  route.RouteOpts.Receiver,
+				Labels:    ag.labels,
+				Alerts:    len(ag.alerts.List()),
+				NextFlush: nextFlush,
+			})
+		}
+	}
+	d.mtx.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].GroupKey < res[j].GroupKey })
+	return res
+}
+
 // Stop the dispatcher.
 func (d *Dispatcher) Stop() {
 	if d == nil {
@@ -342,6 +393,15 @@ func (d *Dispatcher) processAlert(alert *types.Alert, route *Route) {
 	ag.insert(alert)
 
 	go ag.run(func(ctx context.Context, alerts ...*types.Alert) bool {
+		if d.priorities != nil {
+			release, err := d.priorities.acquire(ctx, alerts)
+			if err != nil {
+				level.Debug(d.logger).Log("msg", "Notify for alerts canceled while waiting", "num_alerts", len(alerts), "err", err)
+				return false
+			}
+			defer release()
+		}
+
 		_, _, err := d.stage.Exec(ctx, d.logger, alerts...)
 		if err != nil {
 			lvl := level.Error(d.logger)
@@ -383,9 +443,11 @@ type aggrGroup struct {
 	done    chan struct{}
 	next    *time.Timer
 	timeout func(time.Duration) time.Duration
+	now     func() time.Time
 
 	mtx        sync.RWMutex
 	hasFlushed bool
+	nextFlush  time.Time
 }
 
 // newAggrGroup returns a new aggregation group.
@@ -398,6 +460,7 @@ func newAggrGroup(ctx context.Context, labels model.LabelSet, r *Route, to func(
 		routeKey: r.Key(),
 		opts:     &r.RouteOpts,
 		timeout:  to,
+		now:      time.Now,
 		alerts:   store.NewAlerts(),
 		done:     make(chan struct{}),
 	}
@@ -408,6 +471,7 @@ func newAggrGroup(ctx context.Context, labels model.LabelSet, r *Route, to func(
 	// Set an initial one-time wait before flushing
 	// the first batch of notifications.
 	ag.next = time.NewTimer(ag.opts.GroupWait)
+	ag.nextFlush = ag.now().Add(ag.opts.GroupWait)
 
 	return ag
 }
@@ -448,14 +512,27 @@ func (ag *aggrGroup) run(nf notifyFunc) {
 			ctx = notify.WithRepeatInterval(ctx, ag.opts.RepeatInterval)
 			ctx = notify.WithMuteTimeIntervals(ctx, ag.opts.MuteTimeIntervals)
 
-			// Wait the configured interval before calling flush again.
+			// Wait the configured interval before calling flush again,
+			// unless an alert escalates earlier.
 			ag.mtx.Lock()
-			ag.next.Reset(ag.opts.GroupInterval)
+			ag.resetTimer(ag.opts.GroupInterval)
 			ag.hasFlushed = true
 			ag.mtx.Unlock()
 
 			ag.flush(func(alerts ...*types.Alert) bool {
-				return nf(ctx, alerts...)
+				ok := nf(ctx, alerts...)
+				for _, e := range ag.opts.Escalations {
+					escalated := ag.escalated(e, alerts)
+					if len(escalated) == 0 {
+						continue
+					}
+					ectx := notify.WithGroupKey(ctx, ag.escalationGroupKey(e))
+					ectx = notify.WithReceiverName(ectx, e.Receiver)
+					if !nf(ectx, escalated...) {
+						ok = false
+					}
+				}
+				return ok
 			})
 
 			cancel()
@@ -483,9 +560,78 @@ func (ag *aggrGroup) insert(alert *types.Alert) {
 	// alert is already over.
 	ag.mtx.Lock()
 	defer ag.mtx.Unlock()
-	if !ag.hasFlushed && alert.StartsAt.Add(ag.opts.GroupWait).Before(time.Now()) {
+	now := ag.now()
+	if !ag.hasFlushed && alert.StartsAt.Add(ag.opts.GroupWait).Before(now) {
 		ag.next.Reset(0)
+		ag.nextFlush = now
+		return
+	}
+	// Flush early if the alert escalates before the next flush.
+	if at, ok := ag.escalatesAt(alert, now); ok && at.Before(ag.nextFlush) {
+		ag.next.Reset(at.Sub(now))
+		ag.nextFlush = at
+	}
+}
+
+// resetTimer schedules the next flush after d, or earlier if an alert of the
+// group escalates before that. ag.mtx must be held.
+func (ag *aggrGroup) resetTimer(d time.Duration) {
+	now := ag.now()
+	next := now.Add(d)
+	for _, a := range ag.alerts.List() {
+		if at, ok := ag.escalatesAt(a, now); ok && at.Before(next) {
+			next = at
+		}
 	}
+	ag.next.Reset(next.Sub(now))
+	ag.nextFlush = next
+}
+
+// escalatesAt returns the earliest time after now at which the firing alert
+// escalates. It returns false if the alert doesn't escalate after now.
+func (ag *aggrGroup) escalatesAt(a *types.Alert, now time.Time) (time.Time, bool) {
+	if a.ResolvedAt(now) {
+		return time.Time{}, false
+	}
+	var (
+		next time.Time
+		ok   bool
+	)
+	for _, e := range ag.opts.Escalations {
+		at := e.at(a)
+		if at.After(now) && (!ok || at.Before(next)) {
+			next, ok = at, true
+		}
+	}
+	return next, ok
+}
+
+// escalated returns the alerts which escalate to the given escalation. It
+// includes resolved alerts that were firing long enough to escalate, so that
+// the escalation receiver is notified about their resolution.
+func (ag *aggrGroup) escalated(e Escalation, alerts []*types.Alert) []*types.Alert {
+	var (
+		res []*types.Alert
+		now = ag.now()
+	)
+	for _, a := range alerts {
+		at := e.at(a)
+		if at.After(now) {
+			continue
+		}
+		if a.ResolvedAt(now) && a.EndsAt.Before(at) {
+			continue
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// escalationGroupKey returns the group key used for notifications of the
+// given escalation. It differs from the group key of the aggregation group so
+// that the notification log tracks the escalation separately.
+func (ag *aggrGroup) escalationGroupKey(e Escalation) string {
+	return fmt.Sprintf("%s/escalation:%s:%s", ag.GroupKey(), e.Receiver, e.After)
 }
 
 func (ag *aggrGroup) empty() bool {
@@ -501,7 +647,7 @@ func (ag *aggrGroup) flush(notify func(...*types.Alert) bool) {
 	var (
 		alerts      = ag.alerts.List()
 		alertsSlice = make(types.AlertSlice, 0, len(alerts))
-		now         = time.Now()
+		now         = ag.now()
 	)
 	for _, alert := range alerts {
 		a := *alert
@@ -269,6 +269,129 @@ func TestAggrGroup(t *testing.T) {
 	ag.stop()
 }
 
+func TestAggrGroupEscalation(t *testing.T) {
+	var (
+		mtx sync.Mutex
+		now = time.Now()
+	)
+	clock := func() time.Time {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		now = now.Add(d)
+	}
+
+	lset := model.LabelSet{"a": "v1"}
+	route := &Route{
+		RouteOpts: RouteOpts{
+			Receiver:       "n1",
+			GroupBy:        map[model.LabelName]struct{}{"a": struct{}{}},
+			GroupWait:      10 * time.Millisecond,
+			GroupInterval:  50 * time.Millisecond,
+			RepeatInterval: time.Hour,
+			Escalations: []Escalation{
+				{After: 30 * time.Minute, Receiver: "pager"},
+			},
+		},
+	}
+
+	type notification struct {
+		receiver string
+		groupKey string
+		alerts   []*types.Alert
+	}
+	notifications := make(chan notification)
+	ntfy := func(ctx context.Context, alerts ...*types.Alert) bool {
+		rcv, _ := notify.ReceiverName(ctx)
+		key, _ := notify.GroupKey(ctx)
+		select {
+		case notifications <- notification{receiver: rcv, groupKey: key, alerts: alerts}:
+		case <-ctx.Done():
+		}
+		return true
+	}
+
+	ag := newAggrGroup(context.Background(), lset, route, nil, log.NewNopLogger())
+	ag.now = clock
+	go ag.run(ntfy)
+	defer ag.stop()
+
+	a := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"a": "v1", "b": "v2"},
+			StartsAt: clock(),
+			EndsAt:   clock().Add(2 * time.Hour),
+		},
+		UpdatedAt: clock(),
+	}
+	ag.insert(a)
+
+	// Before the escalation threshold only the route's receiver is notified.
+	for i := 0; i < 3; i++ {
+		select {
+		case <-time.After(time.Second):
+			t.Fatalf("expected notification")
+		case n := <-notifications:
+			require.Equal(t, "n1", n.receiver)
+			require.Equal(t, ag.GroupKey(), n.groupKey)
+		}
+	}
+
+	advance(31 * time.Minute)
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("expected escalation notification")
+		case n := <-notifications:
+			if n.receiver != "pager" {
+				continue
+			}
+			require.NotEqual(t, ag.GroupKey(), n.groupKey)
+			require.Len(t, n.alerts, 1)
+			require.Equal(t, a.Labels, n.alerts[0].Labels)
+			return
+		}
+	}
+}
+
+func TestAggrGroupEscalatesAt(t *testing.T) {
+	now := time.Now()
+	ag := &aggrGroup{
+		opts: &RouteOpts{
+			Escalations: []Escalation{
+				{After: time.Hour, Receiver: "r2"},
+				{After: 30 * time.Minute, Receiver: "r1"},
+			},
+		},
+	}
+	a := &types.Alert{
+		Alert: model.Alert{
+			StartsAt: now.Add(-10 * time.Minute),
+		},
+	}
+
+	at, ok := ag.escalatesAt(a, now)
+	require.True(t, ok)
+	require.Equal(t, a.StartsAt.Add(30*time.Minute), at)
+
+	at, ok = ag.escalatesAt(a, now.Add(30*time.Minute))
+	require.True(t, ok)
+	require.Equal(t, a.StartsAt.Add(time.Hour), at)
+
+	_, ok = ag.escalatesAt(a, now.Add(time.Hour))
+	require.False(t, ok)
+
+	a.EndsAt = now.Add(-time.Minute)
+	_, ok = ag.escalatesAt(a, now)
+	require.False(t, ok)
+}
+
 func TestGroupLabels(t *testing.T) {
 	var a = &types.Alert{
 		Alert: model.Alert{
@@ -568,6 +691,65 @@ route:
 	require.Len(t, alertGroups, 6)
 }
 
+func TestDispatcherStats(t *testing.T) {
+	conf, err := config.Load(`receivers:
+- name: 'prod'
+
+route:
+  group_by: ['alertname']
+  group_wait: 10ms
+  group_interval: 1h
+  receiver: 'prod'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := log.NewNopLogger()
+	route := NewRoute(conf.Route, nil)
+	marker := types.NewMarker(prometheus.NewRegistry())
+	alerts, err := mem.NewAlerts(context.Background(), marker, time.Hour, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer alerts.Close()
+
+	var nilDispatcher *Dispatcher
+	require.Empty(t, nilDispatcher.Stats())
+
+	timeout := func(d time.Duration) time.Duration { return time.Duration(0) }
+	recorder := &recordStage{alerts: make(map[string]map[model.Fingerprint]*types.Alert)}
+	dispatcher := NewDispatcher(alerts, route, recorder, marker, timeout, nil, logger, NewDispatcherMetrics(false, prometheus.NewRegistry()))
+	go dispatcher.Run()
+	defer dispatcher.Stop()
+
+	err = alerts.Put(
+		newAlert(model.LabelSet{"alertname": "HighErrorRate", "instance": "inst1"}),
+		newAlert(model.LabelSet{"alertname": "HighErrorRate", "instance": "inst2"}),
+		newAlert(model.LabelSet{"alertname": "HighLatency", "instance": "inst1"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Let alerts get processed.
+	for i := 0; len(recorder.Alerts()) != 3 && i < 10; i++ {
+		time.Sleep(200 * time.Millisecond)
+	}
+	require.Equal(t, 3, len(recorder.Alerts()))
+
+	stats := dispatcher.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, `{}:{alertname="HighErrorRate"}`, stats[0].GroupKey)
+	require.Equal(t, "prod", stats[0].Receiver)
+	require.Equal(t, model.LabelSet{"alertname": "HighErrorRate"}, stats[0].Labels)
+	require.Equal(t, 2, stats[0].Alerts)
+	require.Equal(t, 1, stats[1].Alerts)
+	// The groups have flushed and wait for the group interval.
+	for _, s := range stats {
+		require.True(t, s.NextFlush.After(time.Now().Add(30*time.Minute)), "next flush at %v", s.NextFlush)
+	}
+}
+
 type recordStage struct {
 	mtx    sync.RWMutex
 	alerts map[string]map[model.Fingerprint]*types.Alert
@@ -0,0 +1,163 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dispatch
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// Priorities limits the number of aggregation groups notifying at the same
+// time. Groups waiting to notify are served in order of the priority of
+// their alerts, and in the order they started waiting for equal priorities.
+type Priorities struct {
+	label  model.LabelName
+	values map[model.LabelValue]int
+
+	mtx     sync.Mutex
+	free    int
+	seq     uint64
+	waiting waitQueue
+}
+
+// NewPriorities returns new Priorities for the given configuration.
+func NewPriorities(c *config.DispatchPriority) *Priorities {
+	p := &Priorities{
+		label:  c.Label,
+		values: make(map[model.LabelValue]int, len(c.Values)),
+		free:   c.MaxConcurrentNotifications,
+	}
+	for i, v := range c.Values {
+		p.values[v] = i
+	}
+	return p
+}
+
+// priority returns the priority of the highest priority alert. Lower values
+// denote higher priorities.
+func (p *Priorities) priority(alerts []*types.Alert) int {
+	res := len(p.values)
+	for _, a := range alerts {
+		if i, ok := p.values[a.Labels[p.label]]; ok && i < res {
+			res = i
+		}
+	}
+	return res
+}
+
+// acquire blocks until the alerts may be notified about. The returned
+// function must be called once the notification is done. It returns an error
+// if the context is canceled before.
+func (p *Priorities) acquire(ctx context.Context, alerts []*types.Alert) (func(), error) {
+	p.mtx.Lock()
+	if p.free > 0 && p.waiting.Len() == 0 {
+		p.free--
+		p.mtx.Unlock()
+		return p.release, nil
+	}
+	w := &waiter{
+		priority: p.priority(alerts),
+		seq:      p.seq,
+		ready:    make(chan struct{}),
+	}
+	p.seq++
+	heap.Push(&p.waiting, w)
+	p.mtx.Unlock()
+
+	select {
+	case <-w.ready:
+		return p.release, nil
+	case <-ctx.Done():
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if w.index < 0 {
+		// The slot was handed over while the context was canceled.
+		p.releaseLocked()
+	} else {
+		heap.Remove(&p.waiting, w.index)
+	}
+	return nil, ctx.Err()
+}
+
+func (p *Priorities) release() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.releaseLocked()
+}
+
+// releaseLocked hands the slot over to the next waiter. p.mtx must be held.
+func (p *Priorities) releaseLocked() {
+	if p.waiting.Len() == 0 {
+		p.free++
+		return
+	}
+	w := heap.Pop(&p.waiting).(*waiter)
+	close(w.ready)
+}
+
+// queued returns the number of waiting groups.
+func (p *Priorities) queued() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.waiting.Len()
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	// index is the index in the heap, or -1 once removed.
+	index int
+}
+
+// waitQueue implements heap.Interface for waiters.
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority < q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
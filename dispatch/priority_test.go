@@ -0,0 +1,168 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dispatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/types"
+)
+
+func newPriorityAlert(name, severity string) *types.Alert {
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": model.LabelValue(name), "severity": model.LabelValue(severity)},
+			StartsAt: time.Now(),
+			EndsAt:   time.Now().Add(time.Hour),
+		},
+		UpdatedAt: time.Now(),
+	}
+}
+
+func waitQueued(t *testing.T, p *Priorities, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return p.queued() == n }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPrioritiesOrder(t *testing.T) {
+	p := NewPriorities(&config.DispatchPriority{
+		Label:                      "severity",
+		Values:                     []model.LabelValue{"critical", "warning"},
+		MaxConcurrentNotifications: 1,
+	})
+
+	release, err := p.acquire(context.Background(), []*types.Alert{newPriorityAlert("a", "warning")})
+	require.NoError(t, err)
+
+	order := make(chan string)
+	wait := func(name, severity string) {
+		release, err := p.acquire(context.Background(), []*types.Alert{newPriorityAlert(name, severity)})
+		require.NoError(t, err)
+		order <- name
+		release()
+	}
+	go wait("info", "info")
+	waitQueued(t, p, 1)
+	go wait("warning2", "warning")
+	waitQueued(t, p, 2)
+	go wait("warning1", "warning")
+	waitQueued(t, p, 3)
+	go wait("critical", "critical")
+	waitQueued(t, p, 4)
+
+	release()
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, <-order)
+	}
+	// Higher priorities go first, equal priorities in the order they
+	// started waiting.
+	require.Equal(t, []string{"critical", "warning2", "warning1", "info"}, got)
+}
+
+func TestPrioritiesCancel(t *testing.T) {
+	p := NewPriorities(&config.DispatchPriority{
+		Label:                      "severity",
+		Values:                     []model.LabelValue{"critical"},
+		MaxConcurrentNotifications: 1,
+	})
+
+	release, err := p.acquire(context.Background(), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		_, err := p.acquire(ctx, nil)
+		errc <- err
+	}()
+	waitQueued(t, p, 1)
+	cancel()
+	require.Equal(t, context.Canceled, <-errc)
+	require.Equal(t, 0, p.queued())
+
+	// The slot is available again once released.
+	release()
+	release, err = p.acquire(context.Background(), nil)
+	require.NoError(t, err)
+	release()
+}
+
+// blockingStage records the alert names of the notifications. The
+// notifications of alerts named "blocker" block until unblocked.
+type blockingStage struct {
+	notified  chan string
+	unblocked chan struct{}
+}
+
+func (s *blockingStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	name := string(alerts[0].Labels[model.AlertNameLabel])
+	s.notified <- name
+	if name == "blocker" {
+		<-s.unblocked
+	}
+	return ctx, alerts, nil
+}
+
+func TestDispatcherPriorities(t *testing.T) {
+	conf, err := config.Load(`
+route:
+  receiver: default
+  group_by: [alertname]
+  group_wait: 0s
+receivers:
+- name: default
+dispatch_priority:
+  label: severity
+  values: [critical, warning]
+  max_concurrent_notifications: 1
+`)
+	require.NoError(t, err)
+
+	logger := log.NewNopLogger()
+	marker := types.NewMarker(prometheus.NewRegistry())
+	alerts, err := mem.NewAlerts(context.Background(), marker, time.Hour, nil, logger)
+	require.NoError(t, err)
+	defer alerts.Close()
+
+	stage := &blockingStage{notified: make(chan string, 3), unblocked: make(chan struct{})}
+	timeout := func(d time.Duration) time.Duration { return d }
+	dispatcher := NewDispatcher(alerts, NewRoute(conf.Route, nil), stage, marker, timeout, nil, logger, NewDispatcherMetrics(false, prometheus.NewRegistry()))
+	priorities := NewPriorities(conf.DispatchPriority)
+	dispatcher.SetPriorities(priorities)
+	go dispatcher.Run()
+	defer dispatcher.Stop()
+
+	// The blocker occupies the only notification slot, so that the other
+	// groups queue up.
+	require.NoError(t, alerts.Put(newPriorityAlert("blocker", "info")))
+	require.Equal(t, "blocker", <-stage.notified)
+	require.NoError(t, alerts.Put(newPriorityAlert("warning", "warning")))
+	waitQueued(t, priorities, 1)
+	require.NoError(t, alerts.Put(newPriorityAlert("critical", "critical")))
+	waitQueued(t, priorities, 2)
+
+	close(stage.unblocked)
+	require.Equal(t, "critical", <-stage.notified)
+	require.Equal(t, "warning", <-stage.notified)
+}
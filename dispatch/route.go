@@ -24,6 +24,7 @@ import (
 
 	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
 )
 
 // DefaultRouteOpts are the defaulting routing options which apply
@@ -88,6 +89,15 @@ func NewRoute(cr *config.Route, parent *Route) *Route {
 	if cr.RepeatInterval != nil {
 		opts.RepeatInterval = time.Duration(*cr.RepeatInterval)
 	}
+	if cr.Escalations != nil {
+		opts.Escalations = make([]Escalation, 0, len(cr.Escalations))
+		for _, e := range cr.Escalations {
+			opts.Escalations = append(opts.Escalations, Escalation{
+				After:    time.Duration(e.After),
+				Receiver: e.Receiver,
+			})
+		}
+	}
 
 	// Build matchers.
 	var matchers labels.Matchers
@@ -167,6 +177,81 @@ func (r *Route) Match(lset model.LabelSet) []*Route {
 	return all
 }
 
+// MayMatch returns the routing nodes that may match alerts whose labels
+// satisfy all of the given matchers, for example the matchers of a silence.
+// The result is an approximation: a route is assumed to match unless one of
+// its matchers contradicts an equality matcher on the same label, and a route
+// is only known to match all such alerts if each of its matchers is satisfied
+// by an equality matcher. Thus the result may contain routes that no alert
+// satisfying the matchers reaches, but it contains all routes that they do
+// reach.
+func (r *Route) MayMatch(ms labels.Matchers) []*Route {
+	if !mayIntersect(r.Matchers, ms) {
+		return nil
+	}
+
+	var (
+		all      []*Route
+		captured bool
+	)
+	for _, cr := range r.Routes {
+		all = append(all, cr.MayMatch(ms)...)
+
+		if implies(ms, cr.Matchers) {
+			// All alerts reach this child, so none stay with the current
+			// node nor, unless the child continues, with later children.
+			captured = true
+			if !cr.Continue {
+				break
+			}
+		}
+	}
+
+	// Alerts not matching any child node stay with the current node.
+	if !captured {
+		all = append(all, r)
+	}
+
+	return all
+}
+
+// mayIntersect returns false if no label set satisfies both the matchers of a
+// route and the given matchers because they contradict each other.
+func mayIntersect(route, ms labels.Matchers) bool {
+	for _, rm := range route {
+		for _, m := range ms {
+			if m.Name != rm.Name {
+				continue
+			}
+			if rm.Type == labels.MatchEqual && !m.Matches(rm.Value) {
+				return false
+			}
+			if m.Type == labels.MatchEqual && !rm.Matches(m.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// implies returns true if all label sets satisfying the given matchers satisfy
+// the matchers of a route.
+func implies(ms, route labels.Matchers) bool {
+	for _, rm := range route {
+		satisfied := false
+		for _, m := range ms {
+			if m.Name == rm.Name && m.Type == labels.MatchEqual && rm.Matches(m.Value) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
+
 // Key returns a key for the route. It does not uniquely identify the route in general.
 func (r *Route) Key() string {
 	b := strings.Builder{}
@@ -179,6 +264,19 @@ func (r *Route) Key() string {
 	return b.String()
 }
 
+// Path returns the routing nodes from the root of the route tree down to the
+// route itself.
+func (r *Route) Path() []*Route {
+	var path []*Route
+	for ; r != nil; r = r.parent {
+		path = append(path, r)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
 // Walk traverses the route tree in depth-first order.
 func (r *Route) Walk(visit func(*Route)) {
 	visit(r)
@@ -210,6 +308,22 @@ type RouteOpts struct {
 
 	// A list of time intervals for which the route is muted.
 	MuteTimeIntervals []string
+
+	// Receivers additionally notified about alerts which have been firing
+	// for a given duration.
+	Escalations []Escalation
+}
+
+// Escalation holds a receiver which is notified about the alerts of a group
+// that have been firing for longer than After.
+type Escalation struct {
+	After    time.Duration
+	Receiver string
+}
+
+// at returns the time at which the alert escalates.
+func (e Escalation) at(a *types.Alert) time.Time {
+	return a.StartsAt.Add(e.After)
 }
 
 func (ro *RouteOpts) String() string {
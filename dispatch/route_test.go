@@ -23,6 +23,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
 )
 
 func TestRouteMatch(t *testing.T) {
@@ -268,6 +269,115 @@ routes:
 	}
 }
 
+func TestRouteMayMatch(t *testing.T) {
+	in := `
+receiver: 'notify-def'
+
+routes:
+- match:
+    owner: 'team-A'
+
+  receiver: 'notify-A'
+
+  routes:
+  - match:
+      env: 'testing'
+
+    receiver: 'notify-testing'
+    group_by: [...]
+
+  - match:
+      env: "production"
+
+    receiver: 'notify-productionA'
+    group_wait: 1m
+
+    continue: true
+
+  - match_re:
+      env: "produ.*"
+      job: ".*"
+
+    receiver: 'notify-productionB'
+    group_wait: 30s
+    group_interval: 5m
+    repeat_interval: 1h
+    group_by: ['job']
+
+
+- match_re:
+    owner: 'team-(B|C)'
+
+  group_by: ['foo', 'bar']
+  group_wait: 2m
+  receiver: 'notify-BC'
+
+- match:
+    group_by: 'role'
+  group_by: ['role']
+
+  routes:
+  - match:
+      env: 'testing'
+    receiver: 'notify-testing'
+    routes:
+    - match:
+        wait: 'long'
+      group_wait: 2m
+`
+
+	var ctree config.Route
+	if err := yaml.UnmarshalStrict([]byte(in), &ctree); err != nil {
+		t.Fatal(err)
+	}
+	tree := NewRoute(&ctree, nil)
+
+	tests := []struct {
+		matchers string
+		result   []string
+	}{
+		{
+			// Alerts are known to reach a single route.
+			matchers: `{owner="team-A",env="testing"}`,
+			result:   []string{"notify-testing"},
+		},
+		{
+			// Alerts may reach any child route or stay with their parent.
+			matchers: `{owner="team-A"}`,
+			result:   []string{"notify-testing", "notify-productionA", "notify-productionB", "notify-A"},
+		},
+		{
+			matchers: `{owner=~"team-.*"}`,
+			result: []string{
+				"notify-testing", "notify-productionA", "notify-productionB", "notify-A",
+				"notify-BC",
+				"notify-testing", "notify-testing", "notify-def",
+				"notify-def",
+			},
+		},
+		{
+			// Routes contradicting the matchers are left out.
+			matchers: `{owner="team-Z",group_by!="role"}`,
+			result:   []string{"notify-def"},
+		},
+	}
+
+	for _, test := range tests {
+		ms, err := labels.ParseMatchers(test.matchers)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, r := range tree.MayMatch(ms) {
+			got = append(got, r.RouteOpts.Receiver)
+		}
+		if !reflect.DeepEqual(got, test.result) {
+			t.Errorf("%s: \nexpected:\n%v\ngot:\n%v", test.matchers, test.result, got)
+		}
+	}
+}
+
 func TestRouteWalk(t *testing.T) {
 	in := `
 receiver: 'notify-def'
@@ -353,6 +463,58 @@ routes:
 	}
 }
 
+func TestRoutePath(t *testing.T) {
+	in := `
+receiver: 'notify-def'
+
+routes:
+- match:
+    owner: 'team-A'
+  receiver: 'notify-A'
+  routes:
+  - match:
+      env: 'testing'
+    receiver: 'notify-testing'
+`
+
+	var ctree config.Route
+	if err := yaml.UnmarshalStrict([]byte(in), &ctree); err != nil {
+		t.Fatal(err)
+	}
+	tree := NewRoute(&ctree, nil)
+
+	for _, tc := range []struct {
+		lset     model.LabelSet
+		expected []string
+	}{
+		{
+			lset:     model.LabelSet{"owner": "team-B"},
+			expected: []string{"notify-def"},
+		},
+		{
+			lset:     model.LabelSet{"owner": "team-A"},
+			expected: []string{"notify-def", "notify-A"},
+		},
+		{
+			lset:     model.LabelSet{"owner": "team-A", "env": "testing"},
+			expected: []string{"notify-def", "notify-A", "notify-testing"},
+		},
+	} {
+		matches := tree.Match(tc.lset)
+		if len(matches) != 1 {
+			t.Fatalf("expected one match for %v, got %d", tc.lset, len(matches))
+		}
+
+		var got []string
+		for _, r := range matches[0].Path() {
+			got = append(got, r.RouteOpts.Receiver)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("\nexpected:\n%v\ngot:\n%v", tc.expected, got)
+		}
+	}
+}
+
 func TestInheritParentGroupByAll(t *testing.T) {
 	in := `
 routes:
@@ -15,6 +15,7 @@ package inhibit
 
 import (
 	"context"
+	"regexp"
 	"sync"
 	"time"
 
@@ -160,6 +161,10 @@ type InhibitRule struct {
 	// A set of label names whose label values need to be identical in source and
 	// target alerts in order for the inhibition to take effect.
 	Equal map[model.LabelName]struct{}
+	// A set of label names whose label values need to match the regular
+	// expression in source and target alerts and yield identical capture
+	// groups in order for the inhibition to take effect.
+	EqualRegex map[model.LabelName]*regexp.Regexp
 
 	// Cache of alerts matching source labels.
 	scache *store.Alerts
@@ -217,15 +222,39 @@ func NewInhibitRule(cr *config.InhibitRule) *InhibitRule {
 	for _, ln := range cr.Equal {
 		equal[ln] = struct{}{}
 	}
+	equalRegex := map[model.LabelName]*regexp.Regexp{}
+	for ln, re := range cr.EqualRegex {
+		equalRegex[model.LabelName(ln)] = re.Regexp
+	}
 
 	return &InhibitRule{
 		SourceMatchers: sourcem,
 		TargetMatchers: targetm,
 		Equal:          equal,
+		EqualRegex:     equalRegex,
 		scache:         store.NewAlerts(),
 	}
 }
 
+// regexEqual returns true if both values match the regular expression and
+// have the same capture groups, or are the same if it has none.
+func regexEqual(re *regexp.Regexp, a, b model.LabelValue) bool {
+	ma := re.FindStringSubmatch(string(a))
+	mb := re.FindStringSubmatch(string(b))
+	if ma == nil || mb == nil {
+		return false
+	}
+	if len(ma) > 1 {
+		ma, mb = ma[1:], mb[1:]
+	}
+	for i := range ma {
+		if ma[i] != mb[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // hasEqual checks whether the source cache contains alerts matching the equal
 // labels for the given label set. If so, the fingerprint of one of those alerts
 // is returned. If excludeTwoSidedMatch is true, alerts that match both the
@@ -242,6 +271,11 @@ Outer:
 				continue Outer
 			}
 		}
+		for n, re := range r.EqualRegex {
+			if !regexEqual(re, a.Labels[n], lset[n]) {
+				continue Outer
+			}
+		}
 		if excludeTwoSidedMatch && r.TargetMatchers.Matches(a.Labels) {
 			continue Outer
 		}
@@ -14,6 +14,7 @@
 package inhibit
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
@@ -138,6 +139,95 @@ func TestInhibitRuleHasEqual(t *testing.T) {
 	}
 }
 
+func TestInhibitRuleHasEqualRegex(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	source := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"instance": "db1:9100", "env": "prod-eu"},
+			StartsAt: now.Add(-time.Minute),
+			EndsAt:   now.Add(time.Hour),
+		},
+	}
+	cases := []struct {
+		equal      model.LabelNames
+		equalRegex map[model.LabelName]string
+		input      model.LabelSet
+		result     bool
+	}{
+		{
+			// Exact matching is the default.
+			equal:  model.LabelNames{"instance"},
+			input:  model.LabelSet{"instance": "db1:9187"},
+			result: false,
+		},
+		{
+			equal:  model.LabelNames{"instance"},
+			input:  model.LabelSet{"instance": "db1:9100"},
+			result: true,
+		},
+		{
+			// Capture groups are compared.
+			equalRegex: map[model.LabelName]string{"instance": "([^:]+):.*"},
+			input:      model.LabelSet{"instance": "db1:9187"},
+			result:     true,
+		},
+		{
+			equalRegex: map[model.LabelName]string{"instance": "([^:]+):.*"},
+			input:      model.LabelSet{"instance": "db2:9100"},
+			result:     false,
+		},
+		{
+			// Values not matching the regular expression don't correlate.
+			equalRegex: map[model.LabelName]string{"instance": "([^:]+):.*"},
+			input:      model.LabelSet{"instance": "db1"},
+			result:     false,
+		},
+		{
+			equalRegex: map[model.LabelName]string{"instance": "([^:]+):.*", "env": "([a-z]+)-.*"},
+			input:      model.LabelSet{"instance": "db1:9187", "env": "prod-us"},
+			result:     true,
+		},
+		{
+			equal:      model.LabelNames{"env"},
+			equalRegex: map[model.LabelName]string{"instance": "([^:]+):.*"},
+			input:      model.LabelSet{"instance": "db1:9187", "env": "prod-us"},
+			result:     false,
+		},
+		{
+			// Without capture groups the whole values are compared.
+			equalRegex: map[model.LabelName]string{"env": "prod-.*"},
+			input:      model.LabelSet{"env": "prod-us"},
+			result:     false,
+		},
+		{
+			equalRegex: map[model.LabelName]string{"env": "prod-.*"},
+			input:      model.LabelSet{"env": "prod-eu"},
+			result:     true,
+		},
+	}
+
+	for _, c := range cases {
+		r := &InhibitRule{
+			Equal:      map[model.LabelName]struct{}{},
+			EqualRegex: map[model.LabelName]*regexp.Regexp{},
+			scache:     store.NewAlerts(),
+		}
+		for _, ln := range c.equal {
+			r.Equal[ln] = struct{}{}
+		}
+		for ln, re := range c.equalRegex {
+			r.EqualRegex[ln] = regexp.MustCompile("^(?:" + re + ")$")
+		}
+		r.scache.Set(source)
+
+		if _, have := r.hasEqual(c.input, false); have != c.result {
+			t.Errorf("Unexpected result %t for %v, expected %t", have, c.input, c.result)
+		}
+	}
+}
+
 func TestInhibitRuleMatches(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,288 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	commoncfg "github.com/prometheus/common/config"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// matchersProperty is the iCalendar property of an event holding additional
+// matchers for the alerts suppressed during the event.
+const matchersProperty = "X-ALERTMANAGER-MATCHERS"
+
+// Calendar is a Source reading maintenance windows from the events of an
+// iCalendar feed. The feed is fetched periodically by Run.
+type Calendar struct {
+	conf   *config.MaintenanceCalendar
+	client *http.Client
+	logger log.Logger
+
+	mtx     sync.RWMutex
+	windows []Window
+}
+
+// NewCalendar returns a new Calendar for the given configuration.
+func NewCalendar(conf *config.MaintenanceCalendar, l log.Logger) (*Calendar, error) {
+	client, err := commoncfg.NewClientFromConfig(*conf.HTTPConfig, "maintenance_calendar")
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		l = log.NewNopLogger()
+	}
+	return &Calendar{
+		conf:   conf,
+		client: client,
+		logger: log.With(l, "url", conf.URL.String()),
+	}, nil
+}
+
+// Run fetches the feed immediately and then at the configured refresh
+// interval until the context is canceled. Failed fetches are logged and keep
+// the previously fetched windows in place.
+func (c *Calendar) Run(ctx context.Context) {
+	t := time.NewTicker(time.Duration(c.conf.RefreshInterval))
+	defer t.Stop()
+
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			level.Error(c.logger).Log("msg", "Failed to refresh maintenance calendar", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Refresh fetches the feed and replaces the known maintenance windows.
+func (c *Calendar) Refresh(ctx context.Context) error {
+	req, err := http.NewRequest(http.MethodGet, c.conf.URL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+
+	windows, err := ParseICal(resp.Body, c.conf.Matchers)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	c.windows = windows
+	c.mtx.Unlock()
+	return nil
+}
+
+// Active implements the Source interface.
+func (c *Calendar) Active(t time.Time) []Window {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	var res []Window
+	for _, w := range c.windows {
+		if w.Active(t) {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// ParseICal returns the maintenance windows defined by the events of an
+// iCalendar feed. The given matchers apply to all windows, in addition to
+// the matchers of the X-ALERTMANAGER-MATCHERS property of each event.
+//
+// Only the first occurrence of recurring events is considered, and
+// cancelled events and events without an end are skipped.
+func ParseICal(r io.Reader, matchers []*labels.Matcher) ([]Window, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		windows []Window
+		event   map[string]property
+	)
+	for i, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			event = map[string]property{}
+		case p.name == "END" && p.value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: unexpected end of event", i+1)
+			}
+			w, ok, err := eventWindow(event, matchers)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if ok {
+				windows = append(windows, w)
+			}
+			event = nil
+		case event != nil:
+			event[p.name] = p
+		}
+	}
+	return windows, nil
+}
+
+// eventWindow returns the maintenance window of an event. It returns false
+// if the event doesn't define a window.
+func eventWindow(event map[string]property, matchers []*labels.Matcher) (Window, bool, error) {
+	if strings.EqualFold(event["STATUS"].value, "CANCELLED") {
+		return Window{}, false, nil
+	}
+	start, ok := event["DTSTART"]
+	if !ok {
+		return Window{}, false, nil
+	}
+	w := Window{Matchers: append(labels.Matchers{}, matchers...)}
+
+	var err error
+	if w.StartsAt, err = start.time(); err != nil {
+		return Window{}, false, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+	if end, ok := event["DTEND"]; ok {
+		if w.EndsAt, err = end.time(); err != nil {
+			return Window{}, false, fmt.Errorf("invalid DTEND: %w", err)
+		}
+	} else if start.params["VALUE"] == "DATE" {
+		// An all-day event without an end lasts for the whole day.
+		w.EndsAt = w.StartsAt.AddDate(0, 0, 1)
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return Window{}, false, nil
+	}
+
+	if m, ok := event[matchersProperty]; ok {
+		ms, err := labels.ParseMatchers(unescapeText(m.value))
+		if err != nil {
+			return Window{}, false, fmt.Errorf("invalid %s: %w", matchersProperty, err)
+		}
+		w.Matchers = append(w.Matchers, ms...)
+	}
+	return w, true, nil
+}
+
+// unfold reads the content lines of an iCalendar feed, joining lines that
+// were folded as described in RFC 5545, section 3.1.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, s.Err()
+}
+
+// property is an iCalendar content line.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseProperty(line string) (property, error) {
+	// The value starts after the first colon that is not part of a
+	// quoted parameter value.
+	quoted := false
+	idx := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return property{}, fmt.Errorf("missing value in %q", line)
+	}
+
+	parts := strings.Split(line[:idx], ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[idx+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return property{}, fmt.Errorf("invalid parameter %q", param)
+		}
+		p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return p, nil
+}
+
+// time parses the value of a DATE or DATE-TIME property. Floating times
+// without a time zone are interpreted in the local time zone.
+func (p property) time() (time.Time, error) {
+	loc := time.Local
+	if tzid, ok := p.params["TZID"]; ok {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if p.params["VALUE"] == "DATE" {
+		return time.ParseInLocation("20060102", p.value, loc)
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		return time.Parse("20060102T150405Z", p.value)
+	}
+	return time.ParseInLocation("20060102T150405", p.value, loc)
+}
+
+// unescapeText reverses the escaping of TEXT values described in RFC 5545,
+// section 3.3.11.
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n").Replace(s)
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/config"
+)
+
+const testFeed = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Maintenance//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1@example.com\r\n" +
+	"SUMMARY:Database upgrade\r\n" +
+	"DTSTART:20210601T100000Z\r\n" +
+	"DTEND:20210601T120000Z\r\n" +
+	"X-ALERTMANAGER-MATCHERS:service=\"db\"\\,severity!=\"criti\r\n" +
+	" cal\"\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2@example.com\r\n" +
+	"SUMMARY:Network maintenance\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20210602T020000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20210602T040000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3@example.com\r\n" +
+	"SUMMARY:Datacenter move\r\n" +
+	"DTSTART;VALUE=DATE:20210605\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:4@example.com\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20210601T100000Z\r\n" +
+	"DTEND:20210601T120000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICal(t *testing.T) {
+	windows, err := ParseICal(strings.NewReader(testFeed), mustParseMatchers(t, `env="prod"`))
+	require.NoError(t, err)
+	require.Len(t, windows, 3)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	require.Equal(t, `{env="prod",service="db",severity!="critical"}`, windows[0].Matchers.String())
+	require.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), windows[0].StartsAt)
+	require.Equal(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC), windows[0].EndsAt)
+
+	require.Equal(t, `{env="prod"}`, windows[1].Matchers.String())
+	require.True(t, time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC).Equal(windows[1].StartsAt))
+	require.True(t, time.Date(2021, 6, 2, 4, 0, 0, 0, berlin).Equal(windows[1].EndsAt))
+
+	// All-day events without an end last a day.
+	require.True(t, time.Date(2021, 6, 5, 0, 0, 0, 0, time.Local).Equal(windows[2].StartsAt))
+	require.True(t, time.Date(2021, 6, 6, 0, 0, 0, 0, time.Local).Equal(windows[2].EndsAt))
+}
+
+func TestParseICalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		feed string
+		err  string
+	}{
+		{
+			feed: "BEGIN:VEVENT\nDTSTART:2021-06-01\nDTEND:20210601T120000Z\nEND:VEVENT\n",
+			err:  `line 4: invalid DTSTART: parsing time "2021-06-01"`,
+		},
+		{
+			feed: "BEGIN:VEVENT\nDTSTART:20210601T100000Z\nDTEND:20210601T120000Z\nX-ALERTMANAGER-MATCHERS:foo=~\"(\"\nEND:VEVENT\n",
+			err:  "line 5: invalid X-ALERTMANAGER-MATCHERS: error parsing regexp: missing closing ): `^(?:()$`",
+		},
+		{
+			feed: "BEGIN:VEVENT\nSUMMARY\nEND:VEVENT\n",
+			err:  `line 2: missing value in "SUMMARY"`,
+		},
+		{
+			feed: "END:VEVENT\n",
+			err:  "line 1: unexpected end of event",
+		},
+	} {
+		_, err := ParseICal(strings.NewReader(tc.feed), nil)
+		require.Error(t, err)
+		require.True(t, strings.HasPrefix(err.Error(), tc.err), "unexpected error %q", err)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	feed := testFeed
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if feed == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(feed))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	c, err := NewCalendar(&config.MaintenanceCalendar{
+		URL:             &config.URL{URL: u},
+		HTTPConfig:      &commoncfg.HTTPClientConfig{},
+		RefreshInterval: model.Duration(time.Minute),
+	}, nil)
+	require.NoError(t, err)
+
+	at := time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC)
+	require.Empty(t, c.Active(at))
+
+	require.NoError(t, c.Refresh(context.Background()))
+	active := c.Active(at)
+	require.Len(t, active, 1)
+	require.Equal(t, `{service="db",severity!="critical"}`, active[0].Matchers.String())
+	require.Empty(t, c.Active(at.Add(time.Hour)))
+
+	// Failed refreshes keep the known windows.
+	feed = ""
+	require.EqualError(t, c.Refresh(context.Background()), "unexpected status code 500")
+	require.Len(t, c.Active(at), 1)
+
+	m := NewMuter(c)
+	m.now = func() time.Time { return at }
+	require.True(t, m.Mutes(model.LabelSet{"service": "db", "severity": "warning"}))
+	require.False(t, m.Mutes(model.LabelSet{"service": "db", "severity": "critical"}))
+}
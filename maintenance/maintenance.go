@@ -0,0 +1,77 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance suppresses alerts during planned maintenance windows
+// provided by external sources.
+package maintenance
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// Window is a planned maintenance window. Alerts matching all of its
+// matchers are suppressed between StartsAt and EndsAt.
+type Window struct {
+	Matchers labels.Matchers
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// Active returns true if the window is active at the given time.
+func (w Window) Active(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// Source provides maintenance windows.
+type Source interface {
+	// Active returns the maintenance windows active at the given time.
+	Active(t time.Time) []Window
+}
+
+// NopSource is a Source without any maintenance windows.
+type NopSource struct{}
+
+// Active implements the Source interface.
+func (NopSource) Active(time.Time) []Window { return nil }
+
+// Muter implements the types.Muter interface. It mutes alerts matching a
+// maintenance window that is currently active in any of its sources.
+type Muter struct {
+	sources []Source
+	now     func() time.Time
+}
+
+// NewMuter returns a new Muter consulting the given sources.
+func NewMuter(sources ...Source) *Muter {
+	return &Muter{
+		sources: sources,
+		now:     time.Now,
+	}
+}
+
+// Mutes implements the types.Muter interface.
+func (m *Muter) Mutes(lset model.LabelSet) bool {
+	now := m.now()
+	for _, s := range m.sources {
+		for _, w := range s.Active(now) {
+			if w.Matchers.Matches(lset) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// fakeSource is a Source returning a fixed set of windows.
+type fakeSource []Window
+
+func (f fakeSource) Active(t time.Time) []Window {
+	var res []Window
+	for _, w := range f {
+		if w.Active(t) {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+func mustParseMatchers(t *testing.T, s string) labels.Matchers {
+	t.Helper()
+	ms, err := labels.ParseMatchers(s)
+	require.NoError(t, err)
+	return ms
+}
+
+func TestMuter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	db := fakeSource{
+		{
+			Matchers: mustParseMatchers(t, `{service="db"}`),
+			StartsAt: now.Add(-time.Hour),
+			EndsAt:   now.Add(time.Hour),
+		},
+		{
+			Matchers: mustParseMatchers(t, `{service="web"}`),
+			StartsAt: now.Add(time.Hour),
+			EndsAt:   now.Add(2 * time.Hour),
+		},
+	}
+	cluster := fakeSource{
+		{
+			Matchers: mustParseMatchers(t, `{cluster="eu-1",severity!="critical"}`),
+			StartsAt: now,
+			EndsAt:   now.Add(time.Minute),
+		},
+	}
+
+	m := NewMuter(NopSource{}, db, cluster)
+	m.now = func() time.Time { return now }
+
+	for _, tc := range []struct {
+		lset  model.LabelSet
+		muted bool
+	}{
+		{
+			lset:  model.LabelSet{"alertname": "DBDown", "service": "db"},
+			muted: true,
+		},
+		{
+			// The window for web hasn't started yet.
+			lset:  model.LabelSet{"alertname": "WebDown", "service": "web"},
+			muted: false,
+		},
+		{
+			lset:  model.LabelSet{"alertname": "NodeDown", "cluster": "eu-1", "severity": "warning"},
+			muted: true,
+		},
+		{
+			lset:  model.LabelSet{"alertname": "NodeDown", "cluster": "eu-1", "severity": "critical"},
+			muted: false,
+		},
+		{
+			lset:  model.LabelSet{"alertname": "NodeDown", "cluster": "us-1"},
+			muted: false,
+		},
+	} {
+		require.Equal(t, tc.muted, m.Mutes(tc.lset), "%s", tc.lset)
+	}
+
+	// Windows end exclusively.
+	m.now = func() time.Time { return now.Add(time.Hour) }
+	require.False(t, m.Mutes(model.LabelSet{"service": "db"}))
+	require.True(t, m.Mutes(model.LabelSet{"service": "web"}))
+}
+
+func TestMuterWithoutSources(t *testing.T) {
+	require.False(t, NewMuter().Mutes(model.LabelSet{"alertname": "a"}))
+	require.False(t, NewMuter(NopSource{}).Mutes(model.LabelSet{"alertname": "a"}))
+}
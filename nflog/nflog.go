@@ -24,6 +24,7 @@ import (
 	"io"
 	"math/rand"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -494,6 +495,39 @@ func (l *Log) Query(params ...QueryParam) ([]*pb.Entry, error) {
 	return entries, err
 }
 
+// Entries returns the log entries for the given group key and receiver name,
+// ordered by group key and receiver. Empty arguments match all entries.
+func (l *Log) Entries(groupKey, receiver string) []*pb.Entry {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	var entries []*pb.Entry
+	for _, le := range l.st {
+		e := le.Entry
+		if groupKey != "" && string(e.GroupKey) != groupKey {
+			continue
+		}
+		if receiver != "" && e.Receiver.GroupName != receiver {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if gi, gj := string(entries[i].GroupKey), string(entries[j].GroupKey); gi != gj {
+			return gi < gj
+		}
+		ri, rj := entries[i].Receiver, entries[j].Receiver
+		if ri.GroupName != rj.GroupName {
+			return ri.GroupName < rj.GroupName
+		}
+		if ri.Integration != rj.Integration {
+			return ri.Integration < rj.Integration
+		}
+		return ri.Idx < rj.Idx
+	})
+	return entries
+}
+
 // loadSnapshot loads a snapshot generated by Snapshot() into the state.
 func (l *Log) loadSnapshot(r io.Reader) error {
 	st, err := decodeState(r)
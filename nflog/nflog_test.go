@@ -342,3 +342,47 @@ func TestStateDecodingError(t *testing.T) {
 	_, err = decodeState(bytes.NewReader(msg))
 	require.Equal(t, ErrInvalidState, err)
 }
+
+func TestEntries(t *testing.T) {
+	nl, err := New(WithRetention(time.Hour))
+	require.NoError(t, err, "constructing nflog failed")
+
+	require.Empty(t, nl.Entries("", ""))
+
+	webhook := &pb.Receiver{GroupName: "team-X", Integration: "webhook", Idx: 0}
+	email := &pb.Receiver{GroupName: "team-X", Integration: "email", Idx: 0}
+	other := &pb.Receiver{GroupName: "team-Y", Integration: "webhook", Idx: 0}
+	require.NoError(t, nl.Log(webhook, "key1", []uint64{1}, nil))
+	require.NoError(t, nl.Log(email, "key1", []uint64{1}, nil))
+	require.NoError(t, nl.Log(other, "key1", nil, []uint64{1}))
+	require.NoError(t, nl.Log(webhook, "key2", []uint64{2}, nil))
+
+	receivers := func(entries []*pb.Entry) []string {
+		res := []string{}
+		for _, e := range entries {
+			res = append(res, string(e.GroupKey)+":"+receiverKey(e.Receiver))
+		}
+		return res
+	}
+
+	require.Equal(t, []string{
+		"key1:team-X/email/0",
+		"key1:team-X/webhook/0",
+		"key1:team-Y/webhook/0",
+		"key2:team-X/webhook/0",
+	}, receivers(nl.Entries("", "")))
+	require.Equal(t, []string{
+		"key1:team-X/email/0",
+		"key1:team-X/webhook/0",
+		"key1:team-Y/webhook/0",
+	}, receivers(nl.Entries("key1", "")))
+	require.Equal(t, []string{
+		"key1:team-X/email/0",
+		"key1:team-X/webhook/0",
+		"key2:team-X/webhook/0",
+	}, receivers(nl.Entries("", "team-X")))
+	require.Equal(t, []string{
+		"key2:team-X/webhook/0",
+	}, receivers(nl.Entries("key2", "team-X")))
+	require.Empty(t, nl.Entries("key2", "team-Y"))
+}
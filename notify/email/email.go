@@ -114,18 +114,31 @@ func (n *Email) auth(mechs string) (smtp.Auth, error) {
 	return nil, err
 }
 
-// Notify implements the Notifier interface.
-func (n *Email) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+// Check connects to the smarthost of the given email configuration and
+// negotiates TLS and authentication in the same way as Notify, but doesn't
+// send any email.
+func Check(ctx context.Context, c *config.EmailConfig, l log.Logger) error {
+	n := &Email{conf: c, logger: l}
+	client, _, err := n.connect(ctx)
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// connect establishes an SMTP session with the smarthost, upgrading it to
+// TLS and authenticating as configured. It returns a flag whether the error
+// is recoverable.
+func (n *Email) connect(ctx context.Context) (*smtp.Client, bool, error) {
 	var (
-		c       *smtp.Client
-		conn    net.Conn
-		err     error
-		success = false
+		c    *smtp.Client
+		conn net.Conn
+		err  error
 	)
 	if n.conf.Smarthost.Port == "465" {
 		tlsConfig, err := commoncfg.NewTLSConfig(&n.conf.TLSConfig)
 		if err != nil {
-			return false, errors.Wrap(err, "parse TLS configuration")
+			return nil, false, errors.Wrap(err, "parse TLS configuration")
 		}
 		if tlsConfig.ServerName == "" {
 			tlsConfig.ServerName = n.conf.Smarthost.Host
@@ -133,7 +146,7 @@ func (n *Email) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 
 		conn, err = tls.Dial("tcp", n.conf.Smarthost.String(), tlsConfig)
 		if err != nil {
-			return true, errors.Wrap(err, "establish TLS connection to server")
+			return nil, true, errors.Wrap(err, "establish TLS connection to server")
 		}
 	} else {
 		var (
@@ -142,23 +155,29 @@ func (n *Email) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 		)
 		conn, err = d.DialContext(ctx, "tcp", n.conf.Smarthost.String())
 		if err != nil {
-			return true, errors.Wrap(err, "establish connection to server")
+			return nil, true, errors.Wrap(err, "establish connection to server")
 		}
 	}
 	c, err = smtp.NewClient(conn, n.conf.Smarthost.Host)
 	if err != nil {
 		conn.Close()
-		return true, errors.Wrap(err, "create SMTP client")
+		return nil, true, errors.Wrap(err, "create SMTP client")
 	}
-	defer func() {
-		// Try to clean up after ourselves but don't log anything if something has failed.
-		if err := c.Quit(); success && err != nil {
-			level.Warn(n.logger).Log("msg", "failed to close SMTP connection", "err", err)
-		}
-	}()
 
+	retry, err := n.negotiate(c)
+	if err != nil {
+		// Try to clean up after ourselves but don't log anything as something has failed.
+		c.Quit()
+		return nil, retry, err
+	}
+	return c, false, nil
+}
+
+// negotiate sends the EHLO command and performs the STARTTLS and AUTH
+// exchanges on a freshly opened SMTP session.
+func (n *Email) negotiate(c *smtp.Client) (bool, error) {
 	if n.conf.Hello != "" {
-		err = c.Hello(n.conf.Hello)
+		err := c.Hello(n.conf.Hello)
 		if err != nil {
 			return true, errors.Wrap(err, "send EHLO command")
 		}
@@ -194,6 +213,23 @@ func (n *Email) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 			}
 		}
 	}
+	return false, nil
+}
+
+// Notify implements the Notifier interface.
+func (n *Email) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var success = false
+
+	c, retry, err := n.connect(ctx)
+	if err != nil {
+		return retry, err
+	}
+	defer func() {
+		// Try to clean up after ourselves but don't log anything if something has failed.
+		if err := c.Quit(); success && err != nil {
+			level.Warn(n.logger).Log("msg", "failed to close SMTP connection", "err", err)
+		}
+	}()
 
 	var (
 		tmplErr error
@@ -32,7 +32,9 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
 	"strings"
@@ -606,3 +608,97 @@ func TestEmailNoUsernameStillOk(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, a)
 }
+
+// fakeSMTPServer starts an SMTP server which only understands the EHLO and
+// QUIT commands and advertises the given extensions.
+func fakeSMTPServer(t *testing.T, extensions ...string) config.HostPort {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				tc := textproto.NewConn(conn)
+				tc.PrintfLine("220 localhost ESMTP")
+				for {
+					line, err := tc.ReadLine()
+					if err != nil {
+						return
+					}
+					switch strings.ToUpper(strings.Fields(line)[0]) {
+					case "EHLO":
+						lines := append([]string{"localhost"}, extensions...)
+						for i, l := range lines {
+							sep := "-"
+							if i == len(lines)-1 {
+								sep = " "
+							}
+							tc.PrintfLine("250%s%s", sep, l)
+						}
+					case "QUIT":
+						tc.PrintfLine("221 Bye")
+						return
+					default:
+						tc.PrintfLine("502 Command not implemented")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	return config.HostPort{Host: host, Port: port}
+}
+
+func TestEmailCheck(t *testing.T) {
+	requireTLS, noTLS := true, false
+	for _, tc := range []struct {
+		title      string
+		extensions []string
+		requireTLS *bool
+		username   string
+
+		errMsg string
+	}{
+		{
+			title:      "plaintext session",
+			requireTLS: &noTLS,
+		},
+		{
+			title:      "STARTTLS required but not advertised",
+			requireTLS: &requireTLS,
+			errMsg:     "does not advertise the STARTTLS extension",
+		},
+		{
+			title:      "unknown auth mechanism",
+			extensions: []string{"AUTH XOAUTH2"},
+			requireTLS: &noTLS,
+			username:   "user",
+			errMsg:     "unknown auth mechanism: XOAUTH2",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			cfg := &config.EmailConfig{
+				Smarthost:    fakeSMTPServer(t, tc.extensions...),
+				Hello:        "localhost",
+				RequireTLS:   tc.requireTLS,
+				AuthUsername: tc.username,
+			}
+			err := Check(context.Background(), cfg, log.NewNopLogger())
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package msteams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/notify/test"
+	"github.com/prometheus/alertmanager/types"
+)
+
+func newConfig(u *url.URL) *config.MSTeamsConfig {
+	c := config.DefaultMSTeamsConfig
+	c.WebhookURL = &config.SecretURL{URL: u}
+	c.HTTPConfig = &commoncfg.HTTPClientConfig{}
+	return &c
+}
+
+func TestMSTeamsRetry(t *testing.T) {
+	notifier, err := New(newConfig(nil), test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	for statusCode, expected := range test.RetryTests(append(test.DefaultRetryCodes(), http.StatusTooManyRequests)) {
+		actual, _ := notifier.retrier.Check(statusCode, nil)
+		require.Equal(t, expected, actual, fmt.Sprintf("error on status %d", statusCode))
+	}
+}
+
+func TestMSTeamsRedactedURL(t *testing.T) {
+	ctx, u, fn := test.GetContextWithCancelingURL()
+	defer fn()
+
+	notifier, err := New(newConfig(u), test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	test.AssertNotifyLeaksNoSecret(t, ctx, notifier, u.String())
+}
+
+func TestMSTeamsNotify(t *testing.T) {
+	var got message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte("1"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	conf := newConfig(u)
+	conf.Text = `{{ .CommonLabels.alertname }} on {{ .CommonLabels.instance }}`
+	notifier, err := New(conf, test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "HighLatency"})
+	retry, err := notifier.Notify(ctx, &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighLatency", "instance": "web-1"},
+			StartsAt: time.Now(),
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, retry)
+
+	require.Equal(t, "message", got.Type)
+	require.Len(t, got.Attachments, 1)
+	require.Equal(t, adaptiveCardContentType, got.Attachments[0].ContentType)
+	card := got.Attachments[0].Content
+	require.Equal(t, "AdaptiveCard", card.Type)
+	require.Len(t, card.Body, 2)
+	require.Equal(t, "[FIRING:1] HighLatency (web-1)", card.Body[0].Text)
+	require.Equal(t, "attention", card.Body[0].Color)
+	require.Equal(t, "HighLatency on web-1", card.Body[1].Text)
+}
+
+func TestMSTeamsThrottled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("Microsoft Teams endpoint returned HTTP error 429"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	notifier, err := New(newConfig(u), test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	retry, err := notifier.Notify(ctx, &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "a"},
+			StartsAt: time.Now(),
+		},
+	})
+	require.True(t, retry)
+
+	var te *notify.ThrottledError
+	require.True(t, errors.As(err, &te), "expected a throttled error, got %v", err)
+	require.Equal(t, 30*time.Second, te.RetryAfter)
+}
@@ -16,6 +16,7 @@ package notify
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sort"
 	"sync"
 	"time"
@@ -65,6 +66,21 @@ type Integration struct {
 	rs       ResolvedSender
 	name     string
 	idx      int
+
+	// retryBudget is the maximum number of attempts per notification. Zero
+	// means no limit.
+	retryBudget int
+	// repeatInterval overrides the repeat interval of the route. Zero means
+	// no override.
+	repeatInterval time.Duration
+	// rateLimiter limits the notifications of the integration, usually
+	// shared by all integrations of the receiver. Nil means no limit.
+	rateLimiter *RateLimiter
+	// sendJitter is the maximum random delay before each notification. Zero
+	// means no delay.
+	sendJitter time.Duration
+	// sleep waits for the given duration or until the context is done.
+	sleep func(context.Context, time.Duration) error
 }
 
 // NewIntegration returns a new integration.
@@ -74,6 +90,7 @@ func NewIntegration(notifier Notifier, rs ResolvedSender, name string, idx int)
 		rs:       rs,
 		name:     name,
 		idx:      idx,
+		sleep:    sleep,
 	}
 }
 
@@ -82,11 +99,58 @@ func (i *Integration) Notify(ctx context.Context, alerts ...*types.Alert) (bool,
 	return i.notifier.Notify(ctx, alerts...)
 }
 
+// waitJitter waits for a random delay up to the send jitter, or returns the
+// error of the context if it is done before.
+func (i *Integration) waitJitter(ctx context.Context) error {
+	if i.sendJitter <= 0 {
+		return nil
+	}
+	return i.sleep(ctx, time.Duration(rand.Int63n(int64(i.sendJitter))))
+}
+
+// sleep waits for the given duration or until the context is done, in which
+// case it returns the error of the context.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // SendResolved implements the ResolvedSender interface.
 func (i *Integration) SendResolved() bool {
 	return i.rs.SendResolved()
 }
 
+// SetRetryBudget sets the maximum number of attempts per notification, after
+// which a failed notification is dropped. Zero means no limit.
+func (i *Integration) SetRetryBudget(n int) {
+	i.retryBudget = n
+}
+
+// SetRepeatInterval sets the interval after which unchanged notifications are
+// repeated, overriding that of the route. Zero means no override.
+func (i *Integration) SetRepeatInterval(d time.Duration) {
+	i.repeatInterval = d
+}
+
+// SetRateLimiter sets the limiter of the notifications of the integration.
+// Nil means no limit.
+func (i *Integration) SetRateLimiter(l *RateLimiter) {
+	i.rateLimiter = l
+}
+
+// SetSendJitter sets the maximum random delay before each notification,
+// which spreads the notifications of groups flushed at the same time. Zero
+// means no delay.
+func (i *Integration) SetSendJitter(d time.Duration) {
+	i.sendJitter = d
+}
+
 // Name returns the name of the integration.
 func (i *Integration) Name() string {
 	return i.name
@@ -102,6 +166,97 @@ func (i *Integration) String() string {
 	return fmt.Sprintf("%s[%d]", i.name, i.idx)
 }
 
+// IntegrationError is an error of the integration of the given name and index
+// in the configuration of a receiver.
+type IntegrationError struct {
+	Integration string
+	Index       int
+	Err         error
+}
+
+func (e *IntegrationError) Error() string {
+	return fmt.Sprintf("%s/%d: %s", e.Integration, e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *IntegrationError) Unwrap() error { return e.Err }
+
+// alertLimitNotifier wraps a notifier to limit the number of alerts passed to
+// it per notification.
+type alertLimitNotifier struct {
+	Notifier
+	max int
+}
+
+// LimitAlerts returns a notifier passing at most max alerts per notification
+// to n. Firing alerts are preferred over resolved ones. If alerts are left
+// out, a summary alert standing in for them is appended, so that templates
+// can render a human-readable "+N more" entry.
+func LimitAlerts(n Notifier, max int) Notifier {
+	return &alertLimitNotifier{Notifier: n, max: max}
+}
+
+// Notify implements the Notifier interface.
+func (n *alertLimitNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	if len(alerts) <= n.max {
+		return n.Notifier.Notify(ctx, alerts...)
+	}
+	now, ok := Now(ctx)
+	if !ok {
+		now = time.Now()
+	}
+
+	sorted := make([]*types.Alert, 0, len(alerts))
+	var resolved []*types.Alert
+	for _, a := range alerts {
+		if a.ResolvedAt(now) {
+			resolved = append(resolved, a)
+			continue
+		}
+		sorted = append(sorted, a)
+	}
+	sorted = append(sorted, resolved...)
+
+	kept, omitted := sorted[:n.max], sorted[n.max:]
+	return n.Notifier.Notify(ctx, append(kept[:len(kept):len(kept)], summaryAlert(alerts, omitted))...)
+}
+
+// summaryAlert returns an alert summarizing the omitted alerts. Its labels are
+// the labels common to all alerts, so that it doesn't change the common labels
+// of the notification. It fires as long as any of the omitted alerts fires.
+func summaryAlert(all, omitted []*types.Alert) *types.Alert {
+	labels := all[0].Labels.Clone()
+	for _, a := range all[1:] {
+		for ln, lv := range labels {
+			if a.Labels[ln] != lv {
+				delete(labels, ln)
+			}
+		}
+	}
+
+	summary := &types.Alert{
+		Alert: model.Alert{
+			Labels: labels,
+			Annotations: model.LabelSet{
+				"summary":     model.LabelValue(fmt.Sprintf("+%d more", len(omitted))),
+				"description": model.LabelValue(fmt.Sprintf("%d more alerts were left out of this notification.", len(omitted))),
+			},
+		},
+	}
+	for _, a := range omitted {
+		if summary.StartsAt.IsZero() || a.StartsAt.Before(summary.StartsAt) {
+			summary.StartsAt = a.StartsAt
+		}
+		if a.EndsAt.After(summary.EndsAt) {
+			summary.EndsAt = a.EndsAt
+		}
+		if a.UpdatedAt.After(summary.UpdatedAt) {
+			summary.UpdatedAt = a.UpdatedAt
+		}
+	}
+	return summary
+}
+
 // notifyKey defines a custom type with which a context is populated to
 // avoid accidental collisions.
 type notifyKey int
@@ -237,6 +392,12 @@ type Metrics struct {
 	numNotificationRequestsTotal       *prometheus.CounterVec
 	numNotificationRequestsFailedTotal *prometheus.CounterVec
 	notificationLatencySeconds         *prometheus.HistogramVec
+	numDeadLetteredNotifications       *prometheus.CounterVec
+	numRateLimitedNotifications        *prometheus.CounterVec
+	receiverLatencySeconds             *prometheus.HistogramVec
+	numReceiverRequestsFailedTotal     *prometheus.CounterVec
+	notificationsInFlight              prometheus.Gauge
+	notificationsQueued                prometheus.Gauge
 }
 
 func NewMetrics(r prometheus.Registerer) *Metrics {
@@ -267,6 +428,37 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			Help:      "The latency of notifications in seconds.",
 			Buckets:   []float64{1, 5, 10, 15, 20},
 		}, []string{"integration"}),
+		numDeadLetteredNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "alertmanager",
+			Name:      "notifications_dead_lettered_total",
+			Help:      "The total number of notifications dropped after exhausting the retry budget of their receiver.",
+		}, []string{"receiver", "integration"}),
+		numRateLimitedNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "alertmanager",
+			Name:      "notifications_rate_limited_total",
+			Help:      "The total number of notification attempts delayed or dropped by the rate limit of their receiver.",
+		}, []string{"receiver", "integration", "action"}),
+		receiverLatencySeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "alertmanager",
+			Name:      "receiver_notification_latency_seconds",
+			Help:      "The latency of notification requests in seconds by receiver.",
+			Buckets:   []float64{1, 5, 10, 15, 20},
+		}, []string{"receiver", "integration"}),
+		numReceiverRequestsFailedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "alertmanager",
+			Name:      "receiver_notification_requests_failed_total",
+			Help:      "The total number of failed notification requests by receiver.",
+		}, []string{"receiver", "integration"}),
+		notificationsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "alertmanager",
+			Name:      "notifications_in_flight",
+			Help:      "The number of notification requests in flight.",
+		}),
+		notificationsQueued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "alertmanager",
+			Name:      "notifications_queued",
+			Help:      "The number of notification requests waiting for the limit of concurrent notifications.",
+		}),
 	}
 	for _, integration := range []string{
 		"email",
@@ -278,6 +470,7 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 		"webhook",
 		"victorops",
 		"sns",
+		"msteams",
 	} {
 		m.numNotifications.WithLabelValues(integration)
 		m.numTotalFailedNotifications.WithLabelValues(integration)
@@ -288,27 +481,66 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 	r.MustRegister(
 		m.numNotifications, m.numTotalFailedNotifications,
 		m.numNotificationRequestsTotal, m.numNotificationRequestsFailedTotal,
-		m.notificationLatencySeconds,
+		m.notificationLatencySeconds, m.numDeadLetteredNotifications,
+		m.numRateLimitedNotifications, m.notificationsInFlight,
+		m.notificationsQueued, m.receiverLatencySeconds,
+		m.numReceiverRequestsFailedTotal,
 	)
 	return m
 }
 
 type PipelineBuilder struct {
-	metrics *Metrics
+	metrics   *Metrics
+	throttles *Throttles
+	limiter   *ConcurrencyLimiter
+
+	mtx sync.Mutex
+	// integrations are the names of the integrations by receiver of the
+	// latest pipeline built, whose metric series are reset once they are
+	// removed.
+	integrations map[string][]string
 }
 
 func NewPipelineBuilder(r prometheus.Registerer) *PipelineBuilder {
+	m := NewMetrics(r)
 	return &PipelineBuilder{
-		metrics: NewMetrics(r),
+		metrics:   m,
+		throttles: NewThrottles(),
+		limiter:   newConcurrencyLimiter(0, m),
 	}
 }
 
+// newConcurrencyLimiter returns a ConcurrencyLimiter allowing n notifications
+// in flight. Zero means no limit.
+func newConcurrencyLimiter(n int, m *Metrics) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{
+		inFlight: m.notificationsInFlight,
+		queued:   m.notificationsQueued,
+	}
+	if n > 0 {
+		l.sem = make(chan struct{}, n)
+	}
+	return l
+}
+
+// SetMaxConcurrency sets the maximum number of notification requests in flight
+// across all receivers of the pipelines built afterwards. Zero means no limit.
+func (pb *PipelineBuilder) SetMaxConcurrency(n int) {
+	pb.limiter = newConcurrencyLimiter(n, pb.metrics)
+}
+
+// Throttles returns the rate limiting recorded by the pipelines built.
+func (pb *PipelineBuilder) Throttles() *Throttles {
+	return pb.throttles
+}
+
 // New returns a map of receivers to Stages.
 func (pb *PipelineBuilder) New(
 	receivers map[string][]Integration,
 	wait func() time.Duration,
 	inhibitor *inhibit.Inhibitor,
 	silencer *silence.Silencer,
+	maintenance types.Muter,
 	muteTimes map[string][]timeinterval.TimeInterval,
 	notificationLog NotificationLog,
 	peer Peer,
@@ -318,15 +550,61 @@ func (pb *PipelineBuilder) New(
 	ms := NewGossipSettleStage(peer)
 	is := NewMuteStage(inhibitor)
 	ss := NewMuteStage(silencer)
+	mms := NewMuteStage(maintenance)
 	tms := NewTimeMuteStage(muteTimes)
 
+	integrations := make(map[string][]string, len(receivers))
 	for name := range receivers {
-		st := createReceiverStage(name, receivers[name], wait, notificationLog, pb.metrics)
-		rs[name] = MultiStage{ms, is, tms, ss, st}
+		st := createReceiverStage(name, receivers[name], wait, notificationLog, pb.metrics, pb.throttles, pb.limiter)
+		rs[name] = MultiStage{ms, is, tms, ss, mms, st}
+		for _, i := range receivers[name] {
+			integrations[name] = append(integrations[name], i.Name())
+		}
 	}
+	pb.resetRemovedReceivers(integrations)
 	return rs
 }
 
+// resetRemovedReceivers deletes the metric series of the receivers and
+// integrations of the previous pipeline which aren't part of the new one,
+// so that they don't linger after their removal, and initializes those of the
+// new pipeline.
+func (pb *PipelineBuilder) resetRemovedReceivers(integrations map[string][]string) {
+	pb.mtx.Lock()
+	defer pb.mtx.Unlock()
+
+	m := pb.metrics
+	for name, prev := range pb.integrations {
+		for _, i := range prev {
+			if containsString(integrations[name], i) {
+				continue
+			}
+			m.receiverLatencySeconds.DeleteLabelValues(name, i)
+			m.numReceiverRequestsFailedTotal.DeleteLabelValues(name, i)
+			m.numDeadLetteredNotifications.DeleteLabelValues(name, i)
+			for _, action := range []string{"delayed", "dropped"} {
+				m.numRateLimitedNotifications.DeleteLabelValues(name, i, action)
+			}
+		}
+	}
+	for name, is := range integrations {
+		for _, i := range is {
+			m.receiverLatencySeconds.WithLabelValues(name, i)
+			m.numReceiverRequestsFailedTotal.WithLabelValues(name, i)
+		}
+	}
+	pb.integrations = integrations
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // createReceiverStage creates a pipeline of stages for a receiver.
 func createReceiverStage(
 	name string,
@@ -334,6 +612,8 @@ func createReceiverStage(
 	wait func() time.Duration,
 	notificationLog NotificationLog,
 	metrics *Metrics,
+	throttles *Throttles,
+	limiter *ConcurrencyLimiter,
 ) Stage {
 	var fs FanoutStage
 	for i := range integrations {
@@ -344,8 +624,8 @@ func createReceiverStage(
 		}
 		var s MultiStage
 		s = append(s, NewWaitStage(wait))
-		s = append(s, NewDedupStage(&integrations[i], notificationLog, recv))
-		s = append(s, NewRetryStage(integrations[i], name, metrics))
+		s = append(s, NewDedupStage(&integrations[i], notificationLog, recv, integrations[i].repeatInterval))
+		s = append(s, NewRetryStage(integrations[i], name, metrics, throttles, limiter))
 		s = append(s, NewSetNotifiesStage(notificationLog, recv))
 
 		fs = append(fs, s)
@@ -491,19 +771,24 @@ type DedupStage struct {
 	rs    ResolvedSender
 	nflog NotificationLog
 	recv  *nflogpb.Receiver
+	// repeatInterval overrides the repeat interval in the context if not
+	// zero.
+	repeatInterval time.Duration
 
 	now  func() time.Time
 	hash func(*types.Alert) uint64
 }
 
-// NewDedupStage wraps a DedupStage that runs against the given notification log.
-func NewDedupStage(rs ResolvedSender, l NotificationLog, recv *nflogpb.Receiver) *DedupStage {
+// NewDedupStage wraps a DedupStage that runs against the given notification
+// log. A non-zero repeat interval overrides that of the route.
+func NewDedupStage(rs ResolvedSender, l NotificationLog, recv *nflogpb.Receiver, repeatInterval time.Duration) *DedupStage {
 	return &DedupStage{
-		rs:    rs,
-		nflog: l,
-		recv:  recv,
-		now:   utcNow,
-		hash:  hashAlert,
+		rs:             rs,
+		nflog:          l,
+		recv:           recv,
+		repeatInterval: repeatInterval,
+		now:            utcNow,
+		hash:           hashAlert,
 	}
 }
 
@@ -587,6 +872,9 @@ func (n *DedupStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Al
 	if !ok {
 		return ctx, nil, errors.New("repeat interval missing")
 	}
+	if n.repeatInterval > 0 {
+		repeatInterval = n.repeatInterval
+	}
 
 	firingSet := map[uint64]struct{}{}
 	resolvedSet := map[uint64]struct{}{}
@@ -629,19 +917,28 @@ func (n *DedupStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Al
 }
 
 // RetryStage notifies via passed integration with exponential backoff until it
-// succeeds. It aborts if the context is canceled or timed out.
+// succeeds. It aborts if the context is canceled or timed out, or once the
+// retry budget of the integration is exhausted, in which case a dead-letter
+// event is logged and the notification is passed on as if it was sent. Rate
+// limited attempts are recorded in the throttles, if any.
+// Attempts wait for the send jitter, then for the concurrency limiter, if any.
 type RetryStage struct {
 	integration Integration
 	groupName   string
 	metrics     *Metrics
+	throttles   *Throttles
+	limiter     *ConcurrencyLimiter
 }
 
-// NewRetryStage returns a new instance of a RetryStage.
-func NewRetryStage(i Integration, groupName string, metrics *Metrics) *RetryStage {
+// NewRetryStage returns a new instance of a RetryStage. The throttles and the
+// concurrency limiter may be nil.
+func NewRetryStage(i Integration, groupName string, metrics *Metrics, throttles *Throttles, limiter *ConcurrencyLimiter) *RetryStage {
 	return &RetryStage{
 		integration: i,
 		groupName:   groupName,
 		metrics:     metrics,
+		throttles:   throttles,
+		limiter:     limiter,
 	}
 }
 
@@ -704,15 +1001,54 @@ func (r RetryStage) exec(ctx context.Context, l log.Logger, alerts ...*types.Ale
 
 		select {
 		case <-tick.C:
+			if rl := r.integration.rateLimiter; rl != nil {
+				delay, err := rl.Wait(ctx)
+				if errors.Is(err, ErrRateLimited) {
+					r.metrics.numRateLimitedNotifications.WithLabelValues(r.groupName, r.integration.Name(), "dropped").Inc()
+					return ctx, nil, errors.Wrapf(err, "%s/%s", r.groupName, r.integration.String())
+				}
+				if err != nil {
+					// The context is done.
+					continue
+				}
+				if delay > 0 {
+					r.metrics.numRateLimitedNotifications.WithLabelValues(r.groupName, r.integration.Name(), "delayed").Inc()
+				}
+			}
+			// The jitter is waited before taking a concurrency slot, so as not
+			// to hold it while idle, and isn't part of the latency.
+			if err := r.integration.waitJitter(ctx); err != nil {
+				// The context is done.
+				continue
+			}
+			if err := r.limiter.acquire(ctx); err != nil {
+				// The context is done.
+				continue
+			}
 			now := time.Now()
 			retry, err := r.integration.Notify(ctx, sent...)
-			r.metrics.notificationLatencySeconds.WithLabelValues(r.integration.Name()).Observe(time.Since(now).Seconds())
+			r.limiter.release()
+			latency := time.Since(now).Seconds()
+			r.metrics.notificationLatencySeconds.WithLabelValues(r.integration.Name()).Observe(latency)
+			r.metrics.receiverLatencySeconds.WithLabelValues(r.groupName, r.integration.Name()).Observe(latency)
 			r.metrics.numNotificationRequestsTotal.WithLabelValues(r.integration.Name()).Inc()
 			if err != nil {
 				r.metrics.numNotificationRequestsFailedTotal.WithLabelValues(r.integration.Name()).Inc()
+				r.metrics.numReceiverRequestsFailedTotal.WithLabelValues(r.groupName, r.integration.Name()).Inc()
+				var te *ThrottledError
+				if r.throttles != nil && errors.As(err, &te) {
+					r.throttles.Record(r.groupName, r.integration.String(), time.Now(), te.RetryAfter)
+				}
 				if !retry {
 					return ctx, alerts, errors.Wrapf(err, "%s/%s: notify retry canceled due to unrecoverable error after %d attempts", r.groupName, r.integration.String(), i)
 				}
+				if r.integration.retryBudget > 0 && i >= r.integration.retryBudget {
+					// The dropped notification is passed on to be recorded in
+					// the notification log, so that it isn't sent again until
+					// its alerts change or the repeat interval has passed.
+					r.deadLetter(ctx, l, i, err)
+					return ctx, alerts, nil
+				}
 				if ctx.Err() == nil && (iErr == nil || err.Error() != iErr.Error()) {
 					// Log the error if the context isn't done and the error isn't the same as before.
 					level.Warn(l).Log("msg", "Notify attempt failed, will retry later", "attempts", i, "err", err)
@@ -739,6 +1075,21 @@ func (r RetryStage) exec(ctx context.Context, l log.Logger, alerts ...*types.Ale
 	}
 }
 
+// deadLetter records a notification dropped after the given number of failed
+// attempts. It is counted as failed although no error is returned.
+func (r RetryStage) deadLetter(ctx context.Context, l log.Logger, attempts int, err error) {
+	r.metrics.numDeadLetteredNotifications.WithLabelValues(r.groupName, r.integration.Name()).Inc()
+	r.metrics.numTotalFailedNotifications.WithLabelValues(r.integration.Name()).Inc()
+	gkey, _ := GroupKey(ctx)
+	level.Error(l).Log(
+		"msg", "Notification dropped after exhausting the retry budget",
+		"event", "dead_letter",
+		"group_key", gkey,
+		"attempts", attempts,
+		"err", err,
+	)
+}
+
 // SetNotifiesStage sets the notification information about passed alerts. The
 // passed alerts should have already been sent to the receivers.
 type SetNotifiesStage struct {
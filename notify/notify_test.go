@@ -14,16 +14,19 @@
 package notify
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
@@ -422,6 +425,467 @@ func TestRetryStageWithError(t *testing.T) {
 	require.NotNil(t, resctx)
 }
 
+func TestRetryStageThrottled(t *testing.T) {
+	throttled := true
+	i := Integration{
+		notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			if throttled {
+				throttled = false
+				return true, fmt.Errorf("channel: %w", &ThrottledError{Err: errors.New("rate limited"), RetryAfter: time.Minute})
+			}
+			return false, nil
+		}),
+		rs:   sendResolved(false),
+		name: "slack",
+	}
+	throttles := NewThrottles()
+	r := NewRetryStage(i, "team", NewMetrics(prometheus.NewRegistry()), throttles, nil)
+
+	alerts := []*types.Alert{
+		&types.Alert{
+			Alert: model.Alert{
+				EndsAt: time.Now().Add(time.Hour),
+			},
+		},
+	}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+
+	require.Empty(t, throttles.Receiver("team"))
+
+	before := time.Now()
+	_, _, err := r.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+
+	states := throttles.Receiver("team")
+	require.Len(t, states, 1)
+	s, ok := states["slack[0]"]
+	require.True(t, ok)
+	require.False(t, s.LastThrottledAt.Before(before))
+	require.Equal(t, time.Minute, s.NextAttemptAt.Sub(s.LastThrottledAt))
+	require.Empty(t, throttles.Receiver("other"))
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewRateLimiter(2, time.Minute, true)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		wait, err := l.reserve()
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+	_, err := l.reserve()
+	require.Equal(t, ErrRateLimited, err)
+
+	// A token is refilled every 30 seconds.
+	now = now.Add(30 * time.Second)
+	_, err = l.reserve()
+	require.NoError(t, err)
+	_, err = l.reserve()
+	require.Equal(t, ErrRateLimited, err)
+
+	// The bucket holds at most the limit.
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		_, err = l.reserve()
+		require.NoError(t, err)
+	}
+	_, err = l.reserve()
+	require.Equal(t, ErrRateLimited, err)
+
+	l = NewRateLimiter(2, time.Minute, false)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		_, err := l.reserve()
+		require.NoError(t, err)
+	}
+	// Delayed notifications wait in turn.
+	for _, expected := range []time.Duration{30 * time.Second, time.Minute} {
+		wait, err := l.reserve()
+		require.NoError(t, err)
+		require.Equal(t, expected, wait)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.Wait(ctx)
+	require.Equal(t, context.Canceled, err)
+
+	// Abandoned notifications give back their token, so that they don't
+	// delay the next ones.
+	l = NewRateLimiter(2, time.Minute, false)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		_, err := l.Wait(context.Background())
+		require.NoError(t, err)
+	}
+	_, err = l.Wait(ctx)
+	require.Equal(t, context.Canceled, err)
+	now = now.Add(30 * time.Second)
+	wait, err := l.Wait(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestIntegrationSendJitter(t *testing.T) {
+	notified := 0
+	in := NewIntegration(notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+		notified++
+		return false, nil
+	}), sendResolved(false), "test", 0)
+
+	var delays []time.Duration
+	in.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	alerts := []*types.Alert{{Alert: model.Alert{EndsAt: time.Now().Add(time.Hour)}}}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	// Without jitter, notifications aren't delayed.
+	_, _, err := NewRetryStage(in, "test", metrics, nil, nil).Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Empty(t, delays)
+
+	in.SetSendJitter(time.Minute)
+	for i := 0; i < 100; i++ {
+		_, _, err := NewRetryStage(in, "test", metrics, nil, nil).Exec(ctx, log.NewNopLogger(), alerts...)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 101, notified)
+	require.Len(t, delays, 100)
+	for _, d := range delays {
+		require.True(t, d >= 0 && d < time.Minute, "delay %s out of bounds", d)
+	}
+
+	// The jitter isn't part of the latency of the notification.
+	in.sleep = func(context.Context, time.Duration) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	reg := prometheus.NewRegistry()
+	metrics = NewMetrics(reg)
+	_, _, err = NewRetryStage(in, "test", metrics, nil, nil).Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	var latencies uint64
+	for _, mf := range mfs {
+		if mf.GetName() != "alertmanager_notification_latency_seconds" && mf.GetName() != "alertmanager_receiver_notification_latency_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			latencies += m.GetHistogram().GetSampleCount()
+			require.Less(t, m.GetHistogram().GetSampleSum(), 0.1)
+		}
+	}
+	require.Equal(t, uint64(2), latencies)
+
+	// Notifications are abandoned if the context is done while waiting.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	in.sleep = sleep
+	_, _, err = NewRetryStage(in, "test", metrics, nil, nil).Exec(cctx, log.NewNopLogger(), alerts...)
+	require.Error(t, err)
+	require.Equal(t, 102, notified)
+}
+
+func TestRetryStageRateLimit(t *testing.T) {
+	notified := map[string]int{}
+	newIntegration := func(receiver string) Integration {
+		return Integration{
+			notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+				notified[receiver]++
+				return false, nil
+			}),
+			rs:   sendResolved(false),
+			name: "webhook",
+		}
+	}
+	// The integrations of a receiver share its limiter.
+	limiter := NewRateLimiter(2, time.Hour, true)
+	limited := []Integration{newIntegration("limited"), newIntegration("limited")}
+	for i := range limited {
+		limited[i].SetRateLimiter(limiter)
+	}
+	receivers := map[string][]Integration{
+		"limited": limited,
+		"other":   {newIntegration("other")},
+	}
+
+	alerts := []*types.Alert{
+		&types.Alert{
+			Alert: model.Alert{
+				EndsAt: time.Now().Add(time.Hour),
+			},
+		},
+	}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	var dropped int
+	for n := 0; n < 3; n++ {
+		for name, integrations := range receivers {
+			for _, i := range integrations {
+				_, _, err := NewRetryStage(i, name, metrics, nil, nil).Exec(ctx, log.NewNopLogger(), alerts...)
+				if errors.Is(err, ErrRateLimited) {
+					require.Equal(t, "limited", name)
+					dropped++
+					continue
+				}
+				require.NoError(t, err)
+			}
+		}
+	}
+
+	require.Equal(t, map[string]int{"limited": 2, "other": 3}, notified)
+	require.Equal(t, 4, dropped)
+	require.Equal(t, 4.0, testutil.ToFloat64(metrics.numRateLimitedNotifications.WithLabelValues("limited", "webhook", "dropped")))
+}
+
+func TestReceiverMetrics(t *testing.T) {
+	newIntegration := func(err error) Integration {
+		return Integration{
+			notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+				return false, err
+			}),
+			rs:   sendResolved(false),
+			name: "webhook",
+		}
+	}
+	pb := NewPipelineBuilder(prometheus.NewRegistry())
+	wait := func() time.Duration { return 0 }
+	build := func(receivers map[string][]Integration) {
+		pb.New(receivers, wait, nil, nil, nil, nil, nil, nil)
+	}
+	receivers := map[string][]Integration{
+		"failing": {newIntegration(errors.New("timeout"))},
+		"other":   {newIntegration(nil)},
+	}
+	build(receivers)
+
+	alerts := []*types.Alert{
+		&types.Alert{
+			Alert: model.Alert{
+				EndsAt: time.Now().Add(time.Hour),
+			},
+		},
+	}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+	for name, integrations := range receivers {
+		for _, i := range integrations {
+			NewRetryStage(i, name, pb.metrics, nil, nil).Exec(ctx, log.NewNopLogger(), alerts...)
+		}
+	}
+
+	m := pb.metrics
+	require.Equal(t, 1.0, testutil.ToFloat64(m.numReceiverRequestsFailedTotal.WithLabelValues("failing", "webhook")))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.numReceiverRequestsFailedTotal.WithLabelValues("other", "webhook")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.receiverLatencySeconds))
+
+	// The series of removed receivers are deleted, while the others are kept.
+	delete(receivers, "failing")
+	build(receivers)
+	require.Equal(t, 1, testutil.CollectAndCount(m.numReceiverRequestsFailedTotal))
+	require.Equal(t, 1, testutil.CollectAndCount(m.receiverLatencySeconds))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.numReceiverRequestsFailedTotal.WithLabelValues("other", "webhook")))
+}
+
+func TestRetryStageConcurrencyLimit(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	limiter := newConcurrencyLimiter(2, metrics)
+
+	var (
+		mtx                   sync.Mutex
+		inFlight, maxInFlight int
+		started               = make(chan struct{}, 6)
+		release               = make(chan struct{})
+	)
+	notifier := notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+		mtx.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mtx.Unlock()
+		started <- struct{}{}
+		<-release
+		mtx.Lock()
+		inFlight--
+		mtx.Unlock()
+		return false, nil
+	})
+
+	alerts := []*types.Alert{{Alert: model.Alert{EndsAt: time.Now().Add(time.Hour)}}}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+
+	// The limit applies to the integrations of all receivers together.
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for _, name := range []string{"a", "b", "c"} {
+		for i := 0; i < 2; i++ {
+			rs := NewRetryStage(Integration{notifier: notifier, rs: sendResolved(false), name: "webhook", idx: i}, name, metrics, nil, limiter)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, err := rs.Exec(ctx, log.NewNopLogger(), alerts...)
+				errs <- err
+			}()
+		}
+	}
+
+	<-started
+	<-started
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.notificationsQueued) == 4
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.notificationsInFlight))
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, maxInFlight)
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.notificationsInFlight))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.notificationsQueued))
+
+	// Notifications waiting for the limit are abandoned with their context.
+	limiter = newConcurrencyLimiter(1, metrics)
+	require.NoError(t, limiter.acquire(context.Background()))
+	cctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, limiter.acquire(cctx))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.notificationsQueued))
+	limiter.release()
+
+	// The send jitter is waited without holding a slot.
+	in := Integration{notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+		return false, nil
+	}), rs: sendResolved(false), name: "webhook", sendJitter: time.Minute}
+	var waited bool
+	in.sleep = func(context.Context, time.Duration) error {
+		waited = true
+		require.Equal(t, 0.0, testutil.ToFloat64(metrics.notificationsInFlight))
+		return nil
+	}
+	rs := NewRetryStage(in, "a", metrics, nil, limiter)
+	_, _, err := rs.Exec(ctx, log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.True(t, waited)
+}
+
+func TestReceiverStageRepeatInterval(t *testing.T) {
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "a"},
+			EndsAt: time.Now().Add(time.Hour),
+		},
+	}
+	// Both receivers notified about the group two hours ago.
+	nflog := &testNflog{
+		qres: []*nflogpb.Entry{{
+			FiringAlerts: []uint64{hashAlert(alert)},
+			Timestamp:    utcNow().Add(-2 * time.Hour),
+		}},
+		logFunc: func(r *nflogpb.Receiver, gkey string, firingAlerts, resolvedAlerts []uint64) error {
+			return nil
+		},
+	}
+
+	notified := map[string]int{}
+	newIntegration := func(receiver string) Integration {
+		return Integration{
+			notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+				notified[receiver]++
+				return false, nil
+			}),
+			rs:   sendResolved(false),
+			name: "webhook",
+		}
+	}
+	sms := newIntegration("sms")
+	sms.SetRepeatInterval(4 * time.Hour)
+	receivers := map[string][]Integration{
+		"sms":   {sms},
+		"slack": {newIntegration("slack")},
+	}
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	for name, integrations := range receivers {
+		s := createReceiverStage(name, integrations, func() time.Duration { return 0 }, nflog, metrics, nil, nil)
+
+		ctx := WithGroupKey(context.Background(), "{}:{alertname=\"a\"}")
+		ctx = WithReceiverName(ctx, name)
+		ctx = WithRepeatInterval(ctx, time.Hour)
+		_, _, err := s.Exec(ctx, log.NewNopLogger(), alert)
+		require.NoError(t, err)
+	}
+
+	// The route's repeat interval has passed but not that of the sms
+	// receiver.
+	require.Equal(t, map[string]int{"slack": 1}, notified)
+}
+
+func TestRetryStageRetryBudget(t *testing.T) {
+	attempts := 0
+	i := Integration{
+		notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			attempts++
+			return true, errors.New("connection refused")
+		}),
+		rs:          sendResolved(false),
+		name:        "webhook",
+		retryBudget: 2,
+	}
+	metrics := NewMetrics(prometheus.NewRegistry())
+	r := NewRetryStage(i, "team", metrics, nil, nil)
+
+	alerts := []*types.Alert{
+		&types.Alert{
+			Alert: model.Alert{
+				EndsAt: time.Now().Add(time.Hour),
+			},
+		},
+	}
+	ctx := WithFiringAlerts(context.Background(), []uint64{0})
+	ctx = WithGroupKey(ctx, "{}:{alertname=\"a\"}")
+
+	// The dropped notification is recorded in the notification log, so that
+	// it isn't retried at the next flush of the group.
+	var logged []uint64
+	nflog := &testNflog{
+		logFunc: func(r *nflogpb.Receiver, gkey string, firingAlerts, resolvedAlerts []uint64) error {
+			logged = firingAlerts
+			return nil
+		},
+	}
+	s := MultiStage{r, NewSetNotifiesStage(nflog, &nflogpb.Receiver{GroupName: "team", Integration: "webhook"})}
+	ctx = WithResolvedAlerts(ctx, []uint64{})
+
+	var buf bytes.Buffer
+	resctx, res, err := s.Exec(ctx, log.NewLogfmtLogger(&buf), alerts...)
+	require.NoError(t, err)
+	require.Equal(t, alerts, res)
+	require.NotNil(t, resctx)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, []uint64{0}, logged)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.numDeadLetteredNotifications.WithLabelValues("team", "webhook")))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.numTotalFailedNotifications.WithLabelValues("webhook")))
+
+	event := buf.String()
+	require.Contains(t, event, "event=dead_letter")
+	require.Contains(t, event, `group_key="{}:{alertname=\"a\"}"`)
+	require.Contains(t, event, "receiver=team")
+	require.Contains(t, event, "integration=webhook[0]")
+	require.Contains(t, event, `err="connection refused"`)
+}
+
 func TestRetryStageNoResolved(t *testing.T) {
 	sent := []*types.Alert{}
 	i := Integration{
@@ -524,6 +988,63 @@ func TestRetryStageSendResolved(t *testing.T) {
 	require.NotNil(t, resctx)
 }
 
+func TestLimitAlerts(t *testing.T) {
+	var sent []*types.Alert
+	n := LimitAlerts(notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+		sent = alerts
+		return false, nil
+	}), 2)
+
+	now := time.Now()
+	ctx := WithNow(context.Background(), now)
+	newAlert := func(name string, startsAt, endsAt time.Time) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"job": "test", "alertname": model.LabelValue(name)},
+				StartsAt: startsAt,
+				EndsAt:   endsAt,
+			},
+		}
+	}
+	var (
+		resolved = newAlert("resolved", now.Add(-3*time.Hour), now.Add(-time.Hour))
+		firing1  = newAlert("firing1", now.Add(-2*time.Hour), now.Add(time.Hour))
+		firing2  = newAlert("firing2", now.Add(-time.Hour), now.Add(2*time.Hour))
+		firing3  = newAlert("firing3", now.Add(-4*time.Hour), now.Add(3*time.Hour))
+	)
+
+	// Notifications within the limit are passed through.
+	_, err := n.Notify(ctx, resolved, firing1)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Alert{resolved, firing1}, sent)
+
+	// Firing alerts are kept, the remaining ones are summarized.
+	_, err = n.Notify(ctx, resolved, firing1, firing2, firing3)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Alert{
+		firing1,
+		firing2,
+		{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"job": "test"},
+				Annotations: model.LabelSet{
+					"summary":     "+2 more",
+					"description": "2 more alerts were left out of this notification.",
+				},
+				StartsAt: firing3.StartsAt,
+				EndsAt:   firing3.EndsAt,
+			},
+		},
+	}, sent)
+
+	// The summary alert is resolved if all omitted alerts are resolved.
+	_, err = n.Notify(ctx, firing1, firing2, resolved)
+	require.NoError(t, err)
+	require.Len(t, sent, 3)
+	require.Equal(t, model.LabelValue("+1 more"), sent[2].Annotations["summary"])
+	require.True(t, sent[2].ResolvedAt(now))
+}
+
 func TestSetNotifiesStage(t *testing.T) {
 	tnflog := &testNflog{}
 	s := &SetNotifiesStage{
@@ -644,7 +1165,7 @@ func TestMuteStageWithSilences(t *testing.T) {
 	}
 
 	marker := types.NewMarker(prometheus.NewRegistry())
-	silencer := silence.NewSilencer(silences, marker, log.NewNopLogger())
+	silencer := silence.NewSilencer(silences, nil, marker, log.NewNopLogger())
 	stage := NewMuteStage(silencer)
 
 	in := []model.LabelSet{
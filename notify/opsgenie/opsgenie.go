@@ -102,7 +102,7 @@ func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 		if err != nil {
 			return true, err
 		}
-		shouldRetry, err := n.retrier.Check(resp.StatusCode, resp.Body)
+		shouldRetry, err := n.retrier.CheckResponse(resp)
 		notify.Drain(resp)
 		if err != nil {
 			return shouldRetry, err
@@ -151,6 +151,11 @@ func (n *Notifier) createRequests(ctx context.Context, as ...*types.Alert) ([]*h
 		alias  = key.Hash()
 		alerts = types.Alerts(as...)
 	)
+	if n.conf.AliasLabel != "" {
+		if v := data.CommonLabels[string(n.conf.AliasLabel)]; v != "" {
+			alias = v
+		}
+	}
 	switch alerts.Status() {
 	case model.AlertResolved:
 		resolvedEndpointURL := n.conf.APIURL.Copy()
@@ -195,6 +200,11 @@ func (n *Notifier) createRequests(ctx context.Context, as ...*types.Alert) ([]*h
 			responders = append(responders, responder)
 		}
 
+		priority := notify.SeverityToPriority(n.conf.PriorityMapping, as...)
+		if priority == "" {
+			priority = tmpl(n.conf.Priority)
+		}
+
 		var msg = &opsGenieCreateMessage{
 			Alias:       alias,
 			Message:     message,
@@ -204,7 +214,7 @@ func (n *Notifier) createRequests(ctx context.Context, as ...*types.Alert) ([]*h
 			Responders:  responders,
 			Tags:        safeSplit(string(tmpl(n.conf.Tags)), ","),
 			Note:        tmpl(n.conf.Note),
-			Priority:    tmpl(n.conf.Priority),
+			Priority:    priority,
 		}
 		var buf bytes.Buffer
 		if err := json.NewEncoder(&buf).Encode(msg); err != nil {
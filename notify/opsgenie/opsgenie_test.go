@@ -15,6 +15,7 @@ package opsgenie
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -282,6 +283,88 @@ func TestOpsGenieWithUpdate(t *testing.T) {
 `)
 }
 
+func TestOpsGenieAliasLabel(t *testing.T) {
+	u, err := url.Parse("https://test-opsgenie-url")
+	require.NoError(t, err)
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	key, err := notify.ExtractGroupKey(ctx)
+	require.NoError(t, err)
+
+	notifier, err := New(&config.OpsGenieConfig{
+		APIKey:     "test-api-key",
+		APIURL:     &config.URL{URL: u},
+		HTTPConfig: &commoncfg.HTTPClientConfig{},
+		AliasLabel: "incident",
+	}, test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	newAlert := func(incident string, endsAt time.Time) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": "a", "incident": model.LabelValue(incident)},
+				StartsAt: time.Now().Add(-time.Hour),
+				EndsAt:   endsAt,
+			},
+		}
+	}
+	firing := time.Now().Add(time.Hour)
+
+	// The common label value is used as alias.
+	requests, _, err := notifier.createRequests(ctx, newAlert("db-outage", firing), newAlert("db-outage", firing))
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	var msg opsGenieCreateMessage
+	require.NoError(t, json.Unmarshal([]byte(readBody(t, requests[0])), &msg))
+	require.Equal(t, "db-outage", msg.Alias)
+
+	// Resolving closes the alert with the same alias.
+	requests, _, err = notifier.createRequests(ctx, newAlert("db-outage", time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, "https://test-opsgenie-url/v2/alerts/db-outage/close?identifierType=alias", requests[0].URL.String())
+
+	// The alias falls back to the group key without a common value.
+	requests, _, err = notifier.createRequests(ctx, newAlert("db-outage", firing), newAlert("network", firing))
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.NoError(t, json.Unmarshal([]byte(readBody(t, requests[0])), &msg))
+	require.Equal(t, key.Hash(), msg.Alias)
+}
+
+func TestOpsGeniePriorityMapping(t *testing.T) {
+	u, err := url.Parse("https://test-opsgenie-url")
+	require.NoError(t, err)
+	ctx := notify.WithGroupKey(context.Background(), "1")
+
+	notifier, err := New(&config.OpsGenieConfig{
+		APIKey:     "test-api-key",
+		APIURL:     &config.URL{URL: u},
+		HTTPConfig: &commoncfg.HTTPClientConfig{},
+		Priority:   "P2",
+		PriorityMapping: &config.PriorityMapping{
+			Label:      "severity",
+			Priorities: []config.SeverityPriority{{Severity: "critical", Priority: "P1"}},
+		},
+	}, test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	priority := func(severity string) string {
+		requests, _, err := notifier.createRequests(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": "a", "severity": model.LabelValue(severity)},
+				StartsAt: time.Now(),
+			},
+		})
+		require.NoError(t, err)
+		var msg opsGenieCreateMessage
+		require.NoError(t, json.Unmarshal([]byte(readBody(t, requests[0])), &msg))
+		return msg.Priority
+	}
+	require.Equal(t, "P1", priority("critical"))
+	// Without a default, unmapped severities keep the configured priority.
+	require.Equal(t, "P2", priority("warning"))
+}
+
 func readBody(t *testing.T, r *http.Request) string {
 	t.Helper()
 	body, err := ioutil.ReadAll(r.Body)
@@ -156,7 +156,7 @@ func (n *Notifier) notifyV1(
 	msg := &pagerDutyMessage{
 		ServiceKey:  tmpl(string(n.conf.ServiceKey)),
 		EventType:   eventType,
-		IncidentKey: key.Hash(),
+		IncidentKey: n.dedupKey(key, data),
 		Description: description,
 		Details:     details,
 	}
@@ -186,7 +186,7 @@ func (n *Notifier) notifyV1(
 	}
 	defer notify.Drain(resp)
 
-	return n.retrier.Check(resp.StatusCode, resp.Body)
+	return n.retrier.CheckResponse(resp)
 }
 
 func (n *Notifier) notifyV2(
@@ -203,6 +203,10 @@ func (n *Notifier) notifyV2(
 	if n.conf.Severity == "" {
 		n.conf.Severity = "error"
 	}
+	severity := notify.SeverityToPriority(n.conf.SeverityMapping, as...)
+	if severity == "" {
+		severity = tmpl(n.conf.Severity)
+	}
 
 	summary, truncated := notify.Truncate(tmpl(n.conf.Description), 1024)
 	if truncated {
@@ -214,13 +218,13 @@ func (n *Notifier) notifyV2(
 		ClientURL:   tmpl(n.conf.ClientURL),
 		RoutingKey:  tmpl(string(n.conf.RoutingKey)),
 		EventAction: eventType,
-		DedupKey:    key.Hash(),
+		DedupKey:    n.dedupKey(key, data),
 		Images:      make([]pagerDutyImage, 0, len(n.conf.Images)),
 		Links:       make([]pagerDutyLink, 0, len(n.conf.Links)),
 		Payload: &pagerDutyPayload{
 			Summary:       summary,
 			Source:        tmpl(n.conf.Client),
-			Severity:      tmpl(n.conf.Severity),
+			Severity:      severity,
 			CustomDetails: details,
 			Class:         tmpl(n.conf.Class),
 			Component:     tmpl(n.conf.Component),
@@ -271,7 +275,18 @@ func (n *Notifier) notifyV2(
 	}
 	defer notify.Drain(resp)
 
-	return n.retrier.Check(resp.StatusCode, resp.Body)
+	return n.retrier.CheckResponse(resp)
+}
+
+// dedupKey returns the value of the configured dedup key label common to all
+// alerts, falling back to the hash of the group key.
+func (n *Notifier) dedupKey(key notify.Key, data *template.Data) string {
+	if n.conf.DedupKeyLabel != "" {
+		if v := data.CommonLabels[string(n.conf.DedupKeyLabel)]; v != "" {
+			return v
+		}
+	}
+	return key.Hash()
 }
 
 // Notify implements the Notifier interface.
@@ -233,6 +233,109 @@ func TestPagerDutyTemplating(t *testing.T) {
 	}
 }
 
+func TestPagerDutyDedupKeyLabel(t *testing.T) {
+	var msg pagerDutyMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	key, err := notify.ExtractGroupKey(ctx)
+	require.NoError(t, err)
+
+	newAlert := func(incident string) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": "a", "incident": model.LabelValue(incident)},
+				StartsAt: time.Now(),
+				EndsAt:   time.Now().Add(time.Hour),
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		title  string
+		alerts []*types.Alert
+		key    string
+	}{
+		{
+			title:  "common label value",
+			alerts: []*types.Alert{newAlert("db-outage"), newAlert("db-outage")},
+			key:    "db-outage",
+		},
+		{
+			title:  "differing label values",
+			alerts: []*types.Alert{newAlert("db-outage"), newAlert("network")},
+			key:    key.Hash(),
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			for _, cfg := range []*config.PagerdutyConfig{
+				{RoutingKey: config.Secret("01234567890123456789012345678901")},
+				{ServiceKey: config.Secret("01234567890123456789012345678901")},
+			} {
+				cfg.URL = &config.URL{URL: u}
+				cfg.HTTPConfig = &commoncfg.HTTPClientConfig{}
+				cfg.DedupKeyLabel = "incident"
+				pd, err := New(cfg, test.CreateTmpl(t), log.NewNopLogger())
+				require.NoError(t, err)
+				if pd.apiV1 != "" {
+					pd.apiV1 = u.String()
+				}
+
+				msg = pagerDutyMessage{}
+				_, err = pd.Notify(ctx, tc.alerts...)
+				require.NoError(t, err)
+				if pd.apiV1 != "" {
+					require.Equal(t, tc.key, msg.IncidentKey)
+				} else {
+					require.Equal(t, tc.key, msg.DedupKey)
+				}
+			}
+		})
+	}
+}
+
+func TestPagerDutySeverityMapping(t *testing.T) {
+	var msg pagerDutyMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	pd, err := New(&config.PagerdutyConfig{
+		RoutingKey: config.Secret("01234567890123456789012345678901"),
+		URL:        &config.URL{URL: u},
+		HTTPConfig: &commoncfg.HTTPClientConfig{},
+		SeverityMapping: &config.PriorityMapping{
+			Label:      "level",
+			Priorities: []config.SeverityPriority{{Severity: "page", Priority: "critical"}},
+		},
+	}, test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	for level, severity := range map[string]string{
+		"page": "critical",
+		// Unmapped severities keep the default severity.
+		"ticket": "error",
+	} {
+		msg = pagerDutyMessage{}
+		_, err = pd.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": "a", "level": model.LabelValue(level)},
+				StartsAt: time.Now(),
+				EndsAt:   time.Now().Add(time.Hour),
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, severity, msg.Payload.Severity, level)
+	}
+}
+
 func TestErrDetails(t *testing.T) {
 	for _, tc := range []struct {
 		status int
@@ -0,0 +1,198 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// SilenceExpiringAlertName is the alert name of the notifications about
+// silences that are about to expire.
+const SilenceExpiringAlertName = "SilenceExpiring"
+
+// SilenceExpiryNotifier notifies a receiver about silences that are about to
+// expire. Only silences asking for it are notified, once for each end time.
+// Silences may set their own lead time, which overrides the default one.
+type SilenceExpiryNotifier struct {
+	silences     *silence.Silences
+	receiver     string
+	integrations []Integration
+	before       time.Duration
+	// wait returns how long the peer waits for others to send a notification,
+	// so that only one peer of a cluster notifies about a silence.
+	wait   func() time.Duration
+	logger log.Logger
+
+	now func() time.Time
+
+	mtx sync.Mutex
+	// delivered holds the integrations already notified about the upcoming
+	// expiry of silences by their ID, so that only failed integrations are
+	// retried.
+	delivered map[string]*expiryDelivery
+}
+
+// expiryDelivery is the set of integrations notified about a silence ending
+// at a time.
+type expiryDelivery struct {
+	endsAt       time.Time
+	integrations map[string]struct{}
+}
+
+// NewSilenceExpiryNotifier returns a new SilenceExpiryNotifier notifying the
+// integrations of the given receiver about silences ending within before,
+// unless they set their own lead time.
+func NewSilenceExpiryNotifier(
+	s *silence.Silences,
+	receiver string,
+	integrations []Integration,
+	before time.Duration,
+	wait func() time.Duration,
+	l log.Logger,
+) *SilenceExpiryNotifier {
+	return &SilenceExpiryNotifier{
+		silences:     s,
+		receiver:     receiver,
+		integrations: integrations,
+		before:       before,
+		wait:         wait,
+		logger:       l,
+		now:          utcNow,
+		delivered:    map[string]*expiryDelivery{},
+	}
+}
+
+// Run checks the silences at the given interval until the context is
+// canceled.
+func (n *SilenceExpiryNotifier) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := n.Check(ctx); err != nil {
+				level.Error(n.logger).Log("msg", "Checking silence expiry failed", "err", err)
+			}
+		}
+	}
+}
+
+// Check notifies about the active silences ending soon that were not notified
+// about yet.
+func (n *SilenceExpiryNotifier) Check(ctx context.Context) error {
+	sils, _, err := n.silences.Query(silence.QState(types.SilenceStateActive))
+	if err != nil {
+		return err
+	}
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	now := n.now()
+	// Later peers see silences as due later, by which time the notification
+	// of an earlier peer has been gossiped.
+	wait := n.wait()
+	pending := make(map[string]struct{}, len(n.delivered))
+	for _, sil := range sils {
+		if !sil.NotifyExpiry || sil.ExpiryNotified {
+			continue
+		}
+		before := n.before
+		if sil.NotifyBefore > 0 {
+			before = sil.NotifyBefore
+		}
+		if sil.EndsAt.After(now.Add(before - wait)) {
+			continue
+		}
+		pending[sil.Id] = struct{}{}
+		if !n.notify(ctx, sil, now) {
+			continue
+		}
+		if err := n.silences.MarkExpiryNotified(sil.Id); err != nil {
+			level.Error(n.logger).Log("msg", "Marking silence expiry as notified failed", "silence", sil.Id, "err", err)
+			continue
+		}
+		delete(n.delivered, sil.Id)
+	}
+	// Forget about the silences that were expired, updated or notified about
+	// by another peer in the meantime.
+	for id := range n.delivered {
+		if _, ok := pending[id]; !ok {
+			delete(n.delivered, id)
+		}
+	}
+	return nil
+}
+
+// notify sends the notification about the silence to the integrations that
+// weren't notified about it yet and returns whether all of them were.
+func (n *SilenceExpiryNotifier) notify(ctx context.Context, sil *silencepb.Silence, now time.Time) bool {
+	lset := model.LabelSet{
+		model.AlertNameLabel: SilenceExpiringAlertName,
+		"silence_id":         model.LabelValue(sil.Id),
+	}
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: lset,
+			Annotations: model.LabelSet{
+				"summary":    model.LabelValue(fmt.Sprintf("Silence %s expires at %s", sil.Id, sil.EndsAt.Format(time.RFC3339))),
+				"comment":    model.LabelValue(sil.Comment),
+				"created_by": model.LabelValue(sil.CreatedBy),
+				"ends_at":    model.LabelValue(sil.EndsAt.Format(time.RFC3339)),
+			},
+			StartsAt: now,
+			EndsAt:   sil.EndsAt,
+		},
+		UpdatedAt: now,
+	}
+
+	ctx = WithGroupKey(ctx, "silence_expiry:"+lset.String())
+	ctx = WithReceiverName(ctx, n.receiver)
+	ctx = WithGroupLabels(ctx, lset)
+	ctx = WithNow(ctx, now)
+
+	d, found := n.delivered[sil.Id]
+	if !found || !d.endsAt.Equal(sil.EndsAt) {
+		d = &expiryDelivery{endsAt: sil.EndsAt, integrations: map[string]struct{}{}}
+		n.delivered[sil.Id] = d
+	}
+
+	ok := true
+	for _, i := range n.integrations {
+		if _, done := d.integrations[i.String()]; done {
+			continue
+		}
+		if _, err := i.Notify(ctx, alert); err != nil {
+			level.Error(n.logger).Log("msg", "Notify for silence expiry failed", "silence", sil.Id, "receiver", n.receiver, "integration", i.String(), "err", err)
+			ok = false
+			continue
+		}
+		d.integrations[i.String()] = struct{}{}
+	}
+	return ok
+}
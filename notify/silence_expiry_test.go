@@ -0,0 +1,229 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/types"
+)
+
+func TestSilenceExpiryNotifier(t *testing.T) {
+	silences, err := silence.New(silence.Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := utcNow()
+	id, err := silences.Set(&silencepb.Silence{
+		EndsAt:       now.Add(2 * time.Hour),
+		Matchers:     []*silencepb.Matcher{{Name: "mute", Pattern: "me"}},
+		CreatedBy:    "alice",
+		Comment:      "maintenance",
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+	// Silences not asking for it are never notified.
+	_, err = silences.Set(&silencepb.Silence{
+		EndsAt:   now.Add(2 * time.Hour),
+		Matchers: []*silencepb.Matcher{{Name: "mute", Pattern: "too"}},
+	})
+	require.NoError(t, err)
+
+	var (
+		got  []*types.Alert
+		fail = true
+	)
+	i := Integration{
+		name: "test",
+		notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			rcv, ok := ReceiverName(ctx)
+			require.True(t, ok)
+			require.Equal(t, "expiry", rcv)
+			_, ok = GroupKey(ctx)
+			require.True(t, ok)
+			if fail {
+				return false, errors.New("failure")
+			}
+			got = append(got, alerts...)
+			return false, nil
+		}),
+	}
+	n := NewSilenceExpiryNotifier(silences, "expiry", []Integration{i}, time.Hour, func() time.Duration { return 0 }, log.NewNopLogger())
+
+	// The silence doesn't expire soon.
+	n.now = func() time.Time { return now }
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 0)
+
+	// A failed notification is retried at the next check.
+	n.now = func() time.Time { return now.Add(90 * time.Minute) }
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 0)
+
+	fail = false
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 1)
+	require.Equal(t, model.LabelSet{
+		model.AlertNameLabel: SilenceExpiringAlertName,
+		"silence_id":         model.LabelValue(id),
+	}, got[0].Labels)
+	require.Equal(t, model.LabelValue("alice"), got[0].Annotations["created_by"])
+	require.Equal(t, model.LabelValue("maintenance"), got[0].Annotations["comment"])
+
+	sil, err := silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+	require.True(t, sil.ExpiryNotified)
+
+	// The silence is notified about only once.
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 1)
+
+	// Extending the silence asks for a new notification ahead of its new end.
+	// The silences use the wall clock, so the update must come after the
+	// marking.
+	time.Sleep(time.Millisecond)
+	sil.EndsAt = now.Add(4 * time.Hour)
+	_, err = silences.Set(sil)
+	require.NoError(t, err)
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 1)
+
+	n.now = func() time.Time { return now.Add(3*time.Hour + 30*time.Minute) }
+	require.NoError(t, n.Check(context.Background()))
+	require.Len(t, got, 2)
+	require.Equal(t, model.LabelValue(sil.EndsAt.Format(time.RFC3339)), got[1].Annotations["ends_at"])
+}
+
+func TestSilenceExpiryNotifierRetriesFailedIntegrations(t *testing.T) {
+	silences, err := silence.New(silence.Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := utcNow()
+	id, err := silences.Set(&silencepb.Silence{
+		EndsAt:       now.Add(30 * time.Minute),
+		Matchers:     []*silencepb.Matcher{{Name: "mute", Pattern: "me"}},
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+
+	var (
+		notified = map[string]int{}
+		fail     = true
+	)
+	newIntegration := func(idx int) Integration {
+		i := Integration{name: "test", idx: idx}
+		i.notifier = notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			if idx == 1 && fail {
+				return true, errors.New("failure")
+			}
+			notified[i.String()]++
+			return false, nil
+		})
+		return i
+	}
+	n := NewSilenceExpiryNotifier(silences, "expiry", []Integration{newIntegration(0), newIntegration(1)}, time.Hour, func() time.Duration { return 0 }, log.NewNopLogger())
+
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, map[string]int{"test[0]": 1}, notified)
+
+	// Only the failed integration is retried.
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, map[string]int{"test[0]": 1}, notified)
+
+	fail = false
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, map[string]int{"test[0]": 1, "test[1]": 1}, notified)
+
+	sil, err := silences.QueryOne(silence.QIDs(id))
+	require.NoError(t, err)
+	require.True(t, sil.ExpiryNotified)
+	require.Empty(t, n.delivered)
+}
+
+func TestSilenceExpiryNotifierNotifyBefore(t *testing.T) {
+	silences, err := silence.New(silence.Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := utcNow()
+	_, err = silences.Set(&silencepb.Silence{
+		EndsAt:       now.Add(4 * time.Hour),
+		Matchers:     []*silencepb.Matcher{{Name: "mute", Pattern: "me"}},
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+	early, err := silences.Set(&silencepb.Silence{
+		EndsAt:       now.Add(4 * time.Hour),
+		Matchers:     []*silencepb.Matcher{{Name: "mute", Pattern: "early"}},
+		NotifyExpiry: true,
+		NotifyBefore: 3 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	var got []string
+	i := Integration{
+		name: "test",
+		notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			for _, a := range alerts {
+				got = append(got, string(a.Labels["silence_id"]))
+			}
+			return false, nil
+		}),
+	}
+	n := NewSilenceExpiryNotifier(silences, "expiry", []Integration{i}, time.Hour, func() time.Duration { return 0 }, log.NewNopLogger())
+
+	// The lead time of the silence overrides the default one.
+	n.now = func() time.Time { return now.Add(90 * time.Minute) }
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, []string{early}, got)
+}
+
+func TestSilenceExpiryNotifierWait(t *testing.T) {
+	silences, err := silence.New(silence.Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := utcNow()
+	_, err = silences.Set(&silencepb.Silence{
+		EndsAt:       now.Add(2 * time.Hour),
+		Matchers:     []*silencepb.Matcher{{Name: "mute", Pattern: "me"}},
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+
+	var notified int
+	i := Integration{
+		name: "test",
+		notifier: notifierFunc(func(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+			notified++
+			return false, nil
+		}),
+	}
+	n := NewSilenceExpiryNotifier(silences, "expiry", []Integration{i}, time.Hour, func() time.Duration { return 15 * time.Minute }, log.NewNopLogger())
+
+	// Within the window but not past the wait of the peer.
+	n.now = func() time.Time { return now.Add(70 * time.Minute) }
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, 0, notified)
+
+	n.now = func() time.Time { return now.Add(80 * time.Minute) }
+	require.NoError(t, n.Check(context.Background()))
+	require.Equal(t, 1, notified)
+}
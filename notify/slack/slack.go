@@ -47,6 +47,13 @@ func New(c *config.SlackConfig, t *template.Template, l log.Logger, httpOpts ...
 	if err != nil {
 		return nil, err
 	}
+	for v, st := range c.Templates {
+		for _, text := range []string{st.Color, st.Title, st.TitleLink, st.Pretext, st.Text, st.Footer, st.Fallback} {
+			if err := t.CheckText(text); err != nil {
+				return nil, errors.Wrapf(err, "template for %s=%q", c.TemplateLabel, v)
+			}
+		}
+	}
 
 	return &Notifier{
 		conf:    c,
@@ -84,6 +91,49 @@ type attachment struct {
 	MrkdwnIn   []string             `json:"mrkdwn_in,omitempty"`
 }
 
+// attachmentTemplate returns the configuration of the attachment, with the
+// fields of the message template selected by the common value of the
+// template label taking precedence.
+func (n *Notifier) attachmentTemplate(data *template.Data) config.SlackTemplate {
+	at := config.SlackTemplate{
+		Color:     n.conf.Color,
+		Title:     n.conf.Title,
+		TitleLink: n.conf.TitleLink,
+		Pretext:   n.conf.Pretext,
+		Text:      n.conf.Text,
+		Footer:    n.conf.Footer,
+		Fallback:  n.conf.Fallback,
+	}
+	if n.conf.TemplateLabel == "" {
+		return at
+	}
+	v, ok := data.CommonLabels[n.conf.TemplateLabel]
+	if !ok {
+		return at
+	}
+	st, ok := n.conf.Templates[v]
+	if !ok {
+		return at
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&at.Color, st.Color},
+		{&at.Title, st.Title},
+		{&at.TitleLink, st.TitleLink},
+		{&at.Pretext, st.Pretext},
+		{&at.Text, st.Text},
+		{&at.Footer, st.Footer},
+		{&at.Fallback, st.Fallback},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	return at
+}
+
 // Notify implements the Notifier interface.
 func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 	var err error
@@ -97,17 +147,18 @@ func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 	} else {
 		markdownIn = n.conf.MrkdwnIn
 	}
+	at := n.attachmentTemplate(data)
 	att := &attachment{
-		Title:      tmplText(n.conf.Title),
-		TitleLink:  tmplText(n.conf.TitleLink),
-		Pretext:    tmplText(n.conf.Pretext),
-		Text:       tmplText(n.conf.Text),
-		Fallback:   tmplText(n.conf.Fallback),
+		Title:      tmplText(at.Title),
+		TitleLink:  tmplText(at.TitleLink),
+		Pretext:    tmplText(at.Pretext),
+		Text:       tmplText(at.Text),
+		Fallback:   tmplText(at.Fallback),
 		CallbackID: tmplText(n.conf.CallbackID),
 		ImageURL:   tmplText(n.conf.ImageURL),
 		ThumbURL:   tmplText(n.conf.ThumbURL),
-		Footer:     tmplText(n.conf.Footer),
-		Color:      tmplText(n.conf.Color),
+		Footer:     tmplText(at.Footer),
+		Color:      tmplText(at.Color),
 		MrkdwnIn:   markdownIn,
 	}
 
@@ -197,7 +248,7 @@ func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error)
 	// Only 5xx response codes are recoverable and 2xx codes are successful.
 	// https://api.slack.com/incoming-webhooks#handling_errors
 	// https://api.slack.com/changelog/2016-05-17-changes-to-errors-for-incoming-webhooks
-	retry, err := n.retrier.Check(resp.StatusCode, resp.Body)
+	retry, err := n.retrier.CheckResponse(resp)
 	err = errors.Wrap(err, fmt.Sprintf("channel %q", req.Channel))
 	return retry, err
 }
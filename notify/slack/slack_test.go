@@ -14,16 +14,26 @@
 package slack
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/notify/test"
+	"github.com/prometheus/alertmanager/types"
 )
 
 func TestSlackRetry(t *testing.T) {
@@ -80,3 +90,142 @@ func TestGettingSlackURLFromFile(t *testing.T) {
 
 	test.AssertNotifyLeaksNoSecret(t, ctx, notifier, u.String())
 }
+
+func TestSlackThrottled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("rate_limited"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	notifier, err := New(
+		&config.SlackConfig{
+			APIURL:     &config.SecretURL{URL: u},
+			HTTPConfig: &commoncfg.HTTPClientConfig{},
+		},
+		test.CreateTmpl(t),
+		log.NewNopLogger(),
+	)
+	require.NoError(t, err)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	retry, err := notifier.Notify(ctx, &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "a"},
+			StartsAt: time.Now(),
+		},
+	})
+	require.False(t, retry)
+
+	var te *notify.ThrottledError
+	require.True(t, errors.As(err, &te), "expected a throttled error, got %v", err)
+	require.Equal(t, 30*time.Second, te.RetryAfter)
+}
+
+func TestSlackTemplateSelection(t *testing.T) {
+	var got attachment
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Attachments, 1)
+		got = req.Attachments[0]
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	notifier, err := New(
+		&config.SlackConfig{
+			APIURL:        &config.SecretURL{URL: u},
+			HTTPConfig:    &commoncfg.HTTPClientConfig{},
+			Title:         "default title",
+			Text:          "default text",
+			TemplateLabel: "service",
+			Templates: map[string]*config.SlackTemplate{
+				"db": {
+					Title: `db {{ .CommonLabels.alertname }}`,
+				},
+			},
+		},
+		test.CreateTmpl(t),
+		log.NewNopLogger(),
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		title  string
+		labels []model.LabelSet
+
+		expTitle string
+		expText  string
+	}{
+		{
+			title:    "template selected by label",
+			labels:   []model.LabelSet{{"alertname": "a", "service": "db"}},
+			expTitle: "db a",
+			expText:  "default text",
+		},
+		{
+			title:    "no template for the label value",
+			labels:   []model.LabelSet{{"alertname": "a", "service": "web"}},
+			expTitle: "default title",
+			expText:  "default text",
+		},
+		{
+			title:    "label missing",
+			labels:   []model.LabelSet{{"alertname": "a"}},
+			expTitle: "default title",
+			expText:  "default text",
+		},
+		{
+			title: "label value not common to all alerts",
+			labels: []model.LabelSet{
+				{"alertname": "a", "service": "db"},
+				{"alertname": "a", "service": "web"},
+			},
+			expTitle: "default title",
+			expText:  "default text",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var alerts []*types.Alert
+			for _, ls := range tc.labels {
+				alerts = append(alerts, &types.Alert{
+					Alert: model.Alert{
+						Labels:   ls,
+						StartsAt: time.Now(),
+					},
+				})
+			}
+			ctx := notify.WithGroupKey(context.Background(), "1")
+			_, err := notifier.Notify(ctx, alerts...)
+			require.NoError(t, err)
+			require.Equal(t, tc.expTitle, got.Title)
+			require.Equal(t, tc.expText, got.Text)
+		})
+	}
+}
+
+func TestSlackInvalidTemplate(t *testing.T) {
+	for _, text := range []string{
+		`{{ template "undefined" . }}`,
+		`{{ .CommonLabels`,
+	} {
+		_, err := New(
+			&config.SlackConfig{
+				HTTPConfig:    &commoncfg.HTTPClientConfig{},
+				TemplateLabel: "service",
+				Templates: map[string]*config.SlackTemplate{
+					"db": {Text: text},
+				},
+			},
+			test.CreateTmpl(t),
+			log.NewNopLogger(),
+		)
+		require.Error(t, err, text)
+	}
+}
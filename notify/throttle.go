@@ -0,0 +1,245 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ThrottledError is returned by notifiers whose request was rate limited by
+// the receiving service.
+type ThrottledError struct {
+	Err error
+	// RetryAfter is the time to wait before sending the next request as
+	// requested by the service. It is zero if the service didn't tell.
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// parseRetryAfter returns the duration of the Retry-After header of a
+// response, given either in seconds or as an HTTP date. It returns zero if
+// the header is missing or invalid.
+func parseRetryAfter(resp *http.Response, now time.Time) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0
+		}
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// ThrottleState is the latest rate limiting of an integration.
+type ThrottleState struct {
+	// LastThrottledAt is the time the latest rate limited response was
+	// received.
+	LastThrottledAt time.Time `json:"lastThrottledAt"`
+	// NextAttemptAt is the time after which the service allows sending
+	// again. It equals LastThrottledAt if the service didn't tell.
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+}
+
+// Throttles records the rate limiting of the integrations of receivers.
+type Throttles struct {
+	mtx sync.RWMutex
+	// states is keyed by receiver and integration.
+	states map[string]map[string]ThrottleState
+}
+
+// NewThrottles returns new, empty Throttles.
+func NewThrottles() *Throttles {
+	return &Throttles{states: map[string]map[string]ThrottleState{}}
+}
+
+// Record records a rate limited response received by the integration of the
+// receiver at the given time, asking to wait retryAfter before sending again.
+func (t *Throttles) Record(receiver, integration string, at time.Time, retryAfter time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.states[receiver] == nil {
+		t.states[receiver] = map[string]ThrottleState{}
+	}
+	t.states[receiver][integration] = ThrottleState{
+		LastThrottledAt: at,
+		NextAttemptAt:   at.Add(retryAfter),
+	}
+}
+
+// Receiver returns the latest rate limiting of the integrations of the given
+// receiver, keyed by integration. Integrations that were never rate limited
+// are omitted.
+func (t *Throttles) Receiver(name string) map[string]ThrottleState {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+
+	res := make(map[string]ThrottleState, len(t.states[name]))
+	for i, s := range t.states[name] {
+		res[i] = s
+	}
+	return res
+}
+
+// ErrRateLimited is returned for notifications dropped by the rate limit of
+// their receiver.
+var ErrRateLimited = errors.New("notification dropped by the rate limit of the receiver")
+
+// RateLimiter is a token bucket limiting the notifications of the
+// integrations of a receiver.
+type RateLimiter struct {
+	mtx      sync.Mutex
+	limit    int
+	interval time.Duration
+	drop     bool
+	tokens   float64
+	last     time.Time
+
+	now func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing limit notifications per
+// interval, with bursts of up to limit notifications. Notifications over the
+// limit are dropped if drop is true and delayed otherwise.
+func NewRateLimiter(limit int, interval time.Duration, drop bool) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		interval: interval,
+		drop:     drop,
+		tokens:   float64(limit),
+		now:      time.Now,
+	}
+}
+
+// reserve takes a token from the bucket and returns the time to wait until
+// it is available. It returns ErrRateLimited if the bucket is empty and
+// notifications over the limit are dropped.
+func (l *RateLimiter) reserve() (time.Duration, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	perToken := l.interval / time.Duration(l.limit)
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(perToken)
+		if l.tokens > float64(l.limit) {
+			l.tokens = float64(l.limit)
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, nil
+	}
+	if l.drop {
+		return 0, ErrRateLimited
+	}
+	// Delayed notifications reserve their token in advance, so that
+	// queueing notifications wait in turn.
+	wait := time.Duration((1 - l.tokens) * float64(perToken))
+	l.tokens--
+	return wait, nil
+}
+
+// Wait blocks until the next notification may be sent and returns the time
+// it was delayed. It returns ErrRateLimited if the notification is dropped,
+// and the error of the context if it is done before, in which case the
+// reserved token is given back.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	wait, err := l.reserve()
+	if err != nil || wait == 0 {
+		return 0, err
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return wait, nil
+	case <-ctx.Done():
+		l.cancel()
+		return 0, ctx.Err()
+	}
+}
+
+// cancel gives back a token reserved by a notification that was abandoned
+// while waiting for it.
+func (l *RateLimiter) cancel() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.tokens++
+	if l.tokens > float64(l.limit) {
+		l.tokens = float64(l.limit)
+	}
+}
+
+// ConcurrencyLimiter bounds the number of notifications in flight across all
+// receivers, so that bursts of notifications don't exhaust the connections
+// or file descriptors of the process.
+type ConcurrencyLimiter struct {
+	// sem holds a token per notification in flight. It is nil if the
+	// number isn't limited.
+	sem      chan struct{}
+	inFlight prometheus.Gauge
+	queued   prometheus.Gauge
+}
+
+// acquire blocks until the notification may be sent, or returns the error of
+// the context if it is done before. A nil limiter doesn't limit.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if l.sem != nil {
+		l.queued.Inc()
+		select {
+		case l.sem <- struct{}{}:
+			l.queued.Dec()
+		case <-ctx.Done():
+			l.queued.Dec()
+			return ctx.Err()
+		}
+	}
+	l.inFlight.Inc()
+	return nil
+}
+
+// release ends a notification started by acquire.
+func (l *ConcurrencyLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.inFlight.Dec()
+	if l.sem != nil {
+		<-l.sem
+	}
+}
@@ -21,11 +21,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
 
+	"github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/version"
@@ -94,6 +96,29 @@ func Truncate(s string, n int) (string, bool) {
 	return string(r[:n-3]) + "...", true
 }
 
+// SeverityToPriority returns the priority of the most severe of the alerts by
+// the mapping, or the default priority of the mapping if none of their
+// severities is mapped. It returns an empty string for a nil mapping.
+func SeverityToPriority(m *config.PriorityMapping, alerts ...*types.Alert) string {
+	if m == nil {
+		return ""
+	}
+	best := len(m.Priorities)
+	for _, a := range alerts {
+		severity := string(a.Labels[m.Label])
+		for i := 0; i < best; i++ {
+			if m.Priorities[i].Severity == severity {
+				best = i
+				break
+			}
+		}
+	}
+	if best == len(m.Priorities) {
+		return m.Default
+	}
+	return m.Priorities[best].Priority
+}
+
 // TmplText is using monadic error handling in order to make string templating
 // less verbose. Use with care as the final error checking is easily missed.
 func TmplText(tmpl *template.Template, data *template.Data, err *error) func(string) string {
@@ -210,3 +235,13 @@ func (r *Retrier) Check(statusCode int, body io.Reader) (bool, error) {
 	}
 	return retry, errors.New(s)
 }
+
+// CheckResponse is like Check for the status code and body of the response.
+// The error is a *ThrottledError if the response signals rate limiting.
+func (r *Retrier) CheckResponse(resp *http.Response) (bool, error) {
+	retry, err := r.Check(resp.StatusCode, resp.Body)
+	if err != nil && resp.StatusCode == http.StatusTooManyRequests {
+		err = &ThrottledError{Err: err, RetryAfter: parseRetryAfter(resp, time.Now())}
+	}
+	return retry, err
+}
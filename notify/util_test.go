@@ -15,15 +15,44 @@ package notify
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/types"
 )
 
+func TestSeverityToPriority(t *testing.T) {
+	m := &config.PriorityMapping{
+		Label: "severity",
+		Priorities: []config.SeverityPriority{
+			{Severity: "critical", Priority: "P1"},
+			{Severity: "warning", Priority: "P3"},
+		},
+		Default: "P5",
+	}
+	alert := func(severity string) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"severity": model.LabelValue(severity)}}}
+	}
+
+	require.Equal(t, "P3", SeverityToPriority(m, alert("warning")))
+	// The most severe alert determines the priority.
+	require.Equal(t, "P1", SeverityToPriority(m, alert("info"), alert("warning"), alert("critical")))
+	require.Equal(t, "P3", SeverityToPriority(m, alert("info"), alert("warning")))
+	// Unknown severities fall through to the default.
+	require.Equal(t, "P5", SeverityToPriority(m, alert("info"), &types.Alert{}))
+	require.Equal(t, "P5", SeverityToPriority(m))
+	require.Equal(t, "", SeverityToPriority(nil, alert("critical")))
+}
+
 func TestTruncate(t *testing.T) {
 	testCases := []struct {
 		in string
@@ -177,3 +206,63 @@ func TestRetrierCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrierCheckResponse(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		status     int
+		retryAfter string
+
+		throttled bool
+		expected  time.Duration
+	}{
+		{
+			status: http.StatusOK,
+		},
+		{
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			status:    http.StatusTooManyRequests,
+			throttled: true,
+		},
+		{
+			status:     http.StatusTooManyRequests,
+			retryAfter: "30",
+			throttled:  true,
+			expected:   30 * time.Second,
+		},
+		{
+			status:     http.StatusTooManyRequests,
+			retryAfter: now.Add(time.Hour).UTC().Format(http.TimeFormat),
+			throttled:  true,
+			expected:   time.Hour,
+		},
+		{
+			status:     http.StatusTooManyRequests,
+			retryAfter: "soon",
+			throttled:  true,
+		},
+	} {
+		t.Run(tc.retryAfter, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tc.status,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewBufferString("slow down")),
+			}
+			if tc.retryAfter != "" {
+				resp.Header.Set("Retry-After", tc.retryAfter)
+			}
+
+			_, err := (&Retrier{}).CheckResponse(resp)
+			var te *ThrottledError
+			require.Equal(t, tc.throttled, errors.As(err, &te))
+			if !tc.throttled {
+				return
+			}
+			require.EqualError(t, err, "unexpected status code 429: slow down")
+			// HTTP dates have a precision of one second.
+			require.InDelta(t, tc.expected.Seconds(), te.RetryAfter.Seconds(), 1)
+		})
+	}
+}
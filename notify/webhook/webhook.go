@@ -18,7 +18,9 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"math/rand"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -32,10 +34,22 @@ import (
 
 // Notifier implements a Notifier for generic webhooks.
 type Notifier struct {
-	conf    *config.WebhookConfig
-	tmpl    *template.Template
-	logger  log.Logger
-	client  *http.Client
+	conf      *config.WebhookConfig
+	tmpl      *template.Template
+	logger    log.Logger
+	client    *http.Client
+	endpoints []endpoint
+
+	// next is the index of the endpoint tried first by the next
+	// notification when using the round-robin strategy.
+	next uint64
+	// intn returns a random index for the random strategy.
+	intn func(int) int
+}
+
+// endpoint is a URL notifications are sent to.
+type endpoint struct {
+	url     string
 	retrier *notify.Retrier
 }
 
@@ -45,21 +59,49 @@ func New(conf *config.WebhookConfig, t *template.Template, l log.Logger, httpOpt
 	if err != nil {
 		return nil, err
 	}
-	return &Notifier{
-		conf:   conf,
-		tmpl:   t,
-		logger: l,
-		client: client,
-		// Webhooks are assumed to respond with 2xx response codes on a successful
-		// request and 5xx response codes are assumed to be recoverable.
-		retrier: &notify.Retrier{
-			CustomDetailsFunc: func(int, io.Reader) string {
-				return conf.URL.String()
+	urls := conf.URLs
+	if conf.URL != nil {
+		urls = []*config.URL{conf.URL}
+	}
+	endpoints := make([]endpoint, 0, len(urls))
+	for _, u := range urls {
+		u := u.String()
+		endpoints = append(endpoints, endpoint{
+			url: u,
+			// Webhooks are assumed to respond with 2xx response codes on a successful
+			// request and 5xx response codes are assumed to be recoverable.
+			retrier: &notify.Retrier{
+				CustomDetailsFunc: func(int, io.Reader) string {
+					return u
+				},
 			},
-		},
+		})
+	}
+	return &Notifier{
+		conf:      conf,
+		tmpl:      t,
+		logger:    l,
+		client:    client,
+		endpoints: endpoints,
+		intn:      rand.Intn,
 	}, nil
 }
 
+// order returns the endpoints in the order they are tried for a notification.
+func (n *Notifier) order() []endpoint {
+	if len(n.endpoints) == 1 {
+		return n.endpoints
+	}
+	var start int
+	switch n.conf.URLStrategy {
+	case config.WebhookURLStrategyRandom:
+		start = n.intn(len(n.endpoints))
+	default:
+		start = int((atomic.AddUint64(&n.next, 1) - 1) % uint64(len(n.endpoints)))
+	}
+	return append(append(make([]endpoint, 0, len(n.endpoints)), n.endpoints[start:]...), n.endpoints[:start]...)
+}
+
 // Message defines the JSON object send to webhook endpoints.
 type Message struct {
 	*template.Data
@@ -68,6 +110,32 @@ type Message struct {
 	Version         string `json:"version"`
 	GroupKey        string `json:"groupKey"`
 	TruncatedAlerts uint64 `json:"truncatedAlerts"`
+	// Metadata describes the sending Alertmanager, if configured.
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Metadata defines the JSON object describing the sending Alertmanager.
+type Metadata struct {
+	Instance       string      `json:"instance,omitempty"`
+	Cluster        string      `json:"cluster,omitempty"`
+	ExternalLabels template.KV `json:"externalLabels,omitempty"`
+}
+
+func metadata(conf *config.WebhookMetadata) *Metadata {
+	if conf == nil {
+		return nil
+	}
+	md := &Metadata{
+		Instance: conf.Instance,
+		Cluster:  conf.Cluster,
+	}
+	if len(conf.ExternalLabels) > 0 {
+		md.ExternalLabels = template.KV{}
+		for k, v := range conf.ExternalLabels {
+			md.ExternalLabels[string(k)] = string(v)
+		}
+	}
+	return md
 }
 
 func truncateAlerts(maxAlerts uint64, alerts []*types.Alert) ([]*types.Alert, uint64) {
@@ -78,6 +146,49 @@ func truncateAlerts(maxAlerts uint64, alerts []*types.Alert) ([]*types.Alert, ui
 	return alerts, 0
 }
 
+// filterPayload removes the labels and annotations from the template data
+// that the configuration excludes from webhook messages.
+func filterPayload(data *template.Data, conf *config.WebhookConfig) {
+	filterLabels := func(kv template.KV) template.KV {
+		return filterKV(kv, conf.IncludeLabels, conf.ExcludeLabels)
+	}
+	filterAnnotations := func(kv template.KV) template.KV {
+		return filterKV(kv, conf.IncludeAnnotations, conf.ExcludeAnnotations)
+	}
+
+	data.GroupLabels = filterLabels(data.GroupLabels)
+	data.CommonLabels = filterLabels(data.CommonLabels)
+	data.CommonAnnotations = filterAnnotations(data.CommonAnnotations)
+	for i := range data.Alerts {
+		data.Alerts[i].Labels = filterLabels(data.Alerts[i].Labels)
+		data.Alerts[i].Annotations = filterAnnotations(data.Alerts[i].Annotations)
+	}
+}
+
+// filterKV returns a copy of kv restricted to the included keys, if any are
+// given, and without the excluded keys.
+func filterKV(kv template.KV, include, exclude []string) template.KV {
+	if len(include) == 0 && len(exclude) == 0 {
+		return kv
+	}
+	res := template.KV{}
+	if len(include) > 0 {
+		for _, k := range include {
+			if v, ok := kv[k]; ok {
+				res[k] = v
+			}
+		}
+		return res
+	}
+	for k, v := range kv {
+		res[k] = v
+	}
+	for _, k := range exclude {
+		delete(res, k)
+	}
+	return res
+}
+
 // Notify implements the Notifier interface.
 func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
 	alerts, numTruncated := truncateAlerts(n.conf.MaxAlerts, alerts)
@@ -88,11 +199,14 @@ func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, er
 		level.Error(n.logger).Log("err", err)
 	}
 
+	filterPayload(data, n.conf)
+
 	msg := &Message{
 		Version:         "4",
 		Data:            data,
 		GroupKey:        groupKey.String(),
 		TruncatedAlerts: numTruncated,
+		Metadata:        metadata(n.conf.Metadata),
 	}
 
 	var buf bytes.Buffer
@@ -100,11 +214,48 @@ func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, er
 		return false, err
 	}
 
-	resp, err := notify.PostJSON(ctx, n.client, n.conf.URL.String(), &buf)
-	if err != nil {
-		return true, err
+	// Fall through to the next endpoint if sending fails. The last error is
+	// returned if all endpoints fail.
+	var (
+		retry bool
+		body  = buf.Bytes()
+	)
+	for i, e := range n.order() {
+		if i > 0 {
+			level.Warn(n.logger).Log("msg", "Trying next webhook endpoint", "err", err)
+		}
+		resp, postErr := n.post(ctx, e.url, body)
+		if postErr != nil {
+			retry, err = true, postErr
+		} else {
+			notify.Drain(resp)
+			retry, err = e.retrier.Check(resp.StatusCode, nil)
+		}
+		if err == nil || ctx.Err() != nil {
+			break
+		}
 	}
-	notify.Drain(resp)
+	return retry, err
+}
 
-	return n.retrier.Check(resp.StatusCode, nil)
+// post sends the message to the URL, along with the metadata headers if
+// configured.
+func (n *Notifier) post(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	md := n.conf.Metadata
+	if md == nil || !md.SendHeaders {
+		return notify.PostJSON(ctx, n.client, url, bytes.NewReader(body))
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", notify.UserAgentHeader)
+	req.Header.Set("Content-Type", "application/json")
+	if md.Instance != "" {
+		req.Header.Set("X-Alertmanager-Instance", md.Instance)
+	}
+	if md.Cluster != "" {
+		req.Header.Set("X-Alertmanager-Cluster", md.Cluster)
+	}
+	return n.client.Do(req.WithContext(ctx))
 }
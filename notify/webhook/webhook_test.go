@@ -14,15 +14,24 @@
 package webhook
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	commoncfg "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/notify/test"
 	"github.com/prometheus/alertmanager/types"
 )
@@ -44,7 +53,7 @@ func TestWebhookRetry(t *testing.T) {
 		require.NoError(t, err)
 	}
 	for statusCode, expected := range test.RetryTests(test.DefaultRetryCodes()) {
-		actual, _ := notifier.retrier.Check(statusCode, nil)
+		actual, _ := notifier.endpoints[0].retrier.Check(statusCode, nil)
 		require.Equal(t, expected, actual, fmt.Sprintf("error on status %d", statusCode))
 	}
 }
@@ -64,3 +73,340 @@ func TestWebhookTruncateAlerts(t *testing.T) {
 	require.Len(t, truncatedAlerts, 10)
 	require.EqualValues(t, numTruncated, 0)
 }
+
+func TestWebhookPayloadFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		conf config.WebhookConfig
+
+		labels, annotations []string
+	}{
+		{
+			name:        "no filter",
+			labels:      []string{"alertname", "instance", "severity"},
+			annotations: []string{"description", "summary"},
+		},
+		{
+			name: "include",
+			conf: config.WebhookConfig{
+				IncludeLabels:      []string{"alertname", "severity"},
+				IncludeAnnotations: []string{"summary", "missing"},
+			},
+			labels:      []string{"alertname", "severity"},
+			annotations: []string{"summary"},
+		},
+		{
+			name: "exclude",
+			conf: config.WebhookConfig{
+				ExcludeLabels:      []string{"instance"},
+				ExcludeAnnotations: []string{"description"},
+			},
+			labels:      []string{"alertname", "severity"},
+			annotations: []string{"summary"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			}))
+			defer srv.Close()
+			u, err := url.Parse(srv.URL)
+			require.NoError(t, err)
+
+			conf := tc.conf
+			conf.URL = &config.URL{URL: u}
+			conf.HTTPConfig = &commoncfg.HTTPClientConfig{}
+			notifier, err := New(&conf, test.CreateTmpl(t), log.NewNopLogger())
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "1")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "HighLatency", "instance": "db-1"})
+			_, err = notifier.Notify(ctx, &types.Alert{
+				Alert: model.Alert{
+					Labels:      model.LabelSet{"alertname": "HighLatency", "instance": "db-1", "severity": "page"},
+					Annotations: model.LabelSet{"summary": "Latency is high", "description": "A very long description."},
+					StartsAt:    time.Now(),
+				},
+			})
+			require.NoError(t, err)
+
+			keys := func(v interface{}) []string {
+				res := []string{}
+				for k := range v.(map[string]interface{}) {
+					res = append(res, k)
+				}
+				sort.Strings(res)
+				return res
+			}
+			alert := msg["alerts"].([]interface{})[0].(map[string]interface{})
+			require.Equal(t, tc.labels, keys(alert["labels"]))
+			require.Equal(t, tc.labels, keys(msg["commonLabels"]))
+			require.Equal(t, tc.annotations, keys(alert["annotations"]))
+			require.Equal(t, tc.annotations, keys(msg["commonAnnotations"]))
+			for _, k := range keys(msg["groupLabels"]) {
+				require.Contains(t, tc.labels, k)
+			}
+		})
+	}
+}
+
+func TestWebhookMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		metadata *config.WebhookMetadata
+
+		expected interface{}
+		headers  http.Header
+	}{
+		{
+			name:     "none",
+			expected: nil,
+			headers:  http.Header{},
+		},
+		{
+			name: "payload",
+			metadata: &config.WebhookMetadata{
+				Instance:       "am-1",
+				Cluster:        "eu-west",
+				ExternalLabels: model.LabelSet{"region": "eu"},
+			},
+			expected: map[string]interface{}{
+				"instance":       "am-1",
+				"cluster":        "eu-west",
+				"externalLabels": map[string]interface{}{"region": "eu"},
+			},
+			headers: http.Header{},
+		},
+		{
+			name: "headers",
+			metadata: &config.WebhookMetadata{
+				Cluster:     "eu-west",
+				SendHeaders: true,
+			},
+			expected: map[string]interface{}{"cluster": "eu-west"},
+			headers:  http.Header{"X-Alertmanager-Cluster": {"eu-west"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				msg     map[string]interface{}
+				headers = http.Header{}
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for _, h := range []string{"X-Alertmanager-Instance", "X-Alertmanager-Cluster"} {
+					if v, ok := r.Header[h]; ok {
+						headers[h] = v
+					}
+				}
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			}))
+			defer srv.Close()
+			u, err := url.Parse(srv.URL)
+			require.NoError(t, err)
+
+			notifier, err := New(&config.WebhookConfig{
+				URL:        &config.URL{URL: u},
+				HTTPConfig: &commoncfg.HTTPClientConfig{},
+				Metadata:   tc.metadata,
+			}, test.CreateTmpl(t), log.NewNopLogger())
+			require.NoError(t, err)
+
+			ctx := notify.WithGroupKey(context.Background(), "1")
+			_, err = notifier.Notify(ctx, &types.Alert{
+				Alert: model.Alert{
+					Labels:   model.LabelSet{"alertname": "HighLatency"},
+					StartsAt: time.Now(),
+				},
+			})
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				require.NotContains(t, msg, "metadata")
+			} else {
+				require.Equal(t, tc.expected, msg["metadata"])
+			}
+			require.Equal(t, tc.headers, headers)
+		})
+	}
+}
+
+func TestWebhookMultipleURLs(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		hits = map[int]int{}
+		fail = map[int]bool{}
+		urls []*config.URL
+	)
+	for i := 0; i < 3; i++ {
+		i := i
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			hits[i]++
+			if fail[i] {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer srv.Close()
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		urls = append(urls, &config.URL{URL: u})
+	}
+	reset := func(failing ...int) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		hits = map[int]int{}
+		fail = map[int]bool{}
+		for _, i := range failing {
+			fail[i] = true
+		}
+	}
+
+	newNotifier := func(strategy string) *Notifier {
+		notifier, err := New(
+			&config.WebhookConfig{
+				URLs:        urls,
+				URLStrategy: strategy,
+				HTTPConfig:  &commoncfg.HTTPClientConfig{},
+			},
+			test.CreateTmpl(t),
+			log.NewNopLogger(),
+		)
+		require.NoError(t, err)
+		return notifier
+	}
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighLatency"},
+			StartsAt: time.Now(),
+		},
+	}
+
+	t.Run("round robin", func(t *testing.T) {
+		reset()
+		notifier := newNotifier("")
+		for i := 0; i < 6; i++ {
+			_, err := notifier.Notify(ctx, alert)
+			require.NoError(t, err)
+		}
+		require.Equal(t, map[int]int{0: 2, 1: 2, 2: 2}, hits)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		reset()
+		notifier := newNotifier(config.WebhookURLStrategyRandom)
+		starts := []int{2, 0, 2}
+		notifier.intn = func(n int) int {
+			require.Equal(t, 3, n)
+			i := starts[0]
+			starts = starts[1:]
+			return i
+		}
+		for i := 0; i < 3; i++ {
+			_, err := notifier.Notify(ctx, alert)
+			require.NoError(t, err)
+		}
+		require.Equal(t, map[int]int{0: 1, 2: 2}, hits)
+	})
+
+	t.Run("failover", func(t *testing.T) {
+		reset(0, 1)
+		notifier := newNotifier(config.WebhookURLStrategyRoundRobin)
+		_, err := notifier.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.Equal(t, map[int]int{0: 1, 1: 1, 2: 1}, hits)
+	})
+
+	t.Run("all failing", func(t *testing.T) {
+		reset(0, 1, 2)
+		notifier := newNotifier(config.WebhookURLStrategyRoundRobin)
+		retry, err := notifier.Notify(ctx, alert)
+		require.True(t, retry)
+		require.EqualError(t, err, "unexpected status code 500: "+urls[2].String())
+		require.Equal(t, map[int]int{0: 1, 1: 1, 2: 1}, hits)
+	})
+}
+
+func TestWebhookOAuth2(t *testing.T) {
+	var (
+		mtx       sync.Mutex
+		tokens    int
+		expiresIn = 3600
+		auth      []string
+	)
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		user, pass, _ := r.BasicAuth()
+		require.Equal(t, "client", user)
+		require.Equal(t, "secret", pass)
+
+		mtx.Lock()
+		tokens++
+		res := map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", tokens),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		}
+		mtx.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	defer tokenSrv.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		auth = append(auth, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	notifier, err := New(
+		&config.WebhookConfig{
+			URL: &config.URL{URL: u},
+			HTTPConfig: &commoncfg.HTTPClientConfig{
+				OAuth2: &commoncfg.OAuth2{
+					ClientID:     "client",
+					ClientSecret: "secret",
+					TokenURL:     tokenSrv.URL,
+				},
+			},
+		},
+		test.CreateTmpl(t),
+		log.NewNopLogger(),
+	)
+	require.NoError(t, err)
+
+	ctx := notify.WithGroupKey(context.Background(), "1")
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighLatency"},
+			StartsAt: time.Now(),
+		},
+	}
+
+	// The token is fetched once and reused while it is valid.
+	for i := 0; i < 2; i++ {
+		_, err := notifier.Notify(ctx, alert)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, auth)
+	require.Equal(t, 1, tokens)
+
+	// Tokens about to expire are refreshed.
+	mtx.Lock()
+	expiresIn = 1
+	mtx.Unlock()
+	notifier, err = New(notifier.conf, test.CreateTmpl(t), log.NewNopLogger())
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := notifier.Notify(ctx, alert)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"Bearer token-2", "Bearer token-3"}, auth[2:])
+}
@@ -54,6 +54,10 @@ type Matcher struct {
 	Value string
 
 	re *regexp.Regexp
+	// set holds the alternatives of regular expressions that are a list of
+	// literals, such as those of NewSetMatcher, to match them without the
+	// regular expression.
+	set map[string]struct{}
 }
 
 // NewMatcher returns a matcher object.
@@ -69,10 +73,66 @@ func NewMatcher(t MatchType, n, v string) (*Matcher, error) {
 			return nil, err
 		}
 		m.re = re
+		m.set = literalSet(v)
 	}
 	return m, nil
 }
 
+// NewSetMatcher returns a matcher of the values in the given set, or of those
+// not in the set if negate is true. It is a regular expression matcher of the
+// alternatives of the quoted values, which is matched by set membership.
+func NewSetMatcher(n string, values []string, negate bool) (*Matcher, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty set of values for label %q", n)
+	}
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, regexp.QuoteMeta(v))
+	}
+	t := MatchRegexp
+	if negate {
+		t = MatchNotRegexp
+	}
+	return NewMatcher(t, n, strings.Join(quoted, "|"))
+}
+
+// regexpMeta are the characters escaped by regexp.QuoteMeta.
+const regexpMeta = `\.+*?()|[]{}^$`
+
+// literalSet returns the alternatives of the regular expression if it
+// consists of two or more alternatives of literals, and nil otherwise.
+func literalSet(pattern string) map[string]struct{} {
+	var (
+		set     = map[string]struct{}{}
+		lit     strings.Builder
+		escaped bool
+	)
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			if !strings.ContainsRune(regexpMeta, r) {
+				return nil
+			}
+			lit.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '|':
+			set[lit.String()] = struct{}{}
+			lit.Reset()
+		case strings.ContainsRune(regexpMeta, r):
+			return nil
+		default:
+			lit.WriteRune(r)
+		}
+	}
+	if escaped || len(set) == 0 {
+		return nil
+	}
+	set[lit.String()] = struct{}{}
+	return set
+}
+
 func (m *Matcher) String() string {
 	return fmt.Sprintf(`%s%s"%s"`, m.Name, m.Type, openMetricsEscape(m.Value))
 }
@@ -85,8 +145,16 @@ func (m *Matcher) Matches(s string) bool {
 	case MatchNotEqual:
 		return s != m.Value
 	case MatchRegexp:
+		if m.set != nil {
+			_, ok := m.set[s]
+			return ok
+		}
 		return m.re.MatchString(s)
 	case MatchNotRegexp:
+		if m.set != nil {
+			_, ok := m.set[s]
+			return !ok
+		}
 		return !m.re.MatchString(s)
 	}
 	panic("labels.Matcher.Matches: invalid match type")
@@ -126,6 +126,56 @@ func TestMatcher(t *testing.T) {
 	}
 }
 
+func TestSetMatcher(t *testing.T) {
+	m, err := NewSetMatcher("instance", []string{"db-1", "db.2", "a|b", ""}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `db-1|db\.2|a\|b|`; m.Value != want {
+		t.Fatalf("unexpected value %q, want %q", m.Value, want)
+	}
+	if m.set == nil {
+		t.Fatal("expected the matcher to match by set membership")
+	}
+	for _, v := range []string{"db-1", "db.2", "a|b", "", "db-12", "dbx2", "a", "b", "db-1|db.2"} {
+		if m.Matches(v) != m.re.MatchString(v) {
+			t.Fatalf("set membership and regular expression disagree for %q", v)
+		}
+	}
+	if !m.Matches("db.2") || m.Matches("dbx2") {
+		t.Fatal("unexpected match result")
+	}
+
+	m, err = NewSetMatcher("instance", []string{"a", "b"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != MatchNotRegexp || m.Matches("a") || !m.Matches("c") {
+		t.Fatalf("unexpected negated set matcher %v", m)
+	}
+
+	// Regular expressions of literal alternatives match by set membership
+	// no matter how they were created, others don't.
+	for pattern, isSet := range map[string]bool{
+		"a|b":      true,
+		`a\.b|c`:   true,
+		"a":        false,
+		"a.b|c":    false,
+		"(a|b)":    false,
+		`a\d|b`:    false,
+		"a|b|(?i)": false,
+	} {
+		m := mustNewMatcher(t, MatchRegexp, pattern)
+		if (m.set != nil) != isSet {
+			t.Fatalf("unexpected set of %q: %v", pattern, m.set)
+		}
+	}
+
+	if _, err := NewSetMatcher("instance", nil, false); err == nil {
+		t.Fatal("expected error for empty set")
+	}
+}
+
 func TestMatcherString(t *testing.T) {
 	tests := []struct {
 		name  string
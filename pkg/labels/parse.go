@@ -33,6 +33,14 @@ var (
 	}
 )
 
+var (
+	// setRe matches set matchers such as 'instance in (a, b)'.
+	setRe = regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s+(in|not\s+in)\s*\(((?s).*)\)\s*$`)
+	// setPrefixRe matches the start of set matchers up to the opening
+	// parenthesis.
+	setPrefixRe = regexp.MustCompile(`^\s*[a-zA-Z_:][a-zA-Z0-9_:]*\s+(in|not\s+in)\s*$`)
+)
+
 // ParseMatchers parses a comma-separated list of Matchers. A leading '{' and/or
 // a trailing '}' is optional and will be trimmed before further
 // parsing. Individual Matchers are separated by commas outside of quoted parts
@@ -50,6 +58,7 @@ var (
 //   foo=bar, dings!=bums
 //   {quote="She said: \"Hi, ladies! That's gender-neutral…\""}
 //   statuscode=~"5.."
+//   instance in (db-1, db-2), env!=dev
 //
 // See ParseMatcher for details on how an individual Matcher is parsed.
 func ParseMatchers(s string) ([]*Matcher, error) {
@@ -59,6 +68,7 @@ func ParseMatchers(s string) ([]*Matcher, error) {
 
 	var (
 		insideQuotes bool
+		insideSet    bool
 		escaped      bool
 		token        strings.Builder
 		tokens       []string
@@ -66,11 +76,21 @@ func ParseMatchers(s string) ([]*Matcher, error) {
 	for _, r := range s {
 		switch r {
 		case ',':
-			if !insideQuotes {
+			if !insideQuotes && !insideSet {
 				tokens = append(tokens, token.String())
 				token.Reset()
 				continue
 			}
+		case '(':
+			if !insideQuotes && !insideSet && setPrefixRe.MatchString(token.String()) {
+				insideSet = true
+			}
+			escaped = false
+		case ')':
+			if !insideQuotes {
+				insideSet = false
+			}
+			escaped = false
 		case '"':
 			if !escaped {
 				insideQuotes = !insideQuotes
@@ -114,26 +134,101 @@ func ParseMatchers(s string) ([]*Matcher, error) {
 // character). However, literal line feed characters are tolerated, as are
 // single '\' characters not followed by '\', 'n', or '"'. They act as a literal
 // backslash in that case.
+//
+// Alternatively, a matcher may match a set of values with the syntax
+// 'name in (value, ...)', or those not in the set with 'name not in (value,
+// ...)'. The values are separated by commas and may be quoted like the 3rd
+// token above. Such a matcher is a regular expression matcher, see
+// NewSetMatcher.
 func ParseMatcher(s string) (_ *Matcher, err error) {
+	if ms := setRe.FindStringSubmatch(s); len(ms) > 0 {
+		return parseSetMatcher(ms)
+	}
+
 	ms := re.FindStringSubmatch(s)
 	if len(ms) == 0 {
 		return nil, errors.Errorf("bad matcher format: %s", s)
 	}
 
+	value, err := unquoteValue(ms[3])
+	if err != nil {
+		return nil, err
+	}
+	return NewMatcher(typeMap[ms[2]], ms[1], value)
+}
+
+// parseSetMatcher parses the submatches of setRe. The values are separated by
+// commas outside of double quotes and follow the rules of the values of
+// other matchers.
+func parseSetMatcher(ms []string) (*Matcher, error) {
+	var (
+		insideQuotes bool
+		escaped      bool
+		value        strings.Builder
+		values       []string
+	)
+	add := func() error {
+		raw := strings.TrimSpace(value.String())
+		value.Reset()
+		if raw == "" {
+			return errors.Errorf("empty value in set matcher: %s", ms[3])
+		}
+		v, err := unquoteValue(raw)
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+		return nil
+	}
+	for _, r := range ms[3] {
+		switch r {
+		case ',':
+			if !insideQuotes {
+				if err := add(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		case '"':
+			if !escaped {
+				insideQuotes = !insideQuotes
+			}
+			escaped = false
+		case '\\':
+			escaped = !escaped
+		default:
+			escaped = false
+		}
+		value.WriteRune(r)
+	}
+	if strings.TrimSpace(value.String()) != "" || len(values) > 0 {
+		if err := add(); err != nil {
+			return nil, err
+		}
+	}
+	return NewSetMatcher(ms[1], values, ms[2] != "in")
+}
+
+// unquoteValue returns the value of a matcher given in the input, which may be
+// enclosed in double quotes and escaped as described for ParseMatcher.
+func unquoteValue(rawValue string) (string, error) {
 	var (
-		rawValue            = ms[3]
+		orig                = rawValue
 		value               strings.Builder
 		escaped             bool
 		expectTrailingQuote bool
 	)
 
+	if rawValue == "" {
+		return "", nil
+	}
 	if rawValue[0] == '"' {
 		rawValue = strings.TrimPrefix(rawValue, "\"")
 		expectTrailingQuote = true
 	}
 
 	if !utf8.ValidString(rawValue) {
-		return nil, errors.Errorf("matcher value not valid UTF-8: %s", ms[3])
+		return "", errors.Errorf("matcher value not valid UTF-8: %s", orig)
 	}
 
 	// Unescape the rawValue:
@@ -162,7 +257,7 @@ func ParseMatcher(s string) (_ *Matcher, err error) {
 			value.WriteByte('\\')
 		case '"':
 			if !expectTrailingQuote || i < len(rawValue)-1 {
-				return nil, errors.Errorf("matcher value contains unescaped double quote: %s", ms[3])
+				return "", errors.Errorf("matcher value contains unescaped double quote: %s", orig)
 			}
 			expectTrailingQuote = false
 		default:
@@ -171,8 +266,7 @@ func ParseMatcher(s string) (_ *Matcher, err error) {
 	}
 
 	if expectTrailingQuote {
-		return nil, errors.Errorf("matcher value contains unescaped double quote: %s", ms[3])
+		return "", errors.Errorf("matcher value contains unescaped double quote: %s", orig)
 	}
-
-	return NewMatcher(typeMap[ms[2]], ms[1], value.String())
+	return value.String(), nil
 }
@@ -218,6 +218,45 @@ func TestMatchers(t *testing.T) {
 			input: `"{foo=\"bar"}`,
 			err:   `bad matcher format: "{foo=\"bar"`,
 		},
+		{
+			input: `{instance in (db-1, "db-2,a", db\"3), env="prod"}`,
+			want: func() []*Matcher {
+				ms := []*Matcher{}
+				m, _ := NewSetMatcher("instance", []string{"db-1", "db-2,a", `db"3`}, false)
+				m2, _ := NewMatcher(MatchEqual, "env", "prod")
+				return append(ms, m, m2)
+			}(),
+		},
+		{
+			input: `instance not  in(a.b, "c|d")`,
+			want: func() []*Matcher {
+				ms := []*Matcher{}
+				m, _ := NewMatcher(MatchNotRegexp, "instance", `a\.b|c\|d`)
+				return append(ms, m)
+			}(),
+		},
+		{
+			// Parentheses of other matchers don't group commas.
+			input: `foo=(a, bar=b)`,
+			want: func() []*Matcher {
+				ms := []*Matcher{}
+				m, _ := NewMatcher(MatchEqual, "foo", "(a")
+				m2, _ := NewMatcher(MatchEqual, "bar", "b)")
+				return append(ms, m, m2)
+			}(),
+		},
+		{
+			input: `foo in ()`,
+			err:   `empty set of values for label "foo"`,
+		},
+		{
+			input: `foo in (a,,b)`,
+			err:   `empty value in set matcher: a,,b`,
+		},
+		{
+			input: `foo in (a"b)`,
+			err:   `matcher value contains unescaped double quote: a"b`,
+		},
 		{
 			input: `"foo=\"bar"`,
 			err:   `bad matcher format: "foo=\"bar"`,
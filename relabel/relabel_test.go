@@ -0,0 +1,198 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relabel
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestProcess(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+		input  model.LabelSet
+		output model.LabelSet
+	}{
+		{
+			name: "rename",
+			config: `
+- source_labels: [svc]
+  target_label: service
+- regex: svc
+  action: labeldrop
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "svc": "api"},
+			output: model.LabelSet{"alertname": "HighLatency", "service": "api"},
+		},
+		{
+			name: "rename with labelmap",
+			config: `
+- regex: k8s_(.+)
+  action: labelmap
+- regex: k8s_.+
+  action: labeldrop
+`,
+			input:  model.LabelSet{"alertname": "PodDown", "k8s_pod": "web-0", "k8s_namespace": "prod"},
+			output: model.LabelSet{"alertname": "PodDown", "pod": "web-0", "namespace": "prod"},
+		},
+		{
+			name: "drop matching alert",
+			config: `
+- source_labels: [env]
+  regex: dev|test
+  action: drop
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "env": "dev"},
+			output: nil,
+		},
+		{
+			name: "drop keeps non-matching alert",
+			config: `
+- source_labels: [env]
+  regex: dev|test
+  action: drop
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "env": "prod"},
+			output: model.LabelSet{"alertname": "HighLatency", "env": "prod"},
+		},
+		{
+			name: "keep",
+			config: `
+- source_labels: [team]
+  regex: .+
+  action: keep
+`,
+			input:  model.LabelSet{"alertname": "HighLatency"},
+			output: nil,
+		},
+		{
+			name: "replace with capture groups",
+			config: `
+- source_labels: [instance]
+  regex: '([^:]+):\d+'
+  target_label: host
+  replacement: $1
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "instance": "db-1:9100"},
+			output: model.LabelSet{"alertname": "HighLatency", "instance": "db-1:9100", "host": "db-1"},
+		},
+		{
+			name: "replace with multiple source labels",
+			config: `
+- source_labels: [cluster, namespace]
+  separator: /
+  target_label: scope
+`,
+			input:  model.LabelSet{"cluster": "eu-1", "namespace": "prod"},
+			output: model.LabelSet{"cluster": "eu-1", "namespace": "prod", "scope": "eu-1/prod"},
+		},
+		{
+			name: "replace without match",
+			config: `
+- source_labels: [instance]
+  regex: '([^:]+):\d+'
+  target_label: host
+`,
+			input:  model.LabelSet{"instance": "db-1"},
+			output: model.LabelSet{"instance": "db-1"},
+		},
+		{
+			name: "replace with empty value deletes target",
+			config: `
+- source_labels: [missing]
+  target_label: severity
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "severity": "page"},
+			output: model.LabelSet{"alertname": "HighLatency"},
+		},
+		{
+			name: "labelkeep",
+			config: `
+- regex: alertname|severity
+  action: labelkeep
+`,
+			input:  model.LabelSet{"alertname": "HighLatency", "severity": "page", "pod": "web-0"},
+			output: model.LabelSet{"alertname": "HighLatency", "severity": "page"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfgs []*Config
+			require.NoError(t, yaml.UnmarshalStrict([]byte(tc.config), &cfgs))
+
+			input := tc.input.Clone()
+			require.Equal(t, tc.output, Process(tc.input, cfgs...))
+			// The input label set must not be modified.
+			require.Equal(t, input, tc.input)
+		})
+	}
+}
+
+func TestConfigUnmarshalYAML(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{
+			config: `target_label: service`,
+		},
+		{
+			config: `action: replace`,
+			err:    "relabel configuration for replace action requires 'target_label' value",
+		},
+		{
+			config: `
+target_label: 1service
+action: replace`,
+			err: `"1service" is invalid 'target_label' for replace action`,
+		},
+		{
+			config: `
+regex: (.*)
+action: labelmap
+replacement: "-"`,
+			err: `"-" is invalid 'replacement' for labelmap action`,
+		},
+		{
+			config: `
+source_labels: [svc]
+regex: svc
+action: labeldrop`,
+			err: "labeldrop action requires only 'regex', and no other fields",
+		},
+		{
+			config: `action: rename`,
+			err:    `unknown relabel action "rename"`,
+		},
+		{
+			config: `
+target_label: service
+regex: "("`,
+			err: "error parsing regexp: missing closing ): `^(?:()$`",
+		},
+	} {
+		t.Run(tc.config, func(t *testing.T) {
+			var cfg Config
+			err := yaml.UnmarshalStrict([]byte(tc.config), &cfg)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
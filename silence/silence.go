@@ -34,6 +34,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/alertmanager/cluster"
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/provider"
 	pb "github.com/prometheus/alertmanager/silence/silencepb"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -50,24 +51,59 @@ func utcNow() time.Time {
 	return time.Now().UTC()
 }
 
-type matcherCache map[*pb.Silence]labels.Matchers
+type matcherCache map[*pb.Silence]compiledMatchers
+
+// compiledMatchers holds the compiled matchers and condition matchers of a
+// silence.
+type compiledMatchers struct {
+	matchers   labels.Matchers
+	conditions labels.Matchers
+}
 
 // Get retrieves the matchers for a given silence. If it is a missed cache
 // access, it compiles and adds the matchers of the requested silence to the
 // cache.
 func (c matcherCache) Get(s *pb.Silence) (labels.Matchers, error) {
-	if m, ok := c[s]; ok {
-		return m, nil
+	cm, err := c.get(s)
+	return cm.matchers, err
+}
+
+// Conditions retrieves the condition matchers for a given silence, compiling
+// them on a missed cache access like Get.
+func (c matcherCache) Conditions(s *pb.Silence) (labels.Matchers, error) {
+	cm, err := c.get(s)
+	return cm.conditions, err
+}
+
+func (c matcherCache) get(s *pb.Silence) (compiledMatchers, error) {
+	if cm, ok := c[s]; ok {
+		return cm, nil
 	}
 	return c.add(s)
 }
 
-// add compiles a silences' matchers and adds them to the cache.
-// It returns the compiled matchers.
-func (c matcherCache) add(s *pb.Silence) (labels.Matchers, error) {
-	ms := make(labels.Matchers, len(s.Matchers))
+// add compiles a silences' matchers and condition matchers and adds them to
+// the cache. It returns the compiled matchers.
+func (c matcherCache) add(s *pb.Silence) (compiledMatchers, error) {
+	ms, err := compileMatchers(s.Matchers)
+	if err != nil {
+		return compiledMatchers{}, err
+	}
+	cms, err := compileMatchers(s.ConditionMatchers)
+	if err != nil {
+		return compiledMatchers{}, errors.Wrap(err, "condition")
+	}
+
+	cm := compiledMatchers{matchers: ms, conditions: cms}
+	c[s] = cm
+	return cm, nil
+}
+
+// compileMatchers compiles the given matchers of a silence.
+func compileMatchers(pms []*pb.Matcher) (labels.Matchers, error) {
+	ms := make(labels.Matchers, len(pms))
 
-	for i, m := range s.Matchers {
+	for i, m := range pms {
 		var mt labels.MatchType
 		switch m.Type {
 		case pb.Matcher_EQUAL:
@@ -88,23 +124,24 @@ func (c matcherCache) add(s *pb.Silence) (labels.Matchers, error) {
 
 		ms[i] = matcher
 	}
-
-	c[s] = ms
 	return ms, nil
 }
 
 // Silencer binds together a Marker and a Silences to implement the Muter
-// interface.
+// interface. The alerts are consulted for the conditions of silences.
 type Silencer struct {
 	silences *Silences
+	alerts   provider.Alerts
 	marker   types.Marker
 	logger   log.Logger
 }
 
-// NewSilencer returns a new Silencer.
-func NewSilencer(s *Silences, m types.Marker, l log.Logger) *Silencer {
+// NewSilencer returns a new Silencer. If alerts is nil, silences with
+// condition matchers never mute.
+func NewSilencer(s *Silences, alerts provider.Alerts, m types.Marker, l log.Logger) *Silencer {
 	return &Silencer{
 		silences: s,
+		alerts:   alerts,
 		marker:   m,
 		logger:   l,
 	}
@@ -162,11 +199,18 @@ func (s *Silencer) Mutes(lset model.LabelSet) bool {
 	// current ID slices for concurrency reasons.
 	activeIDs, pendingIDs = nil, nil
 	now := s.silences.now()
+	met := s.conditionsMet(allSils, now, fp)
 	for _, sil := range allSils {
 		switch getState(sil, now) {
 		case types.SilenceStatePending:
 			pendingIDs = append(pendingIDs, sil.Id)
 		case types.SilenceStateActive:
+			if _, ok := met[sil.Id]; len(sil.ConditionMatchers) > 0 && !ok {
+				// The silence must be checked again next time even if
+				// no silences are added, so don't record the version.
+				newVersion = -1
+				continue
+			}
 			activeIDs = append(activeIDs, sil.Id)
 		default:
 			// Do nothing, silence has expired in the meantime.
@@ -187,6 +231,44 @@ func (s *Silencer) Mutes(lset model.LabelSet) bool {
 	return len(activeIDs) > 0
 }
 
+// conditionsMet returns the IDs of the active silences with condition
+// matchers for which a firing alert other than the one with the given
+// fingerprint matches all of them. The alerts are scanned once for all
+// silences.
+func (s *Silencer) conditionsMet(sils []*pb.Silence, now time.Time, fp model.Fingerprint) map[string]struct{} {
+	if s.alerts == nil {
+		return nil
+	}
+	var ids []string
+	for _, sil := range sils {
+		if len(sil.ConditionMatchers) > 0 && getState(sil, now) == types.SilenceStateActive {
+			ids = append(ids, sil.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	conds := s.silences.conditionMatchers(ids)
+
+	met := make(map[string]struct{}, len(conds))
+	it := s.alerts.GetPending()
+	defer it.Close()
+	for a := range it.Next() {
+		if len(met) == len(conds) {
+			break
+		}
+		if a.Resolved() || a.Fingerprint() == fp {
+			continue
+		}
+		for id, ms := range conds {
+			if _, ok := met[id]; !ok && ms.Matches(a.Labels) {
+				met[id] = struct{}{}
+			}
+		}
+	}
+	return met
+}
+
 // Silences holds a silence state that can be modified, queried, and snapshot.
 type Silences struct {
 	logger    log.Logger
@@ -487,6 +569,16 @@ func validateSilence(s *pb.Silence) error {
 	if allMatchEmpty {
 		return errors.New("at least one matcher must not match the empty string")
 	}
+	allMatchEmpty = true
+	for i, m := range s.ConditionMatchers {
+		if err := ValidateMatcher(m); err != nil {
+			return fmt.Errorf("invalid condition matcher %d: %s", i, err)
+		}
+		allMatchEmpty = allMatchEmpty && matchesEmpty(m)
+	}
+	if len(s.ConditionMatchers) > 0 && allMatchEmpty {
+		return errors.New("at least one condition matcher must not match the empty string")
+	}
 	if s.StartsAt.IsZero() {
 		return errors.New("invalid zero start timestamp")
 	}
@@ -554,6 +646,10 @@ func (s *Silences) Set(sil *pb.Silence) (string, error) {
 	}
 	if ok {
 		if canUpdate(prev, sil, now) {
+			// Silences are notified about before they expire once for
+			// each end. The flag isn't set by clients, so it is kept from
+			// the previous version unless the end changes.
+			sil.ExpiryNotified = prev.ExpiryNotified && sil.EndsAt.Equal(prev.EndsAt)
 			return sil.Id, s.setSilence(sil, now)
 		}
 		if getState(prev, s.now()) != types.SilenceStateExpired {
@@ -569,6 +665,7 @@ func (s *Silences) Set(sil *pb.Silence) (string, error) {
 		return "", errors.Wrap(err, "generate uuid")
 	}
 	sil.Id = uid.String()
+	sil.ExpiryNotified = false
 
 	if sil.StartsAt.Before(now) {
 		sil.StartsAt = now
@@ -583,6 +680,9 @@ func canUpdate(a, b *pb.Silence, now time.Time) bool {
 	if !reflect.DeepEqual(a.Matchers, b.Matchers) {
 		return false
 	}
+	if !reflect.DeepEqual(a.ConditionMatchers, b.ConditionMatchers) {
+		return false
+	}
 	// Allowed timestamp modifications depend on the current time.
 	switch st := getState(a, now); st {
 	case types.SilenceStateActive:
@@ -634,6 +734,23 @@ func (s *Silences) expire(id string) error {
 	return s.setSilence(sil, now)
 }
 
+// MarkExpiryNotified records that the notification about the upcoming expiry
+// of the silence with the given ID was sent. Changing the end of the silence
+// resets it.
+func (s *Silences) MarkExpiryNotified(id string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sil, ok := s.getSilence(id)
+	if !ok {
+		return ErrNotFound
+	}
+	sil = cloneSilence(sil)
+	sil.ExpiryNotified = true
+
+	return s.setSilence(sil, s.now())
+}
+
 // QueryParam expresses parameters along which silences are queried.
 type QueryParam func(*query) error
 
@@ -731,6 +848,29 @@ func (s *Silences) Query(params ...QueryParam) ([]*pb.Silence, int, error) {
 	return sils, version, err
 }
 
+// conditionMatchers returns the compiled condition matchers of the silences
+// with the given IDs by ID. The matchers are cached along with those of the
+// silences. Silences whose matchers don't compile are left out.
+func (s *Silences) conditionMatchers(ids []string) map[string]labels.Matchers {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	res := make(map[string]labels.Matchers, len(ids))
+	for _, id := range ids {
+		sil, ok := s.getSilence(id)
+		if !ok {
+			continue
+		}
+		ms, err := s.mc.Conditions(sil)
+		if err != nil {
+			level.Error(s.logger).Log("msg", "Compiling condition matchers failed", "silence", id, "err", err)
+			continue
+		}
+		res[id] = ms
+	}
+	return res
+}
+
 // Version of the silence state.
 func (s *Silences) Version() int {
 	s.mtx.RLock()
@@ -15,6 +15,7 @@ package silence
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/provider/mem"
 	pb "github.com/prometheus/alertmanager/silence/silencepb"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -573,6 +575,22 @@ func TestQMatches(t *testing.T) {
 			},
 			drop: false,
 		},
+		{
+			sil: &pb.Silence{
+				Matchers: []*pb.Matcher{
+					{Name: "instance", Pattern: `web-1|web\.2`, Type: pb.Matcher_REGEXP},
+				},
+			},
+			drop: true,
+		},
+		{
+			sil: &pb.Silence{
+				Matchers: []*pb.Matcher{
+					{Name: "instance", Pattern: `web-1|web\.2`, Type: pb.Matcher_NOT_REGEXP},
+				},
+			},
+			drop: false,
+		},
 	}
 	for _, c := range cases {
 		drop, err := f(c.sil, &Silences{mc: matcherCache{}, st: state{}}, time.Time{})
@@ -693,6 +711,20 @@ func TestSilenceCanUpdate(t *testing.T) {
 			},
 			ok: false,
 		},
+		// Changed condition matchers.
+		{
+			a: &pb.Silence{
+				StartsAt:  now.Add(time.Hour),
+				EndsAt:    now.Add(2 * time.Hour),
+				UpdatedAt: now.Add(-time.Hour),
+			},
+			b: &pb.Silence{
+				ConditionMatchers: []*pb.Matcher{{Name: "alertname", Pattern: "Outage"}},
+				StartsAt:          now.Add(time.Minute),
+				EndsAt:            now.Add(time.Minute),
+			},
+			ok: false,
+		},
 		// Pending silences.
 		{
 			a: &pb.Silence{
@@ -952,6 +984,147 @@ func TestSilenceExpireWithZeroRetention(t *testing.T) {
 	require.Equal(t, 3, count)
 }
 
+func TestSilenceMarkExpiryNotified(t *testing.T) {
+	s, err := New(Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	id, err := s.Set(&pb.Silence{
+		Matchers:     []*pb.Matcher{{Type: pb.Matcher_EQUAL, Name: "a", Pattern: "b"}},
+		StartsAt:     now,
+		EndsAt:       now.Add(time.Hour),
+		NotifyExpiry: true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ErrNotFound, s.MarkExpiryNotified("unknown"))
+	s.now = func() time.Time { return now.Add(time.Minute) }
+	require.NoError(t, s.MarkExpiryNotified(id))
+
+	sil, err := s.QueryOne(QIDs(id))
+	require.NoError(t, err)
+	require.True(t, sil.ExpiryNotified)
+	require.True(t, sil.NotifyExpiry)
+
+	// Updates keeping the end don't ask for a new notification.
+	s.now = func() time.Time { return now.Add(2 * time.Minute) }
+	sil = cloneSilence(sil)
+	sil.Comment = "still ongoing"
+	_, err = s.Set(sil)
+	require.NoError(t, err)
+
+	sil, err = s.QueryOne(QIDs(id))
+	require.NoError(t, err)
+	require.True(t, sil.ExpiryNotified)
+
+	// Extending the silence asks for a new notification.
+	s.now = func() time.Time { return now.Add(3 * time.Minute) }
+	sil = cloneSilence(sil)
+	sil.EndsAt = now.Add(2 * time.Hour)
+	_, err = s.Set(sil)
+	require.NoError(t, err)
+
+	sil, err = s.QueryOne(QIDs(id))
+	require.NoError(t, err)
+	require.False(t, sil.ExpiryNotified)
+
+	// So do replacing silences.
+	s.now = func() time.Time { return now.Add(4 * time.Minute) }
+	require.NoError(t, s.MarkExpiryNotified(id))
+	s.now = func() time.Time { return now.Add(5 * time.Minute) }
+	sil, err = s.QueryOne(QIDs(id))
+	require.NoError(t, err)
+	sil = cloneSilence(sil)
+	sil.Matchers = []*pb.Matcher{{Type: pb.Matcher_EQUAL, Name: "a", Pattern: "c"}}
+	newID, err := s.Set(sil)
+	require.NoError(t, err)
+	require.NotEqual(t, id, newID)
+
+	sil, err = s.QueryOne(QIDs(newID))
+	require.NoError(t, err)
+	require.False(t, sil.ExpiryNotified)
+}
+
+func TestSilencerConditional(t *testing.T) {
+	ss, err := New(Options{Retention: time.Hour})
+	require.NoError(t, err)
+
+	now := time.Now()
+	ss.now = func() time.Time { return now }
+
+	m := types.NewMarker(prometheus.NewRegistry())
+	alerts, err := mem.NewAlerts(context.Background(), m, time.Hour, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	defer alerts.Close()
+	s := NewSilencer(ss, alerts, m, log.NewNopLogger())
+
+	_, err = ss.Set(&pb.Silence{
+		Matchers:          []*pb.Matcher{{Name: "alertname", Pattern: "InstanceDown"}},
+		ConditionMatchers: []*pb.Matcher{{Name: "alertname", Pattern: "NetworkOutage"}},
+		StartsAt:          now.Add(-time.Hour),
+		EndsAt:            now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	newAlert := func(name string, endsAt time.Time) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": model.LabelValue(name)},
+				StartsAt: now.Add(-time.Minute),
+				EndsAt:   endsAt,
+			},
+			UpdatedAt: now,
+		}
+	}
+	lset := model.LabelSet{"alertname": "InstanceDown"}
+
+	require.False(t, s.Mutes(lset), "expected alert not silenced without condition alert")
+	// Evaluating the same silences again must not take the fast path.
+	require.False(t, s.Mutes(lset), "expected alert not silenced without condition alert")
+
+	// The silenced alert doesn't satisfy the condition itself.
+	require.NoError(t, alerts.Put(newAlert("InstanceDown", time.Time{})))
+	require.False(t, s.Mutes(lset), "expected alert not silenced by its own presence")
+
+	require.NoError(t, alerts.Put(newAlert("NetworkOutage", time.Time{})))
+	require.True(t, s.Mutes(lset), "expected alert silenced while the condition alert is firing")
+	activeIDs, _, _, silenced := m.Silenced(lset.Fingerprint())
+	require.True(t, silenced)
+	require.Len(t, activeIDs, 1)
+
+	require.False(t, s.Mutes(model.LabelSet{"alertname": "Other"}), "expected alert not silenced by non-matching silence")
+
+	// The condition alert resolves.
+	require.NoError(t, alerts.Put(newAlert("NetworkOutage", time.Now().Add(-time.Second))))
+	require.False(t, s.Mutes(lset), "expected alert not silenced once the condition alert is resolved")
+	_, _, _, silenced = m.Silenced(lset.Fingerprint())
+	require.False(t, silenced)
+
+	// Without alerts, conditional silences never mute.
+	require.False(t, NewSilencer(ss, nil, m, log.NewNopLogger()).Mutes(lset))
+
+	// The conditions of several silences are checked together, and their
+	// compiled matchers are cached with the silences.
+	id, err := ss.Set(&pb.Silence{
+		Matchers:          []*pb.Matcher{{Name: "alertname", Pattern: "InstanceDown"}},
+		ConditionMatchers: []*pb.Matcher{{Name: "alertname", Pattern: "PowerOutage"}},
+		StartsAt:          now.Add(-time.Hour),
+		EndsAt:            now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NoError(t, alerts.Put(newAlert("PowerOutage", time.Time{})))
+	require.True(t, s.Mutes(lset), "expected alert silenced while the condition alert of a silence is firing")
+	activeIDs, _, _, _ = m.Silenced(lset.Fingerprint())
+	require.Equal(t, []string{id}, activeIDs)
+
+	sil, ok := ss.getSilence(id)
+	require.True(t, ok)
+	require.Contains(t, ss.mc, sil)
+	require.Equal(t, `{alertname="PowerOutage"}`, ss.mc[sil].conditions.String())
+}
+
 func TestSilencer(t *testing.T) {
 	ss, err := New(Options{Retention: time.Hour})
 	require.NoError(t, err)
@@ -960,7 +1133,7 @@ func TestSilencer(t *testing.T) {
 	ss.now = func() time.Time { return now }
 
 	m := types.NewMarker(prometheus.NewRegistry())
-	s := NewSilencer(ss, m, log.NewNopLogger())
+	s := NewSilencer(ss, nil, m, log.NewNopLogger())
 
 	require.False(t, s.Mutes(model.LabelSet{"foo": "bar"}), "expected alert not silenced without any silences")
 
@@ -1165,6 +1338,51 @@ func TestValidateSilence(t *testing.T) {
 			},
 			err: "at least one matcher must not match the empty string",
 		},
+		{
+			s: &pb.Silence{
+				Id: "some_id",
+				Matchers: []*pb.Matcher{
+					&pb.Matcher{Name: "a", Pattern: "b"},
+				},
+				ConditionMatchers: []*pb.Matcher{
+					&pb.Matcher{Name: "alertname", Pattern: "Outage"},
+				},
+				StartsAt:  validTimestamp,
+				EndsAt:    validTimestamp,
+				UpdatedAt: validTimestamp,
+			},
+			err: "",
+		},
+		{
+			s: &pb.Silence{
+				Id: "some_id",
+				Matchers: []*pb.Matcher{
+					&pb.Matcher{Name: "a", Pattern: "b"},
+				},
+				ConditionMatchers: []*pb.Matcher{
+					&pb.Matcher{Name: "\xff", Pattern: "b"},
+				},
+				StartsAt:  validTimestamp,
+				EndsAt:    validTimestamp,
+				UpdatedAt: validTimestamp,
+			},
+			err: "invalid condition matcher",
+		},
+		{
+			s: &pb.Silence{
+				Id: "some_id",
+				Matchers: []*pb.Matcher{
+					&pb.Matcher{Name: "a", Pattern: "b"},
+				},
+				ConditionMatchers: []*pb.Matcher{
+					&pb.Matcher{Name: "c", Pattern: ".*", Type: pb.Matcher_REGEXP},
+				},
+				StartsAt:  validTimestamp,
+				EndsAt:    validTimestamp,
+				UpdatedAt: validTimestamp,
+			},
+			err: "at least one condition matcher must not match the empty string",
+		},
 		{
 			s: &pb.Silence{
 				Id: "some_id",
@@ -166,11 +166,22 @@ type Silence struct {
 	// DEPRECATED: A set of comments made on the silence.
 	Comments []*Comment `protobuf:"bytes,7,rep,name=comments,proto3" json:"comments,omitempty"`
 	// Comment for the silence.
-	CreatedBy            string   `protobuf:"bytes,8,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
-	Comment              string   `protobuf:"bytes,9,opt,name=comment,proto3" json:"comment,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	CreatedBy string `protobuf:"bytes,8,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	Comment   string `protobuf:"bytes,9,opt,name=comment,proto3" json:"comment,omitempty"`
+	// A set of matchers all of which have to be true for a firing alert
+	// to be a condition of the silence. If not empty, the silence only
+	// affects a label set while such a condition alert is present.
+	ConditionMatchers []*Matcher `protobuf:"bytes,10,rep,name=condition_matchers,json=conditionMatchers,proto3" json:"condition_matchers,omitempty"`
+	// Whether a notification is sent shortly before the silence expires.
+	NotifyExpiry bool `protobuf:"varint,11,opt,name=notify_expiry,json=notifyExpiry,proto3" json:"notify_expiry,omitempty"`
+	// Whether the notification about the upcoming expiry was sent.
+	ExpiryNotified bool `protobuf:"varint,12,opt,name=expiry_notified,json=expiryNotified,proto3" json:"expiry_notified,omitempty"`
+	// How long before the silence expires the notification is sent. Zero
+	// uses the lead time of the configuration.
+	NotifyBefore         time.Duration `protobuf:"bytes,13,opt,name=notify_before,json=notifyBefore,proto3,stdduration" json:"notify_before"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *Silence) Reset()         { *m = Silence{} }
@@ -260,36 +271,42 @@ func init() {
 func init() { proto.RegisterFile("silence.proto", fileDescriptor_7fc56058cf68dbd8) }
 
 var fileDescriptor_7fc56058cf68dbd8 = []byte{
-	// 461 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x91, 0xcd, 0x8e, 0xd2, 0x50,
-	0x14, 0xc7, 0xb9, 0x85, 0xa1, 0xdc, 0x43, 0x86, 0x90, 0x13, 0xa3, 0x0d, 0x89, 0x40, 0xba, 0x22,
-	0xd1, 0x94, 0x04, 0xb7, 0xba, 0x28, 0x13, 0xe2, 0xc6, 0xf1, 0xa3, 0x62, 0xe2, 0x6e, 0x52, 0xe8,
-	0x11, 0x9a, 0x4c, 0x3f, 0xd2, 0x1e, 0x12, 0x59, 0xe9, 0x23, 0xf8, 0x0c, 0x3e, 0x0d, 0x4b, 0x9f,
-	0xc0, 0x0f, 0xde, 0xc2, 0x9d, 0xe9, 0xed, 0x2d, 0xce, 0x84, 0x55, 0x77, 0xf7, 0xdc, 0xf3, 0xff,
-	0x9f, 0x8f, 0xdf, 0x81, 0xcb, 0x3c, 0xbc, 0xa5, 0x78, 0x4d, 0x4e, 0x9a, 0x25, 0x9c, 0xa0, 0xd4,
-	0x61, 0xba, 0x1a, 0x8c, 0x36, 0x49, 0xb2, 0xb9, 0xa5, 0xa9, 0x4a, 0xac, 0x76, 0x9f, 0xa6, 0x1c,
-	0x46, 0x94, 0xb3, 0x1f, 0xa5, 0xa5, 0x76, 0xf0, 0x60, 0x93, 0x6c, 0x12, 0xf5, 0x9c, 0x16, 0xaf,
-	0xf2, 0xd7, 0xfe, 0x2e, 0xc0, 0xbc, 0xf6, 0x79, 0xbd, 0xa5, 0x0c, 0x9f, 0x40, 0x8b, 0xf7, 0x29,
-	0x59, 0x62, 0x2c, 0x26, 0xbd, 0xd9, 0x23, 0xe7, 0x54, 0xdc, 0xd1, 0x0a, 0x67, 0xb9, 0x4f, 0xc9,
-	0x53, 0x22, 0x44, 0x68, 0xc5, 0x7e, 0x44, 0x96, 0x31, 0x16, 0x13, 0xe9, 0xa9, 0x37, 0x5a, 0x60,
-	0xa6, 0x3e, 0x33, 0x65, 0xb1, 0xd5, 0x54, 0xdf, 0x55, 0x68, 0x3f, 0x87, 0x56, 0xe1, 0x45, 0x09,
-	0x17, 0x8b, 0x77, 0x1f, 0xdc, 0x57, 0xfd, 0x06, 0x02, 0xb4, 0xbd, 0xc5, 0xcb, 0xc5, 0xc7, 0xb7,
-	0x7d, 0x81, 0x97, 0x20, 0x5f, 0xbf, 0x59, 0xde, 0x94, 0x29, 0x03, 0x7b, 0x00, 0x45, 0xa8, 0xd3,
-	0x4d, 0xfb, 0x0b, 0x98, 0x57, 0x49, 0x14, 0x51, 0xcc, 0xf8, 0x10, 0xda, 0xfe, 0x8e, 0xb7, 0x49,
-	0xa6, 0xa6, 0x94, 0x9e, 0x8e, 0x8a, 0xd6, 0xeb, 0x52, 0xa2, 0x27, 0xaa, 0x42, 0x9c, 0x83, 0x3c,
-	0xa1, 0x50, 0x63, 0x75, 0x67, 0x03, 0xa7, 0x84, 0xe5, 0x54, 0xb0, 0x9c, 0x65, 0xa5, 0x98, 0x77,
-	0x0e, 0x3f, 0x47, 0x8d, 0x6f, 0xbf, 0x46, 0xc2, 0xfb, 0x6f, 0xb3, 0xff, 0x1a, 0x60, 0xbe, 0x2f,
-	0x69, 0x60, 0x0f, 0x8c, 0x30, 0xd0, 0xdd, 0x8d, 0x30, 0x40, 0x07, 0x3a, 0x51, 0x89, 0x27, 0xb7,
-	0x8c, 0x71, 0x73, 0xd2, 0x9d, 0xe1, 0x39, 0x39, 0xef, 0xa4, 0x41, 0x17, 0x64, 0xce, 0x7e, 0xc6,
-	0xf9, 0x8d, 0xcf, 0xb5, 0xe6, 0xe9, 0x94, 0x36, 0x97, 0xf1, 0x05, 0x98, 0x14, 0x07, 0xaa, 0x40,
-	0xab, 0x46, 0x81, 0x76, 0x61, 0x72, 0x19, 0xaf, 0x00, 0x76, 0x69, 0xe0, 0x33, 0x05, 0x45, 0x85,
-	0x8b, 0x3a, 0x48, 0xb4, 0xcf, 0xe5, 0x62, 0x6d, 0x4d, 0x38, 0xb7, 0xcc, 0xb3, 0xb5, 0xf5, 0xb9,
-	0xbc, 0x93, 0x06, 0x1f, 0x03, 0xac, 0x33, 0x52, 0x4d, 0x57, 0x7b, 0xab, 0xa3, 0xf0, 0x49, 0xfd,
-	0x33, 0xdf, 0xdf, 0xbd, 0x9f, 0xbc, 0x77, 0x3f, 0xfb, 0xab, 0x80, 0xee, 0x35, 0xe5, 0xdb, 0x8a,
-	0xff, 0x53, 0x30, 0x75, 0x1f, 0x75, 0x84, 0xfb, 0x7d, 0xb5, 0xc8, 0xab, 0x24, 0xc5, 0xae, 0xf4,
-	0x39, 0x0d, 0x33, 0x52, 0xb4, 0x8c, 0x3a, 0xbb, 0x6a, 0x9f, 0xcb, 0xf3, 0xfe, 0xe1, 0xcf, 0xb0,
-	0x71, 0x38, 0x0e, 0xc5, 0x8f, 0xe3, 0x50, 0xfc, 0x3e, 0x0e, 0xc5, 0xaa, 0xad, 0xac, 0xcf, 0xfe,
-	0x05, 0x00, 0x00, 0xff, 0xff, 0xad, 0x09, 0x3a, 0xe9, 0x90, 0x03, 0x00, 0x00,
+	// 560 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0x4f, 0xaf, 0xd2, 0x4c,
+	0x14, 0xc6, 0x99, 0xc2, 0xa5, 0xf4, 0x70, 0xe1, 0xe5, 0x3d, 0x31, 0x5a, 0x49, 0x04, 0x82, 0x0b,
+	0x49, 0x34, 0x25, 0xc1, 0xad, 0x2e, 0xca, 0x95, 0xe8, 0x42, 0xae, 0x5a, 0x31, 0x71, 0x47, 0x4a,
+	0x3b, 0x40, 0x93, 0xdb, 0x4e, 0xd3, 0x0e, 0x89, 0x5d, 0xe9, 0x47, 0x70, 0xe9, 0xda, 0x4f, 0xc3,
+	0xd2, 0x85, 0x6b, 0xff, 0xf0, 0x49, 0x4c, 0x67, 0xa6, 0xd5, 0x2b, 0x71, 0xc1, 0xae, 0xe7, 0x9c,
+	0xe7, 0x99, 0x73, 0xe6, 0x77, 0xa6, 0xd0, 0x4a, 0x83, 0x2b, 0x1a, 0x79, 0xd4, 0x8a, 0x13, 0xc6,
+	0x19, 0x1a, 0x2a, 0x8c, 0x57, 0xdd, 0xde, 0x86, 0xb1, 0xcd, 0x15, 0x1d, 0x8b, 0xc2, 0x6a, 0xb7,
+	0x1e, 0xfb, 0xbb, 0xc4, 0xe5, 0x01, 0x8b, 0xa4, 0xb4, 0xdb, 0xff, 0xbb, 0xce, 0x83, 0x90, 0xa6,
+	0xdc, 0x0d, 0x63, 0x25, 0xb8, 0xb1, 0x61, 0x1b, 0x26, 0x3e, 0xc7, 0xf9, 0x97, 0xcc, 0x0e, 0x3f,
+	0x13, 0xd0, 0xe7, 0x2e, 0xf7, 0xb6, 0x34, 0xc1, 0xfb, 0x50, 0xe3, 0x59, 0x4c, 0x4d, 0x32, 0x20,
+	0xa3, 0xf6, 0xe4, 0x96, 0x55, 0x36, 0xb7, 0x94, 0xc2, 0x5a, 0x64, 0x31, 0x75, 0x84, 0x08, 0x11,
+	0x6a, 0x91, 0x1b, 0x52, 0x53, 0x1b, 0x90, 0x91, 0xe1, 0x88, 0x6f, 0x34, 0x41, 0x8f, 0x5d, 0xce,
+	0x69, 0x12, 0x99, 0x55, 0x91, 0x2e, 0xc2, 0xe1, 0x23, 0xa8, 0xe5, 0x5e, 0x34, 0xe0, 0x6c, 0xf6,
+	0xea, 0x8d, 0xfd, 0xbc, 0x53, 0x41, 0x80, 0xba, 0x33, 0x7b, 0x3a, 0x7b, 0xfb, 0xb2, 0x43, 0xb0,
+	0x05, 0xc6, 0xe5, 0x8b, 0xc5, 0x52, 0x96, 0x34, 0x6c, 0x03, 0xe4, 0xa1, 0x2a, 0x57, 0x87, 0xef,
+	0x41, 0xbf, 0x60, 0x61, 0x48, 0x23, 0x8e, 0x37, 0xa1, 0xee, 0xee, 0xf8, 0x96, 0x25, 0x62, 0x4a,
+	0xc3, 0x51, 0x51, 0xde, 0xda, 0x93, 0x12, 0x35, 0x51, 0x11, 0xe2, 0x14, 0x8c, 0x12, 0x85, 0x18,
+	0xab, 0x39, 0xe9, 0x5a, 0x12, 0x96, 0x55, 0xc0, 0xb2, 0x16, 0x85, 0x62, 0xda, 0xd8, 0x7f, 0xeb,
+	0x57, 0x3e, 0x7e, 0xef, 0x13, 0xe7, 0xb7, 0x6d, 0xf8, 0xb5, 0x06, 0xfa, 0x6b, 0x49, 0x03, 0xdb,
+	0xa0, 0x05, 0xbe, 0xea, 0xae, 0x05, 0x3e, 0x5a, 0xd0, 0x08, 0x25, 0x9e, 0xd4, 0xd4, 0x06, 0xd5,
+	0x51, 0x73, 0x82, 0xc7, 0xe4, 0x9c, 0x52, 0x83, 0x36, 0x18, 0x29, 0x77, 0x13, 0x9e, 0x2e, 0x5d,
+	0x7e, 0xd2, 0x3c, 0x0d, 0x69, 0xb3, 0x39, 0x3e, 0x06, 0x9d, 0x46, 0xbe, 0x38, 0xa0, 0x76, 0xc2,
+	0x01, 0xf5, 0xdc, 0x64, 0x73, 0xbc, 0x00, 0xd8, 0xc5, 0xbe, 0xcb, 0xa9, 0x9f, 0x9f, 0x70, 0x76,
+	0x0a, 0x12, 0xe5, 0xb3, 0x79, 0x7e, 0x6d, 0x45, 0x38, 0x35, 0xf5, 0xa3, 0x6b, 0xab, 0x75, 0x39,
+	0xa5, 0x06, 0xef, 0x00, 0x78, 0x09, 0x15, 0x4d, 0x57, 0x99, 0xd9, 0x10, 0xf8, 0x0c, 0x95, 0x99,
+	0x66, 0x7f, 0xee, 0xcf, 0xb8, 0xbe, 0x3f, 0x1b, 0xd0, 0x63, 0x91, 0x1f, 0xe4, 0x6f, 0x7d, 0x59,
+	0x92, 0x86, 0x7f, 0x92, 0xfe, 0xbf, 0x54, 0xcf, 0x0b, 0xe4, 0x77, 0xa1, 0x15, 0x31, 0x1e, 0xac,
+	0xb3, 0x25, 0x7d, 0x17, 0x07, 0x49, 0x66, 0x36, 0x07, 0x64, 0xd4, 0x70, 0xce, 0x65, 0x72, 0x26,
+	0x72, 0x78, 0x0f, 0xfe, 0x93, 0xd5, 0xa5, 0x48, 0x07, 0xd4, 0x37, 0xcf, 0x85, 0xac, 0x2d, 0xd3,
+	0x97, 0x2a, 0x8b, 0xcf, 0xca, 0xd3, 0x56, 0x74, 0xcd, 0x12, 0x6a, 0xb6, 0x04, 0xc1, 0xdb, 0x47,
+	0x04, 0x9f, 0xa8, 0x3f, 0x54, 0x02, 0xfc, 0x94, 0x03, 0x54, 0x2d, 0xa7, 0xc2, 0x38, 0xfc, 0x40,
+	0xa0, 0x39, 0xa7, 0xe9, 0xb6, 0x78, 0x5a, 0x0f, 0x40, 0x57, 0xf7, 0x11, 0xef, 0xeb, 0xfa, 0xfd,
+	0x94, 0xc8, 0x29, 0x24, 0xf9, 0x1a, 0xc5, 0x64, 0x54, 0x3c, 0x04, 0xed, 0x94, 0x35, 0x2a, 0x9f,
+	0xcd, 0xa7, 0x9d, 0xfd, 0xcf, 0x5e, 0x65, 0x7f, 0xe8, 0x91, 0x2f, 0x87, 0x1e, 0xf9, 0x71, 0xe8,
+	0x91, 0x55, 0x5d, 0x58, 0x1f, 0xfe, 0x1a, 0x00, 0xa5, 0x74, 0x36, 0x5d, 0x8b, 0x04, 0x00, 0x00,
 }
 
 func (m *Matcher) Marshal() (dAtA []byte, err error) {
@@ -411,6 +428,48 @@ func (m *Silence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.NotifyBefore, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.NotifyBefore):])
+	if err2 != nil {
+		return 0, err2
+	}
+	i -= n2
+	i = encodeVarintSilence(dAtA, i, uint64(n2))
+	i--
+	dAtA[i] = 0x6a
+	if m.ExpiryNotified {
+		i--
+		if m.ExpiryNotified {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.NotifyExpiry {
+		i--
+		if m.NotifyExpiry {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x58
+	}
+	if len(m.ConditionMatchers) > 0 {
+		for iNdEx := len(m.ConditionMatchers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ConditionMatchers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSilence(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x52
+		}
+	}
 	if len(m.Comment) > 0 {
 		i -= len(m.Comment)
 		copy(dAtA[i:], m.Comment)
@@ -439,29 +498,29 @@ func (m *Silence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 			dAtA[i] = 0x3a
 		}
 	}
-	n2, err2 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.UpdatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.UpdatedAt):])
-	if err2 != nil {
-		return 0, err2
-	}
-	i -= n2
-	i = encodeVarintSilence(dAtA, i, uint64(n2))
-	i--
-	dAtA[i] = 0x2a
-	n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.EndsAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.EndsAt):])
+	n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.UpdatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.UpdatedAt):])
 	if err3 != nil {
 		return 0, err3
 	}
 	i -= n3
 	i = encodeVarintSilence(dAtA, i, uint64(n3))
 	i--
-	dAtA[i] = 0x22
-	n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartsAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.StartsAt):])
+	dAtA[i] = 0x2a
+	n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.EndsAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.EndsAt):])
 	if err4 != nil {
 		return 0, err4
 	}
 	i -= n4
 	i = encodeVarintSilence(dAtA, i, uint64(n4))
 	i--
+	dAtA[i] = 0x22
+	n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartsAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.StartsAt):])
+	if err5 != nil {
+		return 0, err5
+	}
+	i -= n5
+	i = encodeVarintSilence(dAtA, i, uint64(n5))
+	i--
 	dAtA[i] = 0x1a
 	if len(m.Matchers) > 0 {
 		for iNdEx := len(m.Matchers) - 1; iNdEx >= 0; iNdEx-- {
@@ -511,12 +570,12 @@ func (m *MeshSilence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.ExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.ExpiresAt):])
-	if err5 != nil {
-		return 0, err5
+	n6, err6 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.ExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.ExpiresAt):])
+	if err6 != nil {
+		return 0, err6
 	}
-	i -= n5
-	i = encodeVarintSilence(dAtA, i, uint64(n5))
+	i -= n6
+	i = encodeVarintSilence(dAtA, i, uint64(n6))
 	i--
 	dAtA[i] = 0x12
 	if m.Silence != nil {
@@ -626,6 +685,20 @@ func (m *Silence) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovSilence(uint64(l))
 	}
+	if len(m.ConditionMatchers) > 0 {
+		for _, e := range m.ConditionMatchers {
+			l = e.Size()
+			n += 1 + l + sovSilence(uint64(l))
+		}
+	}
+	if m.NotifyExpiry {
+		n += 2
+	}
+	if m.ExpiryNotified {
+		n += 2
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.NotifyBefore)
+	n += 1 + l + sovSilence(uint64(l))
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1230,6 +1303,113 @@ func (m *Silence) Unmarshal(dAtA []byte) error {
 			}
 			m.Comment = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConditionMatchers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSilence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSilence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSilence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ConditionMatchers = append(m.ConditionMatchers, &Matcher{})
+			if err := m.ConditionMatchers[len(m.ConditionMatchers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NotifyExpiry", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSilence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.NotifyExpiry = bool(v != 0)
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiryNotified", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSilence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ExpiryNotified = bool(v != 0)
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NotifyBefore", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSilence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSilence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSilence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.NotifyBefore, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSilence(dAtA[iNdEx:])
@@ -0,0 +1,96 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// Recent remembers the recently received updates of alerts to detect
+// duplicate updates. It holds at most a fixed number of alerts and forgets
+// the least recently updated alerts first.
+type Recent struct {
+	mtx      sync.Mutex
+	capacity int
+	entries  map[model.Fingerprint]*list.Element
+	order    *list.List
+}
+
+type recentEntry struct {
+	fp          model.Fingerprint
+	annotations model.Fingerprint
+	at          time.Time
+}
+
+// NewRecent returns a new Recent holding at most capacity alerts.
+func NewRecent(capacity int) *Recent {
+	return &Recent{
+		capacity: capacity,
+		entries:  make(map[model.Fingerprint]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Duplicate returns true if an update of the alert with the same labels and
+// annotations was recorded less than window before now. Otherwise the update
+// is recorded. Duplicates don't extend the window, so an update of an alert
+// is let through at least once per window. Resolved alerts are never
+// duplicates.
+func (r *Recent) Duplicate(a *types.Alert, now time.Time, window time.Duration) bool {
+	fp := a.Fingerprint()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if a.ResolvedAt(now) {
+		if e, ok := r.entries[fp]; ok {
+			r.order.Remove(e)
+			delete(r.entries, fp)
+		}
+		return false
+	}
+
+	annotations := a.Annotations.Fingerprint()
+	if e, ok := r.entries[fp]; ok {
+		ent := e.Value.(*recentEntry)
+		if ent.annotations == annotations && now.Sub(ent.at) < window {
+			return true
+		}
+		ent.annotations = annotations
+		ent.at = now
+		r.order.MoveToFront(e)
+		return false
+	}
+
+	r.entries[fp] = r.order.PushFront(&recentEntry{fp: fp, annotations: annotations, at: now})
+	for r.order.Len() > r.capacity {
+		e := r.order.Back()
+		r.order.Remove(e)
+		delete(r.entries, e.Value.(*recentEntry).fp)
+	}
+	return false
+}
+
+// Len returns the number of alerts held.
+func (r *Recent) Len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return r.order.Len()
+}
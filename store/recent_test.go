@@ -0,0 +1,84 @@
+// Copyright 2021 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentDuplicate(t *testing.T) {
+	now := time.Now()
+	window := time.Minute
+	newAlert := func(summary string) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "a"},
+				Annotations: model.LabelSet{"summary": model.LabelValue(summary)},
+				StartsAt:    now.Add(-time.Hour),
+				EndsAt:      now.Add(time.Hour),
+			},
+		}
+	}
+
+	r := NewRecent(10)
+	require.False(t, r.Duplicate(newAlert("foo"), now, window))
+
+	// Identical updates within the window are duplicates.
+	require.True(t, r.Duplicate(newAlert("foo"), now.Add(time.Second), window))
+	require.True(t, r.Duplicate(newAlert("foo"), now.Add(window-time.Second), window))
+
+	// Duplicates don't extend the window.
+	require.False(t, r.Duplicate(newAlert("foo"), now.Add(window), window))
+	require.True(t, r.Duplicate(newAlert("foo"), now.Add(window+time.Second), window))
+
+	// Changed annotations are no duplicates.
+	require.False(t, r.Duplicate(newAlert("bar"), now.Add(window+2*time.Second), window))
+	require.True(t, r.Duplicate(newAlert("bar"), now.Add(window+3*time.Second), window))
+
+	// Resolved alerts are no duplicates and are forgotten.
+	resolved := newAlert("bar")
+	resolved.EndsAt = now
+	require.False(t, r.Duplicate(resolved, now.Add(window+4*time.Second), window))
+	require.False(t, r.Duplicate(resolved, now.Add(window+5*time.Second), window))
+	require.Equal(t, 0, r.Len())
+	require.False(t, r.Duplicate(newAlert("bar"), now.Add(window+6*time.Second), window))
+}
+
+func TestRecentCapacity(t *testing.T) {
+	now := time.Now()
+	newAlert := func(name string) *types.Alert {
+		return &types.Alert{
+			Alert: model.Alert{
+				Labels:   model.LabelSet{"alertname": model.LabelValue(name)},
+				StartsAt: now,
+			},
+		}
+	}
+
+	r := NewRecent(2)
+	require.False(t, r.Duplicate(newAlert("a"), now, time.Minute))
+	require.False(t, r.Duplicate(newAlert("b"), now, time.Minute))
+	require.False(t, r.Duplicate(newAlert("c"), now, time.Minute))
+	require.Equal(t, 2, r.Len())
+
+	// The least recently updated alert is forgotten first.
+	require.True(t, r.Duplicate(newAlert("c"), now, time.Minute))
+	require.True(t, r.Duplicate(newAlert("b"), now, time.Minute))
+	require.False(t, r.Duplicate(newAlert("a"), now, time.Minute))
+}
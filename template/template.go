@@ -15,6 +15,7 @@ package template
 
 import (
 	"bytes"
+	"fmt"
 	tmplhtml "html/template"
 	"io/ioutil"
 	"net/url"
@@ -23,6 +24,7 @@ import (
 	"sort"
 	"strings"
 	tmpltext "text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -36,6 +38,11 @@ type Template struct {
 	text *tmpltext.Template
 	html *tmplhtml.Template
 
+	// allowedFuncs restricts the functions that templates passed to
+	// ExecuteTextString and ExecuteHTMLString may call. A nil map means
+	// that all functions are allowed.
+	allowedFuncs map[string]struct{}
+
 	ExternalURL *url.URL
 }
 
@@ -86,11 +93,174 @@ func FromGlobs(paths ...string) (*Template, error) {
 	return t, nil
 }
 
+// Restrict returns a copy of the template which only allows the given
+// functions of DefaultFuncs to be called by the templates passed to
+// ExecuteTextString and ExecuteHTMLString. The functions built into the
+// text/template package are always allowed. Named templates that have been
+// loaded from files are not subject to the restriction.
+func (t *Template) Restrict(allowed []string) (*Template, error) {
+	funcs := make(map[string]struct{}, len(allowed))
+	for _, name := range allowed {
+		if _, ok := DefaultFuncs[name]; !ok {
+			return nil, fmt.Errorf("unknown template function %q", name)
+		}
+		funcs[name] = struct{}{}
+	}
+	nt := *t
+	nt.allowedFuncs = funcs
+	return &nt, nil
+}
+
+// CheckFuncs returns an error if the given template text calls a function
+// which isn't allowed by the template.
+func (t *Template) CheckFuncs(text string) error {
+	if t.allowedFuncs == nil || text == "" {
+		return nil
+	}
+	tmpl, err := tmpltext.New("").Funcs(tmpltext.FuncMap(DefaultFuncs)).Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, tt := range tmpl.Templates() {
+		if tt.Tree == nil {
+			continue
+		}
+		if err := t.checkNode(tt.Tree.Root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckText returns an error if the given template text can't be parsed,
+// calls a function which isn't allowed by the template or calls a named
+// template which isn't defined.
+func (t *Template) CheckText(text string) error {
+	if text == "" {
+		return nil
+	}
+	if err := t.CheckFuncs(text); err != nil {
+		return err
+	}
+	tmpl, err := t.text.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl, err = tmpl.New("").Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, tt := range tmpl.Templates() {
+		if tt.Tree == nil {
+			continue
+		}
+		if err := checkTemplateRefs(tt.Tree.Root, tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplateRefs returns an error if the given node calls a named
+// template which isn't defined in tmpl.
+func checkTemplateRefs(node parse.Node, tmpl *tmpltext.Template) error {
+	var lists []*parse.ListNode
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkTemplateRefs(c, tmpl); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		lists = append(lists, n.List, n.ElseList)
+	case *parse.RangeNode:
+		lists = append(lists, n.List, n.ElseList)
+	case *parse.WithNode:
+		lists = append(lists, n.List, n.ElseList)
+	case *parse.TemplateNode:
+		if tt := tmpl.Lookup(n.Name); tt == nil || tt.Tree == nil {
+			return fmt.Errorf("template %q is not defined", n.Name)
+		}
+	}
+	for _, l := range lists {
+		if err := checkTemplateRefs(l, tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Template) checkNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := t.checkNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return t.checkNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := t.checkNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if err := t.checkNode(a); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return t.checkNode(n.Node)
+	case *parse.IfNode:
+		return t.checkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return t.checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return t.checkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return t.checkNode(n.Pipe)
+	case *parse.IdentifierNode:
+		if _, ok := DefaultFuncs[n.Ident]; !ok {
+			// Functions built into text/template are always allowed.
+			return nil
+		}
+		if _, ok := t.allowedFuncs[n.Ident]; !ok {
+			return fmt.Errorf("template function %q is not allowed", n.Ident)
+		}
+	}
+	return nil
+}
+
+func (t *Template) checkBranch(n *parse.BranchNode) error {
+	for _, c := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := t.checkNode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ExecuteTextString needs a meaningful doc comment (TODO(fabxc)).
 func (t *Template) ExecuteTextString(text string, data interface{}) (string, error) {
 	if text == "" {
 		return "", nil
 	}
+	if err := t.CheckFuncs(text); err != nil {
+		return "", err
+	}
 	tmpl, err := t.text.Clone()
 	if err != nil {
 		return "", err
@@ -109,6 +279,9 @@ func (t *Template) ExecuteHTMLString(html string, data interface{}) (string, err
 	if html == "" {
 		return "", nil
 	}
+	if err := t.CheckFuncs(html); err != nil {
+		return "", err
+	}
 	tmpl, err := t.html.Clone()
 	if err != nil {
 		return "", err
@@ -387,3 +387,105 @@ func TestTemplateExpansion(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateRestrict(t *testing.T) {
+	tmpl, err := FromGlobs()
+	require.NoError(t, err)
+
+	_, err = tmpl.Restrict([]string{"notAFunction"})
+	require.Error(t, err)
+
+	restricted, err := tmpl.Restrict([]string{"toUpper"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		title string
+		in    string
+		html  bool
+
+		exp  string
+		fail bool
+	}{
+		{
+			title: "Template without function",
+			in:    `{{ "abc" }}`,
+			exp:   "abc",
+		},
+		{
+			title: "Template using allowed function",
+			in:    `{{ "abc" | toUpper }}`,
+			exp:   "ABC",
+		},
+		{
+			title: "Template using built-in function",
+			in:    `{{ printf "%s-%d" "abc" (len "abc") }}`,
+			exp:   "abc-3",
+		},
+		{
+			title: "Template using disallowed function",
+			in:    `{{ "ABC" | toLower }}`,
+			fail:  true,
+		},
+		{
+			title: "Template using disallowed function in a branch",
+			in:    `{{ if true }}{{ else }}{{ reReplaceAll "a" "b" "abc" }}{{ end }}`,
+			fail:  true,
+		},
+		{
+			title: "Template using disallowed function in a definition",
+			in:    `{{ define "foo" }}{{ "abc" | title }}{{ end }}abc`,
+			fail:  true,
+		},
+		{
+			title: "HTML template using disallowed function",
+			in:    `{{ "<b>" | safeHtml }}`,
+			html:  true,
+			fail:  true,
+		},
+		{
+			title: "Template calling a named template",
+			in:    `{{ template "__subject" . }}`,
+			exp:   "[]  ",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			require.Equal(t, tc.fail, restricted.CheckFuncs(tc.in) != nil)
+
+			f := restricted.ExecuteTextString
+			if tc.html {
+				f = restricted.ExecuteHTMLString
+			}
+			got, err := f(tc.in, Data{})
+			if tc.fail {
+				require.Error(t, err)
+				// The unrestricted template is unaffected.
+				require.NoError(t, tmpl.CheckFuncs(tc.in))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, got)
+		})
+	}
+}
+
+func TestTemplateCheckText(t *testing.T) {
+	tmpl, err := FromGlobs()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		in   string
+		fail bool
+	}{
+		{in: ``},
+		{in: `{{ .Status }}`},
+		{in: `{{ template "slack.default.title" . }}`},
+		{in: `{{ define "foo" }}abc{{ end }}{{ if true }}{{ template "foo" . }}{{ end }}`},
+		{in: `{{ template "undefined" . }}`, fail: true},
+		{in: `{{ range .Alerts }}{{ else }}{{ template "undefined" . }}{{ end }}`, fail: true},
+		{in: `{{ .Status`, fail: true},
+	} {
+		err := tmpl.CheckText(tc.in)
+		require.Equal(t, tc.fail, err != nil, "%q: %v", tc.in, err)
+	}
+}
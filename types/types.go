@@ -391,6 +391,11 @@ type Silence struct {
 	// A set of matchers determining if a label set is affected
 	// by the silence.
 	Matchers labels.Matchers `json:"matchers"`
+	// A set of matchers of which at least one firing alert, other than
+	// the affected one, must match all for the silence to take effect.
+	// The silence is still reported as active while no such alert is
+	// present.
+	ConditionMatchers labels.Matchers `json:"conditionMatchers,omitempty"`
 
 	// Time range of the silence.
 	//
@@ -411,6 +416,12 @@ type Silence struct {
 	CreatedBy string `json:"createdBy"`
 	Comment   string `json:"comment,omitempty"`
 
+	// Whether a notification is sent shortly before the silence expires.
+	NotifyExpiry bool `json:"notifyExpiry,omitempty"`
+	// How long before the silence expires the notification is sent. Zero
+	// uses the lead time of the configuration.
+	NotifyBefore model.Duration `json:"notifyBefore,omitempty"`
+
 	Status SilenceStatus `json:"status"`
 }
 
@@ -423,6 +434,8 @@ func (s *Silence) Expired() bool {
 // SilenceStatus stores the state of a silence.
 type SilenceStatus struct {
 	State SilenceState `json:"state"`
+	// StateReason explains the state in words, such as "starts in 2h".
+	StateReason string `json:"stateReason,omitempty"`
 }
 
 // SilenceState is used as part of SilenceStatus.
@@ -447,3 +460,19 @@ func CalcSilenceState(start, end time.Time) SilenceState {
 	}
 	return SilenceStateExpired
 }
+
+// CalcSilenceStateReason returns a human-readable explanation of the state
+// that a silence with the given start and end time has at the given time,
+// such as "starts in 2h" or "expired 1h ago".
+func CalcSilenceStateReason(start, end, now time.Time) string {
+	since := func(d time.Duration) string {
+		return model.Duration(d.Round(time.Second)).String()
+	}
+	if now.Before(start) {
+		return "starts in " + since(start.Sub(now))
+	}
+	if now.Before(end) {
+		return "ends in " + since(end.Sub(now))
+	}
+	return "expired " + since(now.Sub(end)) + " ago"
+}
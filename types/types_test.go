@@ -281,6 +281,17 @@ func TestCalcSilenceState(t *testing.T) {
 	require.Equal(t, SilenceStateExpired, expected)
 }
 
+func TestCalcSilenceStateReason(t *testing.T) {
+	now := time.Now()
+
+	require.Equal(t, "starts in 2h", CalcSilenceStateReason(now.Add(2*time.Hour), now.Add(3*time.Hour), now))
+	require.Equal(t, "ends in 1h30m", CalcSilenceStateReason(now.Add(-time.Hour), now.Add(90*time.Minute), now))
+	require.Equal(t, "expired 1h ago", CalcSilenceStateReason(now.Add(-2*time.Hour), now.Add(-time.Hour), now))
+	// Durations are rounded to seconds.
+	require.Equal(t, "ends in 5s", CalcSilenceStateReason(now, now.Add(5*time.Second+400*time.Millisecond), now))
+	require.Equal(t, "expired 0s ago", CalcSilenceStateReason(now, now, now))
+}
+
 func TestSilenceExpired(t *testing.T) {
 	now := time.Now()
 	silence := Silence{StartsAt: now, EndsAt: now}
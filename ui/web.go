@@ -14,6 +14,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	_ "net/http/pprof" // Comment this line to disable pprof endpoint.
@@ -24,10 +25,12 @@ import (
 	"github.com/prometheus/common/route"
 
 	"github.com/prometheus/alertmanager/asset"
+	"github.com/prometheus/alertmanager/config"
 )
 
-// Register registers handlers to serve files for the web interface.
-func Register(r *route.Router, reloadCh chan<- chan error, logger log.Logger) {
+// Register registers handlers to serve files for the web interface. The
+// readiness endpoint reports the result of ready, which must not block.
+func Register(r *route.Router, reloadCh chan<- chan error, ready func() bool, logger log.Logger) {
 	r.Get("/metrics", promhttp.Handler().ServeHTTP)
 
 	r.Get("/", func(w http.ResponseWriter, req *http.Request) {
@@ -68,7 +71,13 @@ func Register(r *route.Router, reloadCh chan<- chan error, logger log.Logger) {
 
 		reloadCh <- errc
 		if err := <-errc; err != nil {
-			http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+			// The running configuration is kept if the file is invalid.
+			status := http.StatusInternalServerError
+			var le *config.LoadError
+			if errors.As(err, &le) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, fmt.Sprintf("failed to reload config: %s", err), status)
 		}
 	}))
 
@@ -77,6 +86,11 @@ func Register(r *route.Router, reloadCh chan<- chan error, logger log.Logger) {
 		fmt.Fprintf(w, "OK")
 	}))
 	r.Get("/-/ready", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "Not Ready")
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, "OK")
 	}))